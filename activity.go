@@ -0,0 +1,138 @@
+package evdev
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DeviceActivity summarizes the activity of one device over a period.
+type DeviceActivity struct {
+	Device          string        `json:"device"`
+	Events          int           `json:"events"`
+	EventsPerMinute float64       `json:"events_per_minute"`
+	Active          time.Duration `json:"active_ns"`
+	Idle            time.Duration `json:"idle_ns"`
+}
+
+// ActivitySummary summarizes the activity of all devices over a period.
+type ActivitySummary struct {
+	Start   time.Time        `json:"start"`
+	End     time.Time        `json:"end"`
+	Devices []DeviceActivity `json:"devices"`
+}
+
+// ActivityTracker produces periodic input activity summaries: event rates per
+// device and how long each device was in active use. It is a lightweight
+// alternative to full metrics integration for desktop utilities. It is safe
+// for concurrent use.
+type ActivityTracker struct {
+	// A device counts as active from an event until the next one, unless
+	// the gap between them is longer than IdleAfter.
+	IdleAfter time.Duration
+
+	mu      sync.Mutex
+	start   time.Time
+	devices map[string]*deviceActivity
+}
+
+type deviceActivity struct {
+	events int
+	active time.Duration
+	last   time.Time
+}
+
+// NewActivityTracker creates a tracker whose first period starts now.
+func NewActivityTracker(idleAfter time.Duration) *ActivityTracker {
+	return &ActivityTracker{
+		IdleAfter: idleAfter,
+		start:     time.Now(),
+		devices:   make(map[string]*deviceActivity),
+	}
+}
+
+// Record counts an event of the named device. Synchronization events are
+// ignored.
+func (t *ActivityTracker) Record(device string, ev *InputEvent) {
+	if ev.Type == EV_SYN {
+		return
+	}
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	d, ok := t.devices[device]
+	if !ok {
+		d = &deviceActivity{}
+		t.devices[device] = d
+	}
+
+	if gap := now.Sub(d.last); !d.last.IsZero() && gap <= t.IdleAfter {
+		d.active += gap
+	}
+	d.events++
+	d.last = now
+}
+
+// Summary returns the summary of the period ending now and starts a new one.
+func (t *ActivityTracker) Summary() ActivitySummary {
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	period := now.Sub(t.start)
+	summary := ActivitySummary{Start: t.start, End: now, Devices: make([]DeviceActivity, 0, len(t.devices))}
+
+	for name, d := range t.devices {
+		active := d.active
+		if active > period {
+			// a gap reaching back into the previous period
+			active = period
+		}
+
+		a := DeviceActivity{Device: name, Events: d.events, Active: active, Idle: period - active}
+		if period > 0 {
+			a.EventsPerMinute = float64(d.events) * float64(time.Minute) / float64(period)
+		}
+		summary.Devices = append(summary.Devices, a)
+
+		// keep the last event time so that activity spanning periods counts
+		d.events, d.active = 0, 0
+	}
+	sort.Slice(summary.Devices, func(i, j int) bool {
+		return summary.Devices[i].Device < summary.Devices[j].Device
+	})
+
+	t.start = now
+	return summary
+}
+
+// Run calls fn with a summary every interval until ctx is done.
+func (t *ActivityTracker) Run(ctx context.Context, interval time.Duration, fn func(ActivitySummary)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			fn(t.Summary())
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// WriteActivityJSON returns a function for Run that writes every summary to
+// w as a line of JSON.
+func WriteActivityJSON(w io.Writer) func(ActivitySummary) {
+	enc := json.NewEncoder(w)
+
+	return func(s ActivitySummary) {
+		enc.Encode(s)
+	}
+}
@@ -0,0 +1,72 @@
+package evdev
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestActivityTracker(t *testing.T) {
+	tr := NewActivityTracker(time.Hour)
+	key := NewInputEvent(time.Now(), EV_KEY, KEY_A, 1)
+	syn := NewInputEvent(time.Now(), EV_SYN, SYN_REPORT, 0)
+
+	for i := 0; i < 3; i++ {
+		tr.Record("kbd", &key)
+		tr.Record("kbd", &syn)
+		time.Sleep(5 * time.Millisecond)
+	}
+	tr.Record("mouse", &key)
+
+	s := tr.Summary()
+	if len(s.Devices) != 2 || s.Devices[0].Device != "kbd" || s.Devices[1].Device != "mouse" {
+		t.Fatalf("unexpected devices %+v", s.Devices)
+	}
+
+	period := s.End.Sub(s.Start)
+	tests := []struct {
+		events    int
+		minActive time.Duration
+	}{
+		{3, 10 * time.Millisecond},
+		{1, 0},
+	}
+	for i, tt := range tests {
+		d := s.Devices[i]
+		if d.Events != tt.events || d.Active < tt.minActive || d.Active+d.Idle != period {
+			t.Errorf("unexpected activity %+v in %v", d, period)
+		}
+		if d.EventsPerMinute <= 0 {
+			t.Errorf("expected an event rate for %s", d.Device)
+		}
+	}
+
+	// a new period starts empty
+	if s2 := tr.Summary(); !s2.Start.Equal(s.End) || s2.Devices[0].Events != 0 {
+		t.Errorf("unexpected next summary %+v", s2)
+	}
+
+	var buf bytes.Buffer
+	WriteActivityJSON(&buf)(s)
+	decoded := ActivitySummary{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || decoded.Devices[0].Events != 3 {
+		t.Errorf("unexpected JSON %s (%v)", buf.Bytes(), err)
+	}
+}
+
+func TestActivityTrackerRun(t *testing.T) {
+	tr := NewActivityTracker(time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	summaries := 0
+	err := tr.Run(ctx, time.Millisecond, func(ActivitySummary) {
+		if summaries++; summaries == 2 {
+			cancel()
+		}
+	})
+	if err != context.Canceled || summaries < 2 {
+		t.Errorf("got %d summaries and %v", summaries, err)
+	}
+}
@@ -0,0 +1,82 @@
+//go:build linux
+
+// Compare the events of two devices frame by frame.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	evdev "github.com/rendyananta/golang-evdev"
+)
+
+const usage = "usage: evdiff [-all] [-timeout duration] [-max-delay duration] <device> <device>"
+
+func formatFrame(frame []evdev.InputEvent) string {
+	if frame == nil {
+		return "(missing)"
+	}
+
+	parts := make([]string, 0, len(frame))
+	for _, ev := range frame {
+		parts = append(parts, fmt.Sprintf("%s %d", evdev.CodeName(int(ev.Type), int(ev.Code)), ev.Value))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func main() {
+	all := flag.Bool("all", false, "print matching frames too")
+	timeout := flag.Duration("timeout", 200*time.Millisecond, "time after which an unpaired frame counts as missing")
+	maxDelay := flag.Duration("max-delay", 0, "report matching frames delayed by more than this, 0 to disable")
+	flag.Usage = func() { fmt.Fprintln(os.Stderr, usage) }
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	devices := make([]*evdev.InputDevice, 2)
+	for i, path := range flag.Args() {
+		dev, err := evdev.Open(path)
+		if err != nil {
+			fmt.Printf("unable to open input device: %s\n", path)
+			os.Exit(1)
+		}
+		devices[i] = dev
+	}
+	fmt.Printf("A: %s (%s)\nB: %s (%s)\n", devices[0].Fn, devices[0].Name, devices[1].Fn, devices[1].Name)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	frames, diffs := 0, 0
+	err := evdev.CompareDevices(ctx, devices[0], devices[1], *timeout, func(c evdev.FrameComparison) {
+		frames++
+
+		late := *maxDelay > 0 && (c.Delay > *maxDelay || c.Delay < -*maxDelay)
+		switch {
+		case c.Equal && !late:
+			if *all {
+				fmt.Printf("=  %10s  %s\n", c.Delay, formatFrame(c.A))
+			}
+			return
+		case c.Equal:
+			fmt.Printf("~  %10s  %s\n", c.Delay, formatFrame(c.A))
+		default:
+			fmt.Printf("-  A: %s\n+  B: %s\n", formatFrame(c.A), formatFrame(c.B))
+		}
+		diffs++
+	})
+	if err != nil && err != context.Canceled {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("%d frames, %d differences\n", frames, diffs)
+}
@@ -0,0 +1,43 @@
+//go:build linux
+
+// Test which key combinations a keyboard can report.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	evdev "github.com/rendyananta/golang-evdev"
+)
+
+const usage = "usage: evrollover <device>"
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(1)
+	}
+
+	dev, err := evdev.Open(os.Args[1])
+	if err != nil {
+		fmt.Printf("unable to open input device: %s\n", os.Args[1])
+		os.Exit(1)
+	}
+	fmt.Printf("testing %s (%s), hold esc to stop early\n", dev.Fn, dev.Name)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	test := evdev.NewRolloverTest(evdev.DefaultRolloverCombos())
+	report, err := dev.RunRolloverTest(ctx, test, func(prompt string) {
+		fmt.Println(prompt)
+	})
+	if err != nil && err != context.Canceled && err != evdev.ErrRolloverAborted {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("\n%s\n", report)
+}
@@ -0,0 +1,407 @@
+//go:build linux
+
+// Interactive shell for poking at input devices.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	evdev "github.com/rendyananta/golang-evdev"
+)
+
+const deviceGlob = "/dev/input/event*"
+
+const help = `commands:
+  list                      list input devices
+  open <id|path>            open a device from the list or by path
+  info                      describe the open device
+  absinfo [axis]            show absolute axis parameters
+  keys | leds | switches    show held keys, lit LEDs or active switches
+  props                     show device properties
+  watch [duration]          print events until the duration elapses or ^C
+  rate [duration]           measure the report rate of a mouse while it moves
+  grab | release            grab or release the open device
+  uinput <name>             create a virtual keyboard and mouse to inject into
+  emit <type> <code> <val>  write an event to the virtual device
+  run <script>              run a script on the virtual device, e.g. "tap ctrl+c"
+  help                      show this help
+  quit                      exit`
+
+type shell struct {
+	paths  []string // of the devices listed last
+	dev    *evdev.InputDevice
+	target *evdev.VirtualSeat
+}
+
+type command func(sh *shell, args []string) error
+
+var commands = map[string]command{
+	"list":     (*shell).list,
+	"open":     (*shell).open,
+	"info":     (*shell).info,
+	"absinfo":  (*shell).absinfo,
+	"keys":     (*shell).keys,
+	"leds":     (*shell).leds,
+	"switches": (*shell).switches,
+	"props":    (*shell).props,
+	"watch":    (*shell).watch,
+	"rate":     (*shell).rate,
+	"grab":     (*shell).grab,
+	"release":  (*shell).release,
+	"uinput":   (*shell).uinput,
+	"emit":     (*shell).emit,
+	"run":      (*shell).run,
+}
+
+var errNoDevice = errors.New("no device open, see open")
+
+func (sh *shell) list(args []string) error {
+	devices, err := evdev.ListInputDevices(deviceGlob)
+	if err != nil {
+		return err
+	}
+	sh.paths = make([]string, 0, len(devices))
+
+	for i, dev := range devices {
+		fmt.Printf("%-3d %-20s %s\n", i, dev.Fn, dev.Name)
+		sh.paths = append(sh.paths, dev.Fn)
+		dev.Close()
+	}
+	return nil
+}
+
+func (sh *shell) open(args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: open <id|path>")
+	}
+
+	path := args[0]
+	if i, err := strconv.Atoi(path); err == nil {
+		if i < 0 || i >= len(sh.paths) {
+			return errors.New("no such device, see list")
+		}
+		path = sh.paths[i]
+	}
+
+	dev, err := evdev.Open(path)
+	if err != nil {
+		return err
+	}
+	if sh.dev != nil {
+		sh.dev.Close()
+	}
+	sh.dev = dev
+
+	fmt.Printf("opened %s (%s)\n", dev.Fn, dev.Name)
+	return nil
+}
+
+func (sh *shell) info(args []string) error {
+	if sh.dev == nil {
+		return errNoDevice
+	}
+
+	fmt.Println(sh.dev)
+	return nil
+}
+
+func (sh *shell) absinfo(args []string) error {
+	if sh.dev == nil {
+		return errNoDevice
+	}
+
+	axes := sh.dev.CodesFor(evdev.EV_ABS)
+	if len(args) == 1 {
+		axis, err := parseCode(evdev.EV_ABS, args[0])
+		if err != nil {
+			return err
+		}
+		axes = []int{axis}
+	}
+
+	for _, axis := range axes {
+		abs, err := sh.dev.AbsInfo(axis)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%-20s value %d, min %d, max %d, fuzz %d, flat %d, resolution %d\n",
+			evdev.CodeName(evdev.EV_ABS, axis), abs.Value, abs.Min, abs.Max, abs.Fuzz, abs.Flat, abs.Resolution)
+	}
+	return nil
+}
+
+func (sh *shell) keys(args []string) error {
+	return sh.state(evdev.EV_KEY, (*evdev.InputDevice).KeyState)
+}
+
+func (sh *shell) leds(args []string) error {
+	return sh.state(evdev.EV_LED, (*evdev.InputDevice).LEDState)
+}
+
+func (sh *shell) switches(args []string) error {
+	return sh.state(evdev.EV_SW, (*evdev.InputDevice).SwitchState)
+}
+
+func (sh *shell) state(evType int, query func(*evdev.InputDevice) (evdev.Bitset, error)) error {
+	if sh.dev == nil {
+		return errNoDevice
+	}
+
+	bits, err := query(sh.dev)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0)
+	for _, code := range bits.Codes() {
+		names = append(names, evdev.CodeName(evType, code))
+	}
+	fmt.Println(strings.Join(names, " "))
+	return nil
+}
+
+func (sh *shell) props(args []string) error {
+	if sh.dev == nil {
+		return errNoDevice
+	}
+
+	names := make([]string, 0)
+	for _, prop := range sh.dev.Properties() {
+		names = append(names, evdev.INPUT_PROP[prop])
+	}
+	fmt.Println(strings.Join(names, " "))
+	return nil
+}
+
+func (sh *shell) watch(args []string) error {
+	if sh.dev == nil {
+		return errNoDevice
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if len(args) == 1 {
+		d, err := time.ParseDuration(args[0])
+		if err != nil {
+			return err
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+
+	for {
+		events, err := sh.dev.ReadContext(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		for i := range events {
+			fmt.Println(formatEvent(&events[i]))
+		}
+	}
+}
+
+func (sh *shell) rate(args []string) error {
+	if sh.dev == nil {
+		return errNoDevice
+	}
+
+	window := 5 * time.Second
+	if len(args) == 1 {
+		d, err := time.ParseDuration(args[0])
+		if err != nil {
+			return err
+		}
+		window = d
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Printf("keep moving the device for %s ...\n", window)
+	r, err := sh.dev.MeasurePollingRate(ctx, window)
+	if err != nil && ctx.Err() == nil {
+		return err
+	}
+	if r.Reports == 0 {
+		return errors.New("no movement reported")
+	}
+
+	fmt.Println(r)
+	fmt.Printf("intervals %s to %s\n", r.MinInterval, r.MaxInterval)
+	return nil
+}
+
+func (sh *shell) grab(args []string) error {
+	if sh.dev == nil {
+		return errNoDevice
+	}
+	return sh.dev.Grab()
+}
+
+func (sh *shell) release(args []string) error {
+	if sh.dev == nil {
+		return errNoDevice
+	}
+	return sh.dev.Release()
+}
+
+func (sh *shell) uinput(args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: uinput <name>")
+	}
+
+	target, err := evdev.CreateSeat(args[0], true)
+	if err != nil {
+		return err
+	}
+	if sh.target != nil {
+		sh.target.Close()
+	}
+	sh.target = target
+
+	// the device node appears asynchronously
+	time.Sleep(100 * time.Millisecond)
+	if devnode, err := target.Keyboard.Devnode(); err == nil {
+		fmt.Printf("created %s\n", devnode)
+	}
+	return nil
+}
+
+func (sh *shell) emit(args []string) error {
+	if sh.target == nil {
+		return errors.New("no virtual device, see uinput")
+	}
+	if len(args) != 3 {
+		return errors.New("usage: emit <type> <code> <value>")
+	}
+
+	evType, err := parseType(args[0])
+	if err != nil {
+		return err
+	}
+	code, err := parseCode(evType, args[1])
+	if err != nil {
+		return err
+	}
+	value, err := strconv.Atoi(args[2])
+	if err != nil {
+		return err
+	}
+
+	return sh.target.Emit(evdev.EvType(evType), evdev.EvCode(code), evdev.EvValue(value))
+}
+
+func (sh *shell) run(args []string) error {
+	if sh.target == nil {
+		return errors.New("no virtual device, see uinput")
+	}
+
+	script, err := evdev.ParseScript(args[0])
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	return script.Run(ctx, sh.target)
+}
+
+// Parse an event type given by name or number.
+func parseType(s string) (int, error) {
+	return parseName(evdev.EV, s)
+}
+
+// Parse a code of an event type given by name or number.
+func parseCode(evType int, s string) (int, error) {
+	return parseName(evdev.ByEventType[evType], s)
+}
+
+func parseName(names map[int]string, s string) (int, error) {
+	if n, err := strconv.ParseInt(s, 0, 32); err == nil {
+		return int(n), nil
+	}
+
+	for code, name := range names {
+		if strings.EqualFold(name, s) {
+			return code, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown name %q", s)
+}
+
+func formatEvent(ev *evdev.InputEvent) string {
+	etype, code := int(ev.Type), int(ev.Code)
+
+	if ev.Type == evdev.EV_SYN {
+		return fmt.Sprintf("time %d.%-8d --------- %s --------", ev.Time.Sec, ev.Time.Usec, evdev.CodeName(etype, code))
+	}
+
+	return fmt.Sprintf("time %d.%-8d %-8s %-20s %d", ev.Time.Sec, ev.Time.Usec,
+		evdev.TypeName(etype), evdev.CodeName(etype, code), ev.Value)
+}
+
+func main() {
+	sh := &shell{}
+	defer func() {
+		if sh.target != nil {
+			sh.target.Close()
+		}
+	}()
+
+	if len(os.Args) == 2 {
+		if err := sh.open(os.Args[1:]); err != nil {
+			fmt.Println(err)
+		}
+	}
+
+	in := bufio.NewScanner(os.Stdin)
+	for {
+		fmt.Print("evdev> ")
+		if !in.Scan() {
+			fmt.Println()
+			return
+		}
+
+		fields := strings.Fields(in.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch name := fields[0]; name {
+		case "quit", "exit":
+			return
+		case "help":
+			fmt.Println(help)
+		default:
+			cmd, ok := commands[name]
+			if !ok {
+				fmt.Printf("unknown command %q, see help\n", name)
+				continue
+			}
+			args := fields[1:]
+			if name == "run" {
+				// scripts keep their spacing, e.g. in type "a  b"
+				args = []string{strings.TrimSpace(in.Text())[len(name):]}
+			}
+			if err := cmd(sh, args); err != nil {
+				fmt.Println(err)
+			}
+		}
+	}
+}
@@ -17,22 +17,36 @@ const (
 	deviceGlob = "/dev/input/event*"
 )
 
+// Device labels are read from the file named by this environment variable.
+const labelsEnv = "EVDEV_LABELS"
+
+// loadLabels Load the device labels file, if one is configured.
+func loadLabels() evdev.DeviceLabels {
+	labels, err := evdev.LoadDeviceLabels(os.Getenv(labelsEnv))
+	if err != nil {
+		fmt.Printf("unable to load device labels: %s\n", err)
+	}
+
+	return labels
+}
+
 // selectDevice Select a device from a list of accessible input devices.
 func selectDevice() (*evdev.InputDevice, error) {
 	devices, _ := evdev.ListInputDevices(deviceGlob)
+	loadLabels().Apply(devices...)
 
 	lines := make([]string, 0)
 	max := 0
 	if len(devices) > 0 {
 		for i := range devices {
 			dev := devices[i]
-			str := fmt.Sprintf("%-3d %-20s %-35s %-35s %s", i, dev.Fn, dev.Name, dev.Phys, dev.Ident)
+			str := fmt.Sprintf("%-3d %-20s %-35s %-35s %-20s %s", i, dev.Fn, dev.Name, dev.Phys, dev.Ident, dev.Label)
 			if len(str) > max {
 				max = len(str)
 			}
 			lines = append(lines, str)
 		}
-		fmt.Printf("%-3s %-20s %-35s %-35s %s\n", "ID", "Device", "Name", "Phys", "Ident")
+		fmt.Printf("%-3s %-20s %-35s %-35s %-20s %s\n", "ID", "Device", "Name", "Phys", "Ident", "Label")
 		fmt.Printf(strings.Repeat("-", max) + "\n")
 		fmt.Printf(strings.Join(lines, "\n") + "\n")
 
@@ -54,43 +68,23 @@ func selectDevice() (*evdev.InputDevice, error) {
 }
 
 func formatEvent(ev *evdev.InputEvent) string {
-	var res, f, codeName string
+	var res, f string
 
 	code := int(ev.Code)
 	etype := int(ev.Type)
 
-	switch ev.Type {
-	case evdev.EV_SYN:
+	if ev.Type == evdev.EV_SYN {
 		if ev.Code == evdev.SYN_MT_REPORT {
 			f = "time %d.%-8d +++++++++ %s ++++++++"
 		} else {
 			f = "time %d.%-8d --------- %s --------"
 		}
-		return fmt.Sprintf(f, ev.Time.Sec, ev.Time.Usec, evdev.SYN[code])
-	case evdev.EV_KEY:
-		val, haskey := evdev.KEY[code]
-		if haskey {
-			codeName = val
-		} else {
-			val, haskey := evdev.BTN[code]
-			if haskey {
-				codeName = val
-			} else {
-				codeName = "?"
-			}
-		}
-	default:
-		m, haskey := evdev.ByEventType[etype]
-		if haskey {
-			codeName = m[code]
-		} else {
-			codeName = "?"
-		}
+		return fmt.Sprintf(f, ev.Time.Sec, ev.Time.Usec, evdev.CodeName(etype, code))
 	}
 
 	evfmt := "time %d.%-8d type %d (%s), code %-3d (%s), value %d"
 	res = fmt.Sprintf(evfmt, ev.Time.Sec, ev.Time.Usec, etype,
-		evdev.EV[int(ev.Type)], ev.Code, codeName, ev.Value)
+		evdev.TypeName(etype), ev.Code, evdev.CodeName(etype, code), ev.Value)
 
 	return res
 }
@@ -113,6 +107,7 @@ func main() {
 			fmt.Printf("unable to open input device: %s\n", os.Args[1])
 			os.Exit(1)
 		}
+		loadLabels().Apply(dev)
 	default:
 		fmt.Printf(usage + "\n")
 		os.Exit(1)
@@ -121,13 +116,26 @@ func main() {
 	info := fmt.Sprintf("bus 0x%04x, vendor 0x%04x, product 0x%04x, version 0x%04x",
 		dev.BusType, dev.Vendor, dev.Product, dev.Version)
 
-	repeatInfo := dev.GetRepeatRate()
+	repeatInfo, _ := dev.RepeatSettings()
 
 	fmt.Printf("Evdev protocol version: %d\n", dev.EvdevVersion)
 	fmt.Printf("Device name: %s\n", dev.Name)
+	if dev.Label != "" {
+		fmt.Printf("Device label: %s\n", dev.Label)
+	}
 	fmt.Printf("Device info: %s\n", info)
-	fmt.Printf("Repeat settings: repeat %d. delay %d\n", repeatInfo[0], repeatInfo[1])
+	fmt.Printf("Repeat settings: delay %s, period %s\n", repeatInfo.Delay, repeatInfo.Period)
 	fmt.Printf("Device capabilities:\n")
+	for _, evType := range dev.EventTypes() {
+		fmt.Printf("  Type %s (%d)\n", evdev.TypeName(evType), evType)
+		for _, code := range dev.CodesFor(evType) {
+			fmt.Printf("    Code %s (%d)\n", evdev.CodeName(evType, code), code)
+			if abs, ok := dev.AbsInfos[code]; ok && evType == evdev.EV_ABS {
+				fmt.Printf("      Value %d, Min %d, Max %d, Fuzz %d, Flat %d, Resolution %d\n",
+					abs.Value, abs.Min, abs.Max, abs.Fuzz, abs.Flat, abs.Resolution)
+			}
+		}
+	}
 
 	fmt.Printf("Listening for events ...\n")
 
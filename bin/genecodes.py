@@ -17,16 +17,21 @@ SOURCES = [
     '/usr/include/linux/input-event-codes.h',
 ]
 
-if sys.argv[1:]:
-    SOURCES = sys.argv[1:]
-
 # The golang template file in which we inject the macros.
 template_path = os.path.join(os.path.dirname(__file__), '../ecodes.go.template')
+
+# Arguments are header files, optionally followed by a template file.
+args = sys.argv[1:]
+if args and args[-1].endswith('.template'):
+    template_path = args.pop()
+if args:
+    SOURCES = args
+
 TEMPLATE = string.Template(open(template_path).read())
 
 
 #-----------------------------------------------------------------------------
-MACRO_REGEX = r'#define +((?:KEY|ABS|REL|SW|MSC|LED|BTN|REP|SND|ID|EV|BUS|SYN|FF)_\w+)\s+(\w+)'
+MACRO_REGEX = r'#define +((?:KEY|ABS|REL|SW|MSC|LED|BTN|REP|SND|ID|EV|BUS|SYN|FF|INPUT_PROP)_\w+)\s+(\w+)'
 MACRO_REGEX = re.compile(MACRO_REGEX)
 
 def get_uname():
@@ -53,12 +58,43 @@ def get_ecodes(headers):
         sys.exit('no input macros found in: %s' % ' '.join(SOURCES))
     return all_macros
 
+def get_aliases(ecodes):
+    '''
+    Find the names that share their value with a preferred name of the same
+    kind. Names defined in terms of another name and _MIN/_MAX markers are
+    least preferred, otherwise the last definition wins (BTN_0 over BTN_MISC).
+    '''
+    macros = dict(ecodes)
+
+    def resolve(value):
+        while value in macros:
+            value = macros[value]
+        return value
+
+    def rank(idx, name, value):
+        marker = re.search(r'_(MIN|MAX)(_|$)', name) is not None
+        return (value in macros, marker, -idx)
+
+    groups = {}
+    for idx, (name, value) in enumerate(ecodes):
+        key = (name.split('_')[0], resolve(value))
+        groups.setdefault(key, []).append((rank(idx, name, value), name))
+
+    aliases = []
+    for names in groups.values():
+        names.sort()
+        aliases += [name for _, name in names[1:]]
+    return sorted(aliases)
+
 ECODES = get_ecodes(SOURCES)
 
 context = {
     'UNAME': get_uname(),
     'CODES':   os.linesep.join('\t%s = %s' % i for i in ECODES),
     'CODEMAP': os.linesep.join('\t"%s": %s,' % (i, i) for i, _ in ECODES),
+    'INPUTMAP': os.linesep.join('\t"%s": %s,' % (i, i) for i, _ in ECODES
+                                if i.split('_')[0] in ('KEY', 'BTN', 'ABS')),
+    'ALIASES': os.linesep.join('\t"%s": true,' % i for i in get_aliases(ECODES)),
 }
 
 print(TEMPLATE.safe_substitute(context))
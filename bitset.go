@@ -0,0 +1,73 @@
+package evdev
+
+// Bitset is a set of event codes in the layout of the kernel bitmaps
+// returned by EVIOCGBIT, EVIOCGKEY and similar: bit n is stored in byte n/8
+// at position n%8. Lookups take constant time, which matters for checks made
+// on every event.
+type Bitset []byte
+
+// NewBitset creates an empty set that can hold codes up to max, e.g.
+// NewBitset(CodeMax(EV_KEY)).
+func NewBitset(max int) Bitset {
+	return make(Bitset, max/8+1)
+}
+
+// Has reports whether code is in the set.
+func (b Bitset) Has(code int) bool {
+	return code >= 0 && code/8 < len(b) && b[code/8]&(1<<uint(code%8)) != 0
+}
+
+// Set adds code to the set, growing it as needed.
+func (b *Bitset) Set(code int) {
+	if code < 0 {
+		return
+	}
+	for code/8 >= len(*b) {
+		*b = append(*b, 0)
+	}
+
+	(*b)[code/8] |= 1 << uint(code%8)
+}
+
+// Clear removes code from the set.
+func (b Bitset) Clear(code int) {
+	if code >= 0 && code/8 < len(b) {
+		b[code/8] &^= 1 << uint(code%8)
+	}
+}
+
+// Iterate calls fn for every code in the set in ascending order, until fn
+// returns false.
+func (b Bitset) Iterate(fn func(code int) bool) {
+	for i, v := range b {
+		for bit := 0; v != 0; bit++ {
+			if v&1 != 0 && !fn(i*8+bit) {
+				return
+			}
+			v >>= 1
+		}
+	}
+}
+
+// Codes returns the codes in the set in ascending order.
+func (b Bitset) Codes() []int {
+	codes := make([]int, 0)
+	b.Iterate(func(code int) bool {
+		codes = append(codes, code)
+		return true
+	})
+
+	return codes
+}
+
+// Count returns the number of codes in the set.
+func (b Bitset) Count() int {
+	n := 0
+	for _, v := range b {
+		for ; v != 0; v &= v - 1 {
+			n++
+		}
+	}
+
+	return n
+}
@@ -0,0 +1,40 @@
+package evdev
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBitset(t *testing.T) {
+	b := NewBitset(KEY_MAX)
+	for _, code := range []int{KEY_Q, KEY_A, BTN_LEFT, KEY_MAX} {
+		b.Set(code)
+	}
+
+	if !b.Has(KEY_A) || b.Has(KEY_B) || b.Has(-1) || b.Has(KEY_MAX+100) {
+		t.Error("unexpected membership")
+	}
+	if codes := b.Codes(); !reflect.DeepEqual(codes, []int{KEY_Q, KEY_A, BTN_LEFT, KEY_MAX}) {
+		t.Errorf("unexpected codes %v", codes)
+	}
+
+	b.Clear(KEY_Q)
+	if b.Has(KEY_Q) || b.Count() != 3 {
+		t.Errorf("unexpected set after clear: %v", b.Codes())
+	}
+
+	first := -1
+	b.Iterate(func(code int) bool {
+		first = code
+		return false
+	})
+	if first != KEY_A {
+		t.Errorf("expected iteration to stop at KEY_A, got %d", first)
+	}
+
+	var grown Bitset
+	grown.Set(ABS_MT_SLOT)
+	if !grown.Has(ABS_MT_SLOT) || len(grown) != ABS_MT_SLOT/8+1 {
+		t.Errorf("unexpected grown set %v", grown)
+	}
+}
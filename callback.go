@@ -0,0 +1,155 @@
+//go:build linux
+
+package evdev
+
+import "sync"
+
+// Shared poller delivering the events of the devices registered with
+// OnEvent, run by a single goroutine started with the first registration.
+var callbackPoller struct {
+	once sync.Once
+	err  error
+	p    *Poller
+
+	mu        sync.Mutex
+	callbacks map[*InputDevice]*deviceCallbacks
+	failed    error // error that stopped the goroutine
+}
+
+type deviceCallbacks struct {
+	onEvent func(ev InputEvent)
+	onError func(err error)
+}
+
+// OnEvent delivers the events of the device to fn instead of through Read.
+// The events of all devices registered this way are read by one shared
+// Poller goroutine, which calls fn for each event in order; fn must
+// return quickly, as it delays the events of every such device. This suits
+// constrained systems handling many devices better than a goroutine and
+// channel per device. Passing nil unregisters the device, which must be
+// done before closing it.
+func (dev *InputDevice) OnEvent(fn func(ev InputEvent)) error {
+	cp := &callbackPoller
+	cp.once.Do(func() {
+		cp.p, cp.err = NewPoller()
+		if cp.err != nil {
+			return
+		}
+		cp.callbacks = make(map[*InputDevice]*deviceCallbacks)
+		cp.p.OnError = callbackError
+		go runCallbacks()
+	})
+	if cp.err != nil {
+		return cp.err
+	}
+
+	cp.mu.Lock()
+	cb, ok := cp.callbacks[dev]
+	switch {
+	case fn == nil:
+		delete(cp.callbacks, dev)
+	case ok:
+		cb.onEvent = fn
+	}
+	cp.mu.Unlock()
+
+	if fn == nil {
+		if !ok {
+			return nil
+		}
+		return cp.p.Remove(dev)
+	}
+	if ok {
+		return nil
+	}
+
+	// registered first, so that no event is read without a callback
+	cp.mu.Lock()
+	if cp.failed != nil {
+		cp.mu.Unlock()
+		return cp.failed
+	}
+	cp.callbacks[dev] = &deviceCallbacks{onEvent: fn}
+	cp.mu.Unlock()
+
+	err := cp.p.Add(dev)
+	if err != nil {
+		cp.mu.Lock()
+		delete(cp.callbacks, dev)
+		cp.mu.Unlock()
+	}
+
+	return err
+}
+
+// OnEventError sets the function called on the shared goroutine when
+// reading a device registered with OnEvent fails, e.g. because it was
+// unplugged, or when the shared poller itself fails, which ends the
+// delivery of events to all devices. The device is unregistered before fn
+// is called. It has no effect on devices not registered.
+func (dev *InputDevice) OnEventError(fn func(err error)) {
+	cp := &callbackPoller
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	if cb, ok := cp.callbacks[dev]; ok {
+		cb.onError = fn
+	}
+}
+
+// Deliver the events of the shared poller to their callbacks.
+func runCallbacks() {
+	cp := &callbackPoller
+	for {
+		events, err := cp.p.Wait(-1)
+		if err != nil {
+			// the errors of devices go to callbackError, this one is fatal
+			stopCallbacks(err)
+			return
+		}
+
+		for _, de := range events {
+			cp.mu.Lock()
+			cb, ok := cp.callbacks[de.Device]
+			var fn func(InputEvent)
+			if ok {
+				fn = cb.onEvent
+			}
+			cp.mu.Unlock()
+
+			if fn != nil {
+				fn(de.Event)
+			}
+		}
+	}
+}
+
+// Unregister all devices after the shared poller failed and report the
+// error to each.
+func stopCallbacks(err error) {
+	cp := &callbackPoller
+	cp.mu.Lock()
+	cp.failed = err
+	callbacks := cp.callbacks
+	cp.callbacks = make(map[*InputDevice]*deviceCallbacks)
+	cp.mu.Unlock()
+
+	for _, cb := range callbacks {
+		if cb.onError != nil {
+			cb.onError(err)
+		}
+	}
+}
+
+// Unregister a device that failed and report the error.
+func callbackError(dev *InputDevice, err error) {
+	cp := &callbackPoller
+	cp.mu.Lock()
+	cb := cp.callbacks[dev]
+	delete(cp.callbacks, dev)
+	cp.mu.Unlock()
+
+	if cb != nil && cb.onError != nil {
+		cb.onError(err)
+	}
+}
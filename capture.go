@@ -0,0 +1,496 @@
+//go:build linux
+
+package evdev
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// A capture interleaves the event streams of several devices on a single
+// monotonic timebase. It starts with a header followed by a sequence of
+// records, all little endian:
+//
+//	header  "EVCAP\x00" magic, uint16 version, string source GOARCH
+//	record  uint8 kind, int64 offset (ns since capture start), payload
+//
+// Every device is described by a device record before its first event.
+// Kernel timestamps are stored with fixed 64-bit fields so that captures
+// are portable between architectures; the source architecture is recorded
+// to tell where a capture was taken. Version 1 captures lack it.
+
+const (
+	captureMagic   = "EVCAP\x00"
+	captureVersion = 2
+)
+
+// CaptureRecordKind identifies the type of a record in a capture.
+type CaptureRecordKind uint8
+
+const (
+	CaptureDeviceRecord CaptureRecordKind = 1 // device metadata block
+	CaptureEventRecord  CaptureRecordKind = 2 // input event of a device
+	CaptureMarkerRecord CaptureRecordKind = 3 // named synchronization marker
+)
+
+// ErrBadCapture is returned when reading data that is not a valid capture.
+var ErrBadCapture = errors.New("evdev: malformed capture")
+
+// EventWriter is implemented by anything input events can be written to,
+// such as virtual devices or filters forwarding to them.
+type EventWriter interface {
+	WriteEvent(ev *InputEvent) error
+}
+
+// CaptureDevice describes a captured device.
+type CaptureDevice struct {
+	ID uint16 // identifier of the device within the capture
+
+	Name  string
+	Phys  string
+	Ident string
+
+	BusType uint16
+	Vendor  uint16
+	Product uint16
+	Version uint16
+
+	Capabilities map[CapabilityType][]CapabilityCode
+}
+
+// CaptureRecord is a single record read from a capture.
+type CaptureRecord struct {
+	Kind   CaptureRecordKind
+	Offset time.Duration // position on the global timebase of the capture
+
+	Device   *CaptureDevice // set for device records
+	DeviceID uint16         // originating device of event records
+	Event    InputEvent     // set for event records
+	Marker   string         // set for marker records
+}
+
+type captureEvent struct {
+	Device     uint16
+	Sec, Usec  int64
+	Type, Code uint16
+	Value      int32
+}
+
+type captureIdent struct {
+	BusType, Vendor, Product, Version uint16
+}
+
+// CaptureWriter writes a capture of one or more devices.
+type CaptureWriter struct {
+	w       *bufio.Writer
+	start   time.Time
+	devices uint16
+}
+
+// NewCaptureWriter writes a capture header to w. The global timebase of the
+// capture starts now.
+func NewCaptureWriter(w io.Writer) (*CaptureWriter, error) {
+	cw := &CaptureWriter{w: bufio.NewWriter(w), start: time.Now()}
+
+	if _, err := cw.w.WriteString(captureMagic); err != nil {
+		return nil, err
+	}
+	if err := binary.Write(cw.w, binary.LittleEndian, uint16(captureVersion)); err != nil {
+		return nil, err
+	}
+	if err := writeCaptureString(cw.w, NativeEventLayout.Arch); err != nil {
+		return nil, err
+	}
+
+	return cw, nil
+}
+
+// AddDevice writes a metadata block for dev and returns the id with which
+// its events have to be written.
+func (cw *CaptureWriter) AddDevice(dev *InputDevice) (uint16, error) {
+	id := cw.devices
+	cw.devices++
+
+	if err := cw.writeRecord(CaptureDeviceRecord); err != nil {
+		return 0, err
+	}
+
+	data := []interface{}{
+		id,
+		captureIdent{dev.BusType, dev.Vendor, dev.Product, dev.Version},
+	}
+	for _, v := range data {
+		if err := binary.Write(cw.w, binary.LittleEndian, v); err != nil {
+			return 0, err
+		}
+	}
+
+	for _, s := range []string{dev.Name, dev.Phys, dev.Ident} {
+		if err := writeCaptureString(cw.w, s); err != nil {
+			return 0, err
+		}
+	}
+
+	codes := make([]uint16, 0)
+	for evType, evCodes := range dev.Capabilities {
+		for _, c := range evCodes {
+			codes = append(codes, uint16(evType.Type), uint16(c.Code))
+		}
+	}
+	if err := binary.Write(cw.w, binary.LittleEndian, uint32(len(codes)/2)); err != nil {
+		return 0, err
+	}
+	if err := binary.Write(cw.w, binary.LittleEndian, codes); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// WriteEvent writes an event of the device with the given id.
+func (cw *CaptureWriter) WriteEvent(id uint16, ev *InputEvent) error {
+	if id >= cw.devices {
+		return fmt.Errorf("evdev: unknown capture device %d", id)
+	}
+
+	if err := cw.writeRecord(CaptureEventRecord); err != nil {
+		return err
+	}
+
+	return binary.Write(cw.w, binary.LittleEndian, captureEvent{
+		Device: id,
+		Sec:    int64(ev.Time.Sec),
+		Usec:   int64(ev.Time.Usec),
+		Type:   uint16(ev.Type),
+		Code:   uint16(ev.Code),
+		Value:  int32(ev.Value),
+	})
+}
+
+// WriteMarker inserts a named synchronization marker into the capture. During
+// replay, the marker can be awaited before replaying the events following it.
+func (cw *CaptureWriter) WriteMarker(name string) error {
+	if err := cw.writeRecord(CaptureMarkerRecord); err != nil {
+		return err
+	}
+
+	return writeCaptureString(cw.w, name)
+}
+
+// Flush writes any buffered data to the underlying writer.
+func (cw *CaptureWriter) Flush() error {
+	return cw.w.Flush()
+}
+
+func (cw *CaptureWriter) writeRecord(kind CaptureRecordKind) error {
+	if err := cw.w.WriteByte(byte(kind)); err != nil {
+		return err
+	}
+
+	offset := int64(time.Since(cw.start))
+	return binary.Write(cw.w, binary.LittleEndian, offset)
+}
+
+func writeCaptureString(w io.Writer, s string) error {
+	if len(s) > 0xffff {
+		s = s[:0xffff]
+	}
+	if err := binary.Write(w, binary.LittleEndian, uint16(len(s))); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, s)
+	return err
+}
+
+// CaptureReader reads the records of a capture.
+type CaptureReader struct {
+	r       *bufio.Reader
+	arch    string
+	devices map[uint16]*CaptureDevice
+}
+
+// NewCaptureReader reads and verifies the header of a capture.
+func NewCaptureReader(r io.Reader) (*CaptureReader, error) {
+	cr := &CaptureReader{r: bufio.NewReader(r), devices: make(map[uint16]*CaptureDevice)}
+
+	magic := make([]byte, len(captureMagic))
+	if _, err := io.ReadFull(cr.r, magic); err != nil || string(magic) != captureMagic {
+		return nil, ErrBadCapture
+	}
+
+	var version uint16
+	if err := binary.Read(cr.r, binary.LittleEndian, &version); err != nil {
+		return nil, ErrBadCapture
+	}
+	if version < 1 || version > captureVersion {
+		return nil, fmt.Errorf("evdev: unsupported capture version %d", version)
+	}
+	if version >= 2 {
+		arch, err := readCaptureString(cr.r)
+		if err != nil {
+			return nil, ErrBadCapture
+		}
+		cr.arch = arch
+	}
+
+	return cr, nil
+}
+
+// Arch returns the GOARCH name of the architecture the capture was taken on,
+// or "" if it is unknown.
+func (cr *CaptureReader) Arch() string {
+	return cr.arch
+}
+
+// Layout returns the event layout of the architecture the capture was taken
+// on, e.g. to decode raw event dumps taken alongside it. Events read from the
+// capture itself are always converted to the native layout.
+func (cr *CaptureReader) Layout() (EventLayout, bool) {
+	return EventLayoutFor(cr.arch)
+}
+
+// Device returns the metadata of a device seen so far in the capture.
+func (cr *CaptureReader) Device(id uint16) *CaptureDevice {
+	return cr.devices[id]
+}
+
+// Next returns the next record of the capture or io.EOF at its end.
+func (cr *CaptureReader) Next() (*CaptureRecord, error) {
+	kind, err := cr.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	rec := &CaptureRecord{Kind: CaptureRecordKind(kind)}
+
+	var offset int64
+	if err = binary.Read(cr.r, binary.LittleEndian, &offset); err != nil {
+		return nil, ErrBadCapture
+	}
+	rec.Offset = time.Duration(offset)
+
+	switch rec.Kind {
+	case CaptureDeviceRecord:
+		rec.Device, err = cr.readDevice()
+		if err != nil {
+			return nil, ErrBadCapture
+		}
+		rec.DeviceID = rec.Device.ID
+		cr.devices[rec.Device.ID] = rec.Device
+	case CaptureEventRecord:
+		var ce captureEvent
+		if err = binary.Read(cr.r, binary.LittleEndian, &ce); err != nil {
+			return nil, ErrBadCapture
+		}
+		if _, ok := cr.devices[ce.Device]; !ok {
+			return nil, ErrBadCapture
+		}
+
+		rec.DeviceID = ce.Device
+		rec.Event = InputEvent{
+			Time:  syscall.NsecToTimeval(ce.Sec*1e9 + ce.Usec*1e3),
+			Type:  EvType(ce.Type),
+			Code:  EvCode(ce.Code),
+			Value: EvValue(ce.Value),
+		}
+	case CaptureMarkerRecord:
+		if rec.Marker, err = readCaptureString(cr.r); err != nil {
+			return nil, ErrBadCapture
+		}
+	default:
+		return nil, ErrBadCapture
+	}
+
+	return rec, nil
+}
+
+func (cr *CaptureReader) readDevice() (*CaptureDevice, error) {
+	dev := &CaptureDevice{Capabilities: make(map[CapabilityType][]CapabilityCode)}
+
+	var ident captureIdent
+	if err := binary.Read(cr.r, binary.LittleEndian, &dev.ID); err != nil {
+		return nil, err
+	}
+	if err := binary.Read(cr.r, binary.LittleEndian, &ident); err != nil {
+		return nil, err
+	}
+	dev.BusType, dev.Vendor, dev.Product, dev.Version = ident.BusType, ident.Vendor, ident.Product, ident.Version
+
+	for _, s := range []*string{&dev.Name, &dev.Phys, &dev.Ident} {
+		var err error
+		if *s, err = readCaptureString(cr.r); err != nil {
+			return nil, err
+		}
+	}
+
+	var count uint32
+	if err := binary.Read(cr.r, binary.LittleEndian, &count); err != nil {
+		return nil, err
+	}
+	for i := uint32(0); i < count; i++ {
+		var pair [2]uint16
+		if err := binary.Read(cr.r, binary.LittleEndian, &pair); err != nil {
+			return nil, err
+		}
+
+		evType, code := int(pair[0]), int(pair[1])
+		key := CapabilityType{evType, TypeName(evType)}
+		dev.Capabilities[key] = append(dev.Capabilities[key], CapabilityCode{code, CodeName(evType, code)})
+	}
+
+	return dev, nil
+}
+
+func readCaptureString(r io.Reader) (string, error) {
+	var n uint16
+	if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+		return "", err
+	}
+
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// Replayer plays a capture back in real time, onto one EventWriter per
+// captured device. Since all devices share the timebase of the capture,
+// their events are replayed in their original relative order and timing.
+type Replayer struct {
+	// Open is called for every device of the capture and returns the
+	// writer its events are replayed onto, typically a virtual device
+	// matching the captured one. Events of devices for which Open returns
+	// a nil writer are skipped.
+	Open func(dev *CaptureDevice) (EventWriter, error)
+
+	// Wait is called for every marker of the capture and blocks the replay
+	// until it returns, e.g. until a test harness signals that the screen is
+	// ready. Events following the marker keep their timing relative to it.
+	// Markers are ignored if Wait is nil.
+	Wait func(ctx context.Context, marker string) error
+}
+
+// Replay reads the capture from r until its end or until ctx is done.
+func (rp *Replayer) Replay(ctx context.Context, r *CaptureReader) error {
+	sinks := make(map[uint16]EventWriter)
+	start := time.Now()
+
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch rec.Kind {
+		case CaptureDeviceRecord:
+			sink, err := rp.Open(rec.Device)
+			if err != nil {
+				return err
+			}
+			sinks[rec.DeviceID] = sink
+		case CaptureEventRecord:
+			sink := sinks[rec.DeviceID]
+			if sink == nil {
+				continue
+			}
+			if err = sleepUntil(ctx, start.Add(rec.Offset)); err != nil {
+				return err
+			}
+			if err = sink.WriteEvent(&rec.Event); err != nil {
+				return err
+			}
+		case CaptureMarkerRecord:
+			if rp.Wait == nil {
+				continue
+			}
+			if err = sleepUntil(ctx, start.Add(rec.Offset)); err != nil {
+				return err
+			}
+			if err = rp.Wait(ctx, rec.Marker); err != nil {
+				return err
+			}
+			// shift the timebase by the time spent waiting
+			start = time.Now().Add(-rec.Offset)
+		}
+	}
+}
+
+func sleepUntil(ctx context.Context, t time.Time) error {
+	d := time.Until(t)
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Barriers is a set of named barriers that replays can wait on. A barrier
+// opens once it is signaled and stays open. Its Wait method can be used as
+// Replayer.Wait.
+type Barriers struct {
+	mu       sync.Mutex
+	barriers map[string]chan struct{}
+}
+
+// NewBarriers creates an empty set of barriers.
+func NewBarriers() *Barriers {
+	return &Barriers{barriers: make(map[string]chan struct{})}
+}
+
+// Signal opens the named barrier, releasing all current and future waiters.
+func (b *Barriers) Signal(name string) {
+	ch := b.get(name)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	select {
+	case <-ch:
+	default:
+		close(ch)
+	}
+}
+
+// Wait blocks until the named barrier is signaled or ctx is done.
+func (b *Barriers) Wait(ctx context.Context, name string) error {
+	select {
+	case <-b.get(name):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *Barriers) get(name string) chan struct{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch, ok := b.barriers[name]
+	if !ok {
+		ch = make(chan struct{})
+		b.barriers[name] = ch
+	}
+
+	return ch
+}
@@ -0,0 +1,181 @@
+//go:build linux
+
+package evdev
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestCaptureRoundTrip(t *testing.T) {
+	kbd := &InputDevice{
+		Name:    "keyboard",
+		BusType: BUS_USB,
+		Capabilities: map[CapabilityType][]CapabilityCode{
+			{EV_KEY, "EV_KEY"}: {{KEY_A, "KEY_A"}},
+		},
+	}
+	mouse := &InputDevice{Name: "mouse", Phys: "usb-1"}
+
+	buf := new(bytes.Buffer)
+	cw, err := NewCaptureWriter(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kbdID, _ := cw.AddDevice(kbd)
+	mouseID, _ := cw.AddDevice(mouse)
+
+	ev := NewInputEvent(time.Unix(1700000000, 123000), EV_KEY, KEY_A, 1)
+	if err := cw.WriteEvent(kbdID, &ev); err != nil {
+		t.Fatal(err)
+	}
+	ev = NewInputEvent(time.Unix(1700000001, 0), EV_REL, REL_X, -5)
+	if err := cw.WriteEvent(mouseID, &ev); err != nil {
+		t.Fatal(err)
+	}
+	if err := cw.WriteEvent(7, &ev); err == nil {
+		t.Error("expected error for unknown device")
+	}
+	cw.Flush()
+
+	cr, err := NewCaptureReader(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records := make([]*CaptureRecord, 0)
+	for {
+		rec, err := cr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, rec)
+	}
+
+	if len(records) != 4 {
+		t.Fatalf("expected 4 records, got %d", len(records))
+	}
+
+	if d := records[0].Device; d.Name != "keyboard" || d.BusType != BUS_USB || len(d.Capabilities) != 1 {
+		t.Errorf("unexpected device: %+v", d)
+	}
+	if d := cr.Device(mouseID); d == nil || d.Phys != "usb-1" {
+		t.Errorf("unexpected device: %+v", d)
+	}
+
+	rec := records[2]
+	if rec.DeviceID != kbdID || rec.Event.Code != KEY_A || rec.Event.Time.Usec != 123 {
+		t.Errorf("unexpected event: %+v", rec)
+	}
+	if records[3].Offset < rec.Offset {
+		t.Error("offsets are not monotonic")
+	}
+}
+
+type eventRecorder []InputEvent
+
+func (r *eventRecorder) WriteEvent(ev *InputEvent) error {
+	*r = append(*r, *ev)
+	return nil
+}
+
+func TestReplayMarkers(t *testing.T) {
+	buf := new(bytes.Buffer)
+	cw, _ := NewCaptureWriter(buf)
+	id, _ := cw.AddDevice(&InputDevice{Name: "keyboard"})
+
+	ev := NewInputEvent(time.Now(), EV_KEY, KEY_A, 1)
+	cw.WriteEvent(id, &ev)
+	cw.WriteMarker("ready")
+	cw.WriteEvent(id, &ev)
+	cw.Flush()
+
+	cr, err := NewCaptureReader(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sink := new(eventRecorder)
+	barriers := NewBarriers()
+	rp := Replayer{
+		Open: func(dev *CaptureDevice) (EventWriter, error) { return sink, nil },
+		Wait: func(ctx context.Context, marker string) error {
+			if len(*sink) != 1 {
+				t.Errorf("expected 1 event before marker, got %d", len(*sink))
+			}
+			go barriers.Signal(marker)
+			return barriers.Wait(ctx, marker)
+		},
+	}
+
+	if err := rp.Replay(context.Background(), cr); err != nil {
+		t.Fatal(err)
+	}
+	if len(*sink) != 2 {
+		t.Errorf("expected 2 replayed events, got %d", len(*sink))
+	}
+}
+
+func TestCaptureArch(t *testing.T) {
+	buf := new(bytes.Buffer)
+	cw, _ := NewCaptureWriter(buf)
+	cw.Flush()
+
+	cr, err := NewCaptureReader(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if layout, ok := cr.Layout(); cr.Arch() != runtime.GOARCH || (ok && layout != NativeEventLayout) {
+		t.Errorf("unexpected source %q %+v", cr.Arch(), layout)
+	}
+
+	// version 1 captures have no source architecture
+	cr, err = NewCaptureReader(bytes.NewBufferString("EVCAP\x00\x01\x00"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cr.Arch() != "" {
+		t.Errorf("unexpected arch %q", cr.Arch())
+	}
+}
+
+func TestRawEventLayouts(t *testing.T) {
+	ev := NewInputEvent(time.Unix(1700000000, 250000), EV_REL, REL_X, -3)
+
+	for _, arch := range []string{"arm", "amd64", "mips", "s390x"} {
+		layout, ok := EventLayoutFor(arch)
+		if !ok {
+			t.Fatalf("no layout for %s", arch)
+		}
+
+		b := make([]byte, 2*layout.Size()+1)
+		layout.Encode(b, &ev)
+		layout.Encode(b[layout.Size():], &ev)
+
+		rr := NewRawEventReader(bytes.NewReader(b), layout)
+		for i := 0; i < 2; i++ {
+			got, err := rr.Next()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != ev {
+				t.Errorf("%s: got %v, want %v", arch, &got, &ev)
+			}
+		}
+		if _, err := rr.Next(); err != io.ErrUnexpectedEOF {
+			t.Errorf("%s: expected unexpected EOF, got %v", arch, err)
+		}
+	}
+
+	if layout, _ := EventLayoutFor("arm"); layout.Size() != 16 {
+		t.Errorf("unexpected arm event size %d", layout.Size())
+	}
+}
@@ -2,6 +2,7 @@ package evdev
 
 /*
  #include <linux/input.h>
+ #include <linux/uinput.h>
  static int _EVIOCGNAME(int len) {return EVIOCGNAME(len);}
  static int _EVIOCGPHYS(int len) {return EVIOCGPHYS(len);}
  static int _EVIOCGUNIQ(int len) {return EVIOCGUNIQ(len);}
@@ -15,6 +16,9 @@ package evdev
  static int _EVIOCGBIT(int ev, int len) {return EVIOCGBIT(ev, len);}
  static int _EVIOCGABS(int abs)    {return EVIOCGABS(abs);}
  static int _EVIOCSABS(int abs)    {return EVIOCSABS(abs);}
+ static int _EVIOCGMTSLOTS(int len) {return EVIOCGMTSLOTS(len);}
+
+ static int _UI_GET_SYSNAME(int len) {return UI_GET_SYSNAME(len);}
 */
 import "C"
 import "syscall"
@@ -29,6 +33,7 @@ const (
 	sizeofInputAbsInfo     = C.sizeof_struct_input_absinfo
 	sizeofInputId          = C.sizeof_struct_input_id
 	sizeofInputKeymapEntry = C.sizeof_struct_input_keymap_entry
+	sizeofFFEffect         = C.sizeof_struct_ff_effect
 )
 
 //goland:noinspection ALL
@@ -52,9 +57,38 @@ const (
 	EVIOCGEFFECTS = C.EVIOCGEFFECTS // report number of effects playable at the same time
 
 	EVIOCGRAB     = C.EVIOCGRAB     // grab/release device
+	EVIOCREVOKE   = C.EVIOCREVOKE   // revoke device access
+	EVIOCGMASK    = C.EVIOCGMASK    // get event masks
+	EVIOCSMASK    = C.EVIOCSMASK    // set event masks
 	EVIOCSCLOCKID = C.EVIOCSCLOCKID // set clockid to be used for timestamps
 )
 
+//goland:noinspection ALL
+const (
+	UINPUT_MAX_NAME_SIZE = C.UINPUT_MAX_NAME_SIZE
+
+	UI_DEV_CREATE  = C.UI_DEV_CREATE  // create the device
+	UI_DEV_DESTROY = C.UI_DEV_DESTROY // destroy the device
+	UI_DEV_SETUP   = C.UI_DEV_SETUP   // set device name and ids
+	UI_ABS_SETUP   = C.UI_ABS_SETUP   // set absolute axis parameters
+	UI_GET_VERSION = C.UI_GET_VERSION // get uinput protocol version
+
+	UI_SET_EVBIT   = C.UI_SET_EVBIT   // enable an event type
+	UI_SET_KEYBIT  = C.UI_SET_KEYBIT  // enable a key or button
+	UI_SET_RELBIT  = C.UI_SET_RELBIT  // enable a relative axis
+	UI_SET_ABSBIT  = C.UI_SET_ABSBIT  // enable an absolute axis
+	UI_SET_MSCBIT  = C.UI_SET_MSCBIT  // enable a misc event
+	UI_SET_LEDBIT  = C.UI_SET_LEDBIT  // enable an LED
+	UI_SET_SNDBIT  = C.UI_SET_SNDBIT  // enable a sound
+	UI_SET_FFBIT   = C.UI_SET_FFBIT   // enable a force feedback effect
+	UI_SET_SWBIT   = C.UI_SET_SWBIT   // enable a switch
+	UI_SET_PROPBIT = C.UI_SET_PROPBIT // set a device property
+	UI_SET_PHYS    = C.UI_SET_PHYS    // set physical location
+)
+
+//goland:noinspection ALL
+const INPUT_KEYMAP_BY_INDEX = C.INPUT_KEYMAP_BY_INDEX // look up keymap entries by index
+
 var EVIOCGNAME = C._EVIOCGNAME(MAX_NAME_SIZE) // get device name
 var EVIOCGPHYS = C._EVIOCGPHYS(MAX_NAME_SIZE) // get physical location
 var EVIOCGUNIQ = C._EVIOCGUNIQ(MAX_NAME_SIZE) // get unique identifier
@@ -65,9 +99,12 @@ var EVIOCGLED = C._EVIOCGLED(MAX_NAME_SIZE) // get all LEDs
 var EVIOCGSND = C._EVIOCGSND(MAX_NAME_SIZE) // get all sounds status
 var EVIOCGSW = C._EVIOCGSW(MAX_NAME_SIZE)   // get all switch states
 
+var UI_GET_SYSNAME = C._UI_GET_SYSNAME(MAX_NAME_SIZE) // get sysfs name of a uinput device
+
 func EVIOCGBIT(ev, l int) int { return int(C._EVIOCGBIT(C.int(ev), C.int(l))) } // get event bits
 func EVIOCGABS(abs int) int   { return int(C._EVIOCGABS(C.int(abs))) }          // get abs bits
 func EVIOCSABS(abs int) int   { return int(C._EVIOCSABS(C.int(abs))) }          // set abs bits
+func EVIOCGMTSLOTS(l int) int { return int(C._EVIOCGMTSLOTS(C.int(l))) }        // get multi-touch slot values
 
 func ioctl(fd uintptr, name uintptr, data unsafe.Pointer) syscall.Errno {
 	_, _, err := syscall.RawSyscall(syscall.SYS_IOCTL, fd, name, uintptr(data))
@@ -0,0 +1,214 @@
+package evdev
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Modifiers is a set of held modifier keys, distinguishing left and right.
+type Modifiers uint8
+
+const (
+	ModLeftShift Modifiers = 1 << iota
+	ModRightShift
+	ModLeftCtrl
+	ModRightCtrl
+	ModLeftAlt
+	ModRightAlt
+	ModLeftMeta
+	ModRightMeta
+)
+
+// Either side of a modifier.
+const (
+	ModShift = ModLeftShift | ModRightShift
+	ModCtrl  = ModLeftCtrl | ModRightCtrl
+	ModAlt   = ModLeftAlt | ModRightAlt
+	ModMeta  = ModLeftMeta | ModRightMeta
+)
+
+// Modifier keys and the modifier they set.
+var modifierKeys = map[EvCode]Modifiers{
+	KEY_LEFTSHIFT:  ModLeftShift,
+	KEY_RIGHTSHIFT: ModRightShift,
+	KEY_LEFTCTRL:   ModLeftCtrl,
+	KEY_RIGHTCTRL:  ModRightCtrl,
+	KEY_LEFTALT:    ModLeftAlt,
+	KEY_RIGHTALT:   ModRightAlt,
+	KEY_LEFTMETA:   ModLeftMeta,
+	KEY_RIGHTMETA:  ModRightMeta,
+}
+
+// Modifier names in canonical chord order.
+var modifierNames = []struct {
+	mod  Modifiers
+	name string
+}{
+	{ModCtrl, "ctrl"},
+	{ModAlt, "alt"},
+	{ModShift, "shift"},
+	{ModMeta, "meta"},
+}
+
+// ModifierOf returns the modifier set by a key, or 0 if it is no modifier key.
+func ModifierOf(code EvCode) Modifiers {
+	return modifierKeys[code]
+}
+
+// Sideless returns the modifiers with both sides set for every held modifier,
+// so that left and right ctrl compare equal.
+func (m Modifiers) Sideless() Modifiers {
+	for _, n := range modifierNames {
+		if m&n.mod != 0 {
+			m |= n.mod
+		}
+	}
+
+	return m
+}
+
+// Chord is a key combination such as ctrl+alt+t: a set of modifiers held
+// while a key is pressed. Chords consisting only of modifiers have Key 0.
+type Chord struct {
+	Modifiers Modifiers // held modifiers, either side matches
+	Key       EvCode    // KEY_* or BTN_* code, 0 for modifier-only chords
+}
+
+// Matches reports whether the chord is pressed when key goes down while mods
+// are held.
+func (c Chord) Matches(mods Modifiers, key EvCode) bool {
+	return c.Key == key && c.Modifiers.Sideless() == mods.Sideless()
+}
+
+// String returns the canonical form of the chord, with modifiers in fixed
+// order and lower case key names, e.g. "ctrl+alt+t".
+func (c Chord) String() string {
+	parts := make([]string, 0)
+
+	for _, n := range modifierNames {
+		if c.Modifiers&n.mod != 0 {
+			parts = append(parts, n.name)
+		}
+	}
+	if c.Key != 0 {
+		parts = append(parts, keyName(c.Key))
+	}
+
+	return strings.Join(parts, "+")
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (c Chord) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (c *Chord) UnmarshalText(text []byte) error {
+	parsed, err := ParseChord(string(text))
+	if err != nil {
+		return err
+	}
+
+	*c = parsed
+	return nil
+}
+
+// ParseChord parses a chord such as "ctrl+alt+t" or "CTRL+SHIFT+F1". Key
+// names are KEY_* names with or without the prefix, or BTN_* names.
+func ParseChord(s string) (Chord, error) {
+	chord := Chord{}
+
+	for _, part := range strings.Split(s, "+") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" {
+			return chord, fmt.Errorf("evdev: invalid chord %q", s)
+		}
+
+		if mod := parseModifier(part); mod != 0 {
+			chord.Modifiers |= mod
+			continue
+		}
+
+		code, ok := parseKeyName(part)
+		if !ok || chord.Key != 0 {
+			return chord, fmt.Errorf("evdev: invalid chord %q", s)
+		}
+		chord.Key = code
+	}
+
+	return chord, nil
+}
+
+func parseModifier(name string) Modifiers {
+	switch name {
+	case "super", "win", "cmd":
+		return ModMeta
+	case "control":
+		return ModCtrl
+	}
+
+	for _, n := range modifierNames {
+		if n.name == name {
+			return n.mod
+		}
+	}
+
+	return 0
+}
+
+// Get the short lower case name of a key, e.g. "t" for KEY_T.
+func keyName(code EvCode) string {
+	return strings.ToLower(strings.TrimPrefix(CodeName(EV_KEY, int(code)), "KEY_"))
+}
+
+func parseKeyName(name string) (EvCode, bool) {
+	name = strings.ToUpper(name)
+
+	for _, n := range []string{"KEY_" + name, name} {
+		if code, ok := inputCodeByName(n); ok && (strings.HasPrefix(n, "KEY_") || strings.HasPrefix(n, "BTN_")) {
+			return EvCode(code), true
+		}
+	}
+
+	return 0, false
+}
+
+// ChordRecorder records the next chord pressed on a keyboard. Events are
+// passed to Feed until it reports a complete chord.
+type ChordRecorder struct {
+	mods Modifiers // currently held modifiers
+	seen Modifiers // all modifiers held since the last release of all keys
+}
+
+// Feed passes an event to the recorder. It returns the chord and true once
+// a non-modifier key is pressed, or once all modifiers of a modifier-only
+// chord are released.
+func (r *ChordRecorder) Feed(ev *InputEvent) (Chord, bool) {
+	if ev.Type != EV_KEY || ev.Value == EvValue(KeyHold) {
+		return Chord{}, false
+	}
+
+	if mod := ModifierOf(ev.Code); mod != 0 {
+		if ev.Value == EvValue(KeyDown) {
+			r.mods |= mod
+			r.seen |= mod
+			return Chord{}, false
+		}
+
+		r.mods &^= mod
+		if r.mods == 0 && r.seen != 0 {
+			chord := Chord{Modifiers: r.seen.Sideless()}
+			r.seen = 0
+			return chord, true
+		}
+		return Chord{}, false
+	}
+
+	if ev.Value == EvValue(KeyDown) {
+		chord := Chord{Modifiers: r.mods.Sideless(), Key: ev.Code}
+		r.seen = 0
+		return chord, true
+	}
+
+	return Chord{}, false
+}
@@ -0,0 +1,49 @@
+package evdev
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseChord(t *testing.T) {
+	chord, err := ParseChord("CTRL+Alt+T")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if chord.Key != KEY_T || chord.Modifiers != ModCtrl|ModAlt {
+		t.Errorf("unexpected chord: %+v", chord)
+	}
+	if chord.String() != named("ctrl+alt+t", "ctrl+alt+0x014") {
+		t.Errorf("unexpected canonical form %q", chord)
+	}
+	if !chord.Matches(ModLeftAlt|ModRightCtrl, KEY_T) {
+		t.Error("chord does not match")
+	}
+
+	for _, s := range []string{"ctrl+", "ctrl+a+b", "ctrl+nosuchkey"} {
+		if _, err := ParseChord(s); err == nil {
+			t.Errorf("expected error parsing %q", s)
+		}
+	}
+}
+
+func TestChordRecorder(t *testing.T) {
+	now := time.Now()
+	events := []InputEvent{
+		NewInputEvent(now, EV_KEY, KEY_LEFTSHIFT, 1),
+		NewInputEvent(now, EV_KEY, KEY_LEFTMETA, 1),
+		NewInputEvent(now, EV_KEY, KEY_F1, 1),
+	}
+
+	r := ChordRecorder{}
+	for i, ev := range events {
+		chord, ok := r.Feed(&ev)
+		if ok != (i == len(events)-1) {
+			t.Fatalf("unexpected completion at event %d", i)
+		}
+		if ok && chord.String() != named("shift+meta+f1", "shift+meta+0x03b") {
+			t.Errorf("unexpected chord %q", chord)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+//go:build linux
+
+package evdev
+
+import (
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// Clocks event timestamps can be taken from.
+//
+//goland:noinspection ALL
+const (
+	CLOCK_REALTIME  = 0 // wall clock, the default; jumps when the time is set
+	CLOCK_MONOTONIC = 1 // steady, stops while the system is suspended
+	CLOCK_BOOTTIME  = 7 // steady, includes time spent in suspend
+)
+
+func clockGettime(clock int) time.Duration {
+	var ts syscall.Timespec
+	syscall.Syscall(syscall.SYS_CLOCK_GETTIME, uintptr(clock), uintptr(unsafe.Pointer(&ts)), 0)
+	return time.Duration(ts.Nano())
+}
+
+// SetClockID selects the clock the kernel timestamps events of this handle
+// with, using EVIOCSCLOCKID. Latency measurements should use CLOCK_MONOTONIC
+// or CLOCK_BOOTTIME, which unlike the default CLOCK_REALTIME don't jump when
+// NTP adjusts the time. Events already queued keep their timestamps.
+func (dev *InputDevice) SetClockID(clockid int32) error {
+	if err := ioctl(dev.File.Fd(), uintptr(EVIOCSCLOCKID), unsafe.Pointer(&clockid)); err != 0 {
+		return err
+	}
+	dev.clockID = clockid
+
+	return nil
+}
+
+// ClockID returns the clock events are timestamped with, CLOCK_REALTIME
+// unless changed with SetClockID.
+func (dev *InputDevice) ClockID() int32 {
+	return dev.clockID
+}
+
+// Now returns the current time on the clock events are timestamped with, to
+// compare with event timestamps, e.g. ev.Age(dev.Now()). Times of steady
+// clocks count from an unspecified point, such as boot.
+func (dev *InputDevice) Now() time.Time {
+	if dev.clockID == CLOCK_REALTIME {
+		return time.Now()
+	}
+
+	return time.Unix(0, int64(clockGettime(int(dev.clockID))))
+}
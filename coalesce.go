@@ -0,0 +1,155 @@
+//go:build linux
+
+package evdev
+
+import "sync"
+
+// Coalescer buffers the events of a device for a consumer that may lag
+// behind. While frames are waiting to be consumed, consecutive motion-only
+// frames are merged: absolute axes keep their latest value and relative
+// axes are summed, per multi-touch slot. Frames with key or other events,
+// including BTN_TOUCH and BTN_TOOL_*, and frames starting or ending
+// contacts (ABS_MT_TRACKING_ID) are never merged, so every key and touch
+// transition is preserved along with the position it occurred at. It is
+// safe for concurrent use.
+//
+//	c := evdev.NewCoalescer()
+//	go c.Run(dev)
+//	for range c.Ready() {
+//		handle(c.Pop())
+//	}
+type Coalescer struct {
+	mu      sync.Mutex
+	frames  []*coalescedFrame // complete frames waiting to be consumed
+	current *coalescedFrame   // frame being read
+	slot    EvValue           // current multi-touch slot of the stream
+	ready   chan struct{}
+}
+
+type coalescedFrame struct {
+	events []coalescedEvent
+	motion bool // frame only contains EV_ABS and EV_REL events, but no contact changes
+}
+
+type coalescedEvent struct {
+	slot EvValue // slot of multi-touch events, -1 for others
+	ev   InputEvent
+}
+
+// NewCoalescer creates an empty coalescer.
+func NewCoalescer() *Coalescer {
+	return &Coalescer{
+		current: &coalescedFrame{motion: true},
+		ready:   make(chan struct{}, 1),
+	}
+}
+
+// Push adds an event read from the device.
+func (c *Coalescer) Push(ev InputEvent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch {
+	case ev.Type == EV_ABS && ev.Code == ABS_MT_SLOT:
+		c.slot = ev.Value
+		return
+	case ev.Type == EV_SYN && ev.Code == SYN_REPORT:
+		c.current.events = append(c.current.events, coalescedEvent{-1, ev})
+		c.complete()
+		return
+	case ev.Type != EV_ABS && ev.Type != EV_REL,
+		ev.Type == EV_ABS && ev.Code == ABS_MT_TRACKING_ID:
+		c.current.motion = false
+	}
+
+	slot := EvValue(-1)
+	if ev.Type == EV_ABS && ev.Code >= ABS_MT_SLOT {
+		slot = c.slot
+	}
+	c.current.events = append(c.current.events, coalescedEvent{slot, ev})
+}
+
+// Merge the completed current frame into the last waiting one if both are
+// motion-only, or queue it.
+func (c *Coalescer) complete() {
+	frame := c.current
+	c.current = &coalescedFrame{motion: true}
+
+	n := len(c.frames)
+	if n == 0 || !frame.motion || !c.frames[n-1].motion {
+		c.frames = append(c.frames, frame)
+		select {
+		case c.ready <- struct{}{}:
+		default:
+		}
+		return
+	}
+
+	last := c.frames[n-1]
+	for _, ce := range frame.events {
+		last.merge(ce)
+	}
+}
+
+func (f *coalescedFrame) merge(ce coalescedEvent) {
+	for i := range f.events {
+		old := &f.events[i]
+		if old.ev.Type != ce.ev.Type || old.ev.Code != ce.ev.Code || old.slot != ce.slot {
+			continue
+		}
+
+		if ce.ev.Type == EV_REL {
+			ce.ev.Value += old.ev.Value
+		}
+		old.ev = ce.ev
+		return
+	}
+
+	// keep the SYN_REPORT last
+	last := len(f.events) - 1
+	f.events = append(f.events[:last], ce, f.events[last])
+}
+
+// Ready returns a channel that receives a value when frames are waiting.
+func (c *Coalescer) Ready() <-chan struct{} {
+	return c.ready
+}
+
+// Pop returns the events of all waiting frames, or nil if there are none.
+func (c *Coalescer) Pop() []InputEvent {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.frames) == 0 {
+		return nil
+	}
+
+	events := make([]InputEvent, 0)
+	for _, frame := range c.frames {
+		slot := EvValue(-1)
+		for _, ce := range frame.events {
+			if ce.slot >= 0 && ce.slot != slot {
+				slot = ce.slot
+				events = append(events, NewInputEvent(ce.ev.Timestamp(), EV_ABS, ABS_MT_SLOT, slot))
+			}
+			events = append(events, ce.ev)
+		}
+	}
+	c.frames = nil
+
+	return events
+}
+
+// Run reads events from dev into the coalescer until reading fails.
+func (c *Coalescer) Run(dev *InputDevice) error {
+	for {
+		events, err := dev.Read()
+		if err != nil {
+			return err
+		}
+
+		for _, ev := range events {
+			c.Push(ev)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+//go:build linux
+
+package evdev
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCoalescer(t *testing.T) {
+	c := NewCoalescer()
+	now := time.Now()
+
+	frames := [][]InputEvent{
+		{NewInputEvent(now, EV_REL, REL_X, 2), NewInputEvent(now, EV_ABS, ABS_PRESSURE, 10)},
+		{NewInputEvent(now, EV_REL, REL_X, 3), NewInputEvent(now, EV_REL, REL_Y, 1), NewInputEvent(now, EV_ABS, ABS_PRESSURE, 20)},
+		{NewInputEvent(now, EV_KEY, BTN_LEFT, 1)},
+		{NewInputEvent(now, EV_REL, REL_X, -1)},
+	}
+	for _, frame := range frames {
+		for _, ev := range frame {
+			c.Push(ev)
+		}
+		c.Push(NewInputEvent(now, EV_SYN, SYN_REPORT, 0))
+	}
+
+	want := []InputEvent{
+		NewInputEvent(now, EV_REL, REL_X, 5),
+		NewInputEvent(now, EV_ABS, ABS_PRESSURE, 20),
+		NewInputEvent(now, EV_REL, REL_Y, 1),
+		NewInputEvent(now, EV_SYN, SYN_REPORT, 0),
+		NewInputEvent(now, EV_KEY, BTN_LEFT, 1),
+		NewInputEvent(now, EV_SYN, SYN_REPORT, 0),
+		NewInputEvent(now, EV_REL, REL_X, -1),
+		NewInputEvent(now, EV_SYN, SYN_REPORT, 0),
+	}
+
+	got := c.Pop()
+	if len(got) != len(want) {
+		t.Fatalf("got %d events, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("event %d: got %v, want %v", i, &got[i], &want[i])
+		}
+	}
+
+	if c.Pop() != nil {
+		t.Error("frames left after Pop")
+	}
+}
+
+func TestCoalescerContacts(t *testing.T) {
+	c := NewCoalescer()
+	now := time.Now()
+
+	// a contact moves, lifts and a new one touches down within one window
+	frames := [][]InputEvent{
+		{NewInputEvent(now, EV_ABS, ABS_MT_POSITION_X, 100)},
+		{NewInputEvent(now, EV_ABS, ABS_MT_POSITION_X, 110)},
+		{NewInputEvent(now, EV_ABS, ABS_MT_TRACKING_ID, -1), NewInputEvent(now, EV_KEY, BTN_TOUCH, 0)},
+		{NewInputEvent(now, EV_ABS, ABS_MT_TRACKING_ID, 7), NewInputEvent(now, EV_ABS, ABS_MT_POSITION_X, 500)},
+		{NewInputEvent(now, EV_ABS, ABS_MT_POSITION_X, 510)},
+	}
+	for _, frame := range frames {
+		for _, ev := range frame {
+			c.Push(ev)
+		}
+		c.Push(NewInputEvent(now, EV_SYN, SYN_REPORT, 0))
+	}
+
+	ids := make([]EvValue, 0)
+	reports := 0
+	for _, ev := range c.Pop() {
+		switch {
+		case ev.Type == EV_ABS && ev.Code == ABS_MT_TRACKING_ID:
+			ids = append(ids, ev.Value)
+		case ev.Type == EV_SYN && ev.Code == SYN_REPORT:
+			reports++
+		}
+	}
+	if len(ids) != 2 || ids[0] != -1 || ids[1] != 7 {
+		t.Errorf("expected lift and touch, got tracking IDs %v", ids)
+	}
+	if reports != 4 {
+		t.Errorf("expected 4 frames, got %d", reports)
+	}
+}
@@ -0,0 +1,61 @@
+//go:build linux
+
+package evdev
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// CompareDevices reads two devices simultaneously and calls fn with the
+// comparison of every frame, until ctx is done or reading fails. Frames
+// unpaired for longer than timeout are reported as missing. The timeout must
+// be positive, as it also paces the polling of the devices.
+func CompareDevices(ctx context.Context, a, b *InputDevice, timeout time.Duration, fn func(FrameComparison)) error {
+	if timeout <= 0 {
+		return errors.New("evdev: comparison timeout must be positive")
+	}
+
+	p, err := NewPoller()
+	if err != nil {
+		return err
+	}
+	defer p.Close()
+
+	if err = p.Add(a); err != nil {
+		return err
+	}
+	if err = p.Add(b); err != nil {
+		return err
+	}
+
+	c := NewFrameComparer(timeout)
+	for ctx.Err() == nil {
+		// at least a millisecond, the resolution of the poll
+		wait := timeout / 2
+		if wait < time.Millisecond {
+			wait = time.Millisecond
+		}
+		events, err := p.Wait(wait)
+		if err != nil && err != ErrTimeout {
+			return err
+		}
+
+		results := make([]FrameComparison, 0)
+		for _, ev := range events {
+			if ev.Device == a {
+				results = append(results, c.FeedA(ev.Event)...)
+			} else {
+				results = append(results, c.FeedB(ev.Event)...)
+			}
+		}
+		results = append(results, c.Expire(a.Now())...)
+
+		for _, r := range results {
+			fn(r)
+		}
+	}
+
+	return ctx.Err()
+}
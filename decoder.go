@@ -0,0 +1,116 @@
+//go:build linux
+
+package evdev
+
+// StreamAnomalies counts the irregularities met while decoding a stream of
+// events.
+type StreamAnomalies struct {
+	TornEvents   int // events split across reads, reassembled
+	Resyncs      int // times bytes were skipped to find the next valid event
+	SkippedBytes int // bytes skipped, including a truncated event at the end
+	UnknownTypes int // well-formed events of unknown types, dropped
+}
+
+// EventDecoder decodes a stream of input_event structs that may be torn
+// across reads or corrupted, as produced by misbehaving FUSE or uinput
+// sources or files with trailing garbage. Events split across reads are
+// reassembled. An event with an impossible timestamp, type or code is taken
+// as a sign of a lost boundary: the decoder skips ahead byte by byte up to
+// the next valid event. Anomalies are counted rather than reported as
+// errors, and no input makes the decoder panic.
+type EventDecoder struct {
+	Layout    EventLayout
+	Anomalies StreamAnomalies
+
+	partial  []byte // undecoded bytes of the previous reads
+	skipping bool   // resynchronizing
+}
+
+// NewEventDecoder creates a decoder for events of the given layout.
+func NewEventDecoder(layout EventLayout) *EventDecoder {
+	return &EventDecoder{Layout: layout}
+}
+
+// Decode decodes the events of the next chunk of the stream. A trailing
+// incomplete event is kept for the next call.
+func (d *EventDecoder) Decode(data []byte) []InputEvent {
+	size := d.Layout.Size()
+	if len(d.partial) > 0 && !d.skipping && len(d.partial)+len(data) >= size {
+		d.Anomalies.TornEvents++
+	}
+
+	buf := append(d.partial, data...)
+	events := make([]InputEvent, 0, len(buf)/size)
+
+	i := 0
+	for ; len(buf)-i >= size; i++ {
+		ev, ok := d.decodeOne(buf[i : i+size])
+		if !ok {
+			if !d.skipping {
+				d.skipping = true
+				d.Anomalies.Resyncs++
+			}
+			d.Anomalies.SkippedBytes++
+			continue
+		}
+
+		d.skipping = false
+		if knownEventType(ev.Type) {
+			events = append(events, ev)
+		} else {
+			d.Anomalies.UnknownTypes++
+		}
+		i += size - 1
+	}
+
+	d.partial = append([]byte(nil), buf[i:]...)
+	return events
+}
+
+// Flush ends the stream, counting an incomplete event left over as skipped.
+func (d *EventDecoder) Flush() {
+	d.Anomalies.SkippedBytes += len(d.partial)
+	d.partial = nil
+	d.skipping = false
+}
+
+// Latest plausible event timestamp, in the 23rd century.
+const maxEventSec = 1 << 33
+
+// Decode an event, checking that it is plausible.
+func (d *EventDecoder) decodeOne(b []byte) (InputEvent, bool) {
+	var sec, usec int64
+	if d.Layout.LongSize == 4 {
+		sec, usec = int64(int32(d.Layout.ByteOrder.Uint32(b))), int64(int32(d.Layout.ByteOrder.Uint32(b[4:])))
+	} else {
+		sec, usec = int64(d.Layout.ByteOrder.Uint64(b)), int64(d.Layout.ByteOrder.Uint64(b[8:]))
+	}
+	if sec < 0 || sec > maxEventSec || usec < 0 || usec >= 1e6 {
+		return InputEvent{}, false
+	}
+
+	ev := d.Layout.Decode(b)
+	if ev.Type > EV_MAX {
+		return ev, false
+	}
+
+	max := CodeMax(int(ev.Type))
+	if ev.Type == EV_SYN {
+		max = SYN_MAX
+	}
+	if max >= 0 && int(ev.Code) > max {
+		return ev, false
+	}
+
+	return ev, true
+}
+
+// Check whether events of a type are defined.
+func knownEventType(evType EvType) bool {
+	switch evType {
+	case EV_SYN, EV_PWR, EV_FF_STATUS:
+		return true
+	}
+
+	return CodeMax(int(evType)) >= 0
+}
@@ -0,0 +1,116 @@
+//go:build linux
+
+package evdev
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+	"time"
+)
+
+// Encode events with a layout.
+func encodeEvents(layout EventLayout, events ...InputEvent) []byte {
+	out := make([]byte, 0)
+	b := make([]byte, layout.Size())
+	for i := range events {
+		layout.Encode(b, &events[i])
+		out = append(out, b...)
+	}
+	return out
+}
+
+func TestEventDecoder(t *testing.T) {
+	layout, _ := EventLayoutFor("amd64")
+	now := time.Unix(1700000000, 0)
+	key := NewInputEvent(now, EV_KEY, KEY_A, 1)
+	syn := NewInputEvent(now, EV_SYN, SYN_REPORT, 0)
+	unknown := NewInputEvent(now, 0x06, 0, 0)
+
+	stream := encodeEvents(layout, key, syn)
+	stream = append(stream, 0xff, 0xff, 0xff) // garbage
+	stream = append(stream, encodeEvents(layout, unknown, key, syn)...)
+	stream = append(stream, 0x01, 0x02) // trailing garbage
+
+	// torn writes: feed the stream in chunks not aligned to events
+	d := NewEventDecoder(layout)
+	events := make([]InputEvent, 0)
+	for i := 0; i < len(stream); i += 7 {
+		end := i + 7
+		if end > len(stream) {
+			end = len(stream)
+		}
+		events = append(events, d.Decode(stream[i:end])...)
+	}
+	d.Flush()
+
+	if len(events) != 4 {
+		t.Fatalf("expected 4 events, got %d: %v", len(events), events)
+	}
+	for i, want := range []InputEvent{key, syn, key, syn} {
+		if events[i] != want {
+			t.Errorf("event %d: got %v, want %v", i, &events[i], &want)
+		}
+	}
+
+	a := d.Anomalies
+	if a.Resyncs != 1 || a.SkippedBytes != 5 || a.UnknownTypes != 1 || a.TornEvents == 0 {
+		t.Errorf("unexpected anomalies %+v", a)
+	}
+}
+
+func TestResilientEventReader(t *testing.T) {
+	layout, _ := EventLayoutFor("arm")
+	syn := NewInputEvent(time.Unix(1700000000, 123456000), EV_SYN, SYN_REPORT, 0)
+
+	data := append([]byte{0xff}, encodeEvents(layout, syn, syn)...)
+	data = append(data, 0, 0, 0)
+	rr := NewResilientEventReader(bytes.NewReader(data), layout)
+
+	n := 0
+	for {
+		_, err := rr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		n++
+	}
+
+	if a := rr.Anomalies(); n != 2 || a.Resyncs != 1 || a.SkippedBytes != 4 {
+		t.Errorf("got %d events with anomalies %+v", n, a)
+	}
+}
+
+func FuzzEventDecoder(f *testing.F) {
+	layout, _ := EventLayoutFor("amd64")
+	syn := NewInputEvent(time.Unix(1700000000, 5), EV_SYN, SYN_REPORT, 0)
+	f.Add(encodeEvents(layout, syn), 5)
+	f.Add([]byte{0xff, 0x00, 0x7f}, 1)
+
+	f.Fuzz(func(t *testing.T, data []byte, chunk int) {
+		if chunk <= 0 {
+			chunk = 1
+		}
+
+		for _, layout := range []EventLayout{layout, {"mips", 4, binary.BigEndian}} {
+			d := NewEventDecoder(layout)
+			decoded := 0
+			for i := 0; i < len(data); i += chunk {
+				end := i + chunk
+				if end > len(data) || end < i {
+					end = len(data)
+				}
+				decoded += len(d.Decode(data[i:end]))
+			}
+			d.Flush()
+
+			if decoded*layout.Size() > len(data) {
+				t.Errorf("decoded %d events from %d bytes", decoded, len(data))
+			}
+		}
+	})
+}
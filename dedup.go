@@ -0,0 +1,82 @@
+package evdev
+
+import "time"
+
+// DedupFilter drops key, switch, LED and sound events that do not change
+// the state of their code, e.g. the repeated SW values some firmware emits
+// or a second key press without a release in between, leaving a stream of
+// state changes only. Frames left empty are dropped along with their
+// SYN_REPORT. The first event of a code, and the first after SYN_DROPPED,
+// always passes, as the state is unknown until then.
+type DedupFilter struct {
+	// KeepRepeats passes autorepeat events (value 2) of held keys, which
+	// are dropped by default.
+	KeepRepeats bool
+
+	// Refresh, if nonzero, lets a duplicate through once Refresh passed
+	// since the last event passed for its code, for consumers expecting a
+	// periodic confirmation of the state.
+	Refresh time.Duration
+
+	state   map[[2]int32]dedupState
+	passed  bool // events of the open frame were passed
+	dropped bool // events of the open frame were dropped
+}
+
+type dedupState struct {
+	value EvValue
+	at    time.Time // when the last event was passed
+}
+
+// NewDedupFilter creates a filter dropping autorepeats and duplicates.
+func NewDedupFilter() *DedupFilter {
+	return &DedupFilter{state: make(map[[2]int32]dedupState)}
+}
+
+// Process implements Filter.
+func (f *DedupFilter) Process(ev InputEvent) []InputEvent {
+	switch {
+	case ev.Type == EV_SYN && ev.Code == SYN_DROPPED:
+		f.state = make(map[[2]int32]dedupState)
+	case ev.Type == EV_SYN && ev.Code == SYN_REPORT:
+		empty := f.dropped && !f.passed
+		f.passed, f.dropped = false, false
+		if empty {
+			return nil
+		}
+	case ev.Type == EV_KEY, ev.Type == EV_SW, ev.Type == EV_LED, ev.Type == EV_SND:
+		if !f.changes(ev) {
+			f.dropped = true
+			return nil
+		}
+	}
+
+	if ev.Type != EV_SYN {
+		f.passed = true
+	}
+	return []InputEvent{ev}
+}
+
+// Check whether ev is to be passed, recording the state if so.
+func (f *DedupFilter) changes(ev InputEvent) bool {
+	key := [2]int32{int32(ev.Type), int32(ev.Code)}
+	t := ev.Timestamp()
+
+	if ev.Type == EV_KEY && ev.Value == EvValue(KeyHold) {
+		if !f.KeepRepeats {
+			return false
+		}
+		// repeats pass unless the key is known to be up, but do not change
+		// the state
+		s, ok := f.state[key]
+		return !ok || s.value == EvValue(KeyDown)
+	}
+
+	s, ok := f.state[key]
+	if ok && s.value == ev.Value && (f.Refresh == 0 || t.Sub(s.at) < f.Refresh) {
+		return false
+	}
+
+	f.state[key] = dedupState{value: ev.Value, at: t}
+	return true
+}
@@ -0,0 +1,57 @@
+package evdev
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDedupFilter(t *testing.T) {
+	f := NewDedupFilter()
+	f.Refresh = time.Second
+	t0 := time.Unix(1000, 0)
+
+	ev := func(d time.Duration, evType EvType, code EvCode, value int) InputEvent {
+		return NewInputEvent(t0.Add(d), evType, code, EvValue(value))
+	}
+	syn := func(d time.Duration) InputEvent {
+		return ev(d, EV_SYN, SYN_REPORT, 0)
+	}
+
+	in := []InputEvent{
+		ev(0, EV_SW, SW_LID, 1), syn(0),
+		ev(10*time.Millisecond, EV_SW, SW_LID, 1), syn(10 * time.Millisecond),
+		ev(20*time.Millisecond, EV_KEY, KEY_A, 1), ev(20*time.Millisecond, EV_MSC, MSC_SCAN, 30), syn(20 * time.Millisecond),
+		ev(30*time.Millisecond, EV_KEY, KEY_A, 1), syn(30 * time.Millisecond),
+		ev(40*time.Millisecond, EV_KEY, KEY_A, 2), syn(40 * time.Millisecond),
+		ev(50*time.Millisecond, EV_KEY, KEY_A, 0), syn(50 * time.Millisecond),
+		ev(2*time.Second, EV_SW, SW_LID, 1), syn(2 * time.Second),
+		ev(2*time.Second, EV_SYN, SYN_DROPPED, 0),
+		ev(3*time.Second, EV_KEY, KEY_A, 0), syn(3 * time.Second),
+	}
+	want := []InputEvent{
+		ev(0, EV_SW, SW_LID, 1), syn(0),
+		ev(20*time.Millisecond, EV_KEY, KEY_A, 1), ev(20*time.Millisecond, EV_MSC, MSC_SCAN, 30), syn(20 * time.Millisecond),
+		ev(50*time.Millisecond, EV_KEY, KEY_A, 0), syn(50 * time.Millisecond),
+		ev(2*time.Second, EV_SW, SW_LID, 1), syn(2 * time.Second),
+		ev(2*time.Second, EV_SYN, SYN_DROPPED, 0),
+		ev(3*time.Second, EV_KEY, KEY_A, 0), syn(3 * time.Second),
+	}
+
+	got := make([]InputEvent, 0)
+	for _, e := range in {
+		got = append(got, f.Process(e)...)
+	}
+	if !sameEvents(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	f = NewDedupFilter()
+	f.KeepRepeats = true
+	got = got[:0]
+	for _, e := range in[4:11] {
+		got = append(got, f.Process(e)...)
+	}
+	if len(got) != 5 || got[3].Value != 2 {
+		t.Errorf("expected repeats to pass, got %v", got)
+	}
+}
@@ -4,12 +4,17 @@ package evdev
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"syscall"
+	"time"
 	"unsafe"
 )
 
@@ -20,6 +25,7 @@ type InputDevice struct {
 	Name  string   // device name
 	Phys  string   // physical topology of device
 	Ident string   // unique identifier
+	Label string   // user assigned label, see DeviceLabels
 	File  *os.File // an open file handle to the input device
 
 	BusType uint16 // bus type identifier
@@ -29,22 +35,111 @@ type InputDevice struct {
 
 	EvdevVersion int // evdev protocol version
 
-	Capabilities     map[CapabilityType][]CapabilityCode // supported event types and codes.
-	CapabilitiesFlat map[int][]int
+	Capabilities    map[CapabilityType][]CapabilityCode // supported event types and codes.
+	RawCapabilities map[int]Bitset                      // raw EVIOCGBIT bitmaps by event type (EV_SYN holds the event types)
+	AbsInfos        map[int]AbsInfo                     // parameters of the absolute axes when opened
+
+	properties Bitset // INPUT_PROP_* bitmap
+	grabbed    bool   // grabbed through this handle
+	clockID    int32  // clock of the event timestamps
+
+	pending    []InputEvent  // pre-roll events returned before reading
+	decoder    *EventDecoder // set in resilient mode
+	lowLatency []InputEvent  // read buffer in low-latency mode
+
+	features *Features // probed optional ioctls, see Features
 }
 
+// ErrTimeout is returned by operations that wait for input when the wait
+// times out.
+var ErrTimeout = errors.New("evdev: timeout")
+
+// ErrClosed is returned when reading from or writing to a closed device.
+var ErrClosed = errors.New("evdev: device closed")
+
 // Open an evdev input device.
 func Open(devnode string) (*InputDevice, error) {
-	f, err := os.Open(devnode)
+	return OpenWithFlags(devnode, os.O_RDONLY)
+}
+
+// OpenWithFlags opens an evdev input device with the given os.OpenFile flags,
+// e.g. os.O_RDWR for devices that events are written to. With
+// syscall.O_NONBLOCK the device is opened as with Options.NonBlocking.
+func OpenWithFlags(devnode string, flag int) (*InputDevice, error) {
+	if flag&syscall.O_NONBLOCK != 0 {
+		return OpenWithOptions(devnode, Options{Flag: flag &^ syscall.O_NONBLOCK, NonBlocking: true})
+	}
+
+	f, err := os.OpenFile(devnode, flag, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewInputDevice(f)
+}
+
+// Options configures how OpenWithOptions opens a device.
+type Options struct {
+	Flag        int  // os.OpenFile flags, os.O_RDONLY if zero
+	NonBlocking bool // Read and ReadOne fail with syscall.EAGAIN instead of waiting
+	Grab        bool // grab the device once opened
+	PreRoll     bool // start with events describing the current state, see PreRoll
+	Resilient   bool // resynchronize on malformed input, see SetResilient
+}
+
+// OpenWithOptions opens an evdev input device configured by opts.
+func OpenWithOptions(devnode string, opts Options) (*InputDevice, error) {
+	// The file is created from a blocking descriptor, so that os.File keeps
+	// its hands off the non-blocking mode set below.
+	fd, err := syscall.Open(devnode, opts.Flag|syscall.O_CLOEXEC, 0)
 	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: devnode, Err: err}
+	}
+	f := os.NewFile(uintptr(fd), devnode)
+
+	dev, err := NewInputDevice(f)
+	if err != nil {
+		f.Close()
 		return nil, err
 	}
 
+	if opts.NonBlocking {
+		if err = syscall.SetNonblock(fd, true); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+
+	if opts.Grab {
+		if err = dev.Grab(); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+
+	if opts.Resilient {
+		dev.SetResilient()
+	}
+
+	if opts.PreRoll {
+		if err = dev.PreRoll(); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+
+	return dev, nil
+}
+
+// NewInputDevice creates an input device from an already opened devnode,
+// e.g. one received from another process. The name of the file is used as
+// the devnode path.
+func NewInputDevice(f *os.File) (*InputDevice, error) {
 	dev := InputDevice{}
-	dev.Fn = devnode
+	dev.Fn = f.Name()
 	dev.File = f
 
-	err = dev.setDeviceInfo()
+	err := dev.setDeviceInfo()
 	if err != nil {
 		return nil, err
 	}
@@ -53,17 +148,30 @@ func Open(devnode string) (*InputDevice, error) {
 		return nil, err
 	}
 
+	// not supported by very old kernels, which is the same as no properties
+	dev.properties, _ = dev.stateBits(uintptr(EVIOCGPROP))
+
 	return &dev, nil
 }
 
 // Read and return a slice of input events from device.
 func (dev *InputDevice) Read() ([]InputEvent, error) {
+	if len(dev.pending) > 0 {
+		return dev.takePending(16), nil
+	}
+	if dev.lowLatency != nil {
+		return dev.readLowLatency(dev.readBlocking)
+	}
+
 	events := make([]InputEvent, 16)
 	buffer := make([]byte, eventsize*16)
 
-	_, err := dev.File.Read(buffer)
+	n, err := dev.File.Read(buffer)
 	if err != nil {
-		return events, err
+		return events, closedErr(err)
+	}
+	if dev.decoder != nil {
+		return dev.decoder.Decode(buffer[:n]), nil
 	}
 
 	b := bytes.NewBuffer(buffer)
@@ -75,7 +183,7 @@ func (dev *InputDevice) Read() ([]InputEvent, error) {
 	// remove trailing structures
 	for i := range events {
 		if events[i].Time.Sec == 0 {
-			events = append(events[:i])
+			events = events[:i]
 			break
 		}
 	}
@@ -85,12 +193,19 @@ func (dev *InputDevice) Read() ([]InputEvent, error) {
 
 // ReadOne Read and return a single input event.
 func (dev *InputDevice) ReadOne() (*InputEvent, error) {
+	if len(dev.pending) > 0 {
+		return &dev.takePending(1)[0], nil
+	}
+	if dev.decoder != nil {
+		return dev.readOneDecoded(dev.Read)
+	}
+
 	event := InputEvent{}
 	buffer := make([]byte, eventsize)
 
 	_, err := dev.File.Read(buffer)
 	if err != nil {
-		return &event, err
+		return &event, closedErr(err)
 	}
 
 	b := bytes.NewBuffer(buffer)
@@ -102,9 +217,162 @@ func (dev *InputDevice) ReadOne() (*InputEvent, error) {
 	return &event, err
 }
 
+// ReadContext is like Read, but returns ctx.Err() if ctx is done before
+// events arrive.
+func (dev *InputDevice) ReadContext(ctx context.Context) ([]InputEvent, error) {
+	if len(dev.pending) > 0 {
+		return dev.takePending(16), nil
+	}
+	if dev.lowLatency != nil {
+		return dev.readLowLatency(func(buffer []byte) (int, error) { return dev.readContext(ctx, buffer) })
+	}
+
+	buffer := make([]byte, eventsize*16)
+
+	n, err := dev.readContext(ctx, buffer)
+	if err != nil {
+		return nil, err
+	}
+	if dev.decoder != nil {
+		return dev.decoder.Decode(buffer[:n]), nil
+	}
+
+	events := make([]InputEvent, n/eventsize)
+	err = binary.Read(bytes.NewBuffer(buffer[:n]), binary.LittleEndian, &events)
+	return events, err
+}
+
+// ReadOneContext is like ReadOne, but returns ctx.Err() if ctx is done before
+// an event arrives.
+func (dev *InputDevice) ReadOneContext(ctx context.Context) (*InputEvent, error) {
+	if len(dev.pending) > 0 {
+		return &dev.takePending(1)[0], nil
+	}
+	if dev.decoder != nil {
+		return dev.readOneDecoded(func() ([]InputEvent, error) { return dev.ReadContext(ctx) })
+	}
+
+	event := InputEvent{}
+	buffer := make([]byte, eventsize)
+
+	if _, err := dev.readContext(ctx, buffer); err != nil {
+		return nil, err
+	}
+
+	err := binary.Read(bytes.NewBuffer(buffer), binary.LittleEndian, &event)
+	return &event, err
+}
+
+// ReadTimeout is like Read, but fails with ErrTimeout if no events arrive
+// within the timeout.
+func (dev *InputDevice) ReadTimeout(timeout time.Duration) ([]InputEvent, error) {
+	if len(dev.pending) > 0 {
+		return dev.takePending(16), nil
+	}
+
+	fd := dev.File.Fd()
+	if fd == ^uintptr(0) {
+		return nil, ErrClosed
+	}
+
+	buffer := make([]byte, eventsize*16)
+	deadline := time.Now().Add(timeout)
+
+	for {
+		remaining := time.Until(deadline)
+		if remaining < 0 {
+			return nil, ErrTimeout
+		}
+
+		ok, err := waitReadable(fd, remaining)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrTimeout
+		}
+
+		n, err := readReady(fd, buffer)
+		switch {
+		case err == syscall.EAGAIN:
+			continue
+		case err != nil:
+			return nil, err
+		case n == 0:
+			return nil, io.EOF
+		}
+		if dev.decoder != nil {
+			return dev.decoder.Decode(buffer[:n]), nil
+		}
+
+		events := make([]InputEvent, n/eventsize)
+		err = binary.Read(bytes.NewBuffer(buffer[:n]), binary.LittleEndian, &events)
+		return events, err
+	}
+}
+
+// Wait for the device to become readable, then read without blocking.
+func (dev *InputDevice) readContext(ctx context.Context, buffer []byte) (int, error) {
+	fd := dev.File.Fd()
+	if fd == ^uintptr(0) {
+		return 0, ErrClosed
+	}
+
+	for {
+		if err := waitReadableContext(ctx, fd); err != nil {
+			return 0, err
+		}
+
+		n, err := readReady(fd, buffer)
+		switch {
+		case err == syscall.EAGAIN:
+			continue
+		case err != nil:
+			return 0, err
+		case n == 0:
+			return 0, io.EOF
+		}
+
+		return n, nil
+	}
+}
+
+// WriteEvent writes a single input event to the device. The device must have
+// been opened for writing.
+func (dev *InputDevice) WriteEvent(ev *InputEvent) error {
+	buffer := new(bytes.Buffer)
+
+	err := binary.Write(buffer, binary.LittleEndian, ev)
+	if err != nil {
+		return err
+	}
+
+	_, err = dev.File.Write(buffer.Bytes())
+	return closedErr(err)
+}
+
+// Close releases the grab taken through this handle, if any, and closes the
+// device. Reads and writes on a closed device fail with ErrClosed.
+func (dev *InputDevice) Close() error {
+	if dev.grabbed {
+		dev.Release()
+	}
+
+	return closedErr(dev.File.Close())
+}
+
+// Translate the error of an operation on a closed file into ErrClosed.
+func closedErr(err error) error {
+	if errors.Is(err, os.ErrClosed) {
+		return ErrClosed
+	}
+	return err
+}
+
 // Get a useful description for an input device. Example:
 //
 //	InputDevice /dev/input/event3 (fd 3)
+//	  label left kiosk panel
 //	  name Logitech USB Laser Mouse
 //	  phys usb-0000:00:12.0-2/input0
 //	  bus 0x3, vendor 0x46d, product 0xc069, version 0x110
@@ -117,65 +385,218 @@ func (dev *InputDevice) String() string {
 	}
 	rawEvTypes := strings.Join(evTypes, ", ")
 
+	label := ""
+	if dev.Label != "" {
+		label = fmt.Sprintf("  label %s\n", dev.Label)
+	}
+
 	return fmt.Sprintf(
 		"InputDevice %s (fd %d)\n"+
+			"%s"+
 			"  name %s\n"+
 			"  phys %s\n"+
 			"  ident %s\n"+
 			"  bus 0x%04x, vendor 0x%04x, product 0x%04x, version 0x%04x\n"+
 			"  events %s",
-		dev.Fn, dev.File.Fd(), dev.Name, dev.Phys, dev.Ident, dev.BusType,
+		dev.Fn, dev.File.Fd(), label, dev.Name, dev.Phys, dev.Ident, dev.BusType,
 		dev.Vendor, dev.Product, dev.Version, rawEvTypes)
 }
 
+// Fingerprint returns a string identifying the physical device across
+// reconnects and reboots, unlike its devnode. It is made of the bus type,
+// vendor, product and version ids followed by the unique identifier of the
+// device, or its physical location if it has none.
+func (dev *InputDevice) Fingerprint() string {
+	location := dev.Ident
+	if location == "" {
+		location = dev.Phys
+	}
+
+	return fmt.Sprintf("%04x:%04x:%04x:%04x:%s",
+		dev.BusType, dev.Vendor, dev.Product, dev.Version, location)
+}
+
 // Gets the event types and event codes that the input device supports.
 func (dev *InputDevice) setDeviceCapabilities() error {
+	err := dev.setRawCapabilities()
+	if err != nil {
+		return err
+	}
+
 	// Capabilities is a map of supported event types to lists of
 	// events e.g: {1: [272, 273, 274, 275], 2: [0, 1, 6, 8]}
-	// capabilities := make(map[int][]int)
 	capabilities := make(map[CapabilityType][]CapabilityCode)
-
-	evBits := new([(EV_MAX + 1) / 8]byte)
-	codeBits := new([(KEY_MAX + 1) / 8]byte)
-	// absbits  := new([6]byte)
-
-	err := ioctl(dev.File.Fd(), uintptr(EVIOCGBIT(0, EV_MAX)), unsafe.Pointer(evBits))
-	if err != 0 {
-		return err
-	}
+	evBits := dev.RawCapabilities[EV_SYN]
 
 	// Build a map of the device's capabilities
-	for evType := 0; evType < EV_MAX; evType++ {
-		if evBits[evType/8]&(1<<uint(evType%8)) != 0 {
-			eventCodes := make([]CapabilityCode, 0)
-
-			err = ioctl(dev.File.Fd(), uintptr(EVIOCGBIT(evType, KEY_MAX)), unsafe.Pointer(codeBits))
-			if err != 0 {
-				// ignore invalid capabilities such as EV_REP for some devices
-				if err == syscall.EINVAL {
-					continue
-				}
+	for evType := 0; evType <= EV_MAX; evType++ {
+		if !evBits.Has(evType) {
+			continue
+		}
 
-				return err
-			}
+		eventCodes := make([]CapabilityCode, 0)
+		codeBits, ok := dev.RawCapabilities[evType]
 
-			for evCode := 0; evCode < KEY_MAX; evCode++ {
-				if codeBits[evCode/8]&(1<<uint(evCode%8)) != 0 {
-					c := CapabilityCode{evCode, ByEventType[evType][evCode]}
+		switch {
+		case evType == EV_SYN:
+			// the EV_SYN bitmap holds the event types, not codes; every
+			// device emits these synchronization events
+			for _, evCode := range []int{SYN_REPORT, SYN_DROPPED} {
+				eventCodes = append(eventCodes, CapabilityCode{evCode, CodeName(evType, evCode)})
+			}
+		case evType == EV_FF_STATUS:
+			// force feedback status can't be queried with EVIOCGBIT either
+			for evCode := 0; evCode <= FF_STATUS_MAX; evCode++ {
+				eventCodes = append(eventCodes, CapabilityCode{evCode, CodeName(evType, evCode)})
+			}
+		case evType == EV_REP && !ok:
+			// autorepeat can't be queried with EVIOCGBIT
+			for evCode := 0; evCode <= REP_MAX; evCode++ {
+				eventCodes = append(eventCodes, CapabilityCode{evCode, CodeName(evType, evCode)})
+			}
+		default:
+			for evCode := 0; evCode <= CodeMax(evType); evCode++ {
+				if codeBits.Has(evCode) {
+					c := CapabilityCode{evCode, CodeName(evType, evCode)}
 					eventCodes = append(eventCodes, c)
 				}
 			}
-
-			// capabilities[EV_KEY] = [KEY_A, KEY_B, KEY_C, ...]
-			key := CapabilityType{evType, EV[evType]}
-			capabilities[key] = eventCodes
 		}
+
+		// capabilities[EV_KEY] = [KEY_A, KEY_B, KEY_C, ...]
+		key := CapabilityType{evType, TypeName(evType)}
+		capabilities[key] = eventCodes
 	}
 
 	dev.Capabilities = capabilities
+
+	// absInfos[ABS_X] = {Min: 0, Max: 4095, ...}
+	absInfos := make(map[int]AbsInfo)
+	for _, axis := range dev.CodesFor(EV_ABS) {
+		info, err := dev.AbsInfo(axis)
+		if err != nil {
+			return err
+		}
+		absInfos[axis] = info
+	}
+	dev.AbsInfos = absInfos
+
+	return nil
+}
+
+// EventTypes returns the event types supported by the device, in ascending order.
+func (dev *InputDevice) EventTypes() []int {
+	types := make([]int, 0, len(dev.Capabilities))
+	for evType := range dev.Capabilities {
+		types = append(types, evType.Type)
+	}
+	sort.Ints(types)
+
+	return types
+}
+
+// CodesFor returns the codes of an event type supported by the device, in
+// ascending order. It returns nil if the event type is not supported.
+func (dev *InputDevice) CodesFor(evType int) []int {
+	for t, eventCodes := range dev.Capabilities {
+		if t.Type != evType {
+			continue
+		}
+
+		codes := make([]int, 0, len(eventCodes))
+		for _, c := range eventCodes {
+			codes = append(codes, c.Code)
+		}
+		sort.Ints(codes)
+
+		return codes
+	}
+
+	return nil
+}
+
+// CodeMax returns the highest code of an event type, or -1 for event types
+// without codes. For EV_SYN it returns EV_MAX, as the EV_SYN bitmap of
+// a device holds the event types it supports.
+func CodeMax(evType int) int {
+	switch evType {
+	case EV_SYN:
+		return EV_MAX
+	case EV_KEY:
+		return KEY_MAX
+	case EV_REL:
+		return REL_MAX
+	case EV_ABS:
+		return ABS_MAX
+	case EV_MSC:
+		return MSC_MAX
+	case EV_SW:
+		return SW_MAX
+	case EV_LED:
+		return LED_MAX
+	case EV_SND:
+		return SND_MAX
+	case EV_FF:
+		return FF_MAX
+	case EV_REP:
+		return REP_MAX
+	}
+
+	return -1
+}
+
+// CapabilityBits returns the raw EVIOCGBIT bitmap of an event type, with
+// one bit per code up to CodeMax(evType). Bit n is stored in byte n/8 at
+// position n%8.
+func (dev *InputDevice) CapabilityBits(evType int) (Bitset, error) {
+	max := CodeMax(evType)
+	if max < 0 {
+		return nil, syscall.EINVAL
+	}
+
+	bits := NewBitset(max)
+	err := ioctl(dev.File.Fd(), uintptr(EVIOCGBIT(evType, len(bits))), unsafe.Pointer(&bits[0]))
+	if err != 0 {
+		return nil, err
+	}
+
+	return bits, nil
+}
+
+// Gets the raw capability bitmaps of all event types the device supports.
+func (dev *InputDevice) setRawCapabilities() error {
+	evBits, err := dev.CapabilityBits(EV_SYN)
+	if err != nil {
+		return err
+	}
+
+	raw := map[int]Bitset{EV_SYN: evBits}
+	for evType := 1; evType <= EV_MAX; evType++ {
+		if !evBits.Has(evType) || CodeMax(evType) < 0 {
+			continue
+		}
+
+		bits, err := dev.CapabilityBits(evType)
+		if err == syscall.EINVAL {
+			// not every event type can be queried, e.g. EV_REP
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		raw[evType] = bits
+	}
+
+	dev.RawCapabilities = raw
 	return nil
 }
 
+// HasCode reports whether the device supports a code of an event type, in
+// constant time. For EV_SYN it reports whether an event type is supported.
+func (dev *InputDevice) HasCode(evType, code int) bool {
+	return dev.RawCapabilities[evType].Has(code)
+}
+
 // An all-in-one function for describing an input device.
 func (dev *InputDevice) setDeviceInfo() error {
 	info := deviceInfo{}
@@ -221,29 +642,82 @@ func (dev *InputDevice) setDeviceInfo() error {
 
 // GetRepeatRate as a two element array.
 //
-//	[0] repeat rate in characters per second
-//	[1] amount of time that a key must be depressed before it will start
+//	[0] amount of time that a key must be depressed before it will start
 //	    to repeat (in milliseconds)
+//	[1] time between repeats (in milliseconds)
+//
+// Deprecated: use RepeatSettings, which reports errors.
 func (dev *InputDevice) GetRepeatRate() *[2]uint {
-	repeatDelay := new([2]uint)
+	repeatDelay := new([2]uint32)
 	ioctl(dev.File.Fd(), uintptr(EVIOCGREP), unsafe.Pointer(repeatDelay))
 
-	return repeatDelay
+	return &[2]uint{uint(repeatDelay[0]), uint(repeatDelay[1])}
 }
 
-// SetRepeatRate Set repeat rate and delay.
-func (dev *InputDevice) SetRepeatRate(repeat, delay uint) {
-	repeatDelay := new([2]uint)
-	repeatDelay[0], repeatDelay[1] = repeat, delay
+// SetRepeatRate Set repeat delay and period (in milliseconds).
+//
+// Deprecated: use SetRepeatSettings, which reports errors.
+func (dev *InputDevice) SetRepeatRate(delay, period uint) {
+	repeatDelay := new([2]uint32)
+	repeatDelay[0], repeatDelay[1] = uint32(delay), uint32(period)
 	ioctl(dev.File.Fd(), uintptr(EVIOCSREP), unsafe.Pointer(repeatDelay))
 }
 
+// RepeatSettings describes the kernel autorepeat of a device.
+type RepeatSettings struct {
+	Delay  time.Duration // time a key must be held before it starts to repeat
+	Period time.Duration // time between repeats
+}
+
+// RepeatSettings returns the current autorepeat settings of the device.
+func (dev *InputDevice) RepeatSettings() (RepeatSettings, error) {
+	rep := new([2]uint32)
+
+	if err := ioctl(dev.File.Fd(), uintptr(EVIOCGREP), unsafe.Pointer(rep)); err != 0 {
+		return RepeatSettings{}, err
+	}
+
+	return RepeatSettings{
+		Delay:  time.Duration(rep[0]) * time.Millisecond,
+		Period: time.Duration(rep[1]) * time.Millisecond,
+	}, nil
+}
+
+// SetRepeatSettings changes the autorepeat settings of the device. If the
+// device refuses EVIOCSREP, the settings are written as EV_REP events
+// instead, which requires the device to be opened for writing.
+func (dev *InputDevice) SetRepeatSettings(rs RepeatSettings) error {
+	rep := new([2]uint32)
+	rep[0] = uint32(rs.Delay / time.Millisecond)
+	rep[1] = uint32(rs.Period / time.Millisecond)
+
+	if err := ioctl(dev.File.Fd(), uintptr(EVIOCSREP), unsafe.Pointer(rep)); err == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	events := []InputEvent{
+		NewInputEvent(now, EV_REP, REP_DELAY, EvValue(rep[0])),
+		NewInputEvent(now, EV_REP, REP_PERIOD, EvValue(rep[1])),
+		NewInputEvent(now, EV_SYN, SYN_REPORT, 0),
+	}
+
+	for i := range events {
+		if err := dev.WriteEvent(&events[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // Grab the input device exclusively.
 func (dev *InputDevice) Grab() error {
 	grab := int(1)
 	if err := ioctl(dev.File.Fd(), uintptr(EVIOCGRAB), unsafe.Pointer(&grab)); err != 0 {
 		return err
 	}
+	dev.grabbed = true
 
 	return nil
 }
@@ -253,6 +727,21 @@ func (dev *InputDevice) Release() error {
 	if err := ioctl(dev.File.Fd(), uintptr(EVIOCGRAB), unsafe.Pointer(nil)); err != 0 {
 		return err
 	}
+	dev.grabbed = false
+
+	return nil
+}
+
+// Revoke permanently revokes access to the device through this file and all
+// its duplicates, including ones passed to other processes (see SendDevice).
+// Afterwards reads fail with ENODEV. This lets a privileged launcher hand a
+// device to an unprivileged process and take it back later, the way Wayland
+// compositors do on session switches.
+func (dev *InputDevice) Revoke() error {
+	if err := ioctl(dev.File.Fd(), uintptr(EVIOCREVOKE), unsafe.Pointer(nil)); err != 0 {
+		return err
+	}
+	dev.grabbed = false
 
 	return nil
 }
@@ -267,29 +756,48 @@ type CapabilityCode struct {
 	Name string
 }
 
+// AbsInfo describes an absolute axis. It corresponds to the input_absinfo
+// struct.
 type AbsInfo struct {
-	value      int32
-	minimum    int32
-	maximum    int32
-	fuzz       int32
-	flat       int32
-	resolution int32
+	Value      int32 // current value of the axis
+	Min        int32 // minimum value of the axis
+	Max        int32 // maximum value of the axis
+	Fuzz       int32 // noise filtered by the kernel
+	Flat       int32 // size of the dead zone around the center
+	Resolution int32 // units per mm, or per radian for rotational axes
 }
 
-// Corresponds to the input_id struct.
-type deviceInfo struct {
-	busType, vendor, product, version uint16
+// AbsInfo returns the parameters of an absolute axis with EVIOCGABS.
+func (dev *InputDevice) AbsInfo(axis int) (AbsInfo, error) {
+	info := AbsInfo{}
+
+	err := ioctl(dev.File.Fd(), uintptr(EVIOCGABS(axis)), unsafe.Pointer(&info))
+	if err != 0 {
+		return info, err
+	}
+
+	return info, nil
 }
 
-// Return the keys of a map as a slice (dict.keys())
-func keys(cap *map[int][]int) []int {
-	slice := make([]int, 0)
+// SetAbsInfo sets the parameters of an absolute axis with EVIOCSABS, e.g. to
+// apply a touchscreen calibration. Changing the parameters usually requires
+// opening the device read-write (see OpenWithFlags) and root privileges.
+func (dev *InputDevice) SetAbsInfo(axis int, info AbsInfo) error {
+	err := ioctl(dev.File.Fd(), uintptr(EVIOCSABS(axis)), unsafe.Pointer(&info))
+	if err != 0 {
+		return err
+	}
 
-	for key := range *cap {
-		slice = append(slice, key)
+	if dev.AbsInfos != nil {
+		dev.AbsInfos[axis] = info
 	}
 
-	return slice
+	return nil
+}
+
+// Corresponds to the input_id struct.
+type deviceInfo struct {
+	busType, vendor, product, version uint16
 }
 
 // IsInputDevice determine if a path exist and is a character input device.
@@ -0,0 +1,73 @@
+//go:build linux
+
+package evdev
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+func TestCapabilityAccessors(t *testing.T) {
+	dev := &InputDevice{
+		Capabilities: map[CapabilityType][]CapabilityCode{
+			{EV_REL, "EV_REL"}: {{REL_WHEEL, "REL_WHEEL"}, {REL_X, "REL_X"}, {REL_Y, "REL_Y"}},
+			{EV_SYN, "EV_SYN"}: {{SYN_REPORT, "SYN_REPORT"}},
+			{EV_KEY, "EV_KEY"}: {{BTN_RIGHT, "BTN_RIGHT"}, {BTN_LEFT, "BTN_LEFT"}},
+		},
+	}
+
+	if types := dev.EventTypes(); !reflect.DeepEqual(types, []int{EV_SYN, EV_KEY, EV_REL}) {
+		t.Errorf("unexpected event types: %v", types)
+	}
+
+	if codes := dev.CodesFor(EV_REL); !reflect.DeepEqual(codes, []int{REL_X, REL_Y, REL_WHEEL}) {
+		t.Errorf("unexpected EV_REL codes: %v", codes)
+	}
+
+	if codes := dev.CodesFor(EV_KEY); !reflect.DeepEqual(codes, []int{BTN_LEFT, BTN_RIGHT}) {
+		t.Errorf("unexpected EV_KEY codes: %v", codes)
+	}
+
+	if codes := dev.CodesFor(EV_ABS); codes != nil {
+		t.Errorf("expected no EV_ABS codes, got %v", codes)
+	}
+}
+
+func TestReadContext(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	dev := &InputDevice{File: r}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := dev.ReadContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+
+	ev := NewInputEvent(time.Now(), EV_KEY, KEY_A, 1)
+	w.Write(unsafe.Slice((*byte)(unsafe.Pointer(&ev)), eventsize))
+
+	got, err := dev.ReadOneContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *got != ev {
+		t.Errorf("got %v, want %v", got, &ev)
+	}
+
+	if _, err := dev.ReadTimeout(10 * time.Millisecond); err != ErrTimeout {
+		t.Errorf("expected ErrTimeout, got %v", err)
+	}
+
+	dev.Close()
+	if _, err := dev.ReadContext(context.Background()); err != ErrClosed {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+}
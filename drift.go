@@ -0,0 +1,313 @@
+//go:build linux
+
+package evdev
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// AxisCorrection corrects the rest position of a worn gamepad stick.
+type AxisCorrection struct {
+	Center   int32 `json:"center"`   // value of the axis at rest
+	Deadzone int32 `json:"deadzone"` // distance from Center reported as centered
+}
+
+// GamepadCorrections holds the corrections of the axes of a gamepad. In
+// JSON the axes are keyed by name, e.g. "ABS_X".
+type GamepadCorrections map[EvCode]AxisCorrection
+
+// MarshalJSON implements json.Marshaler.
+func (c GamepadCorrections) MarshalJSON() ([]byte, error) {
+	named := make(map[string]AxisCorrection, len(c))
+	for axis, corr := range c {
+		named[CodeName(EV_ABS, int(axis))] = corr
+	}
+
+	return json.Marshal(named)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (c *GamepadCorrections) UnmarshalJSON(data []byte) error {
+	named := make(map[string]AxisCorrection)
+	if err := json.Unmarshal(data, &named); err != nil {
+		return err
+	}
+
+	*c = make(GamepadCorrections, len(named))
+	for name, corr := range named {
+		axis, ok := inputCodeByName(name)
+		if !ok || !strings.HasPrefix(name, "ABS_") {
+			return fmt.Errorf("evdev: unknown axis %q", name)
+		}
+		(*c)[EvCode(axis)] = corr
+	}
+
+	return nil
+}
+
+// GamepadCorrectionStore maps device fingerprints to the corrections of
+// their axes, stored as a JSON object in a file like DeviceLabels.
+type GamepadCorrectionStore map[string]GamepadCorrections
+
+// LoadGamepadCorrectionStore reads corrections from a file. A missing file
+// yields an empty store.
+func LoadGamepadCorrectionStore(path string) (GamepadCorrectionStore, error) {
+	store := make(GamepadCorrectionStore)
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(data, &store); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// Save writes the corrections to a file.
+func (s GamepadCorrectionStore) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// For returns the corrections of a device, creating them if it has none.
+func (s GamepadCorrectionStore) For(dev *InputDevice) GamepadCorrections {
+	c, ok := s[dev.Fingerprint()]
+	if !ok {
+		c = make(GamepadCorrections)
+		s[dev.Fingerprint()] = c
+	}
+
+	return c
+}
+
+// AxisCorrectionFilter is a Filter applying corrections to the axes of a
+// gamepad: values within the deadzone around the corrected center are
+// reported as the nominal center of the axis, the middle of its range, and
+// the rest of the travel is stretched to the full range.
+type AxisCorrectionFilter struct {
+	Corrections GamepadCorrections
+	Axes        map[int]AbsInfo // ranges of the axes, e.g. InputDevice.AbsInfos
+}
+
+// NewAxisCorrectionFilter creates a filter applying corrections to axes
+// with the given ranges.
+func NewAxisCorrectionFilter(corrections GamepadCorrections, axes map[int]AbsInfo) *AxisCorrectionFilter {
+	return &AxisCorrectionFilter{Corrections: corrections, Axes: axes}
+}
+
+// Process implements Filter.
+func (f *AxisCorrectionFilter) Process(ev InputEvent) []InputEvent {
+	if ev.Type != EV_ABS {
+		return []InputEvent{ev}
+	}
+	corr, ok := f.Corrections[ev.Code]
+	info, known := f.Axes[int(ev.Code)]
+	if !ok || !known {
+		return []InputEvent{ev}
+	}
+
+	ev.Value = EvValue(corr.apply(int32(ev.Value), info))
+	return []InputEvent{ev}
+}
+
+// Map a value of an axis with the range of info.
+func (c AxisCorrection) apply(v int32, info AbsInfo) int32 {
+	nominal := int64(info.Min+info.Max) / 2
+	d := int64(v) - int64(c.Center)
+
+	var out int64
+	switch {
+	case d > int64(c.Deadzone):
+		travel := int64(info.Max) - int64(c.Center) - int64(c.Deadzone)
+		out = nominal + (d-int64(c.Deadzone))*(int64(info.Max)-nominal)/maxInt64(travel, 1)
+	case d < -int64(c.Deadzone):
+		travel := int64(c.Center) - int64(c.Deadzone) - int64(info.Min)
+		out = nominal + (d+int64(c.Deadzone))*(nominal-int64(info.Min))/maxInt64(travel, 1)
+	default:
+		out = nominal
+	}
+
+	if out > int64(info.Max) {
+		out = int64(info.Max)
+	}
+	if out < int64(info.Min) {
+		out = int64(info.Min)
+	}
+	return int32(out)
+}
+
+func maxInt64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// DriftMonitor detects stick drift: an axis resting at a steady value off
+// its center, outside of the deadzone. Values within MaxOffset of the
+// center that vary by no more than MaxNoise for Window are taken as the rest
+// position. When the rest position is outside of the current deadzone, the
+// monitor suggests a correction centered on it, with a deadzone covering
+// its noise, through OnDrift, and with AutoApply applies it to Corrections,
+// e.g. those of an AxisCorrectionFilter and a GamepadCorrectionStore.
+type DriftMonitor struct {
+	Axes        map[int]AbsInfo // ranges of the monitored axes
+	Corrections GamepadCorrections
+
+	Window    time.Duration
+	MaxOffset float64 // fraction of the half range
+	MaxNoise  float64 // fraction of the half range
+
+	AutoApply bool
+	OnDrift   func(axis EvCode, suggested AxisCorrection)
+
+	rest map[EvCode]*axisRest
+}
+
+// Rest window of an axis.
+type axisRest struct {
+	start, last time.Time
+	min, max    int32
+	sum         int64
+	n           int64
+	reported    bool // drift of this rest position was reported
+}
+
+// NewDriftMonitor creates a monitor of axes with the given ranges, judging
+// rest positions over 3s within a fifth of the half range of the center and
+// varying by no more than 2%.
+func NewDriftMonitor(axes map[int]AbsInfo, corrections GamepadCorrections) *DriftMonitor {
+	return &DriftMonitor{
+		Axes:        axes,
+		Corrections: corrections,
+		Window:      3 * time.Second,
+		MaxOffset:   0.2,
+		MaxNoise:    0.02,
+		rest:        make(map[EvCode]*axisRest),
+	}
+}
+
+// NewDriftMonitorFor creates a monitor of the sticks of a gamepad, applying
+// drift corrections to corrections.
+func NewDriftMonitorFor(dev *InputDevice, corrections GamepadCorrections) *DriftMonitor {
+	axes := make(map[int]AbsInfo)
+	for _, axis := range []int{ABS_X, ABS_Y, ABS_RX, ABS_RY} {
+		if info, ok := dev.AbsInfos[axis]; ok {
+			axes[axis] = info
+		}
+	}
+
+	return NewDriftMonitor(axes, corrections)
+}
+
+// Feed passes an event of the gamepad to the monitor.
+func (m *DriftMonitor) Feed(ev *InputEvent) {
+	if ev.Type != EV_ABS {
+		return
+	}
+	info, ok := m.Axes[int(ev.Code)]
+	if !ok {
+		return
+	}
+
+	t := ev.Timestamp()
+	m.check(ev.Code, t)
+	m.sample(ev.Code, info, int32(ev.Value), t)
+}
+
+// Tick judges the rest positions at now. Resting axes send no events, so
+// it must be called periodically. Axes without events yet are taken to
+// rest at the value of their AbsInfo.
+func (m *DriftMonitor) Tick(now time.Time) {
+	for axis, info := range m.Axes {
+		code := EvCode(axis)
+		if _, ok := m.rest[code]; !ok {
+			m.sample(code, info, info.Value, now)
+		}
+		m.check(code, now)
+	}
+}
+
+// Add a sample to the rest window of an axis.
+func (m *DriftMonitor) sample(axis EvCode, info AbsInfo, v int32, t time.Time) {
+	half := float64(info.Max-info.Min) / 2
+	center := (info.Min + info.Max) / 2
+
+	if float64(abs32(v-center)) > m.MaxOffset*half {
+		// in use
+		delete(m.rest, axis)
+		return
+	}
+
+	r, ok := m.rest[axis]
+	if ok && float64(max32(r.max, v)-min32(r.min, v)) > 2*m.MaxNoise*half {
+		// moved to another rest position
+		ok = false
+	}
+	if !ok {
+		r = &axisRest{start: t, min: v, max: v}
+		m.rest[axis] = r
+	}
+
+	r.min, r.max = min32(r.min, v), max32(r.max, v)
+	r.last = t
+	r.sum += int64(v)
+	r.n++
+}
+
+// Report drift once an axis rested for Window.
+func (m *DriftMonitor) check(axis EvCode, now time.Time) {
+	r, ok := m.rest[axis]
+	if !ok || r.reported || now.Sub(r.start) < m.Window {
+		return
+	}
+	info := m.Axes[int(axis)]
+
+	current, ok := m.Corrections[axis]
+	if !ok {
+		current = AxisCorrection{Center: (info.Min + info.Max) / 2, Deadzone: info.Flat}
+	}
+
+	mean := int32(r.sum / r.n)
+	if abs32(r.min-current.Center) <= current.Deadzone && abs32(r.max-current.Center) <= current.Deadzone {
+		return
+	}
+	r.reported = true
+
+	// cover the noise, with a margin of the same size
+	suggested := AxisCorrection{Center: mean, Deadzone: max32(info.Flat, 2*max32(r.max-mean, mean-r.min))}
+	if m.AutoApply && m.Corrections != nil {
+		m.Corrections[axis] = suggested
+	}
+	if m.OnDrift != nil {
+		m.OnDrift(axis, suggested)
+	}
+}
+
+func min32(a, b int32) int32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max32(a, b int32) int32 {
+	if a > b {
+		return a
+	}
+	return b
+}
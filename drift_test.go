@@ -0,0 +1,91 @@
+//go:build linux
+
+package evdev
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestDriftMonitor(t *testing.T) {
+	axes := map[int]AbsInfo{
+		ABS_X: {Min: -32768, Max: 32767, Flat: 128},
+		ABS_Y: {Min: -32768, Max: 32767, Flat: 128},
+	}
+	corrections := make(GamepadCorrections)
+	m := NewDriftMonitor(axes, corrections)
+	m.AutoApply = true
+
+	var reported []EvCode
+	var suggested AxisCorrection
+	m.OnDrift = func(axis EvCode, c AxisCorrection) {
+		reported = append(reported, axis)
+		suggested = c
+	}
+
+	t0 := time.Unix(1000, 0)
+	feed := func(d time.Duration, axis EvCode, v int32) {
+		ev := NewInputEvent(t0.Add(d), EV_ABS, axis, EvValue(v))
+		m.Feed(&ev)
+	}
+
+	// X rests off center, Y within its flat
+	for i := 0; i < 40; i++ {
+		d := time.Duration(i) * 100 * time.Millisecond
+		feed(d, ABS_X, 3000+int32(i%3-1)*50)
+		feed(d, ABS_Y, int32(i%3-1)*50)
+		m.Tick(t0.Add(d))
+	}
+	if len(reported) != 1 || reported[0] != ABS_X {
+		t.Fatalf("expected drift of ABS_X only, got %v", reported)
+	}
+	if suggested.Center != 3000 || suggested.Deadzone < 100 || suggested.Deadzone > 200 {
+		t.Errorf("unexpected correction %+v", suggested)
+	}
+	if corrections[ABS_X] != suggested {
+		t.Errorf("correction not applied: %+v", corrections)
+	}
+
+	// corrected, the same rest position is no longer drift
+	reported = nil
+	feed(5*time.Second, ABS_X, 30000)
+	for i := 0; i < 40; i++ {
+		feed(6*time.Second+time.Duration(i)*100*time.Millisecond, ABS_X, 3000)
+	}
+	if len(reported) != 0 {
+		t.Errorf("expected no drift after correction, got %v", reported)
+	}
+}
+
+func TestAxisCorrectionFilter(t *testing.T) {
+	info := AbsInfo{Min: 0, Max: 1000}
+	f := NewAxisCorrectionFilter(GamepadCorrections{ABS_X: {Center: 600, Deadzone: 50}}, map[int]AbsInfo{ABS_X: info})
+
+	for _, c := range []struct{ in, want int32 }{
+		{600, 500},
+		{640, 500},
+		{560, 500},
+		{1000, 1000},
+		{0, 0},
+		{825, 750},
+		{275, 250},
+	} {
+		got := f.Process(NewInputEvent(time.Unix(1000, 0), EV_ABS, ABS_X, EvValue(c.in)))
+		if len(got) != 1 || int32(got[0].Value) != c.want {
+			t.Errorf("%d: got %v, want %d", c.in, got, c.want)
+		}
+	}
+
+	data, err := json.Marshal(GamepadCorrectionStore{"pad": f.Corrections})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var store GamepadCorrectionStore
+	if err = json.Unmarshal(data, &store); err != nil {
+		t.Fatal(err)
+	}
+	if store["pad"][ABS_X] != f.Corrections[ABS_X] {
+		t.Errorf("round trip through %s lost the correction", data)
+	}
+}
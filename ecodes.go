@@ -11,6 +11,12 @@
 //   evdev.EV[evdev.EV_KEY]  // "EV_KEY"
 //   evdev.ByEventType[EV_REL][0]  // "REL_X"
 //
+// Building with the evdev_minimal tag leaves out the names of all constants
+// but the event types (see ecodes_names.go). The reverse mappings are then
+// empty and code names fall back to numbers, e.g. "KEY_0x01e". The names
+// of keys, buttons and axes are still accepted when parsing chords, layouts
+// and configuration files, as are such numeric names.
+//
 // Generated on: Linux 5.19.13-arch1-1 #1 SMP PREEMPT_DYNAMIC Tue, 04 Oct 2022 14:36:58 +0000 x86_64
 
 package evdev
@@ -71,6 +77,14 @@ const (
 	FF_AUTOCENTER                = 0x61
 	FF_MAX_EFFECTS               = FF_GAIN
 	FF_MAX                       = 0x7f
+	INPUT_PROP_POINTER           = 0x00
+	INPUT_PROP_DIRECT            = 0x01
+	INPUT_PROP_BUTTONPAD         = 0x02
+	INPUT_PROP_SEMI_MT           = 0x03
+	INPUT_PROP_TOPBUTTONPAD      = 0x04
+	INPUT_PROP_POINTING_STICK    = 0x05
+	INPUT_PROP_ACCELEROMETER     = 0x06
+	INPUT_PROP_MAX               = 0x1f
 	EV_SYN                       = 0x00
 	EV_KEY                       = 0x01
 	EV_REL                       = 0x02
@@ -825,811 +839,40 @@ const (
 	SND_MAX                      = 0x07
 )
 
-var ecodes = map[string]int{
-	"EV_VERSION":                   EV_VERSION,
-	"ID_BUS":                       ID_BUS,
-	"ID_VENDOR":                    ID_VENDOR,
-	"ID_PRODUCT":                   ID_PRODUCT,
-	"ID_VERSION":                   ID_VERSION,
-	"BUS_PCI":                      BUS_PCI,
-	"BUS_ISAPNP":                   BUS_ISAPNP,
-	"BUS_USB":                      BUS_USB,
-	"BUS_HIL":                      BUS_HIL,
-	"BUS_BLUETOOTH":                BUS_BLUETOOTH,
-	"BUS_VIRTUAL":                  BUS_VIRTUAL,
-	"BUS_ISA":                      BUS_ISA,
-	"BUS_I8042":                    BUS_I8042,
-	"BUS_XTKBD":                    BUS_XTKBD,
-	"BUS_RS232":                    BUS_RS232,
-	"BUS_GAMEPORT":                 BUS_GAMEPORT,
-	"BUS_PARPORT":                  BUS_PARPORT,
-	"BUS_AMIGA":                    BUS_AMIGA,
-	"BUS_ADB":                      BUS_ADB,
-	"BUS_I2C":                      BUS_I2C,
-	"BUS_HOST":                     BUS_HOST,
-	"BUS_GSC":                      BUS_GSC,
-	"BUS_ATARI":                    BUS_ATARI,
-	"BUS_SPI":                      BUS_SPI,
-	"BUS_RMI":                      BUS_RMI,
-	"BUS_CEC":                      BUS_CEC,
-	"BUS_INTEL_ISHTP":              BUS_INTEL_ISHTP,
-	"FF_STATUS_STOPPED":            FF_STATUS_STOPPED,
-	"FF_STATUS_PLAYING":            FF_STATUS_PLAYING,
-	"FF_STATUS_MAX":                FF_STATUS_MAX,
-	"FF_RUMBLE":                    FF_RUMBLE,
-	"FF_PERIODIC":                  FF_PERIODIC,
-	"FF_CONSTANT":                  FF_CONSTANT,
-	"FF_SPRING":                    FF_SPRING,
-	"FF_FRICTION":                  FF_FRICTION,
-	"FF_DAMPER":                    FF_DAMPER,
-	"FF_INERTIA":                   FF_INERTIA,
-	"FF_RAMP":                      FF_RAMP,
-	"FF_EFFECT_MIN":                FF_EFFECT_MIN,
-	"FF_EFFECT_MAX":                FF_EFFECT_MAX,
-	"FF_SQUARE":                    FF_SQUARE,
-	"FF_TRIANGLE":                  FF_TRIANGLE,
-	"FF_SINE":                      FF_SINE,
-	"FF_SAW_UP":                    FF_SAW_UP,
-	"FF_SAW_DOWN":                  FF_SAW_DOWN,
-	"FF_CUSTOM":                    FF_CUSTOM,
-	"FF_WAVEFORM_MIN":              FF_WAVEFORM_MIN,
-	"FF_WAVEFORM_MAX":              FF_WAVEFORM_MAX,
-	"FF_GAIN":                      FF_GAIN,
-	"FF_AUTOCENTER":                FF_AUTOCENTER,
-	"FF_MAX_EFFECTS":               FF_MAX_EFFECTS,
-	"FF_MAX":                       FF_MAX,
-	"EV_SYN":                       EV_SYN,
-	"EV_KEY":                       EV_KEY,
-	"EV_REL":                       EV_REL,
-	"EV_ABS":                       EV_ABS,
-	"EV_MSC":                       EV_MSC,
-	"EV_SW":                        EV_SW,
-	"EV_LED":                       EV_LED,
-	"EV_SND":                       EV_SND,
-	"EV_REP":                       EV_REP,
-	"EV_FF":                        EV_FF,
-	"EV_PWR":                       EV_PWR,
-	"EV_FF_STATUS":                 EV_FF_STATUS,
-	"EV_MAX":                       EV_MAX,
-	"SYN_REPORT":                   SYN_REPORT,
-	"SYN_CONFIG":                   SYN_CONFIG,
-	"SYN_MT_REPORT":                SYN_MT_REPORT,
-	"SYN_DROPPED":                  SYN_DROPPED,
-	"SYN_MAX":                      SYN_MAX,
-	"KEY_RESERVED":                 KEY_RESERVED,
-	"KEY_ESC":                      KEY_ESC,
-	"KEY_1":                        KEY_1,
-	"KEY_2":                        KEY_2,
-	"KEY_3":                        KEY_3,
-	"KEY_4":                        KEY_4,
-	"KEY_5":                        KEY_5,
-	"KEY_6":                        KEY_6,
-	"KEY_7":                        KEY_7,
-	"KEY_8":                        KEY_8,
-	"KEY_9":                        KEY_9,
-	"KEY_0":                        KEY_0,
-	"KEY_MINUS":                    KEY_MINUS,
-	"KEY_EQUAL":                    KEY_EQUAL,
-	"KEY_BACKSPACE":                KEY_BACKSPACE,
-	"KEY_TAB":                      KEY_TAB,
-	"KEY_Q":                        KEY_Q,
-	"KEY_W":                        KEY_W,
-	"KEY_E":                        KEY_E,
-	"KEY_R":                        KEY_R,
-	"KEY_T":                        KEY_T,
-	"KEY_Y":                        KEY_Y,
-	"KEY_U":                        KEY_U,
-	"KEY_I":                        KEY_I,
-	"KEY_O":                        KEY_O,
-	"KEY_P":                        KEY_P,
-	"KEY_LEFTBRACE":                KEY_LEFTBRACE,
-	"KEY_RIGHTBRACE":               KEY_RIGHTBRACE,
-	"KEY_ENTER":                    KEY_ENTER,
-	"KEY_LEFTCTRL":                 KEY_LEFTCTRL,
-	"KEY_A":                        KEY_A,
-	"KEY_S":                        KEY_S,
-	"KEY_D":                        KEY_D,
-	"KEY_F":                        KEY_F,
-	"KEY_G":                        KEY_G,
-	"KEY_H":                        KEY_H,
-	"KEY_J":                        KEY_J,
-	"KEY_K":                        KEY_K,
-	"KEY_L":                        KEY_L,
-	"KEY_SEMICOLON":                KEY_SEMICOLON,
-	"KEY_APOSTROPHE":               KEY_APOSTROPHE,
-	"KEY_GRAVE":                    KEY_GRAVE,
-	"KEY_LEFTSHIFT":                KEY_LEFTSHIFT,
-	"KEY_BACKSLASH":                KEY_BACKSLASH,
-	"KEY_Z":                        KEY_Z,
-	"KEY_X":                        KEY_X,
-	"KEY_C":                        KEY_C,
-	"KEY_V":                        KEY_V,
-	"KEY_B":                        KEY_B,
-	"KEY_N":                        KEY_N,
-	"KEY_M":                        KEY_M,
-	"KEY_COMMA":                    KEY_COMMA,
-	"KEY_DOT":                      KEY_DOT,
-	"KEY_SLASH":                    KEY_SLASH,
-	"KEY_RIGHTSHIFT":               KEY_RIGHTSHIFT,
-	"KEY_KPASTERISK":               KEY_KPASTERISK,
-	"KEY_LEFTALT":                  KEY_LEFTALT,
-	"KEY_SPACE":                    KEY_SPACE,
-	"KEY_CAPSLOCK":                 KEY_CAPSLOCK,
-	"KEY_F1":                       KEY_F1,
-	"KEY_F2":                       KEY_F2,
-	"KEY_F3":                       KEY_F3,
-	"KEY_F4":                       KEY_F4,
-	"KEY_F5":                       KEY_F5,
-	"KEY_F6":                       KEY_F6,
-	"KEY_F7":                       KEY_F7,
-	"KEY_F8":                       KEY_F8,
-	"KEY_F9":                       KEY_F9,
-	"KEY_F10":                      KEY_F10,
-	"KEY_NUMLOCK":                  KEY_NUMLOCK,
-	"KEY_SCROLLLOCK":               KEY_SCROLLLOCK,
-	"KEY_KP7":                      KEY_KP7,
-	"KEY_KP8":                      KEY_KP8,
-	"KEY_KP9":                      KEY_KP9,
-	"KEY_KPMINUS":                  KEY_KPMINUS,
-	"KEY_KP4":                      KEY_KP4,
-	"KEY_KP5":                      KEY_KP5,
-	"KEY_KP6":                      KEY_KP6,
-	"KEY_KPPLUS":                   KEY_KPPLUS,
-	"KEY_KP1":                      KEY_KP1,
-	"KEY_KP2":                      KEY_KP2,
-	"KEY_KP3":                      KEY_KP3,
-	"KEY_KP0":                      KEY_KP0,
-	"KEY_KPDOT":                    KEY_KPDOT,
-	"KEY_ZENKAKUHANKAKU":           KEY_ZENKAKUHANKAKU,
-	"KEY_102ND":                    KEY_102ND,
-	"KEY_F11":                      KEY_F11,
-	"KEY_F12":                      KEY_F12,
-	"KEY_RO":                       KEY_RO,
-	"KEY_KATAKANA":                 KEY_KATAKANA,
-	"KEY_HIRAGANA":                 KEY_HIRAGANA,
-	"KEY_HENKAN":                   KEY_HENKAN,
-	"KEY_KATAKANAHIRAGANA":         KEY_KATAKANAHIRAGANA,
-	"KEY_MUHENKAN":                 KEY_MUHENKAN,
-	"KEY_KPJPCOMMA":                KEY_KPJPCOMMA,
-	"KEY_KPENTER":                  KEY_KPENTER,
-	"KEY_RIGHTCTRL":                KEY_RIGHTCTRL,
-	"KEY_KPSLASH":                  KEY_KPSLASH,
-	"KEY_SYSRQ":                    KEY_SYSRQ,
-	"KEY_RIGHTALT":                 KEY_RIGHTALT,
-	"KEY_LINEFEED":                 KEY_LINEFEED,
-	"KEY_HOME":                     KEY_HOME,
-	"KEY_UP":                       KEY_UP,
-	"KEY_PAGEUP":                   KEY_PAGEUP,
-	"KEY_LEFT":                     KEY_LEFT,
-	"KEY_RIGHT":                    KEY_RIGHT,
-	"KEY_END":                      KEY_END,
-	"KEY_DOWN":                     KEY_DOWN,
-	"KEY_PAGEDOWN":                 KEY_PAGEDOWN,
-	"KEY_INSERT":                   KEY_INSERT,
-	"KEY_DELETE":                   KEY_DELETE,
-	"KEY_MACRO":                    KEY_MACRO,
-	"KEY_MUTE":                     KEY_MUTE,
-	"KEY_VOLUMEDOWN":               KEY_VOLUMEDOWN,
-	"KEY_VOLUMEUP":                 KEY_VOLUMEUP,
-	"KEY_POWER":                    KEY_POWER,
-	"KEY_KPEQUAL":                  KEY_KPEQUAL,
-	"KEY_KPPLUSMINUS":              KEY_KPPLUSMINUS,
-	"KEY_PAUSE":                    KEY_PAUSE,
-	"KEY_SCALE":                    KEY_SCALE,
-	"KEY_KPCOMMA":                  KEY_KPCOMMA,
-	"KEY_HANGEUL":                  KEY_HANGEUL,
-	"KEY_HANGUEL":                  KEY_HANGUEL,
-	"KEY_HANJA":                    KEY_HANJA,
-	"KEY_YEN":                      KEY_YEN,
-	"KEY_LEFTMETA":                 KEY_LEFTMETA,
-	"KEY_RIGHTMETA":                KEY_RIGHTMETA,
-	"KEY_COMPOSE":                  KEY_COMPOSE,
-	"KEY_STOP":                     KEY_STOP,
-	"KEY_AGAIN":                    KEY_AGAIN,
-	"KEY_PROPS":                    KEY_PROPS,
-	"KEY_UNDO":                     KEY_UNDO,
-	"KEY_FRONT":                    KEY_FRONT,
-	"KEY_COPY":                     KEY_COPY,
-	"KEY_OPEN":                     KEY_OPEN,
-	"KEY_PASTE":                    KEY_PASTE,
-	"KEY_FIND":                     KEY_FIND,
-	"KEY_CUT":                      KEY_CUT,
-	"KEY_HELP":                     KEY_HELP,
-	"KEY_MENU":                     KEY_MENU,
-	"KEY_CALC":                     KEY_CALC,
-	"KEY_SETUP":                    KEY_SETUP,
-	"KEY_SLEEP":                    KEY_SLEEP,
-	"KEY_WAKEUP":                   KEY_WAKEUP,
-	"KEY_FILE":                     KEY_FILE,
-	"KEY_SENDFILE":                 KEY_SENDFILE,
-	"KEY_DELETEFILE":               KEY_DELETEFILE,
-	"KEY_XFER":                     KEY_XFER,
-	"KEY_PROG1":                    KEY_PROG1,
-	"KEY_PROG2":                    KEY_PROG2,
-	"KEY_WWW":                      KEY_WWW,
-	"KEY_MSDOS":                    KEY_MSDOS,
-	"KEY_COFFEE":                   KEY_COFFEE,
-	"KEY_SCREENLOCK":               KEY_SCREENLOCK,
-	"KEY_ROTATE_DISPLAY":           KEY_ROTATE_DISPLAY,
-	"KEY_DIRECTION":                KEY_DIRECTION,
-	"KEY_CYCLEWINDOWS":             KEY_CYCLEWINDOWS,
-	"KEY_MAIL":                     KEY_MAIL,
-	"KEY_BOOKMARKS":                KEY_BOOKMARKS,
-	"KEY_COMPUTER":                 KEY_COMPUTER,
-	"KEY_BACK":                     KEY_BACK,
-	"KEY_FORWARD":                  KEY_FORWARD,
-	"KEY_CLOSECD":                  KEY_CLOSECD,
-	"KEY_EJECTCD":                  KEY_EJECTCD,
-	"KEY_EJECTCLOSECD":             KEY_EJECTCLOSECD,
-	"KEY_NEXTSONG":                 KEY_NEXTSONG,
-	"KEY_PLAYPAUSE":                KEY_PLAYPAUSE,
-	"KEY_PREVIOUSSONG":             KEY_PREVIOUSSONG,
-	"KEY_STOPCD":                   KEY_STOPCD,
-	"KEY_RECORD":                   KEY_RECORD,
-	"KEY_REWIND":                   KEY_REWIND,
-	"KEY_PHONE":                    KEY_PHONE,
-	"KEY_ISO":                      KEY_ISO,
-	"KEY_CONFIG":                   KEY_CONFIG,
-	"KEY_HOMEPAGE":                 KEY_HOMEPAGE,
-	"KEY_REFRESH":                  KEY_REFRESH,
-	"KEY_EXIT":                     KEY_EXIT,
-	"KEY_MOVE":                     KEY_MOVE,
-	"KEY_EDIT":                     KEY_EDIT,
-	"KEY_SCROLLUP":                 KEY_SCROLLUP,
-	"KEY_SCROLLDOWN":               KEY_SCROLLDOWN,
-	"KEY_KPLEFTPAREN":              KEY_KPLEFTPAREN,
-	"KEY_KPRIGHTPAREN":             KEY_KPRIGHTPAREN,
-	"KEY_NEW":                      KEY_NEW,
-	"KEY_REDO":                     KEY_REDO,
-	"KEY_F13":                      KEY_F13,
-	"KEY_F14":                      KEY_F14,
-	"KEY_F15":                      KEY_F15,
-	"KEY_F16":                      KEY_F16,
-	"KEY_F17":                      KEY_F17,
-	"KEY_F18":                      KEY_F18,
-	"KEY_F19":                      KEY_F19,
-	"KEY_F20":                      KEY_F20,
-	"KEY_F21":                      KEY_F21,
-	"KEY_F22":                      KEY_F22,
-	"KEY_F23":                      KEY_F23,
-	"KEY_F24":                      KEY_F24,
-	"KEY_PLAYCD":                   KEY_PLAYCD,
-	"KEY_PAUSECD":                  KEY_PAUSECD,
-	"KEY_PROG3":                    KEY_PROG3,
-	"KEY_PROG4":                    KEY_PROG4,
-	"KEY_ALL_APPLICATIONS":         KEY_ALL_APPLICATIONS,
-	"KEY_DASHBOARD":                KEY_DASHBOARD,
-	"KEY_SUSPEND":                  KEY_SUSPEND,
-	"KEY_CLOSE":                    KEY_CLOSE,
-	"KEY_PLAY":                     KEY_PLAY,
-	"KEY_FASTFORWARD":              KEY_FASTFORWARD,
-	"KEY_BASSBOOST":                KEY_BASSBOOST,
-	"KEY_PRINT":                    KEY_PRINT,
-	"KEY_HP":                       KEY_HP,
-	"KEY_CAMERA":                   KEY_CAMERA,
-	"KEY_SOUND":                    KEY_SOUND,
-	"KEY_QUESTION":                 KEY_QUESTION,
-	"KEY_EMAIL":                    KEY_EMAIL,
-	"KEY_CHAT":                     KEY_CHAT,
-	"KEY_SEARCH":                   KEY_SEARCH,
-	"KEY_CONNECT":                  KEY_CONNECT,
-	"KEY_FINANCE":                  KEY_FINANCE,
-	"KEY_SPORT":                    KEY_SPORT,
-	"KEY_SHOP":                     KEY_SHOP,
-	"KEY_ALTERASE":                 KEY_ALTERASE,
-	"KEY_CANCEL":                   KEY_CANCEL,
-	"KEY_BRIGHTNESSDOWN":           KEY_BRIGHTNESSDOWN,
-	"KEY_BRIGHTNESSUP":             KEY_BRIGHTNESSUP,
-	"KEY_MEDIA":                    KEY_MEDIA,
-	"KEY_SWITCHVIDEOMODE":          KEY_SWITCHVIDEOMODE,
-	"KEY_KBDILLUMTOGGLE":           KEY_KBDILLUMTOGGLE,
-	"KEY_KBDILLUMDOWN":             KEY_KBDILLUMDOWN,
-	"KEY_KBDILLUMUP":               KEY_KBDILLUMUP,
-	"KEY_SEND":                     KEY_SEND,
-	"KEY_REPLY":                    KEY_REPLY,
-	"KEY_FORWARDMAIL":              KEY_FORWARDMAIL,
-	"KEY_SAVE":                     KEY_SAVE,
-	"KEY_DOCUMENTS":                KEY_DOCUMENTS,
-	"KEY_BATTERY":                  KEY_BATTERY,
-	"KEY_BLUETOOTH":                KEY_BLUETOOTH,
-	"KEY_WLAN":                     KEY_WLAN,
-	"KEY_UWB":                      KEY_UWB,
-	"KEY_UNKNOWN":                  KEY_UNKNOWN,
-	"KEY_VIDEO_NEXT":               KEY_VIDEO_NEXT,
-	"KEY_VIDEO_PREV":               KEY_VIDEO_PREV,
-	"KEY_BRIGHTNESS_CYCLE":         KEY_BRIGHTNESS_CYCLE,
-	"KEY_BRIGHTNESS_AUTO":          KEY_BRIGHTNESS_AUTO,
-	"KEY_BRIGHTNESS_ZERO":          KEY_BRIGHTNESS_ZERO,
-	"KEY_DISPLAY_OFF":              KEY_DISPLAY_OFF,
-	"KEY_WWAN":                     KEY_WWAN,
-	"KEY_WIMAX":                    KEY_WIMAX,
-	"KEY_RFKILL":                   KEY_RFKILL,
-	"KEY_MICMUTE":                  KEY_MICMUTE,
-	"BTN_MISC":                     BTN_MISC,
-	"BTN_0":                        BTN_0,
-	"BTN_1":                        BTN_1,
-	"BTN_2":                        BTN_2,
-	"BTN_3":                        BTN_3,
-	"BTN_4":                        BTN_4,
-	"BTN_5":                        BTN_5,
-	"BTN_6":                        BTN_6,
-	"BTN_7":                        BTN_7,
-	"BTN_8":                        BTN_8,
-	"BTN_9":                        BTN_9,
-	"BTN_MOUSE":                    BTN_MOUSE,
-	"BTN_LEFT":                     BTN_LEFT,
-	"BTN_RIGHT":                    BTN_RIGHT,
-	"BTN_MIDDLE":                   BTN_MIDDLE,
-	"BTN_SIDE":                     BTN_SIDE,
-	"BTN_EXTRA":                    BTN_EXTRA,
-	"BTN_FORWARD":                  BTN_FORWARD,
-	"BTN_BACK":                     BTN_BACK,
-	"BTN_TASK":                     BTN_TASK,
-	"BTN_JOYSTICK":                 BTN_JOYSTICK,
-	"BTN_TRIGGER":                  BTN_TRIGGER,
-	"BTN_THUMB":                    BTN_THUMB,
-	"BTN_THUMB2":                   BTN_THUMB2,
-	"BTN_TOP":                      BTN_TOP,
-	"BTN_TOP2":                     BTN_TOP2,
-	"BTN_PINKIE":                   BTN_PINKIE,
-	"BTN_BASE":                     BTN_BASE,
-	"BTN_BASE2":                    BTN_BASE2,
-	"BTN_BASE3":                    BTN_BASE3,
-	"BTN_BASE4":                    BTN_BASE4,
-	"BTN_BASE5":                    BTN_BASE5,
-	"BTN_BASE6":                    BTN_BASE6,
-	"BTN_DEAD":                     BTN_DEAD,
-	"BTN_GAMEPAD":                  BTN_GAMEPAD,
-	"BTN_SOUTH":                    BTN_SOUTH,
-	"BTN_A":                        BTN_A,
-	"BTN_EAST":                     BTN_EAST,
-	"BTN_B":                        BTN_B,
-	"BTN_C":                        BTN_C,
-	"BTN_NORTH":                    BTN_NORTH,
-	"BTN_X":                        BTN_X,
-	"BTN_WEST":                     BTN_WEST,
-	"BTN_Y":                        BTN_Y,
-	"BTN_Z":                        BTN_Z,
-	"BTN_TL":                       BTN_TL,
-	"BTN_TR":                       BTN_TR,
-	"BTN_TL2":                      BTN_TL2,
-	"BTN_TR2":                      BTN_TR2,
-	"BTN_SELECT":                   BTN_SELECT,
-	"BTN_START":                    BTN_START,
-	"BTN_MODE":                     BTN_MODE,
-	"BTN_THUMBL":                   BTN_THUMBL,
-	"BTN_THUMBR":                   BTN_THUMBR,
-	"BTN_DIGI":                     BTN_DIGI,
-	"BTN_TOOL_PEN":                 BTN_TOOL_PEN,
-	"BTN_TOOL_RUBBER":              BTN_TOOL_RUBBER,
-	"BTN_TOOL_BRUSH":               BTN_TOOL_BRUSH,
-	"BTN_TOOL_PENCIL":              BTN_TOOL_PENCIL,
-	"BTN_TOOL_AIRBRUSH":            BTN_TOOL_AIRBRUSH,
-	"BTN_TOOL_FINGER":              BTN_TOOL_FINGER,
-	"BTN_TOOL_MOUSE":               BTN_TOOL_MOUSE,
-	"BTN_TOOL_LENS":                BTN_TOOL_LENS,
-	"BTN_TOOL_QUINTTAP":            BTN_TOOL_QUINTTAP,
-	"BTN_STYLUS3":                  BTN_STYLUS3,
-	"BTN_TOUCH":                    BTN_TOUCH,
-	"BTN_STYLUS":                   BTN_STYLUS,
-	"BTN_STYLUS2":                  BTN_STYLUS2,
-	"BTN_TOOL_DOUBLETAP":           BTN_TOOL_DOUBLETAP,
-	"BTN_TOOL_TRIPLETAP":           BTN_TOOL_TRIPLETAP,
-	"BTN_TOOL_QUADTAP":             BTN_TOOL_QUADTAP,
-	"BTN_WHEEL":                    BTN_WHEEL,
-	"BTN_GEAR_DOWN":                BTN_GEAR_DOWN,
-	"BTN_GEAR_UP":                  BTN_GEAR_UP,
-	"KEY_OK":                       KEY_OK,
-	"KEY_SELECT":                   KEY_SELECT,
-	"KEY_GOTO":                     KEY_GOTO,
-	"KEY_CLEAR":                    KEY_CLEAR,
-	"KEY_POWER2":                   KEY_POWER2,
-	"KEY_OPTION":                   KEY_OPTION,
-	"KEY_INFO":                     KEY_INFO,
-	"KEY_TIME":                     KEY_TIME,
-	"KEY_VENDOR":                   KEY_VENDOR,
-	"KEY_ARCHIVE":                  KEY_ARCHIVE,
-	"KEY_PROGRAM":                  KEY_PROGRAM,
-	"KEY_CHANNEL":                  KEY_CHANNEL,
-	"KEY_FAVORITES":                KEY_FAVORITES,
-	"KEY_EPG":                      KEY_EPG,
-	"KEY_PVR":                      KEY_PVR,
-	"KEY_MHP":                      KEY_MHP,
-	"KEY_LANGUAGE":                 KEY_LANGUAGE,
-	"KEY_TITLE":                    KEY_TITLE,
-	"KEY_SUBTITLE":                 KEY_SUBTITLE,
-	"KEY_ANGLE":                    KEY_ANGLE,
-	"KEY_FULL_SCREEN":              KEY_FULL_SCREEN,
-	"KEY_ZOOM":                     KEY_ZOOM,
-	"KEY_MODE":                     KEY_MODE,
-	"KEY_KEYBOARD":                 KEY_KEYBOARD,
-	"KEY_ASPECT_RATIO":             KEY_ASPECT_RATIO,
-	"KEY_SCREEN":                   KEY_SCREEN,
-	"KEY_PC":                       KEY_PC,
-	"KEY_TV":                       KEY_TV,
-	"KEY_TV2":                      KEY_TV2,
-	"KEY_VCR":                      KEY_VCR,
-	"KEY_VCR2":                     KEY_VCR2,
-	"KEY_SAT":                      KEY_SAT,
-	"KEY_SAT2":                     KEY_SAT2,
-	"KEY_CD":                       KEY_CD,
-	"KEY_TAPE":                     KEY_TAPE,
-	"KEY_RADIO":                    KEY_RADIO,
-	"KEY_TUNER":                    KEY_TUNER,
-	"KEY_PLAYER":                   KEY_PLAYER,
-	"KEY_TEXT":                     KEY_TEXT,
-	"KEY_DVD":                      KEY_DVD,
-	"KEY_AUX":                      KEY_AUX,
-	"KEY_MP3":                      KEY_MP3,
-	"KEY_AUDIO":                    KEY_AUDIO,
-	"KEY_VIDEO":                    KEY_VIDEO,
-	"KEY_DIRECTORY":                KEY_DIRECTORY,
-	"KEY_LIST":                     KEY_LIST,
-	"KEY_MEMO":                     KEY_MEMO,
-	"KEY_CALENDAR":                 KEY_CALENDAR,
-	"KEY_RED":                      KEY_RED,
-	"KEY_GREEN":                    KEY_GREEN,
-	"KEY_YELLOW":                   KEY_YELLOW,
-	"KEY_BLUE":                     KEY_BLUE,
-	"KEY_CHANNELUP":                KEY_CHANNELUP,
-	"KEY_CHANNELDOWN":              KEY_CHANNELDOWN,
-	"KEY_FIRST":                    KEY_FIRST,
-	"KEY_LAST":                     KEY_LAST,
-	"KEY_AB":                       KEY_AB,
-	"KEY_NEXT":                     KEY_NEXT,
-	"KEY_RESTART":                  KEY_RESTART,
-	"KEY_SLOW":                     KEY_SLOW,
-	"KEY_SHUFFLE":                  KEY_SHUFFLE,
-	"KEY_BREAK":                    KEY_BREAK,
-	"KEY_PREVIOUS":                 KEY_PREVIOUS,
-	"KEY_DIGITS":                   KEY_DIGITS,
-	"KEY_TEEN":                     KEY_TEEN,
-	"KEY_TWEN":                     KEY_TWEN,
-	"KEY_VIDEOPHONE":               KEY_VIDEOPHONE,
-	"KEY_GAMES":                    KEY_GAMES,
-	"KEY_ZOOMIN":                   KEY_ZOOMIN,
-	"KEY_ZOOMOUT":                  KEY_ZOOMOUT,
-	"KEY_ZOOMRESET":                KEY_ZOOMRESET,
-	"KEY_WORDPROCESSOR":            KEY_WORDPROCESSOR,
-	"KEY_EDITOR":                   KEY_EDITOR,
-	"KEY_SPREADSHEET":              KEY_SPREADSHEET,
-	"KEY_GRAPHICSEDITOR":           KEY_GRAPHICSEDITOR,
-	"KEY_PRESENTATION":             KEY_PRESENTATION,
-	"KEY_DATABASE":                 KEY_DATABASE,
-	"KEY_NEWS":                     KEY_NEWS,
-	"KEY_VOICEMAIL":                KEY_VOICEMAIL,
-	"KEY_ADDRESSBOOK":              KEY_ADDRESSBOOK,
-	"KEY_MESSENGER":                KEY_MESSENGER,
-	"KEY_DISPLAYTOGGLE":            KEY_DISPLAYTOGGLE,
-	"KEY_BRIGHTNESS_TOGGLE":        KEY_BRIGHTNESS_TOGGLE,
-	"KEY_SPELLCHECK":               KEY_SPELLCHECK,
-	"KEY_LOGOFF":                   KEY_LOGOFF,
-	"KEY_DOLLAR":                   KEY_DOLLAR,
-	"KEY_EURO":                     KEY_EURO,
-	"KEY_FRAMEBACK":                KEY_FRAMEBACK,
-	"KEY_FRAMEFORWARD":             KEY_FRAMEFORWARD,
-	"KEY_CONTEXT_MENU":             KEY_CONTEXT_MENU,
-	"KEY_MEDIA_REPEAT":             KEY_MEDIA_REPEAT,
-	"KEY_10CHANNELSUP":             KEY_10CHANNELSUP,
-	"KEY_10CHANNELSDOWN":           KEY_10CHANNELSDOWN,
-	"KEY_IMAGES":                   KEY_IMAGES,
-	"KEY_NOTIFICATION_CENTER":      KEY_NOTIFICATION_CENTER,
-	"KEY_PICKUP_PHONE":             KEY_PICKUP_PHONE,
-	"KEY_HANGUP_PHONE":             KEY_HANGUP_PHONE,
-	"KEY_DEL_EOL":                  KEY_DEL_EOL,
-	"KEY_DEL_EOS":                  KEY_DEL_EOS,
-	"KEY_INS_LINE":                 KEY_INS_LINE,
-	"KEY_DEL_LINE":                 KEY_DEL_LINE,
-	"KEY_FN":                       KEY_FN,
-	"KEY_FN_ESC":                   KEY_FN_ESC,
-	"KEY_FN_F1":                    KEY_FN_F1,
-	"KEY_FN_F2":                    KEY_FN_F2,
-	"KEY_FN_F3":                    KEY_FN_F3,
-	"KEY_FN_F4":                    KEY_FN_F4,
-	"KEY_FN_F5":                    KEY_FN_F5,
-	"KEY_FN_F6":                    KEY_FN_F6,
-	"KEY_FN_F7":                    KEY_FN_F7,
-	"KEY_FN_F8":                    KEY_FN_F8,
-	"KEY_FN_F9":                    KEY_FN_F9,
-	"KEY_FN_F10":                   KEY_FN_F10,
-	"KEY_FN_F11":                   KEY_FN_F11,
-	"KEY_FN_F12":                   KEY_FN_F12,
-	"KEY_FN_1":                     KEY_FN_1,
-	"KEY_FN_2":                     KEY_FN_2,
-	"KEY_FN_D":                     KEY_FN_D,
-	"KEY_FN_E":                     KEY_FN_E,
-	"KEY_FN_F":                     KEY_FN_F,
-	"KEY_FN_S":                     KEY_FN_S,
-	"KEY_FN_B":                     KEY_FN_B,
-	"KEY_FN_RIGHT_SHIFT":           KEY_FN_RIGHT_SHIFT,
-	"KEY_BRL_DOT1":                 KEY_BRL_DOT1,
-	"KEY_BRL_DOT2":                 KEY_BRL_DOT2,
-	"KEY_BRL_DOT3":                 KEY_BRL_DOT3,
-	"KEY_BRL_DOT4":                 KEY_BRL_DOT4,
-	"KEY_BRL_DOT5":                 KEY_BRL_DOT5,
-	"KEY_BRL_DOT6":                 KEY_BRL_DOT6,
-	"KEY_BRL_DOT7":                 KEY_BRL_DOT7,
-	"KEY_BRL_DOT8":                 KEY_BRL_DOT8,
-	"KEY_BRL_DOT9":                 KEY_BRL_DOT9,
-	"KEY_BRL_DOT10":                KEY_BRL_DOT10,
-	"KEY_NUMERIC_0":                KEY_NUMERIC_0,
-	"KEY_NUMERIC_1":                KEY_NUMERIC_1,
-	"KEY_NUMERIC_2":                KEY_NUMERIC_2,
-	"KEY_NUMERIC_3":                KEY_NUMERIC_3,
-	"KEY_NUMERIC_4":                KEY_NUMERIC_4,
-	"KEY_NUMERIC_5":                KEY_NUMERIC_5,
-	"KEY_NUMERIC_6":                KEY_NUMERIC_6,
-	"KEY_NUMERIC_7":                KEY_NUMERIC_7,
-	"KEY_NUMERIC_8":                KEY_NUMERIC_8,
-	"KEY_NUMERIC_9":                KEY_NUMERIC_9,
-	"KEY_NUMERIC_STAR":             KEY_NUMERIC_STAR,
-	"KEY_NUMERIC_POUND":            KEY_NUMERIC_POUND,
-	"KEY_NUMERIC_A":                KEY_NUMERIC_A,
-	"KEY_NUMERIC_B":                KEY_NUMERIC_B,
-	"KEY_NUMERIC_C":                KEY_NUMERIC_C,
-	"KEY_NUMERIC_D":                KEY_NUMERIC_D,
-	"KEY_CAMERA_FOCUS":             KEY_CAMERA_FOCUS,
-	"KEY_WPS_BUTTON":               KEY_WPS_BUTTON,
-	"KEY_TOUCHPAD_TOGGLE":          KEY_TOUCHPAD_TOGGLE,
-	"KEY_TOUCHPAD_ON":              KEY_TOUCHPAD_ON,
-	"KEY_TOUCHPAD_OFF":             KEY_TOUCHPAD_OFF,
-	"KEY_CAMERA_ZOOMIN":            KEY_CAMERA_ZOOMIN,
-	"KEY_CAMERA_ZOOMOUT":           KEY_CAMERA_ZOOMOUT,
-	"KEY_CAMERA_UP":                KEY_CAMERA_UP,
-	"KEY_CAMERA_DOWN":              KEY_CAMERA_DOWN,
-	"KEY_CAMERA_LEFT":              KEY_CAMERA_LEFT,
-	"KEY_CAMERA_RIGHT":             KEY_CAMERA_RIGHT,
-	"KEY_ATTENDANT_ON":             KEY_ATTENDANT_ON,
-	"KEY_ATTENDANT_OFF":            KEY_ATTENDANT_OFF,
-	"KEY_ATTENDANT_TOGGLE":         KEY_ATTENDANT_TOGGLE,
-	"KEY_LIGHTS_TOGGLE":            KEY_LIGHTS_TOGGLE,
-	"BTN_DPAD_UP":                  BTN_DPAD_UP,
-	"BTN_DPAD_DOWN":                BTN_DPAD_DOWN,
-	"BTN_DPAD_LEFT":                BTN_DPAD_LEFT,
-	"BTN_DPAD_RIGHT":               BTN_DPAD_RIGHT,
-	"KEY_ALS_TOGGLE":               KEY_ALS_TOGGLE,
-	"KEY_ROTATE_LOCK_TOGGLE":       KEY_ROTATE_LOCK_TOGGLE,
-	"KEY_BUTTONCONFIG":             KEY_BUTTONCONFIG,
-	"KEY_TASKMANAGER":              KEY_TASKMANAGER,
-	"KEY_JOURNAL":                  KEY_JOURNAL,
-	"KEY_CONTROLPANEL":             KEY_CONTROLPANEL,
-	"KEY_APPSELECT":                KEY_APPSELECT,
-	"KEY_SCREENSAVER":              KEY_SCREENSAVER,
-	"KEY_VOICECOMMAND":             KEY_VOICECOMMAND,
-	"KEY_ASSISTANT":                KEY_ASSISTANT,
-	"KEY_KBD_LAYOUT_NEXT":          KEY_KBD_LAYOUT_NEXT,
-	"KEY_EMOJI_PICKER":             KEY_EMOJI_PICKER,
-	"KEY_DICTATE":                  KEY_DICTATE,
-	"KEY_BRIGHTNESS_MIN":           KEY_BRIGHTNESS_MIN,
-	"KEY_BRIGHTNESS_MAX":           KEY_BRIGHTNESS_MAX,
-	"KEY_KBDINPUTASSIST_PREV":      KEY_KBDINPUTASSIST_PREV,
-	"KEY_KBDINPUTASSIST_NEXT":      KEY_KBDINPUTASSIST_NEXT,
-	"KEY_KBDINPUTASSIST_PREVGROUP": KEY_KBDINPUTASSIST_PREVGROUP,
-	"KEY_KBDINPUTASSIST_NEXTGROUP": KEY_KBDINPUTASSIST_NEXTGROUP,
-	"KEY_KBDINPUTASSIST_ACCEPT":    KEY_KBDINPUTASSIST_ACCEPT,
-	"KEY_KBDINPUTASSIST_CANCEL":    KEY_KBDINPUTASSIST_CANCEL,
-	"KEY_RIGHT_UP":                 KEY_RIGHT_UP,
-	"KEY_RIGHT_DOWN":               KEY_RIGHT_DOWN,
-	"KEY_LEFT_UP":                  KEY_LEFT_UP,
-	"KEY_LEFT_DOWN":                KEY_LEFT_DOWN,
-	"KEY_ROOT_MENU":                KEY_ROOT_MENU,
-	"KEY_MEDIA_TOP_MENU":           KEY_MEDIA_TOP_MENU,
-	"KEY_NUMERIC_11":               KEY_NUMERIC_11,
-	"KEY_NUMERIC_12":               KEY_NUMERIC_12,
-	"KEY_AUDIO_DESC":               KEY_AUDIO_DESC,
-	"KEY_3D_MODE":                  KEY_3D_MODE,
-	"KEY_NEXT_FAVORITE":            KEY_NEXT_FAVORITE,
-	"KEY_STOP_RECORD":              KEY_STOP_RECORD,
-	"KEY_PAUSE_RECORD":             KEY_PAUSE_RECORD,
-	"KEY_VOD":                      KEY_VOD,
-	"KEY_UNMUTE":                   KEY_UNMUTE,
-	"KEY_FASTREVERSE":              KEY_FASTREVERSE,
-	"KEY_SLOWREVERSE":              KEY_SLOWREVERSE,
-	"KEY_DATA":                     KEY_DATA,
-	"KEY_ONSCREEN_KEYBOARD":        KEY_ONSCREEN_KEYBOARD,
-	"KEY_PRIVACY_SCREEN_TOGGLE":    KEY_PRIVACY_SCREEN_TOGGLE,
-	"KEY_SELECTIVE_SCREENSHOT":     KEY_SELECTIVE_SCREENSHOT,
-	"KEY_NEXT_ELEMENT":             KEY_NEXT_ELEMENT,
-	"KEY_PREVIOUS_ELEMENT":         KEY_PREVIOUS_ELEMENT,
-	"KEY_AUTOPILOT_ENGAGE_TOGGLE":  KEY_AUTOPILOT_ENGAGE_TOGGLE,
-	"KEY_MARK_WAYPOINT":            KEY_MARK_WAYPOINT,
-	"KEY_SOS":                      KEY_SOS,
-	"KEY_NAV_CHART":                KEY_NAV_CHART,
-	"KEY_FISHING_CHART":            KEY_FISHING_CHART,
-	"KEY_SINGLE_RANGE_RADAR":       KEY_SINGLE_RANGE_RADAR,
-	"KEY_DUAL_RANGE_RADAR":         KEY_DUAL_RANGE_RADAR,
-	"KEY_RADAR_OVERLAY":            KEY_RADAR_OVERLAY,
-	"KEY_TRADITIONAL_SONAR":        KEY_TRADITIONAL_SONAR,
-	"KEY_CLEARVU_SONAR":            KEY_CLEARVU_SONAR,
-	"KEY_SIDEVU_SONAR":             KEY_SIDEVU_SONAR,
-	"KEY_NAV_INFO":                 KEY_NAV_INFO,
-	"KEY_BRIGHTNESS_MENU":          KEY_BRIGHTNESS_MENU,
-	"KEY_MACRO1":                   KEY_MACRO1,
-	"KEY_MACRO2":                   KEY_MACRO2,
-	"KEY_MACRO3":                   KEY_MACRO3,
-	"KEY_MACRO4":                   KEY_MACRO4,
-	"KEY_MACRO5":                   KEY_MACRO5,
-	"KEY_MACRO6":                   KEY_MACRO6,
-	"KEY_MACRO7":                   KEY_MACRO7,
-	"KEY_MACRO8":                   KEY_MACRO8,
-	"KEY_MACRO9":                   KEY_MACRO9,
-	"KEY_MACRO10":                  KEY_MACRO10,
-	"KEY_MACRO11":                  KEY_MACRO11,
-	"KEY_MACRO12":                  KEY_MACRO12,
-	"KEY_MACRO13":                  KEY_MACRO13,
-	"KEY_MACRO14":                  KEY_MACRO14,
-	"KEY_MACRO15":                  KEY_MACRO15,
-	"KEY_MACRO16":                  KEY_MACRO16,
-	"KEY_MACRO17":                  KEY_MACRO17,
-	"KEY_MACRO18":                  KEY_MACRO18,
-	"KEY_MACRO19":                  KEY_MACRO19,
-	"KEY_MACRO20":                  KEY_MACRO20,
-	"KEY_MACRO21":                  KEY_MACRO21,
-	"KEY_MACRO22":                  KEY_MACRO22,
-	"KEY_MACRO23":                  KEY_MACRO23,
-	"KEY_MACRO24":                  KEY_MACRO24,
-	"KEY_MACRO25":                  KEY_MACRO25,
-	"KEY_MACRO26":                  KEY_MACRO26,
-	"KEY_MACRO27":                  KEY_MACRO27,
-	"KEY_MACRO28":                  KEY_MACRO28,
-	"KEY_MACRO29":                  KEY_MACRO29,
-	"KEY_MACRO30":                  KEY_MACRO30,
-	"KEY_MACRO_RECORD_START":       KEY_MACRO_RECORD_START,
-	"KEY_MACRO_RECORD_STOP":        KEY_MACRO_RECORD_STOP,
-	"KEY_MACRO_PRESET_CYCLE":       KEY_MACRO_PRESET_CYCLE,
-	"KEY_MACRO_PRESET1":            KEY_MACRO_PRESET1,
-	"KEY_MACRO_PRESET2":            KEY_MACRO_PRESET2,
-	"KEY_MACRO_PRESET3":            KEY_MACRO_PRESET3,
-	"KEY_KBD_LCD_MENU1":            KEY_KBD_LCD_MENU1,
-	"KEY_KBD_LCD_MENU2":            KEY_KBD_LCD_MENU2,
-	"KEY_KBD_LCD_MENU3":            KEY_KBD_LCD_MENU3,
-	"KEY_KBD_LCD_MENU4":            KEY_KBD_LCD_MENU4,
-	"KEY_KBD_LCD_MENU5":            KEY_KBD_LCD_MENU5,
-	"BTN_TRIGGER_HAPPY":            BTN_TRIGGER_HAPPY,
-	"BTN_TRIGGER_HAPPY1":           BTN_TRIGGER_HAPPY1,
-	"BTN_TRIGGER_HAPPY2":           BTN_TRIGGER_HAPPY2,
-	"BTN_TRIGGER_HAPPY3":           BTN_TRIGGER_HAPPY3,
-	"BTN_TRIGGER_HAPPY4":           BTN_TRIGGER_HAPPY4,
-	"BTN_TRIGGER_HAPPY5":           BTN_TRIGGER_HAPPY5,
-	"BTN_TRIGGER_HAPPY6":           BTN_TRIGGER_HAPPY6,
-	"BTN_TRIGGER_HAPPY7":           BTN_TRIGGER_HAPPY7,
-	"BTN_TRIGGER_HAPPY8":           BTN_TRIGGER_HAPPY8,
-	"BTN_TRIGGER_HAPPY9":           BTN_TRIGGER_HAPPY9,
-	"BTN_TRIGGER_HAPPY10":          BTN_TRIGGER_HAPPY10,
-	"BTN_TRIGGER_HAPPY11":          BTN_TRIGGER_HAPPY11,
-	"BTN_TRIGGER_HAPPY12":          BTN_TRIGGER_HAPPY12,
-	"BTN_TRIGGER_HAPPY13":          BTN_TRIGGER_HAPPY13,
-	"BTN_TRIGGER_HAPPY14":          BTN_TRIGGER_HAPPY14,
-	"BTN_TRIGGER_HAPPY15":          BTN_TRIGGER_HAPPY15,
-	"BTN_TRIGGER_HAPPY16":          BTN_TRIGGER_HAPPY16,
-	"BTN_TRIGGER_HAPPY17":          BTN_TRIGGER_HAPPY17,
-	"BTN_TRIGGER_HAPPY18":          BTN_TRIGGER_HAPPY18,
-	"BTN_TRIGGER_HAPPY19":          BTN_TRIGGER_HAPPY19,
-	"BTN_TRIGGER_HAPPY20":          BTN_TRIGGER_HAPPY20,
-	"BTN_TRIGGER_HAPPY21":          BTN_TRIGGER_HAPPY21,
-	"BTN_TRIGGER_HAPPY22":          BTN_TRIGGER_HAPPY22,
-	"BTN_TRIGGER_HAPPY23":          BTN_TRIGGER_HAPPY23,
-	"BTN_TRIGGER_HAPPY24":          BTN_TRIGGER_HAPPY24,
-	"BTN_TRIGGER_HAPPY25":          BTN_TRIGGER_HAPPY25,
-	"BTN_TRIGGER_HAPPY26":          BTN_TRIGGER_HAPPY26,
-	"BTN_TRIGGER_HAPPY27":          BTN_TRIGGER_HAPPY27,
-	"BTN_TRIGGER_HAPPY28":          BTN_TRIGGER_HAPPY28,
-	"BTN_TRIGGER_HAPPY29":          BTN_TRIGGER_HAPPY29,
-	"BTN_TRIGGER_HAPPY30":          BTN_TRIGGER_HAPPY30,
-	"BTN_TRIGGER_HAPPY31":          BTN_TRIGGER_HAPPY31,
-	"BTN_TRIGGER_HAPPY32":          BTN_TRIGGER_HAPPY32,
-	"BTN_TRIGGER_HAPPY33":          BTN_TRIGGER_HAPPY33,
-	"BTN_TRIGGER_HAPPY34":          BTN_TRIGGER_HAPPY34,
-	"BTN_TRIGGER_HAPPY35":          BTN_TRIGGER_HAPPY35,
-	"BTN_TRIGGER_HAPPY36":          BTN_TRIGGER_HAPPY36,
-	"BTN_TRIGGER_HAPPY37":          BTN_TRIGGER_HAPPY37,
-	"BTN_TRIGGER_HAPPY38":          BTN_TRIGGER_HAPPY38,
-	"BTN_TRIGGER_HAPPY39":          BTN_TRIGGER_HAPPY39,
-	"BTN_TRIGGER_HAPPY40":          BTN_TRIGGER_HAPPY40,
-	"KEY_MIN_INTERESTING":          KEY_MIN_INTERESTING,
-	"KEY_MAX":                      KEY_MAX,
-	"REL_X":                        REL_X,
-	"REL_Y":                        REL_Y,
-	"REL_Z":                        REL_Z,
-	"REL_RX":                       REL_RX,
-	"REL_RY":                       REL_RY,
-	"REL_RZ":                       REL_RZ,
-	"REL_HWHEEL":                   REL_HWHEEL,
-	"REL_DIAL":                     REL_DIAL,
-	"REL_WHEEL":                    REL_WHEEL,
-	"REL_MISC":                     REL_MISC,
-	"REL_RESERVED":                 REL_RESERVED,
-	"REL_WHEEL_HI_RES":             REL_WHEEL_HI_RES,
-	"REL_HWHEEL_HI_RES":            REL_HWHEEL_HI_RES,
-	"REL_MAX":                      REL_MAX,
-	"ABS_X":                        ABS_X,
-	"ABS_Y":                        ABS_Y,
-	"ABS_Z":                        ABS_Z,
-	"ABS_RX":                       ABS_RX,
-	"ABS_RY":                       ABS_RY,
-	"ABS_RZ":                       ABS_RZ,
-	"ABS_THROTTLE":                 ABS_THROTTLE,
-	"ABS_RUDDER":                   ABS_RUDDER,
-	"ABS_WHEEL":                    ABS_WHEEL,
-	"ABS_GAS":                      ABS_GAS,
-	"ABS_BRAKE":                    ABS_BRAKE,
-	"ABS_HAT0X":                    ABS_HAT0X,
-	"ABS_HAT0Y":                    ABS_HAT0Y,
-	"ABS_HAT1X":                    ABS_HAT1X,
-	"ABS_HAT1Y":                    ABS_HAT1Y,
-	"ABS_HAT2X":                    ABS_HAT2X,
-	"ABS_HAT2Y":                    ABS_HAT2Y,
-	"ABS_HAT3X":                    ABS_HAT3X,
-	"ABS_HAT3Y":                    ABS_HAT3Y,
-	"ABS_PRESSURE":                 ABS_PRESSURE,
-	"ABS_DISTANCE":                 ABS_DISTANCE,
-	"ABS_TILT_X":                   ABS_TILT_X,
-	"ABS_TILT_Y":                   ABS_TILT_Y,
-	"ABS_TOOL_WIDTH":               ABS_TOOL_WIDTH,
-	"ABS_VOLUME":                   ABS_VOLUME,
-	"ABS_MISC":                     ABS_MISC,
-	"ABS_RESERVED":                 ABS_RESERVED,
-	"ABS_MT_SLOT":                  ABS_MT_SLOT,
-	"ABS_MT_TOUCH_MAJOR":           ABS_MT_TOUCH_MAJOR,
-	"ABS_MT_TOUCH_MINOR":           ABS_MT_TOUCH_MINOR,
-	"ABS_MT_WIDTH_MAJOR":           ABS_MT_WIDTH_MAJOR,
-	"ABS_MT_WIDTH_MINOR":           ABS_MT_WIDTH_MINOR,
-	"ABS_MT_ORIENTATION":           ABS_MT_ORIENTATION,
-	"ABS_MT_POSITION_X":            ABS_MT_POSITION_X,
-	"ABS_MT_POSITION_Y":            ABS_MT_POSITION_Y,
-	"ABS_MT_TOOL_TYPE":             ABS_MT_TOOL_TYPE,
-	"ABS_MT_BLOB_ID":               ABS_MT_BLOB_ID,
-	"ABS_MT_TRACKING_ID":           ABS_MT_TRACKING_ID,
-	"ABS_MT_PRESSURE":              ABS_MT_PRESSURE,
-	"ABS_MT_DISTANCE":              ABS_MT_DISTANCE,
-	"ABS_MT_TOOL_X":                ABS_MT_TOOL_X,
-	"ABS_MT_TOOL_Y":                ABS_MT_TOOL_Y,
-	"ABS_MAX":                      ABS_MAX,
-	"SW_LID":                       SW_LID,
-	"SW_TABLET_MODE":               SW_TABLET_MODE,
-	"SW_HEADPHONE_INSERT":          SW_HEADPHONE_INSERT,
-	"SW_RFKILL_ALL":                SW_RFKILL_ALL,
-	"SW_RADIO":                     SW_RADIO,
-	"SW_MICROPHONE_INSERT":         SW_MICROPHONE_INSERT,
-	"SW_DOCK":                      SW_DOCK,
-	"SW_LINEOUT_INSERT":            SW_LINEOUT_INSERT,
-	"SW_JACK_PHYSICAL_INSERT":      SW_JACK_PHYSICAL_INSERT,
-	"SW_VIDEOOUT_INSERT":           SW_VIDEOOUT_INSERT,
-	"SW_CAMERA_LENS_COVER":         SW_CAMERA_LENS_COVER,
-	"SW_KEYPAD_SLIDE":              SW_KEYPAD_SLIDE,
-	"SW_FRONT_PROXIMITY":           SW_FRONT_PROXIMITY,
-	"SW_ROTATE_LOCK":               SW_ROTATE_LOCK,
-	"SW_LINEIN_INSERT":             SW_LINEIN_INSERT,
-	"SW_MUTE_DEVICE":               SW_MUTE_DEVICE,
-	"SW_PEN_INSERTED":              SW_PEN_INSERTED,
-	"SW_MACHINE_COVER":             SW_MACHINE_COVER,
-	"SW_MAX":                       SW_MAX,
-	"MSC_SERIAL":                   MSC_SERIAL,
-	"MSC_PULSELED":                 MSC_PULSELED,
-	"MSC_GESTURE":                  MSC_GESTURE,
-	"MSC_RAW":                      MSC_RAW,
-	"MSC_SCAN":                     MSC_SCAN,
-	"MSC_TIMESTAMP":                MSC_TIMESTAMP,
-	"MSC_MAX":                      MSC_MAX,
-	"LED_NUML":                     LED_NUML,
-	"LED_CAPSL":                    LED_CAPSL,
-	"LED_SCROLLL":                  LED_SCROLLL,
-	"LED_COMPOSE":                  LED_COMPOSE,
-	"LED_KANA":                     LED_KANA,
-	"LED_SLEEP":                    LED_SLEEP,
-	"LED_SUSPEND":                  LED_SUSPEND,
-	"LED_MUTE":                     LED_MUTE,
-	"LED_MISC":                     LED_MISC,
-	"LED_MAIL":                     LED_MAIL,
-	"LED_CHARGING":                 LED_CHARGING,
-	"LED_MAX":                      LED_MAX,
-	"REP_DELAY":                    REP_DELAY,
-	"REP_PERIOD":                   REP_PERIOD,
-	"REP_MAX":                      REP_MAX,
-	"SND_CLICK":                    SND_CLICK,
-	"SND_BELL":                     SND_BELL,
-	"SND_TONE":                     SND_TONE,
-	"SND_MAX":                      SND_MAX,
+// Names sharing their value with a preferred name of the same kind, such as
+// KEY_MIN_INTERESTING (KEY_MUTE) or BTN_MISC (BTN_0). They are left out of
+// the reverse mappings.
+var ecodeAliases = map[string]bool{
+	"BTN_A":                 true,
+	"BTN_B":                 true,
+	"BTN_DIGI":              true,
+	"BTN_GAMEPAD":           true,
+	"BTN_JOYSTICK":          true,
+	"BTN_MISC":              true,
+	"BTN_MOUSE":             true,
+	"BTN_TRIGGER_HAPPY":     true,
+	"BTN_WHEEL":             true,
+	"BTN_X":                 true,
+	"BTN_Y":                 true,
+	"FF_EFFECT_MAX":         true,
+	"FF_EFFECT_MIN":         true,
+	"FF_MAX_EFFECTS":        true,
+	"FF_STATUS_MAX":         true,
+	"FF_WAVEFORM_MAX":       true,
+	"FF_WAVEFORM_MIN":       true,
+	"KEY_BRIGHTNESS_TOGGLE": true,
+	"KEY_BRIGHTNESS_ZERO":   true,
+	"KEY_DASHBOARD":         true,
+	"KEY_DIRECTION":         true,
+	"KEY_HANGUEL":           true,
+	"KEY_MIN_INTERESTING":   true,
+	"KEY_SCREEN":            true,
+	"KEY_SCREENLOCK":        true,
+	"KEY_WIMAX":             true,
+	"KEY_ZOOM":              true,
+	"REP_MAX":               true,
+	"SW_MAX":                true,
+	"SW_RADIO":              true,
 }
 
 var KEY = map[int]string{}
@@ -1646,25 +889,36 @@ var EV = map[int]string{}
 var BUS = map[int]string{}
 var SYN = map[int]string{}
 var FF = map[int]string{}
+var FF_STATUS = map[int]string{}
+var INPUT_PROP = map[int]string{}
+
+// KEY and BTN codes share the code space of EV_KEY.
+var keysAndButtons = map[int]string{}
 
 var ByEventType = map[int]map[int]string{
-	EV_KEY: KEY,
-	EV_ABS: ABS,
-	EV_REL: REL,
-	EV_SW:  SW,
-	EV_MSC: MSC,
-	EV_LED: LED,
-	EV_REP: REP,
-	EV_SND: SND,
-	EV_SYN: SYN,
-	EV_FF:  FF,
+	EV_KEY:       keysAndButtons,
+	EV_ABS:       ABS,
+	EV_REL:       REL,
+	EV_SW:        SW,
+	EV_MSC:       MSC,
+	EV_LED:       LED,
+	EV_REP:       REP,
+	EV_SND:       SND,
+	EV_SYN:       SYN,
+	EV_FF:        FF,
+	EV_FF_STATUS: FF_STATUS,
 }
 
 func init() {
 	for code, value := range ecodes {
+		if ecodeAliases[code] {
+			continue
+		}
+
 		switch {
 		case strings.HasPrefix(code, "KEY"):
 			KEY[value] = code
+			keysAndButtons[value] = code
 		case strings.HasPrefix(code, "ABS"):
 			ABS[value] = code
 		case strings.HasPrefix(code, "REL"):
@@ -1677,6 +931,9 @@ func init() {
 			LED[value] = code
 		case strings.HasPrefix(code, "BTN"):
 			BTN[value] = code
+			keysAndButtons[value] = code
+		case strings.HasPrefix(code, "REP"):
+			REP[value] = code
 		case strings.HasPrefix(code, "SND"):
 			SND[value] = code
 		case strings.HasPrefix(code, "ID"):
@@ -1687,8 +944,12 @@ func init() {
 			BUS[value] = code
 		case strings.HasPrefix(code, "SYN"):
 			SYN[value] = code
+		case strings.HasPrefix(code, "FF_STATUS"):
+			FF_STATUS[value] = code
 		case strings.HasPrefix(code, "FF"):
 			FF[value] = code
+		case strings.HasPrefix(code, "INPUT_PROP"):
+			INPUT_PROP[value] = code
 		}
 	}
 }
@@ -1,6 +1,11 @@
+//go:build !evdev_minimal
+
 package evdev
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestAccess(t *testing.T) {
 	if KEY_A != ecodes["KEY_A"] {
@@ -19,3 +24,50 @@ func TestAccess(t *testing.T) {
 		t.Error()
 	}
 }
+
+func TestCodeName(t *testing.T) {
+	names := []struct {
+		evType, code int
+		name         string
+	}{
+		{EV_KEY, KEY_A, "KEY_A"},
+		{EV_KEY, KEY_MUTE, "KEY_MUTE"},
+		{EV_KEY, BTN_LEFT, "BTN_LEFT"},
+		{EV_KEY, BTN_0, "BTN_0"},
+		{EV_KEY, BTN_SOUTH, "BTN_SOUTH"},
+		{EV_KEY, 0x2f0, "KEY_0x2f0"},
+		{EV_REP, REP_PERIOD, "REP_PERIOD"},
+		{EV_SW, SW_MACHINE_COVER, "SW_MACHINE_COVER"},
+		{EV_FF_STATUS, FF_STATUS_PLAYING, "FF_STATUS_PLAYING"},
+	}
+
+	for _, n := range names {
+		if name := CodeName(n.evType, n.code); name != n.name {
+			t.Errorf("CodeName(%d, %d) = %q, want %q", n.evType, n.code, name, n.name)
+		}
+	}
+}
+
+func TestTypedNames(t *testing.T) {
+	ev := InputEvent{Type: EV_KEY, Code: KEY_A, Value: 1}
+
+	if ev.Type.String() != "EV_KEY" {
+		t.Errorf("unexpected type name %q", ev.Type)
+	}
+
+	if ev.Code.Name(ev.Type) != "KEY_A" {
+		t.Errorf("unexpected code name %q", ev.Code.Name(ev.Type))
+	}
+}
+
+func TestRawEvent(t *testing.T) {
+	ev := NewRawInputEvent(time.Unix(1000, 0), 0x01, 30, -1)
+	if ev.Type != EV_KEY || ev.Code != KEY_A || ev.Value != -1 {
+		t.Errorf("unexpected event %v", &ev)
+	}
+
+	evType, code, value := ev.Raw()
+	if evType != 0x01 || code != 30 || value != -1 {
+		t.Errorf("unexpected raw values %d, %d, %d", evType, code, value)
+	}
+}
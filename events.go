@@ -3,14 +3,15 @@ package evdev
 import (
 	"fmt"
 	"syscall"
+	"time"
 	"unsafe"
 )
 
 type InputEvent struct {
 	Time  syscall.Timeval // time in seconds since epoch at which event occurred
-	Type  uint16          // event type - one of ecodes.EV_*
-	Code  uint16          // event code related to the event type
-	Value int32           // event value related to the event type
+	Type  EvType          // event type - one of ecodes.EV_*
+	Code  EvCode          // event code related to the event type
+	Value EvValue         // event value related to the event type
 }
 
 // Get a useful description for an input event. Example:
@@ -23,6 +24,40 @@ func (ev *InputEvent) String() string {
 
 var eventsize = int(unsafe.Sizeof(InputEvent{}))
 
+// NewInputEvent creates an input event with the given type, code and value,
+// timestamped at t.
+func NewInputEvent(t time.Time, evType EvType, code EvCode, value EvValue) InputEvent {
+	return InputEvent{
+		Time:  syscall.NsecToTimeval(t.UnixNano()),
+		Type:  evType,
+		Code:  code,
+		Value: value,
+	}
+}
+
+// NewRawInputEvent is like NewInputEvent, but takes the type, code and value
+// as plain integers, for code written before the typed fields.
+func NewRawInputEvent(t time.Time, evType, code uint16, value int32) InputEvent {
+	return NewInputEvent(t, EvType(evType), EvCode(code), EvValue(value))
+}
+
+// Raw returns the type, code and value of the event as plain integers, as
+// they were before the typed fields.
+func (ev *InputEvent) Raw() (evType, code uint16, value int32) {
+	return uint16(ev.Type), uint16(ev.Code), int32(ev.Value)
+}
+
+// Timestamp returns the time at which the event occurred.
+func (ev *InputEvent) Timestamp() time.Time {
+	return time.Unix(int64(ev.Time.Sec), int64(ev.Time.Usec)*1000)
+}
+
+// Age returns how long ago the event occurred, as seen at now. The current
+// time must be taken from the same clock as the event timestamps.
+func (ev *InputEvent) Age(now time.Time) time.Duration {
+	return now.Sub(ev.Timestamp())
+}
+
 type KeyEventState uint8
 
 const (
@@ -35,8 +70,8 @@ const (
 // or other key-like devices.
 type KeyEvent struct {
 	Event    *InputEvent
-	Scancode uint16
-	Keycode  uint16
+	Scancode EvCode
+	Keycode  EvCode
 	State    KeyEventState
 }
 
@@ -97,14 +132,14 @@ func NewRelEvent(ev *InputEvent) *RelEvent {
 func (rev *RelEvent) String() string {
 	return fmt.Sprintf("relative axis event at %d.%d, %s",
 		rev.Event.Time.Sec, rev.Event.Time.Usec,
-		REL[int(rev.Event.Code)])
+		CodeName(EV_REL, int(rev.Event.Code)))
 }
 
 // TODO: Make this work
 
-var EventFactory map[uint16]interface{} = make(map[uint16]interface{})
+var EventFactory map[EvType]interface{} = make(map[EvType]interface{})
 
 func init() {
-	EventFactory[uint16(EV_KEY)] = NewKeyEvent
-	EventFactory[uint16(EV_REL)] = NewRelEvent
+	EventFactory[EV_KEY] = NewKeyEvent
+	EventFactory[EV_REL] = NewRelEvent
 }
@@ -0,0 +1,61 @@
+//go:build linux
+
+package evdev
+
+import (
+	"errors"
+	"net"
+	"os"
+	"syscall"
+)
+
+// SendDevice passes the open file descriptor of a device to another process
+// over a unix socket (SCM_RIGHTS), together with its devnode path. This lets
+// a privileged broker open devices on behalf of sandboxed consumers, which
+// reconstruct them with ReceiveDevice.
+func SendDevice(conn *net.UnixConn, dev *InputDevice) error {
+	rights := syscall.UnixRights(int(dev.File.Fd()))
+
+	_, _, err := conn.WriteMsgUnix([]byte(dev.Fn), rights, nil)
+	return err
+}
+
+// ReceiveDevice receives a device sent with SendDevice.
+func ReceiveDevice(conn *net.UnixConn) (*InputDevice, error) {
+	buf := make([]byte, 4096)
+	oob := make([]byte, syscall.CmsgSpace(4))
+
+	n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	if err != nil {
+		return nil, err
+	}
+
+	msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	if err != nil {
+		return nil, err
+	}
+	if len(msgs) != 1 {
+		return nil, errors.New("evdev: no device descriptor received")
+	}
+
+	fds, err := syscall.ParseUnixRights(&msgs[0])
+	if err != nil {
+		return nil, err
+	}
+	if len(fds) != 1 {
+		for _, fd := range fds {
+			syscall.Close(fd)
+		}
+		return nil, errors.New("evdev: unexpected number of descriptors received")
+	}
+
+	f := os.NewFile(uintptr(fds[0]), string(buf[:n]))
+
+	dev, err := NewInputDevice(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return dev, nil
+}
@@ -0,0 +1,59 @@
+//go:build linux
+
+package evdev
+
+import (
+	"net"
+	"os"
+	"syscall"
+	"testing"
+)
+
+// Connect a pair of unix sockets.
+func unixPair(t *testing.T) (a, b *net.UnixConn) {
+	fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_SEQPACKET, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conns := make([]*net.UnixConn, 2)
+	for i, fd := range fds {
+		f := os.NewFile(uintptr(fd), "socket")
+		c, err := net.FileConn(f)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		conns[i] = c.(*net.UnixConn)
+	}
+
+	return conns[0], conns[1]
+}
+
+func TestReceiveDevice(t *testing.T) {
+	a, b := unixPair(t)
+	defer a.Close()
+	defer b.Close()
+
+	// a message without descriptor
+	if _, err := a.Write([]byte("/dev/input/event3")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReceiveDevice(b); err == nil || err.Error() != "evdev: no device descriptor received" {
+		t.Errorf("expected a missing descriptor, got %v", err)
+	}
+
+	// the descriptor arrives, but doesn't refer to an input device
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	if err := SendDevice(a, &InputDevice{Fn: "/dev/input/event3", File: r}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReceiveDevice(b); err != syscall.ENOTTY {
+		t.Errorf("expected ENOTTY for a pipe, got %v", err)
+	}
+}
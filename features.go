@@ -0,0 +1,104 @@
+//go:build linux
+
+package evdev
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unsafe"
+)
+
+// Features is a set of optional ioctls supported for a device. Older
+// kernels lack some of them; higher layers can check for them to degrade
+// gracefully rather than fail.
+type Features uint
+
+const (
+	FeatureEventMask Features = 1 << iota // EVIOCSMASK/EVIOCGMASK, since Linux 4.4
+	FeatureClockID                        // EVIOCSCLOCKID, since Linux 3.4
+	FeatureMTSlots                        // EVIOCGMTSLOTS, on multi-touch devices with slots
+	FeatureRevoke                         // EVIOCREVOKE, since Linux 3.12, judged by the kernel version
+)
+
+var featureNames = []struct {
+	feature Features
+	name    string
+}{
+	{FeatureEventMask, "EVIOCSMASK"},
+	{FeatureClockID, "EVIOCSCLOCKID"},
+	{FeatureMTSlots, "EVIOCGMTSLOTS"},
+	{FeatureRevoke, "EVIOCREVOKE"},
+}
+
+// Has reports whether all of the given features are supported.
+func (f Features) Has(features Features) bool {
+	return f&features == features
+}
+
+// String lists the ioctls of the set, e.g. "EVIOCSMASK|EVIOCREVOKE".
+func (f Features) String() string {
+	names := make([]string, 0, len(featureNames))
+	for _, n := range featureNames {
+		if f&n.feature != 0 {
+			names = append(names, n.name)
+		}
+	}
+
+	return strings.Join(names, "|")
+}
+
+// Features probes which optional ioctls the kernel supports for the device,
+// without changing its state. The result is recorded on the device, later
+// calls return it without probing again.
+func (dev *InputDevice) Features() Features {
+	if dev.features != nil {
+		return *dev.features
+	}
+
+	fd := dev.File.Fd()
+	var f Features
+
+	// reading the mask is harmless and came with setting it
+	if err := dev.eventMask(uintptr(EVIOCGMASK), EV_SYN, NewBitset(EV_MAX)); err == nil {
+		f |= FeatureEventMask
+	}
+
+	// setting the clock in use changes nothing
+	clockid := dev.clockID
+	if ioctl(fd, uintptr(EVIOCSCLOCKID), unsafe.Pointer(&clockid)) == 0 {
+		f |= FeatureClockID
+	}
+
+	// fails with EINVAL on devices without slots
+	values := []int32{ABS_MT_TRACKING_ID, 0}
+	if ioctl(fd, uintptr(EVIOCGMTSLOTS(len(values)*4)), unsafe.Pointer(&values[0])) == 0 {
+		f |= FeatureMTSlots
+	}
+
+	// revoking can't be probed without revoking: evdev rejects unknown
+	// ioctls with EINVAL, just like invalid arguments to EVIOCREVOKE
+	if kernelAtLeast(3, 12) {
+		f |= FeatureRevoke
+	}
+
+	dev.features = &f
+	return f
+}
+
+// Report whether the running kernel is at least the given version.
+func kernelAtLeast(major, minor int) bool {
+	release, err := os.ReadFile("/proc/sys/kernel/osrelease")
+	if err != nil {
+		return false
+	}
+
+	ma, mi, ok := parseKernelRelease(string(release))
+	return ok && (ma > major || ma == major && mi >= minor)
+}
+
+// Parse the version of a kernel release such as "6.1.0-13-amd64".
+func parseKernelRelease(release string) (major, minor int, ok bool) {
+	_, err := fmt.Sscanf(release, "%d.%d", &major, &minor)
+	return major, minor, err == nil
+}
@@ -0,0 +1,44 @@
+//go:build linux
+
+package evdev
+
+import "testing"
+
+func TestFeatures(t *testing.T) {
+	f := FeatureEventMask | FeatureRevoke
+
+	if !f.Has(FeatureEventMask) || !f.Has(FeatureEventMask|FeatureRevoke) {
+		t.Error("expected features to be present")
+	}
+	if f.Has(FeatureClockID) || f.Has(FeatureEventMask|FeatureMTSlots) {
+		t.Error("unexpected features present")
+	}
+	if !Features(0).Has(0) {
+		t.Error("the empty set should always be present")
+	}
+
+	if s := f.String(); s != "EVIOCSMASK|EVIOCREVOKE" {
+		t.Errorf("unexpected names %q", s)
+	}
+	if s := Features(0).String(); s != "" {
+		t.Errorf("unexpected names %q", s)
+	}
+}
+
+func TestParseKernelRelease(t *testing.T) {
+	for _, c := range []struct {
+		release      string
+		major, minor int
+		ok           bool
+	}{
+		{"6.1.0-13-amd64\n", 6, 1, true},
+		{"3.12", 3, 12, true},
+		{"5.19.13-arch1-1", 5, 19, true},
+		{"unknown", 0, 0, false},
+	} {
+		major, minor, ok := parseKernelRelease(c.release)
+		if ok != c.ok || ok && (major != c.major || minor != c.minor) {
+			t.Errorf("%q: got %d.%d %v", c.release, major, minor, ok)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+//go:build linux
+
+package evdev
+
+import (
+	"encoding/binary"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// FFEnvelope shapes the start and end of an effect. Lengths are in ms,
+// levels range up to 0x7fff.
+type FFEnvelope struct {
+	AttackLength uint16
+	AttackLevel  uint16
+	FadeLength   uint16
+	FadeLevel    uint16
+}
+
+// FFTrigger starts an effect with a button of the device.
+type FFTrigger struct {
+	Button   uint16 // BTN_* code, 0 for none
+	Interval uint16 // minimum time between two triggers, in ms
+}
+
+// FFReplay schedules an effect once played.
+type FFReplay struct {
+	Length uint16 // duration of the effect in ms, 0 for infinite
+	Delay  uint16 // delay before the effect starts, in ms
+}
+
+// FFParams are the parameters of a kind of force feedback effect: one of
+// FFRumble, FFPeriodic, FFConstant, FFRamp or FFCondition.
+type FFParams interface {
+	ffType() uint16
+	encode(b []byte)
+}
+
+// FFRumble vibrates the device with two motors, as in gamepads.
+type FFRumble struct {
+	StrongMagnitude uint16 // magnitude of the heavy motor
+	WeakMagnitude   uint16 // magnitude of the light motor
+}
+
+// FFPeriodic applies a force following a waveform.
+type FFPeriodic struct {
+	Waveform  uint16 // FF_SQUARE, FF_TRIANGLE, FF_SINE, FF_SAW_UP or FF_SAW_DOWN
+	Period    uint16 // in ms
+	Magnitude int16
+	Offset    int16  // mean value of the wave
+	Phase     uint16 // horizontal shift
+	Envelope  FFEnvelope
+}
+
+// FFConstant applies a constant force.
+type FFConstant struct {
+	Level    int16
+	Envelope FFEnvelope
+}
+
+// FFRamp applies a force changing linearly from Start to End.
+type FFRamp struct {
+	Start    int16
+	End      int16
+	Envelope FFEnvelope
+}
+
+// FFConditionAxis holds the parameters of a condition effect on one axis.
+type FFConditionAxis struct {
+	RightSaturation uint16 // maximum force on the right side
+	LeftSaturation  uint16 // maximum force on the left side
+	RightCoeff      int16  // how fast the force grows on the right side
+	LeftCoeff       int16  // how fast the force grows on the left side
+	Deadband        uint16 // size of the dead zone around Center
+	Center          int16  // position of the dead zone
+}
+
+// FFCondition applies a force depending on the position or movement of the
+// device, e.g. the spring of a steering wheel.
+type FFCondition struct {
+	Type uint16             // FF_SPRING, FF_FRICTION, FF_DAMPER or FF_INERTIA
+	Axes [2]FFConditionAxis // the X and Y axis
+}
+
+func (FFRumble) ffType() uint16      { return FF_RUMBLE }
+func (FFPeriodic) ffType() uint16    { return FF_PERIODIC }
+func (FFConstant) ffType() uint16    { return FF_CONSTANT }
+func (FFRamp) ffType() uint16        { return FF_RAMP }
+func (c FFCondition) ffType() uint16 { return c.Type }
+
+func (r FFRumble) encode(b []byte) {
+	putUint16s(b, r.StrongMagnitude, r.WeakMagnitude)
+}
+
+func (p FFPeriodic) encode(b []byte) {
+	putUint16s(b, p.Waveform, p.Period, uint16(p.Magnitude), uint16(p.Offset), p.Phase)
+	p.Envelope.encode(b[10:])
+}
+
+func (c FFConstant) encode(b []byte) {
+	putUint16s(b, uint16(c.Level))
+	c.Envelope.encode(b[2:])
+}
+
+func (r FFRamp) encode(b []byte) {
+	putUint16s(b, uint16(r.Start), uint16(r.End))
+	r.Envelope.encode(b[4:])
+}
+
+func (c FFCondition) encode(b []byte) {
+	for i, a := range c.Axes {
+		putUint16s(b[i*12:], a.RightSaturation, a.LeftSaturation,
+			uint16(a.RightCoeff), uint16(a.LeftCoeff), a.Deadband, uint16(a.Center))
+	}
+}
+
+func (e FFEnvelope) encode(b []byte) {
+	putUint16s(b, e.AttackLength, e.AttackLevel, e.FadeLength, e.FadeLevel)
+}
+
+func putUint16s(b []byte, values ...uint16) {
+	for i, v := range values {
+		binary.LittleEndian.PutUint16(b[2*i:], v)
+	}
+}
+
+// Effect is a force feedback effect. It corresponds to the ff_effect struct.
+type Effect struct {
+	ID        int16  // -1 to upload a new effect
+	Direction uint16 // 0x0000 down, 0x4000 left, 0x8000 up, 0xc000 right
+	Trigger   FFTrigger
+	Replay    FFReplay
+	Params    FFParams
+}
+
+// NewEffect creates a new effect with the given parameters, lasting length
+// (0 for infinite).
+func NewEffect(params FFParams, length time.Duration) Effect {
+	return Effect{ID: -1, Replay: FFReplay{Length: uint16(length / time.Millisecond)}, Params: params}
+}
+
+// Get the ff_effect struct of an effect. The union of effect parameters
+// starts after the header, aligned for the pointer of periodic effects.
+func (e *Effect) encode() []byte {
+	b := make([]byte, sizeofFFEffect)
+
+	putUint16s(b, e.Params.ffType(), uint16(e.ID), e.Direction,
+		e.Trigger.Button, e.Trigger.Interval, e.Replay.Length, e.Replay.Delay)
+	e.Params.encode(b[16:])
+
+	return b
+}
+
+// FFEffectsMax returns the number of effects the device can hold at once,
+// as queried with EVIOCGEFFECTS. Devices without force feedback return 0.
+func (dev *InputDevice) FFEffectsMax() (int, error) {
+	var effects int32
+
+	if err := ioctl(dev.File.Fd(), EVIOCGEFFECTS, unsafe.Pointer(&effects)); err != 0 {
+		return 0, err
+	}
+
+	return int(effects), nil
+}
+
+// UploadEffect uploads an effect to the device with EVIOCSFF and returns its
+// id, to be passed to PlayEffect. Uploading an effect with the id of an
+// uploaded one updates it, even while playing.
+func (dev *InputDevice) UploadEffect(e Effect) (int16, error) {
+	if e.Params == nil {
+		return 0, syscall.EINVAL
+	}
+	b := e.encode()
+
+	if err := ioctl(dev.File.Fd(), EVIOCSFF, unsafe.Pointer(&b[0])); err != 0 {
+		return 0, err
+	}
+
+	return int16(binary.LittleEndian.Uint16(b[2:])), nil
+}
+
+// EraseEffect removes an uploaded effect with EVIOCRMFF.
+func (dev *InputDevice) EraseEffect(id int16) error {
+	if err := ioctlInt(dev.File.Fd(), EVIOCRMFF, uintptr(id)); err != 0 {
+		return err
+	}
+
+	return nil
+}
+
+// PlayEffect starts an uploaded effect, repeating it count times. The device
+// must have been opened for writing.
+func (dev *InputDevice) PlayEffect(id int16, count int32) error {
+	return writeFF(dev, EvCode(id), EvValue(count))
+}
+
+// StopEffect stops a playing effect.
+func (dev *InputDevice) StopEffect(id int16) error {
+	return writeFF(dev, EvCode(id), 0)
+}
+
+// Write a force feedback event to w, followed by a SYN_REPORT.
+func writeFF(w EventWriter, code EvCode, value EvValue) error {
+	now := time.Now()
+
+	ev := NewInputEvent(now, EV_FF, code, value)
+	if err := w.WriteEvent(&ev); err != nil {
+		return err
+	}
+
+	syn := NewInputEvent(now, EV_SYN, SYN_REPORT, 0)
+	return w.WriteEvent(&syn)
+}
+
+// SetFFGain sets the overall strength of the effects of the device, from 0
+// to 100 percent. The device must support FF_GAIN.
+func (dev *InputDevice) SetFFGain(percent int) error {
+	return dev.setFFLevel(FF_GAIN, percent)
+}
+
+// SetFFAutocenter sets the strength of the force pulling the device back to
+// its center, as in steering wheels, from 0 (off) to 100 percent. The device
+// must support FF_AUTOCENTER.
+func (dev *InputDevice) SetFFAutocenter(percent int) error {
+	return dev.setFFLevel(FF_AUTOCENTER, percent)
+}
+
+// Set a control taking a level from 0 to 0xffff.
+func (dev *InputDevice) setFFLevel(code EvCode, percent int) error {
+	if percent < 0 || percent > 100 {
+		return syscall.EINVAL
+	}
+
+	return writeFF(dev, code, EvValue(percent*0xffff/100))
+}
@@ -0,0 +1,102 @@
+//go:build linux
+
+package evdev
+
+import (
+	"encoding/binary"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestEffectEncode(t *testing.T) {
+	env := FFEnvelope{AttackLength: 1, AttackLevel: 2, FadeLength: 3, FadeLevel: 4}
+
+	tests := []struct {
+		name   string
+		params FFParams
+		want   []uint16 // the header, then the union of parameters
+	}{
+		{"rumble", FFRumble{StrongMagnitude: 0x8000, WeakMagnitude: 0x4000},
+			[]uint16{FF_RUMBLE, 0xffff, 0x4000, 0, 0, 250, 0, 0, 0x8000, 0x4000}},
+		{"periodic", FFPeriodic{Waveform: FF_SINE, Period: 100, Magnitude: -1, Offset: 5, Phase: 6, Envelope: env},
+			[]uint16{FF_PERIODIC, 0xffff, 0x4000, 0, 0, 250, 0, 0, FF_SINE, 100, 0xffff, 5, 6, 1, 2, 3, 4}},
+		{"constant", FFConstant{Level: -2, Envelope: env},
+			[]uint16{FF_CONSTANT, 0xffff, 0x4000, 0, 0, 250, 0, 0, 0xfffe, 1, 2, 3, 4}},
+		{"ramp", FFRamp{Start: 10, End: -10, Envelope: env},
+			[]uint16{FF_RAMP, 0xffff, 0x4000, 0, 0, 250, 0, 0, 10, 0xfff6, 1, 2, 3, 4}},
+		{"condition", FFCondition{Type: FF_SPRING, Axes: [2]FFConditionAxis{
+			{RightSaturation: 1, LeftSaturation: 2, RightCoeff: 3, LeftCoeff: -4, Deadband: 5, Center: 6},
+			{Center: 7},
+		}}, []uint16{FF_SPRING, 0xffff, 0x4000, 0, 0, 250, 0, 0, 1, 2, 3, 0xfffc, 5, 6, 0, 0, 0, 0, 0, 7}},
+	}
+
+	for _, tt := range tests {
+		e := NewEffect(tt.params, 250*time.Millisecond)
+		e.Direction = 0x4000
+		b := e.encode()
+
+		if len(b) != sizeofFFEffect {
+			t.Fatalf("%s: encoded %d bytes, want %d", tt.name, len(b), sizeofFFEffect)
+		}
+		for i, want := range tt.want {
+			if got := binary.LittleEndian.Uint16(b[2*i:]); got != want {
+				t.Errorf("%s: word %d is %#x, want %#x", tt.name, i, got, want)
+			}
+		}
+	}
+}
+
+func TestEffectPlayback(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	dev := &InputDevice{File: w}
+	if _, err := dev.UploadEffect(Effect{ID: -1}); err != syscall.EINVAL {
+		t.Errorf("expected EINVAL without parameters, got %v", err)
+	}
+	if _, err := dev.UploadEffect(NewEffect(FFRumble{}, 0)); err != syscall.ENOTTY {
+		t.Errorf("expected ENOTTY from a pipe, got %v", err)
+	}
+	for _, percent := range []int{-1, 101} {
+		if err := dev.SetFFGain(percent); err != syscall.EINVAL {
+			t.Errorf("expected EINVAL for a gain of %d%%, got %v", percent, err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		write func() error
+		code  EvCode
+		value EvValue
+	}{
+		{"play", func() error { return dev.PlayEffect(3, 2) }, 3, 2},
+		{"stop", func() error { return dev.StopEffect(3) }, 3, 0},
+		{"gain", func() error { return dev.SetFFGain(50) }, FF_GAIN, 0x7fff},
+		{"autocenter", func() error { return dev.SetFFAutocenter(100) }, FF_AUTOCENTER, 0xffff},
+	}
+
+	in := &InputDevice{File: r}
+	for _, tt := range tests {
+		if err := tt.write(); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		got, err := in.Read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []InputEvent{
+			NewInputEvent(time.Unix(1000, 0), EV_FF, tt.code, tt.value),
+			NewInputEvent(time.Unix(1000, 0), EV_SYN, SYN_REPORT, 0),
+		}
+		if !sameEvents(got, want) {
+			t.Errorf("%s: wrote %v, want %v", tt.name, got, want)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package evdev
+
+import "time"
+
+// Filter transforms a stream of input events. Process is called for every
+// event in stream order and returns the events that should be passed on in
+// its place - none, the event itself, or any number of synthesized events.
+type Filter interface {
+	Process(ev InputEvent) []InputEvent
+}
+
+// TimedFilter is a Filter that also generates events on its own as time
+// passes (ramping, autorepeat, turbo). Tick is called periodically by the
+// owner of the filter and returns the events that became due at now.
+type TimedFilter interface {
+	Filter
+	Tick(now time.Time) []InputEvent
+}
+
+// FilterFunc adapts an ordinary function to the Filter interface.
+type FilterFunc func(ev InputEvent) []InputEvent
+
+// Process calls f(ev).
+func (f FilterFunc) Process(ev InputEvent) []InputEvent {
+	return f(ev)
+}
+
+// Chain runs events through a sequence of filters, feeding the output of
+// each filter into the next one.
+type Chain []Filter
+
+// Process passes ev through every filter of the chain.
+func (c Chain) Process(ev InputEvent) []InputEvent {
+	return c.run(0, []InputEvent{ev})
+}
+
+// Tick ticks every TimedFilter of the chain and passes the events generated
+// by it through the filters that follow it.
+func (c Chain) Tick(now time.Time) []InputEvent {
+	out := make([]InputEvent, 0)
+
+	for i, f := range c {
+		if tf, ok := f.(TimedFilter); ok {
+			out = append(out, c.run(i+1, tf.Tick(now))...)
+		}
+	}
+
+	return out
+}
+
+func (c Chain) run(start int, events []InputEvent) []InputEvent {
+	for _, f := range c[start:] {
+		next := make([]InputEvent, 0, len(events))
+		for _, ev := range events {
+			next = append(next, f.Process(ev)...)
+		}
+		events = next
+	}
+
+	return events
+}
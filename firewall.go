@@ -0,0 +1,134 @@
+//go:build linux
+
+package evdev
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// FirewallAlert reports a device caught injecting suspicious input.
+type FirewallAlert struct {
+	Device  *InputDevice
+	Reason  string
+	Blocked bool  // whether the device was grabbed
+	Err     error // error grabbing the device, if any
+}
+
+// InputFirewall guards against untrusted devices injecting keystrokes, such
+// as a USB "rubber ducky" posing as a keyboard and typing at superhuman
+// speed. Every key press is checked against the typing rate of its device;
+// a device producing Burst consecutive presses less than MinInterval apart
+// is reported and, if Block is set, grabbed so its input no longer reaches
+// anyone else. It is safe for concurrent use.
+type InputFirewall struct {
+	MinInterval time.Duration   // presses closer than this are superhuman
+	Burst       int             // consecutive superhuman presses tolerated
+	Block       bool            // grab offending devices
+	Trusted     map[string]bool // fingerprints of devices never blocked
+	OnAlert     func(alert FirewallAlert)
+
+	mu      sync.Mutex
+	devices map[*InputDevice]*firewallState
+}
+
+type firewallState struct {
+	last    time.Time // time of the last key press
+	fast    int       // consecutive presses less than MinInterval apart
+	blocked bool
+}
+
+// NewInputFirewall creates a firewall blocking devices that type 20 keys in
+// a row less than 10ms apart.
+func NewInputFirewall(onAlert func(alert FirewallAlert)) *InputFirewall {
+	return &InputFirewall{
+		MinInterval: 10 * time.Millisecond,
+		Burst:       20,
+		Block:       true,
+		Trusted:     make(map[string]bool),
+		OnAlert:     onAlert,
+		devices:     make(map[*InputDevice]*firewallState),
+	}
+}
+
+// Check inspects an event read from dev and reports whether it should be
+// passed on. Events of blocked devices are always rejected.
+func (fw *InputFirewall) Check(dev *InputDevice, ev *InputEvent) bool {
+	fw.mu.Lock()
+	state, ok := fw.devices[dev]
+	if !ok {
+		state = &firewallState{}
+		fw.devices[dev] = state
+	}
+
+	if state.blocked {
+		fw.mu.Unlock()
+		return false
+	}
+
+	if ev.Type != EV_KEY || ev.Value != EvValue(KeyDown) || fw.Trusted[dev.Fingerprint()] {
+		fw.mu.Unlock()
+		return true
+	}
+
+	t := ev.Timestamp()
+	if !state.last.IsZero() && t.Sub(state.last) < fw.MinInterval {
+		state.fast++
+	} else {
+		state.fast = 0
+	}
+	state.last = t
+
+	if state.fast < fw.Burst {
+		fw.mu.Unlock()
+		return true
+	}
+
+	alert := FirewallAlert{
+		Device: dev,
+		Reason: fmt.Sprintf("%d key presses less than %v apart", state.fast+1, fw.MinInterval),
+	}
+	if fw.Block {
+		alert.Err = dev.Grab()
+		alert.Blocked = alert.Err == nil
+		state.blocked = alert.Blocked
+	}
+	state.fast = 0
+	fw.mu.Unlock()
+
+	if fw.OnAlert != nil {
+		fw.OnAlert(alert)
+	}
+
+	return !alert.Blocked
+}
+
+// Blocked returns the devices blocked so far.
+func (fw *InputFirewall) Blocked() []*InputDevice {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	devices := make([]*InputDevice, 0)
+	for dev, state := range fw.devices {
+		if state.blocked {
+			devices = append(devices, dev)
+		}
+	}
+
+	return devices
+}
+
+// Unblock releases the grab on a blocked device and lets its events pass again.
+func (fw *InputFirewall) Unblock(dev *InputDevice) error {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	state, ok := fw.devices[dev]
+	if !ok || !state.blocked {
+		return nil
+	}
+	state.blocked = false
+
+	return dev.Release()
+}
@@ -0,0 +1,67 @@
+//go:build linux
+
+package evdev
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInputFirewall(t *testing.T) {
+	alerts := make([]FirewallAlert, 0)
+	fw := NewInputFirewall(func(a FirewallAlert) { alerts = append(alerts, a) })
+	fw.Burst = 3
+	fw.Block = false
+
+	ducky := &InputDevice{Name: "ducky", Phys: "usb-1"}
+	kbd := &InputDevice{Name: "kbd", Phys: "usb-2"}
+	trusted := &InputDevice{Name: "macro pad", Phys: "usb-3"}
+	fw.Trusted[trusted.Fingerprint()] = true
+
+	t0 := time.Unix(1000, 0)
+	tests := []struct {
+		dev    *InputDevice
+		at     time.Duration
+		value  KeyEventState
+		alerts int
+	}{
+		// human typing
+		{kbd, 0, KeyDown, 0},
+		{kbd, 100 * time.Millisecond, KeyDown, 0},
+		{kbd, 101 * time.Millisecond, KeyUp, 0},
+		// injected keystrokes, 4 presses 1ms apart
+		{ducky, 0, KeyDown, 0},
+		{ducky, time.Millisecond, KeyDown, 0},
+		{ducky, 2 * time.Millisecond, KeyDown, 0},
+		{ducky, 3 * time.Millisecond, KeyDown, 1},
+		// the count restarts after an alert and after a pause
+		{ducky, 4 * time.Millisecond, KeyDown, 1},
+		{ducky, 100 * time.Millisecond, KeyDown, 1},
+		{ducky, 101 * time.Millisecond, KeyDown, 1},
+		// trusted devices may type fast
+		{trusted, 0, KeyDown, 1},
+		{trusted, time.Millisecond, KeyDown, 1},
+		{trusted, 2 * time.Millisecond, KeyDown, 1},
+		{trusted, 3 * time.Millisecond, KeyDown, 1},
+	}
+
+	for i, tt := range tests {
+		ev := NewInputEvent(t0.Add(tt.at), EV_KEY, KEY_A, EvValue(tt.value))
+		if !fw.Check(tt.dev, &ev) {
+			t.Errorf("%d: expected the event to pass without blocking", i)
+		}
+		if len(alerts) != tt.alerts {
+			t.Fatalf("%d: expected %d alerts, got %v", i, tt.alerts, alerts)
+		}
+	}
+
+	if a := alerts[0]; a.Device != ducky || a.Blocked || a.Reason != "4 key presses less than 10ms apart" {
+		t.Errorf("unexpected alert %+v", a)
+	}
+	if blocked := fw.Blocked(); len(blocked) != 0 {
+		t.Errorf("expected no blocked devices, got %v", blocked)
+	}
+	if err := fw.Unblock(ducky); err != nil {
+		t.Errorf("unblocking a device not blocked: %v", err)
+	}
+}
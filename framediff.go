@@ -0,0 +1,137 @@
+package evdev
+
+import "time"
+
+// FrameComparison is the result of comparing a frame of events of one
+// device, delimited by SYN_REPORT, with the corresponding frame of another.
+type FrameComparison struct {
+	A, B  []InputEvent  // the frames, nil if missing on that side
+	Delay time.Duration // time of B relative to A
+	Equal bool          // both frames hold the same events
+}
+
+// FrameComparer compares the event streams of two devices frame by frame,
+// e.g. to validate that a proxy or clone faithfully mirrors its source.
+// Frames are paired in order; a single missing or extra frame is detected
+// by looking one frame ahead. Both devices must timestamp events with the
+// same clock.
+type FrameComparer struct {
+	// A frame left unpaired for longer than Timeout is reported as
+	// missing on the other side by Expire.
+	Timeout time.Duration
+
+	a, b       [][]InputEvent // complete frames waiting to be paired
+	curA, curB []InputEvent   // frames being read
+}
+
+// NewFrameComparer creates a comparer reporting frames unpaired for longer
+// than timeout as missing.
+func NewFrameComparer(timeout time.Duration) *FrameComparer {
+	return &FrameComparer{Timeout: timeout}
+}
+
+// FeedA passes an event of the first device and returns the comparisons
+// it completes.
+func (c *FrameComparer) FeedA(ev InputEvent) []FrameComparison {
+	return c.feed(&c.curA, &c.a, ev)
+}
+
+// FeedB passes an event of the second device and returns the comparisons
+// it completes.
+func (c *FrameComparer) FeedB(ev InputEvent) []FrameComparison {
+	return c.feed(&c.curB, &c.b, ev)
+}
+
+func (c *FrameComparer) feed(cur *[]InputEvent, frames *[][]InputEvent, ev InputEvent) []FrameComparison {
+	if ev.Type != EV_SYN || ev.Code != SYN_REPORT {
+		*cur = append(*cur, ev)
+		return nil
+	}
+	if len(*cur) == 0 {
+		return nil
+	}
+
+	*frames = append(*frames, *cur)
+	*cur = nil
+
+	return c.pair()
+}
+
+// Expire reports frames unpaired for longer than the timeout, as seen at
+// now, as missing on the other side, or as differing from an unpaired frame
+// of the other side.
+func (c *FrameComparer) Expire(now time.Time) []FrameComparison {
+	results := c.pair()
+
+	deadline := now.Add(-c.Timeout)
+	for {
+		expiredA := len(c.a) > 0 && c.a[0][0].Timestamp().Before(deadline)
+		expiredB := len(c.b) > 0 && c.b[0][0].Timestamp().Before(deadline)
+		if !expiredA && !expiredB {
+			return results
+		}
+
+		switch {
+		case len(c.a) > 0 && len(c.b) > 0:
+			results = append(results, compareFrames(c.a[0], c.b[0]))
+			c.a, c.b = c.a[1:], c.b[1:]
+		case len(c.a) > 0:
+			results = append(results, FrameComparison{A: c.a[0]})
+			c.a = c.a[1:]
+		default:
+			results = append(results, FrameComparison{B: c.b[0]})
+			c.b = c.b[1:]
+		}
+		results = append(results, c.pair()...)
+	}
+}
+
+// Pair up complete frames, as far as possible without waiting for more.
+func (c *FrameComparer) pair() []FrameComparison {
+	results := make([]FrameComparison, 0)
+
+	for len(c.a) > 0 && len(c.b) > 0 {
+		switch {
+		case sameEvents(c.a[0], c.b[0]):
+			results = append(results, compareFrames(c.a[0], c.b[0]))
+			c.a, c.b = c.a[1:], c.b[1:]
+		case len(c.a) > 1 && sameEvents(c.a[1], c.b[0]):
+			results = append(results, FrameComparison{A: c.a[0]})
+			c.a = c.a[1:]
+		case len(c.b) > 1 && sameEvents(c.a[0], c.b[1]):
+			results = append(results, FrameComparison{B: c.b[0]})
+			c.b = c.b[1:]
+		case len(c.a) > 1 && len(c.b) > 1:
+			results = append(results, compareFrames(c.a[0], c.b[0]))
+			c.a, c.b = c.a[1:], c.b[1:]
+		default:
+			// wait for the next frame to tell a mismatch from a gap
+			return results
+		}
+	}
+
+	return results
+}
+
+func compareFrames(a, b []InputEvent) FrameComparison {
+	return FrameComparison{
+		A:     a,
+		B:     b,
+		Delay: b[0].Timestamp().Sub(a[0].Timestamp()),
+		Equal: sameEvents(a, b),
+	}
+}
+
+// Compare the types, codes and values of two frames, ignoring timestamps.
+func sameEvents(a, b []InputEvent) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Type != b[i].Type || a[i].Code != b[i].Code || a[i].Value != b[i].Value {
+			return false
+		}
+	}
+
+	return true
+}
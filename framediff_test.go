@@ -0,0 +1,55 @@
+package evdev
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFrameComparer(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	frame := func(offset time.Duration, code EvCode, value EvValue) []InputEvent {
+		at := start.Add(offset)
+		return []InputEvent{
+			NewInputEvent(at, EV_REL, code, value),
+			NewInputEvent(at, EV_SYN, SYN_REPORT, 0),
+		}
+	}
+
+	c := NewFrameComparer(100 * time.Millisecond)
+	results := make([]FrameComparison, 0)
+	feed := func(fn func(InputEvent) []FrameComparison, events []InputEvent) {
+		for _, ev := range events {
+			results = append(results, fn(ev)...)
+		}
+	}
+
+	// frame 2 is missing on B, frame 4 differs
+	feed(c.FeedA, frame(0, REL_X, 1))
+	feed(c.FeedB, frame(2*time.Millisecond, REL_X, 1))
+	feed(c.FeedA, frame(10*time.Millisecond, REL_X, 2))
+	feed(c.FeedA, frame(20*time.Millisecond, REL_X, 3))
+	feed(c.FeedB, frame(21*time.Millisecond, REL_X, 3))
+	feed(c.FeedA, frame(30*time.Millisecond, REL_Y, 4))
+	feed(c.FeedB, frame(31*time.Millisecond, REL_Y, 5))
+
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results before expiry, got %+v", results)
+	}
+	if !results[0].Equal || results[0].Delay != 2*time.Millisecond {
+		t.Errorf("unexpected first result %+v", results[0])
+	}
+	if results[1].B != nil || results[1].A[0].Value != 2 {
+		t.Errorf("expected missing frame, got %+v", results[1])
+	}
+	if !results[2].Equal || results[2].Delay != time.Millisecond {
+		t.Errorf("unexpected third result %+v", results[2])
+	}
+
+	if r := c.Expire(start.Add(50 * time.Millisecond)); len(r) != 0 {
+		t.Errorf("unexpected early expiry %+v", r)
+	}
+	r := c.Expire(start.Add(time.Second))
+	if len(r) != 1 || r[0].Equal || r[0].A[0].Value != 4 || r[0].B[0].Value != 5 {
+		t.Errorf("expected differing frames, got %+v", r)
+	}
+}
@@ -0,0 +1,196 @@
+package evdev
+
+import "time"
+
+// AxisBinding binds a key to one direction of a gamepad axis.
+type AxisBinding struct {
+	Axis      EvCode // ABS_* code of the axis
+	Direction int32  // -1 for the minimum, +1 for the maximum of the axis
+}
+
+// GamepadMapping describes how keyboard keys are translated into the buttons
+// and axes of a virtual gamepad.
+type GamepadMapping struct {
+	Buttons map[EvCode]EvCode      // KEY_* code -> BTN_* code
+	Axes    map[EvCode]AxisBinding // KEY_* code -> axis direction
+
+	Min, Max EvValue       // range of the emitted axes
+	Ramp     time.Duration // time for an axis to travel from center to end (0 is instant)
+}
+
+// WASDGamepadMapping is a preset that maps WASD to the left stick, the arrow
+// keys to the right stick and a handful of common keys to gamepad buttons.
+func WASDGamepadMapping() GamepadMapping {
+	return GamepadMapping{
+		Buttons: map[EvCode]EvCode{
+			KEY_SPACE:     BTN_SOUTH,
+			KEY_LEFTSHIFT: BTN_EAST,
+			KEY_E:         BTN_WEST,
+			KEY_Q:         BTN_NORTH,
+			KEY_TAB:       BTN_TL,
+			KEY_R:         BTN_TR,
+			KEY_ENTER:     BTN_START,
+			KEY_ESC:       BTN_SELECT,
+		},
+		Axes: map[EvCode]AxisBinding{
+			KEY_W:     {ABS_Y, -1},
+			KEY_S:     {ABS_Y, +1},
+			KEY_A:     {ABS_X, -1},
+			KEY_D:     {ABS_X, +1},
+			KEY_UP:    {ABS_RY, -1},
+			KEY_DOWN:  {ABS_RY, +1},
+			KEY_LEFT:  {ABS_RX, -1},
+			KEY_RIGHT: {ABS_RX, +1},
+		},
+		Min:  -32768,
+		Max:  32767,
+		Ramp: 150 * time.Millisecond,
+	}
+}
+
+// GamepadMapper translates the events of a keyboard into the events of a
+// virtual gamepad according to a GamepadMapping. Keys that are not part of
+// the mapping are dropped. With a non-zero Ramp the axes move gradually and
+// Tick must be called regularly to advance them. The zero value, given a
+// Mapping, starts with all axes centered.
+type GamepadMapper struct {
+	Mapping GamepadMapping
+
+	held     map[EvCode]bool    // currently held axis keys
+	position map[EvCode]EvValue // current axis positions
+	lastTick time.Time
+}
+
+// NewGamepadMapper creates a mapper with all axes centered.
+func NewGamepadMapper(mapping GamepadMapping) *GamepadMapper {
+	m := &GamepadMapper{Mapping: mapping}
+	m.init()
+
+	return m
+}
+
+// Center the axes on first use.
+func (m *GamepadMapper) init() {
+	if m.position != nil {
+		return
+	}
+
+	m.held = make(map[EvCode]bool)
+	m.position = make(map[EvCode]EvValue)
+	for _, b := range m.Mapping.Axes {
+		m.position[b.Axis] = m.center()
+	}
+}
+
+// Process translates a single keyboard event.
+func (m *GamepadMapper) Process(ev InputEvent) []InputEvent {
+	if ev.Type != EV_KEY || ev.Value == EvValue(KeyHold) {
+		return nil
+	}
+	m.init()
+	t := ev.Timestamp()
+
+	if btn, ok := m.Mapping.Buttons[ev.Code]; ok {
+		return []InputEvent{
+			NewInputEvent(t, EV_KEY, btn, ev.Value),
+			NewInputEvent(t, EV_SYN, SYN_REPORT, 0),
+		}
+	}
+
+	binding, ok := m.Mapping.Axes[ev.Code]
+	if !ok {
+		return nil
+	}
+	m.held[ev.Code] = ev.Value != 0
+
+	if m.Mapping.Ramp > 0 {
+		// let Tick move the axis, starting from the time of the key press
+		if m.lastTick.IsZero() {
+			m.lastTick = t
+		}
+		return nil
+	}
+
+	m.position[binding.Axis] = m.target(binding.Axis)
+	return []InputEvent{
+		NewInputEvent(t, EV_ABS, binding.Axis, m.position[binding.Axis]),
+		NewInputEvent(t, EV_SYN, SYN_REPORT, 0),
+	}
+}
+
+// Tick moves every ramping axis towards its target position.
+func (m *GamepadMapper) Tick(now time.Time) []InputEvent {
+	if m.Mapping.Ramp <= 0 {
+		return nil
+	}
+	m.init()
+
+	elapsed := now.Sub(m.lastTick)
+	if m.lastTick.IsZero() || elapsed < 0 {
+		elapsed = 0
+	}
+	m.lastTick = now
+
+	halfRange := int64(m.Mapping.Max - m.center())
+	step := EvValue(halfRange * int64(elapsed) / int64(m.Mapping.Ramp))
+
+	events := make([]InputEvent, 0)
+	for axis, pos := range m.position {
+		target := m.target(axis)
+		next := pos
+
+		switch {
+		case pos < target:
+			next = minValue(pos+step, target)
+		case pos > target:
+			next = maxValue(pos-step, target)
+		}
+
+		if next != pos {
+			m.position[axis] = next
+			events = append(events, NewInputEvent(now, EV_ABS, axis, next))
+		}
+	}
+
+	if len(events) > 0 {
+		events = append(events, NewInputEvent(now, EV_SYN, SYN_REPORT, 0))
+	}
+
+	return events
+}
+
+func (m *GamepadMapper) center() EvValue {
+	return EvValue((int64(m.Mapping.Min) + int64(m.Mapping.Max)) / 2)
+}
+
+// Get the position an axis should move to given the currently held keys.
+func (m *GamepadMapper) target(axis EvCode) EvValue {
+	direction := int32(0)
+	for key, b := range m.Mapping.Axes {
+		if b.Axis == axis && m.held[key] {
+			direction += b.Direction
+		}
+	}
+
+	switch {
+	case direction < 0:
+		return m.Mapping.Min
+	case direction > 0:
+		return m.Mapping.Max
+	}
+	return m.center()
+}
+
+func minValue(a, b EvValue) EvValue {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxValue(a, b EvValue) EvValue {
+	if a > b {
+		return a
+	}
+	return b
+}
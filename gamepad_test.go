@@ -0,0 +1,66 @@
+package evdev
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGamepadMapper(t *testing.T) {
+	mapping := WASDGamepadMapping()
+	mapping.Ramp = 100 * time.Millisecond
+	m := NewGamepadMapper(mapping)
+
+	start := time.Unix(1000, 0)
+
+	out := m.Process(NewInputEvent(start, EV_KEY, KEY_SPACE, 1))
+	if len(out) != 2 || out[0].Code != BTN_SOUTH || out[0].Value != 1 {
+		t.Errorf("unexpected button events: %v", out)
+	}
+
+	if out := m.Process(NewInputEvent(start, EV_KEY, KEY_D, 1)); len(out) != 0 {
+		t.Errorf("ramping axis moved without tick: %v", out)
+	}
+
+	out = m.Tick(start.Add(50 * time.Millisecond))
+	if len(out) != 2 || out[0].Code != ABS_X || out[0].Value <= 0 || out[0].Value >= mapping.Max {
+		t.Errorf("expected half deflection, got %v", out)
+	}
+
+	out = m.Tick(start.Add(200 * time.Millisecond))
+	if len(out) != 2 || out[0].Value != mapping.Max {
+		t.Errorf("expected full deflection, got %v", out)
+	}
+
+	if out := m.Tick(start.Add(300 * time.Millisecond)); len(out) != 0 {
+		t.Errorf("axis at rest emitted events: %v", out)
+	}
+}
+
+func TestGamepadMapperZeroValue(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	// no mapping, nothing to translate
+	var zero GamepadMapper
+	if out := zero.Process(NewInputEvent(now, EV_KEY, KEY_D, 1)); len(out) != 0 {
+		t.Errorf("unexpected events %v", out)
+	}
+
+	mapping := WASDGamepadMapping()
+	mapping.Ramp = 0
+	m := &GamepadMapper{Mapping: mapping}
+	out := m.Process(NewInputEvent(now, EV_KEY, KEY_D, 1))
+	if len(out) != 2 || out[0].Code != ABS_X || out[0].Value != mapping.Max {
+		t.Errorf("expected full deflection, got %v", out)
+	}
+	out = m.Process(NewInputEvent(now, EV_KEY, KEY_D, 0))
+	if len(out) != 2 || out[0].Value != m.center() {
+		t.Errorf("expected the axis centered, got %v", out)
+	}
+
+	// ramping axes start centered
+	mapping.Ramp = 100 * time.Millisecond
+	m = &GamepadMapper{Mapping: mapping}
+	if out := m.Tick(now); len(out) != 0 {
+		t.Errorf("axis at rest emitted events: %v", out)
+	}
+}
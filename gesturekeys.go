@@ -0,0 +1,194 @@
+//go:build linux
+
+package evdev
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// GestureKind is a kind of touch gesture.
+type GestureKind int
+
+const (
+	GestureSwipeLeft GestureKind = iota + 1
+	GestureSwipeRight
+	GestureSwipeUp
+	GestureSwipeDown
+	GesturePinchIn
+	GesturePinchOut
+	GestureTap
+	GestureDoubleTap
+	GestureLongPress
+	GestureRotateCW  // clockwise
+	GestureRotateCCW // counterclockwise
+)
+
+var gestureKindNames = map[GestureKind]string{
+	GestureSwipeLeft:  "swipe-left",
+	GestureSwipeRight: "swipe-right",
+	GestureSwipeUp:    "swipe-up",
+	GestureSwipeDown:  "swipe-down",
+	GesturePinchIn:    "pinch-in",
+	GesturePinchOut:   "pinch-out",
+	GestureTap:        "tap",
+	GestureDoubleTap:  "double-tap",
+	GestureLongPress:  "long-press",
+	GestureRotateCW:   "rotate-cw",
+	GestureRotateCCW:  "rotate-ccw",
+}
+
+// Gesture is a recognized touch gesture (see GestureRecognizer). Swipes are
+// named after the direction the fingers move in.
+type Gesture struct {
+	Kind    GestureKind
+	Fingers int // number of fingers, 0 for any
+}
+
+// String returns the name of the gesture, e.g. "swipe-left/3" for a three
+// finger swipe to the left, or "pinch-in" for a pinch with any number of
+// fingers.
+func (g Gesture) String() string {
+	name, ok := gestureKindNames[g.Kind]
+	if !ok {
+		name = fmt.Sprintf("gesture(%d)", g.Kind)
+	}
+	if g.Fingers == 0 {
+		return name
+	}
+
+	return name + "/" + strconv.Itoa(g.Fingers)
+}
+
+// ParseGesture parses a gesture name as returned by String.
+func ParseGesture(s string) (Gesture, error) {
+	name, fingers := strings.ToLower(strings.TrimSpace(s)), ""
+	if i := strings.IndexByte(name, '/'); i >= 0 {
+		name, fingers = name[:i], name[i+1:]
+	}
+
+	g := Gesture{}
+	for kind, n := range gestureKindNames {
+		if n == name {
+			g.Kind = kind
+		}
+	}
+	if g.Kind == 0 {
+		return g, fmt.Errorf("evdev: invalid gesture %q", s)
+	}
+
+	if fingers != "" {
+		n, err := strconv.Atoi(fingers)
+		if err != nil || n < 1 {
+			return g, fmt.Errorf("evdev: invalid gesture %q", s)
+		}
+		g.Fingers = n
+	}
+
+	return g, nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (g Gesture) MarshalText() ([]byte, error) {
+	return []byte(g.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (g *Gesture) UnmarshalText(text []byte) error {
+	parsed, err := ParseGesture(string(text))
+	if err != nil {
+		return err
+	}
+
+	*g = parsed
+	return nil
+}
+
+// GestureKeys maps touchpad gestures to the chords they type, so that
+// compositors without gesture support still get e.g. back/forward and zoom.
+// Gestures with Fingers 0 apply to any number of fingers not mapped
+// explicitly. Mappings are stored as a JSON object in a file, e.g.
+// {"swipe-right/3": "alt+left", "pinch-out": "ctrl+equal"}.
+type GestureKeys map[Gesture]Chord
+
+// BrowserGestureKeys returns the mapping of browser navigation: three
+// finger swipes go back and forward and pinches zoom.
+func BrowserGestureKeys() GestureKeys {
+	return GestureKeys{
+		{GestureSwipeRight, 3}: {Modifiers: ModAlt, Key: KEY_LEFT},
+		{GestureSwipeLeft, 3}:  {Modifiers: ModAlt, Key: KEY_RIGHT},
+		{GesturePinchOut, 0}:   {Modifiers: ModCtrl, Key: KEY_EQUAL},
+		{GesturePinchIn, 0}:    {Modifiers: ModCtrl, Key: KEY_MINUS},
+	}
+}
+
+// WorkspaceGestureKeys returns the mapping of workspace switching: four
+// finger swipes switch to the neighbouring workspace, as in GNOME.
+func WorkspaceGestureKeys() GestureKeys {
+	return GestureKeys{
+		{GestureSwipeLeft, 4}:  {Modifiers: ModCtrl | ModAlt, Key: KEY_RIGHT},
+		{GestureSwipeRight, 4}: {Modifiers: ModCtrl | ModAlt, Key: KEY_LEFT},
+		{GestureSwipeUp, 4}:    {Modifiers: ModCtrl | ModAlt, Key: KEY_DOWN},
+		{GestureSwipeDown, 4}:  {Modifiers: ModCtrl | ModAlt, Key: KEY_UP},
+	}
+}
+
+// LoadGestureKeys reads a mapping from a file.
+func LoadGestureKeys(path string) (GestureKeys, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(GestureKeys)
+	if err = json.Unmarshal(data, &keys); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// Save writes the mapping to a file.
+func (m GestureKeys) Save(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Merge adds the mappings of other, replacing existing mappings of the same
+// gestures, e.g. to combine presets.
+func (m GestureKeys) Merge(other GestureKeys) GestureKeys {
+	for g, chord := range other {
+		m[g] = chord
+	}
+
+	return m
+}
+
+// Lookup returns the chord of a gesture, falling back to the mapping for
+// any number of fingers.
+func (m GestureKeys) Lookup(g Gesture) (Chord, bool) {
+	if chord, ok := m[g]; ok {
+		return chord, true
+	}
+
+	chord, ok := m[Gesture{Kind: g.Kind}]
+	return chord, ok
+}
+
+// Send types the chord of a gesture on a keyboard. It reports whether the
+// gesture is mapped.
+func (m GestureKeys) Send(g Gesture, k *OnScreenKeyboard) (bool, error) {
+	chord, ok := m.Lookup(g)
+	if !ok {
+		return false, nil
+	}
+
+	return true, k.tapChord(chord)
+}
@@ -0,0 +1,60 @@
+//go:build linux
+
+package evdev
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestGestureKeys(t *testing.T) {
+	keys := BrowserGestureKeys().Merge(WorkspaceGestureKeys())
+
+	data, err := json.Marshal(keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := make(GestureKeys)
+	if err = json.Unmarshal(data, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != len(keys) {
+		t.Fatalf("expected %d mappings, got %d", len(keys), len(loaded))
+	}
+	for g, chord := range keys {
+		if loaded[g] != chord {
+			t.Errorf("%s: got %s, want %s", g, loaded[g], chord)
+		}
+	}
+
+	if _, err := ParseGesture("swipe-left/0"); err == nil {
+		t.Error("expected error parsing zero fingers")
+	}
+
+	// pinches map for any number of fingers, swipes only for three
+	sink := new(eventRecorder)
+	k := NewOnScreenKeyboard(sink)
+	if ok, err := keys.Send(Gesture{GesturePinchOut, 2}, k); !ok || err != nil {
+		t.Fatalf("pinch not sent: %v %v", ok, err)
+	}
+	if ok, _ := keys.Send(Gesture{GestureSwipeRight, 2}, k); ok {
+		t.Error("two finger swipe should not be mapped")
+	}
+
+	want := []EvCode{KEY_LEFTCTRL, KEY_EQUAL, KEY_EQUAL, KEY_LEFTCTRL}
+	got := make([]EvCode, 0)
+	for _, ev := range *sink {
+		if ev.Type == EV_KEY {
+			got = append(got, ev.Code)
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got keys %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got keys %v, want %v", got, want)
+			break
+		}
+	}
+}
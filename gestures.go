@@ -0,0 +1,246 @@
+//go:build linux
+
+package evdev
+
+import (
+	"math"
+	"time"
+)
+
+// GestureConfig holds the thresholds of gesture recognition. Distances are
+// in device units.
+type GestureConfig struct {
+	TapTimeout       time.Duration // longest touch counting as a tap
+	TapDistance      int32         // largest movement of a tap or long press
+	DoubleTapTimeout time.Duration // longest pause between the taps of a double tap
+	LongPress        time.Duration // shortest touch counting as a long press
+	SwipeDistance    int32         // shortest movement counting as a swipe
+	PinchScale       float64       // smallest relative change of finger spread counting as a pinch
+	RotationAngle    float64       // smallest rotation counting as one, in radians
+}
+
+// DefaultGestureConfig returns thresholds for a touch surface width units
+// wide.
+func DefaultGestureConfig(width int32) GestureConfig {
+	return GestureConfig{
+		TapTimeout:       250 * time.Millisecond,
+		TapDistance:      width / 40,
+		DoubleTapTimeout: 300 * time.Millisecond,
+		LongPress:        600 * time.Millisecond,
+		SwipeDistance:    width / 8,
+		PinchScale:       0.25,
+		RotationAngle:    math.Pi / 8,
+	}
+}
+
+// GestureEvent is a recognized gesture.
+type GestureEvent struct {
+	Gesture
+	X, Y     int32         // center of the fingers when the gesture started
+	DX, DY   int32         // movement of the center
+	Scale    float64       // change of finger spread, > 1 when spreading
+	Rotation float64       // rotation in radians, positive clockwise
+	Duration time.Duration // time from the first touch to the gesture
+}
+
+// GestureRecognizer turns multi-touch contacts into gestures: taps and
+// double taps, long presses, and swipes, pinches and rotations with any
+// number of fingers. A gesture lasts from the first touch until all fingers
+// are lifted and is reported at its end, except for long presses, which are
+// reported once the fingers rested long enough; a double tap is preceded by
+// the report of its first tap. Movement of two or more fingers is taken as
+// a pinch if their spread changes enough, else as a rotation if they rotate
+// enough, else as a swipe.
+type GestureRecognizer struct {
+	GestureConfig
+	OnGesture func(g GestureEvent)
+
+	// MT tracks the contacts; set MT.ProtocolA for protocol A devices.
+	MT *MTState
+
+	tracks    map[int32]*gestureTrack // contacts of the gesture by tracking id
+	start     time.Time               // first touch of the gesture
+	now       time.Time               // time of the current frame
+	fingers   int                     // most fingers down at once
+	moved     bool                    // moved more than TapDistance
+	longPress bool                    // long press reported
+	lastTap   GestureEvent            // for double taps
+	lastTapAt time.Time               // end of the last tap, zero if none
+}
+
+type gestureTrack struct {
+	start, last Contact
+}
+
+// NewGestureRecognizer creates a recognizer reporting gestures to
+// onGesture.
+func NewGestureRecognizer(config GestureConfig, onGesture func(g GestureEvent)) *GestureRecognizer {
+	r := &GestureRecognizer{
+		GestureConfig: config,
+		OnGesture:     onGesture,
+		MT:            NewMTState(),
+		tracks:        make(map[int32]*gestureTrack),
+	}
+
+	r.MT.OnTouchDown = func(c Contact) {
+		if len(r.tracks) == 0 {
+			r.start = r.now
+			r.fingers, r.moved, r.longPress = 0, false, false
+		}
+		r.tracks[c.TrackingID] = &gestureTrack{start: c, last: c}
+	}
+	r.MT.OnTouchMove = r.update
+	r.MT.OnTouchUp = r.update
+
+	return r
+}
+
+func (r *GestureRecognizer) update(c Contact) {
+	if t, ok := r.tracks[c.TrackingID]; ok {
+		t.last = c
+		if distance(t.start, t.last) > float64(r.TapDistance) {
+			r.moved = true
+		}
+	}
+}
+
+// Feed passes an event of the touch device to the recognizer.
+func (r *GestureRecognizer) Feed(ev *InputEvent) {
+	r.now = ev.Timestamp()
+	r.MT.Feed(ev)
+
+	if ev.Type != EV_SYN || ev.Code != SYN_REPORT || len(r.tracks) == 0 {
+		return
+	}
+
+	down := len(r.MT.Contacts())
+	if down > r.fingers {
+		r.fingers = down
+	}
+	if down == 0 {
+		r.end()
+		return
+	}
+	r.Tick(r.now)
+}
+
+// Tick reports a long press once the fingers rested long enough, as seen at
+// now. It must be called periodically, as resting fingers generate no
+// events, with now taken from the clock of the event timestamps.
+func (r *GestureRecognizer) Tick(now time.Time) {
+	if len(r.tracks) == 0 || r.moved || r.longPress || now.Sub(r.start) < r.LongPress {
+		return
+	}
+
+	r.longPress = true
+	r.report(r.gesture(GestureLongPress, now))
+}
+
+// End a gesture once all fingers are lifted.
+func (r *GestureRecognizer) end() {
+	defer func() { r.tracks = make(map[int32]*gestureTrack) }()
+
+	if r.longPress {
+		return
+	}
+
+	if !r.moved {
+		if r.now.Sub(r.start) > r.TapTimeout {
+			return
+		}
+
+		g := r.gesture(GestureTap, r.now)
+		if !r.lastTapAt.IsZero() && r.lastTap.Fingers == g.Fingers && r.start.Sub(r.lastTapAt) <= r.DoubleTapTimeout {
+			g.Kind = GestureDoubleTap
+			r.lastTapAt = time.Time{}
+		} else {
+			r.lastTap, r.lastTapAt = g, r.now
+		}
+		r.report(g)
+		return
+	}
+
+	g := r.gesture(0, r.now)
+	switch {
+	case r.fingers > 1 && math.Abs(g.Scale-1) >= r.PinchScale:
+		g.Kind = GesturePinchOut
+		if g.Scale < 1 {
+			g.Kind = GesturePinchIn
+		}
+	case r.fingers > 1 && math.Abs(g.Rotation) >= r.RotationAngle:
+		g.Kind = GestureRotateCW
+		if g.Rotation < 0 {
+			g.Kind = GestureRotateCCW
+		}
+	case math.Hypot(float64(g.DX), float64(g.DY)) >= float64(r.SwipeDistance):
+		switch {
+		case abs32(g.DX) >= abs32(g.DY) && g.DX > 0:
+			g.Kind = GestureSwipeRight
+		case abs32(g.DX) >= abs32(g.DY):
+			g.Kind = GestureSwipeLeft
+		case g.DY > 0:
+			g.Kind = GestureSwipeDown
+		default:
+			g.Kind = GestureSwipeUp
+		}
+	default:
+		return
+	}
+	r.report(g)
+}
+
+// Describe the gesture made by the contacts so far.
+func (r *GestureRecognizer) gesture(kind GestureKind, now time.Time) GestureEvent {
+	var x0, y0, x1, y1 float64
+	for _, t := range r.tracks {
+		x0, y0 = x0+float64(t.start.X), y0+float64(t.start.Y)
+		x1, y1 = x1+float64(t.last.X), y1+float64(t.last.Y)
+	}
+	n := float64(len(r.tracks))
+	x0, y0, x1, y1 = x0/n, y0/n, x1/n, y1/n
+
+	var spread0, spread1, rotation float64
+	for _, t := range r.tracks {
+		dx0, dy0 := float64(t.start.X)-x0, float64(t.start.Y)-y0
+		dx1, dy1 := float64(t.last.X)-x1, float64(t.last.Y)-y1
+		spread0 += math.Hypot(dx0, dy0)
+		spread1 += math.Hypot(dx1, dy1)
+
+		// with y pointing down, growing angles turn clockwise
+		angle := math.Atan2(dy1, dx1) - math.Atan2(dy0, dx0)
+		rotation += math.Remainder(angle, 2*math.Pi)
+	}
+
+	scale := 1.0
+	if spread0 > 0 {
+		scale = spread1 / spread0
+	}
+
+	return GestureEvent{
+		Gesture:  Gesture{Kind: kind, Fingers: r.fingers},
+		X:        int32(x0),
+		Y:        int32(y0),
+		DX:       int32(x1 - x0),
+		DY:       int32(y1 - y0),
+		Scale:    scale,
+		Rotation: rotation / n,
+		Duration: now.Sub(r.start),
+	}
+}
+
+func (r *GestureRecognizer) report(g GestureEvent) {
+	if r.OnGesture != nil {
+		r.OnGesture(g)
+	}
+}
+
+func distance(a, b Contact) float64 {
+	return math.Hypot(float64(b.X-a.X), float64(b.Y-a.Y))
+}
+
+func abs32(v int32) int32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
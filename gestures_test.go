@@ -0,0 +1,100 @@
+//go:build linux
+
+package evdev
+
+import (
+	"testing"
+	"time"
+)
+
+// Feeds frames of contacts to a gesture recognizer on a simulated clock.
+type gestureFeeder struct {
+	r      *GestureRecognizer
+	now    time.Time
+	nextID int
+	slots  map[int]bool // slots down
+}
+
+// Move the contacts of the given slots to positions, touching down new
+// slots and lifting the others, after d.
+func (f *gestureFeeder) frame(d time.Duration, contacts map[int][2]int) {
+	f.now = f.now.Add(d)
+	feed := func(evType EvType, code EvCode, value int) {
+		ev := NewInputEvent(f.now, evType, code, EvValue(value))
+		f.r.Feed(&ev)
+	}
+
+	for slot := range f.slots {
+		if _, ok := contacts[slot]; !ok {
+			feed(EV_ABS, ABS_MT_SLOT, slot)
+			feed(EV_ABS, ABS_MT_TRACKING_ID, -1)
+			delete(f.slots, slot)
+		}
+	}
+	for slot, pos := range contacts {
+		feed(EV_ABS, ABS_MT_SLOT, slot)
+		if !f.slots[slot] {
+			f.nextID++
+			feed(EV_ABS, ABS_MT_TRACKING_ID, f.nextID)
+			f.slots[slot] = true
+		}
+		feed(EV_ABS, ABS_MT_POSITION_X, pos[0])
+		feed(EV_ABS, ABS_MT_POSITION_Y, pos[1])
+	}
+	feed(EV_SYN, SYN_REPORT, 0)
+}
+
+func TestGestureRecognizer(t *testing.T) {
+	gestures := make([]GestureEvent, 0)
+	r := NewGestureRecognizer(DefaultGestureConfig(4000), func(g GestureEvent) {
+		gestures = append(gestures, g)
+	})
+	f := &gestureFeeder{r: r, now: time.Unix(1000, 0), slots: make(map[int]bool)}
+	ms := time.Millisecond
+
+	// double tap
+	f.frame(0, map[int][2]int{0: {1000, 1000}})
+	f.frame(80*ms, nil)
+	f.frame(100*ms, map[int][2]int{0: {1010, 1000}})
+	f.frame(80*ms, nil)
+
+	// long press, moving a little
+	f.frame(time.Second, map[int][2]int{0: {2000, 2000}})
+	f.frame(300*ms, map[int][2]int{0: {2020, 2000}})
+	r.Tick(f.now.Add(400 * ms))
+	f.frame(500*ms, nil)
+
+	// three finger swipe to the left
+	f.frame(time.Second, map[int][2]int{0: {3000, 1000}, 1: {3100, 1000}, 2: {3200, 1000}})
+	f.frame(50*ms, map[int][2]int{0: {2500, 1010}, 1: {2600, 1010}, 2: {2700, 1010}})
+	f.frame(50*ms, map[int][2]int{0: {2000, 1020}, 1: {2100, 1020}, 2: {2200, 1020}})
+	f.frame(50*ms, nil)
+
+	// pinch out, then clockwise rotation
+	f.frame(time.Second, map[int][2]int{0: {1900, 2000}, 1: {2100, 2000}})
+	f.frame(100*ms, map[int][2]int{0: {1700, 2000}, 1: {2300, 2000}})
+	f.frame(50*ms, nil)
+	f.frame(time.Second, map[int][2]int{0: {1800, 2000}, 1: {2200, 2000}})
+	f.frame(100*ms, map[int][2]int{0: {1859, 1859}, 1: {2141, 2141}})
+	f.frame(50*ms, nil)
+
+	want := []Gesture{
+		{GestureTap, 1}, {GestureDoubleTap, 1}, {GestureLongPress, 1},
+		{GestureSwipeLeft, 3}, {GesturePinchOut, 2}, {GestureRotateCW, 2},
+	}
+	if len(gestures) != len(want) {
+		t.Fatalf("got gestures %v, want %v", gestures, want)
+	}
+	for i, g := range gestures {
+		if g.Gesture != want[i] {
+			t.Errorf("gesture %d: got %s, want %s", i, g.Gesture, want[i])
+		}
+	}
+
+	if g := gestures[4]; g.Scale < 2.9 || g.Scale > 3.1 {
+		t.Errorf("expected pinch scale 3, got %v", g.Scale)
+	}
+	if g := gestures[3]; g.DX != -1000 || g.DY != 20 {
+		t.Errorf("unexpected swipe movement %d,%d", g.DX, g.DY)
+	}
+}
@@ -0,0 +1,128 @@
+//go:build linux
+
+package evdev
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// GrabLockDir is the directory holding the advisory grab locks. All
+// cooperating processes must use the same directory.
+var GrabLockDir = filepath.Join(os.TempDir(), "evdev-grab")
+
+// GrabHolder identifies the process holding the grab lock of a device.
+type GrabHolder struct {
+	PID   int
+	Owner string // name given by the holder
+}
+
+// GrabHeldError is returned when the grab lock of a device is held by
+// another process.
+type GrabHeldError struct {
+	Holder GrabHolder
+}
+
+func (e *GrabHeldError) Error() string {
+	return fmt.Sprintf("evdev: device grabbed by %s (pid %d)", e.Holder.Owner, e.Holder.PID)
+}
+
+// GrabLock is an acquired advisory grab lock together with the grab itself.
+type GrabLock struct {
+	dev  *InputDevice
+	file *os.File
+}
+
+// GrabArbitrated grabs the device after taking its advisory grab lock, so
+// that cooperating processes using this package don't fight over grabs of
+// the same device. Locks are keyed by device fingerprint and are released
+// automatically when the holding process exits. If another process holds
+// the lock, a *GrabHeldError is returned.
+func (dev *InputDevice) GrabArbitrated(owner string) (*GrabLock, error) {
+	if err := os.MkdirAll(GrabLockDir, 0777); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(dev.grabLockPath(), os.O_RDWR|os.O_CREATE, 0666)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if err == syscall.EWOULDBLOCK {
+			holder, _, _ := dev.GrabHolder()
+			return nil, &GrabHeldError{holder}
+		}
+		return nil, err
+	}
+
+	if err = dev.Grab(); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	// record the holder for GrabHolder
+	f.Truncate(0)
+	f.WriteAt([]byte(fmt.Sprintf("%d %s\n", os.Getpid(), owner)), 0)
+
+	return &GrabLock{dev: dev, file: f}, nil
+}
+
+// Release releases the grab and the grab lock.
+func (l *GrabLock) Release() error {
+	err := l.dev.Release()
+	l.file.Truncate(0)
+
+	if cerr := l.file.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
+
+// GrabHolder returns the current holder of the device's grab lock. The
+// boolean result is false if nobody holds the lock.
+func (dev *InputDevice) GrabHolder() (GrabHolder, bool, error) {
+	f, err := os.Open(dev.grabLockPath())
+	if os.IsNotExist(err) {
+		return GrabHolder{}, false, nil
+	}
+	if err != nil {
+		return GrabHolder{}, false, err
+	}
+	defer f.Close()
+
+	// if a shared lock can be taken, nobody holds the exclusive one
+	if err = syscall.Flock(int(f.Fd()), syscall.LOCK_SH|syscall.LOCK_NB); err == nil {
+		return GrabHolder{}, false, nil
+	}
+
+	data := make([]byte, 4096)
+	n, _ := f.Read(data)
+
+	holder := GrabHolder{}
+	fields := strings.SplitN(strings.TrimSpace(string(data[:n])), " ", 2)
+	holder.PID, _ = strconv.Atoi(fields[0])
+	if len(fields) > 1 {
+		holder.Owner = fields[1]
+	}
+
+	return holder, true, nil
+}
+
+func (dev *InputDevice) grabLockPath() string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-':
+			return r
+		}
+		return '_'
+	}, dev.Fingerprint())
+
+	return filepath.Join(GrabLockDir, name+".lock")
+}
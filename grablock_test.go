@@ -0,0 +1,56 @@
+//go:build linux
+
+package evdev
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestGrabLock(t *testing.T) {
+	defer func(dir string) { GrabLockDir = dir }(GrabLockDir)
+	GrabLockDir = t.TempDir()
+
+	dev := &InputDevice{BusType: 3, Vendor: 0x046d, Product: 0xc52b, Version: 0x111, Phys: "usb-0000:00:14.0-2/input0"}
+	if p := dev.grabLockPath(); p != filepath.Join(GrabLockDir, "0003_046d_c52b_0111_usb-0000_00_14.0-2_input0.lock") {
+		t.Errorf("unexpected lock path %q", p)
+	}
+
+	if _, held, err := dev.GrabHolder(); held || err != nil {
+		t.Errorf("expected no holder without a lock file, got %v, %v", held, err)
+	}
+
+	// another process holds the lock
+	f, err := os.OpenFile(dev.grabLockPath(), os.O_RDWR|os.O_CREATE, 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, held, _ := dev.GrabHolder(); held {
+		t.Error("expected no holder of an unlocked file")
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("4242 remapper\n")
+
+	holder, held, err := dev.GrabHolder()
+	if !held || err != nil || holder != (GrabHolder{PID: 4242, Owner: "remapper"}) {
+		t.Errorf("unexpected holder %+v, %v, %v", holder, held, err)
+	}
+
+	_, err = dev.GrabArbitrated("test")
+	if e, ok := err.(*GrabHeldError); !ok || e.Holder.PID != 4242 {
+		t.Fatalf("expected a GrabHeldError, got %v", err)
+	}
+	if msg := err.Error(); msg != "evdev: device grabbed by remapper (pid 4242)" {
+		t.Errorf("unexpected error %q", msg)
+	}
+
+	// the lock is released with the holder
+	f.Close()
+	if _, held, _ := dev.GrabHolder(); held {
+		t.Error("expected the lock to be released")
+	}
+}
@@ -0,0 +1,52 @@
+//go:build linux
+
+package evdev
+
+import "time"
+
+// TouchHaptics gives haptic feedback on touch-down: at the end of every frame
+// in which a finger touched the screen it calls Play, e.g. to play a short
+// rumble effect on a vibration motor. Both single touch (BTN_TOUCH) and
+// multi-touch (ABS_MT_TRACKING_ID) touchscreens are supported.
+type TouchHaptics struct {
+	Play        func() error
+	MinInterval time.Duration // minimum time between two feedbacks
+
+	touched bool      // a touch-down happened in the current frame
+	last    time.Time // time of the last feedback
+}
+
+// NewTouchHaptics creates haptic feedback calling play on touch-down.
+func NewTouchHaptics(play func() error) *TouchHaptics {
+	return &TouchHaptics{Play: play, MinInterval: 50 * time.Millisecond}
+}
+
+// PlayFFEffect returns a Play function starting an already uploaded force
+// feedback effect on w, typically the touchscreen's own device.
+func PlayFFEffect(w EventWriter, id EvCode) func() error {
+	return func() error {
+		return writeFF(w, id, 1)
+	}
+}
+
+// Feed passes an event of the touchscreen. Errors of Play are returned.
+func (h *TouchHaptics) Feed(ev *InputEvent) error {
+	switch {
+	case ev.Type == EV_KEY && ev.Code == BTN_TOUCH && ev.Value == 1:
+		h.touched = true
+	case ev.Type == EV_ABS && ev.Code == ABS_MT_TRACKING_ID && ev.Value >= 0:
+		h.touched = true
+	case ev.Type == EV_SYN && ev.Code == SYN_REPORT && h.touched:
+		h.touched = false
+
+		t := ev.Timestamp()
+		if !h.last.IsZero() && t.Sub(h.last) < h.MinInterval {
+			return nil
+		}
+		h.last = t
+
+		return h.Play()
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+//go:build linux
+
+package evdev
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTouchHaptics(t *testing.T) {
+	plays := 0
+	var fail error
+	h := NewTouchHaptics(func() error {
+		plays++
+		return fail
+	})
+	t0 := time.Unix(1000, 0)
+	ms := time.Millisecond
+
+	tests := []struct {
+		at    time.Duration
+		ev    [3]int // type, code, value of the event before the SYN_REPORT
+		plays int
+	}{
+		{0, [3]int{EV_KEY, BTN_TOUCH, 1}, 1},
+		{10 * ms, [3]int{EV_ABS, ABS_X, 100}, 1},             // movement
+		{20 * ms, [3]int{EV_KEY, BTN_TOUCH, 0}, 1},           // lift
+		{30 * ms, [3]int{EV_KEY, BTN_TOUCH, 1}, 1},           // too soon
+		{100 * ms, [3]int{EV_ABS, ABS_MT_TRACKING_ID, 7}, 2}, // multi-touch
+		{200 * ms, [3]int{EV_ABS, ABS_MT_TRACKING_ID, -1}, 2},
+	}
+	for _, tt := range tests {
+		ev := NewInputEvent(t0.Add(tt.at), EvType(tt.ev[0]), EvCode(tt.ev[1]), EvValue(tt.ev[2]))
+		syn := NewInputEvent(t0.Add(tt.at), EV_SYN, SYN_REPORT, 0)
+		h.Feed(&ev)
+		h.Feed(&syn)
+		if plays != tt.plays {
+			t.Errorf("at %v: %d feedbacks, want %d", tt.at, plays, tt.plays)
+		}
+	}
+
+	fail = errors.New("no motor")
+	ev := NewInputEvent(t0.Add(time.Second), EV_KEY, BTN_TOUCH, 1)
+	syn := NewInputEvent(t0.Add(time.Second), EV_SYN, SYN_REPORT, 0)
+	h.Feed(&ev)
+	if err := h.Feed(&syn); err != fail {
+		t.Errorf("expected the error of Play, got %v", err)
+	}
+}
+
+func TestPlayFFEffect(t *testing.T) {
+	sink := new(eventRecorder)
+	if err := PlayFFEffect(sink, 3)(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := *sink
+	if len(got) != 2 || got[0].Type != EV_FF || got[0].Code != 3 || got[0].Value != 1 ||
+		got[1].Type != EV_SYN || got[1].Code != SYN_REPORT {
+		t.Errorf("expected effect 3 played once, got %v", got)
+	}
+}
@@ -0,0 +1,118 @@
+//go:build linux
+
+package evdev
+
+// HID usage pages.
+const (
+	HIDPageKeyboard = 0x07
+	HIDPageButton   = 0x09
+	HIDPageConsumer = 0x0c
+)
+
+// HIDUsage is a HID usage page and id. Unlike kernel keycodes, which depend
+// on the keymap loaded for a device, HID usages identify the physical key
+// position reported by USB and Bluetooth keyboards.
+type HIDUsage struct {
+	Page uint16
+	ID   uint16
+}
+
+// HIDUsageFromScancode decodes the MSC_SCAN value that HID devices report
+// before every key event.
+func HIDUsageFromScancode(scan EvValue) HIDUsage {
+	return HIDUsage{Page: uint16(uint32(scan) >> 16), ID: uint16(scan)}
+}
+
+// Scancode returns the MSC_SCAN value of the usage, e.g. to set keycodes
+// with ApplyKeycodeTable.
+func (u HIDUsage) Scancode() uint32 {
+	return uint32(u.Page)<<16 | uint32(u.ID)
+}
+
+// Keycodes of the keyboard usage page, indexed by usage id. This is the
+// default translation of the kernel (hid_keyboard in drivers/hid/hid-input.c).
+var hidKeyboardUsages = [256]EvCode{
+	0, 0, 0, 0, 30, 48, 46, 32, 18, 33, 34, 35, 23, 36, 37, 38,
+	50, 49, 24, 25, 16, 19, 31, 20, 22, 47, 17, 45, 21, 44, 2, 3,
+	4, 5, 6, 7, 8, 9, 10, 11, 28, 1, 14, 15, 57, 12, 13, 26,
+	27, 43, 43, 39, 40, 41, 51, 52, 53, 58, 59, 60, 61, 62, 63, 64,
+	65, 66, 67, 68, 87, 88, 99, 70, 119, 110, 102, 104, 111, 107, 109, 106,
+	105, 108, 103, 69, 98, 55, 74, 78, 96, 79, 80, 81, 75, 76, 77, 71,
+	72, 73, 82, 83, 86, 127, 116, 117, 183, 184, 185, 186, 187, 188, 189, 190,
+	191, 192, 193, 194, 134, 138, 130, 132, 128, 129, 131, 137, 133, 135, 136, 113,
+	115, 114, 0, 0, 0, 121, 0, 89, 93, 124, 92, 94, 95, 0, 0, 0,
+	122, 123, 90, 91, 85, 0, 0, 0, 0, 0, 0, 0, 111, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 179, 180, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 111, 0, 0, 0, 0, 0, 0, 0,
+	29, 42, 56, 125, 97, 54, 100, 126, 164, 166, 165, 163, 161, 115, 114, 113,
+	150, 158, 159, 128, 136, 177, 178, 176, 142, 152, 173, 140, 0, 0, 0, 0,
+}
+
+// HIDUsageToKey returns the keycode the kernel assigns to a keyboard usage
+// by default.
+func HIDUsageToKey(u HIDUsage) (EvCode, bool) {
+	if u.Page != HIDPageKeyboard || int(u.ID) >= len(hidKeyboardUsages) {
+		return 0, false
+	}
+
+	code := hidKeyboardUsages[u.ID]
+	return code, code != 0
+}
+
+// KeyToHIDUsage returns the keyboard usage the kernel translates to code by
+// default. If several usages share the keycode, the lowest one is returned.
+func KeyToHIDUsage(code EvCode) (HIDUsage, bool) {
+	for id, c := range hidKeyboardUsages {
+		if c == code && c != 0 {
+			return HIDUsage{HIDPageKeyboard, uint16(id)}, true
+		}
+	}
+
+	return HIDUsage{}, false
+}
+
+// HIDRemap is a remap table in terms of HID usages, which stays the same
+// whatever keymap is loaded for a device.
+type HIDRemap map[HIDUsage]HIDUsage
+
+// KeyRemap translates the table into a KeyRemap on default keycodes. Usages
+// without a keycode are skipped.
+func (r HIDRemap) KeyRemap() KeyRemap {
+	remap := make(KeyRemap)
+
+	for from, to := range r {
+		fromCode, ok1 := HIDUsageToKey(from)
+		toCode, ok2 := HIDUsageToKey(to)
+		if ok1 && ok2 {
+			remap[fromCode] = toCode
+		}
+	}
+
+	return remap
+}
+
+// HIDUsageTracker pairs the key events of a HID device with the usage
+// reported in the preceding MSC_SCAN event of the same frame.
+type HIDUsageTracker struct {
+	usage HIDUsage
+	valid bool
+}
+
+// Feed passes an event to the tracker. For key events it returns the usage
+// of the key and true, if the device reported one.
+func (t *HIDUsageTracker) Feed(ev *InputEvent) (HIDUsage, bool) {
+	switch {
+	case ev.Type == EV_MSC && ev.Code == MSC_SCAN:
+		t.usage = HIDUsageFromScancode(ev.Value)
+		t.valid = true
+	case ev.Type == EV_KEY && t.valid:
+		t.valid = false
+		return t.usage, true
+	case ev.Type == EV_SYN:
+		t.valid = false
+	}
+
+	return HIDUsage{}, false
+}
@@ -0,0 +1,84 @@
+//go:build linux
+
+package evdev
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHIDUsages(t *testing.T) {
+	tests := []struct {
+		usage HIDUsage
+		code  EvCode
+		ok    bool
+	}{
+		{HIDUsage{HIDPageKeyboard, 0x04}, KEY_A, true},
+		{HIDUsage{HIDPageKeyboard, 0x1e}, KEY_1, true},
+		{HIDUsage{HIDPageKeyboard, 0x28}, KEY_ENTER, true},
+		{HIDUsage{HIDPageKeyboard, 0xe0}, KEY_LEFTCTRL, true},
+		{HIDUsage{HIDPageKeyboard, 0xe7}, KEY_RIGHTMETA, true},
+		{HIDUsage{HIDPageKeyboard, 0x01}, 0, false},
+		{HIDUsage{HIDPageConsumer, 0xe9}, 0, false},
+	}
+	for _, tt := range tests {
+		code, ok := HIDUsageToKey(tt.usage)
+		if code != tt.code || ok != tt.ok {
+			t.Errorf("%+v: got %d, %v, want %d, %v", tt.usage, code, ok, tt.code, tt.ok)
+		}
+		if !tt.ok {
+			continue
+		}
+		if u, ok := KeyToHIDUsage(tt.code); !ok || u != tt.usage {
+			t.Errorf("%d: got usage %+v, want %+v", tt.code, u, tt.usage)
+		}
+		if u := HIDUsageFromScancode(EvValue(tt.usage.Scancode())); u != tt.usage {
+			t.Errorf("scancode of %+v decoded to %+v", tt.usage, u)
+		}
+	}
+
+	// the non-US backslash shares KEY_BACKSLASH with the US one
+	if u, _ := KeyToHIDUsage(KEY_BACKSLASH); u.ID != 0x31 {
+		t.Errorf("expected the lowest usage of KEY_BACKSLASH, got %#x", u.ID)
+	}
+	if _, ok := KeyToHIDUsage(KEY_RESERVED); ok {
+		t.Error("expected no usage for KEY_RESERVED")
+	}
+}
+
+func TestHIDRemap(t *testing.T) {
+	capsLock, ctrl := HIDUsage{HIDPageKeyboard, 0x39}, HIDUsage{HIDPageKeyboard, 0xe0}
+	remap := HIDRemap{
+		capsLock:                ctrl,
+		{HIDPageConsumer, 0xe9}: ctrl, // no keycode
+	}
+
+	if got := remap.KeyRemap(); !reflect.DeepEqual(got, KeyRemap{KEY_CAPSLOCK: KEY_LEFTCTRL}) {
+		t.Errorf("unexpected remap %v", got)
+	}
+}
+
+func TestHIDUsageTracker(t *testing.T) {
+	tr := HIDUsageTracker{}
+	now := time.Unix(1000, 0)
+
+	tests := []struct {
+		ev    InputEvent
+		usage HIDUsage
+		ok    bool
+	}{
+		{NewInputEvent(now, EV_MSC, MSC_SCAN, 0x70004), HIDUsage{}, false},
+		{NewInputEvent(now, EV_KEY, KEY_A, 1), HIDUsage{HIDPageKeyboard, 0x04}, true},
+		{NewInputEvent(now, EV_SYN, SYN_REPORT, 0), HIDUsage{}, false},
+		{NewInputEvent(now, EV_KEY, KEY_A, 2), HIDUsage{}, false}, // repeats have no scan code
+		{NewInputEvent(now, EV_MSC, MSC_SCAN, 0x70005), HIDUsage{}, false},
+		{NewInputEvent(now, EV_SYN, SYN_REPORT, 0), HIDUsage{}, false},
+		{NewInputEvent(now, EV_KEY, KEY_B, 0), HIDUsage{}, false}, // scan code of an earlier frame
+	}
+	for i, tt := range tests {
+		if usage, ok := tr.Feed(&tt.ev); usage != tt.usage || ok != tt.ok {
+			t.Errorf("%d: got %+v, %v, want %+v, %v", i, usage, ok, tt.usage, tt.ok)
+		}
+	}
+}
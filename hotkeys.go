@@ -0,0 +1,209 @@
+package evdev
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Hotkey is a chord or sequence of chords registered with Hotkeys.
+type Hotkey struct {
+	Sequence []Chord
+
+	// Suppress keeps the keys of the hotkey from the events passed on by
+	// Hotkeys.Process, so that running Hotkeys as the filter of a Proxy
+	// hides them from other programs. Set it before feeding events.
+	Suppress bool
+
+	// C receives the time of every completion of a hotkey created with
+	// Subscribe. Completions are dropped while a previous one is unread.
+	C <-chan time.Time
+
+	fn func()
+	c  chan time.Time
+}
+
+// ParseHotkey parses a hotkey: a chord such as "ctrl+alt+t", or a sequence
+// of chords separated by spaces or commas such as "ctrl+k ctrl+c".
+func ParseHotkey(spec string) ([]Chord, error) {
+	fields := strings.FieldsFunc(spec, func(r rune) bool { return r == ' ' || r == ',' })
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("evdev: empty hotkey")
+	}
+
+	sequence := make([]Chord, 0, len(fields))
+	for _, field := range fields {
+		chord, err := ParseChord(field)
+		if err != nil {
+			return nil, err
+		}
+		if chord.Key == 0 {
+			return nil, fmt.Errorf("evdev: hotkey %q needs a key besides modifiers", spec)
+		}
+		sequence = append(sequence, chord)
+	}
+
+	return sequence, nil
+}
+
+// Hotkeys is a registry of global hotkeys. Key events are passed to Feed,
+// or to Process when used as a Filter, and hotkeys completed by them notify
+// their subscribers. The chords of a sequence must follow each other within
+// SequenceTimeout.
+//
+// To suppress hotkeys, grab the keyboard and forward its events to a clone
+// through a Proxy with the Hotkeys as its filter. Keys advancing or
+// completing a suppressed hotkey are dropped along with their repeats and
+// release; those of an abandoned sequence are not replayed. Modifiers always
+// pass.
+type Hotkeys struct {
+	SequenceTimeout time.Duration
+
+	mu        sync.Mutex
+	hotkeys   []*Hotkey
+	progress  map[*Hotkey]int // chords of the sequence completed
+	last      time.Time       // time of the last chord
+	mods      Modifiers
+	swallowed map[EvCode]bool // keys held whose events are dropped
+}
+
+// NewHotkeys creates a registry with a sequence timeout of 1s.
+func NewHotkeys() *Hotkeys {
+	return &Hotkeys{
+		SequenceTimeout: time.Second,
+		progress:        make(map[*Hotkey]int),
+		swallowed:       make(map[EvCode]bool),
+	}
+}
+
+// Register registers a hotkey calling fn on completion. fn is called by the
+// goroutine feeding events and should return quickly.
+func (h *Hotkeys) Register(spec string, fn func()) (*Hotkey, error) {
+	return h.add(spec, &Hotkey{fn: fn})
+}
+
+// Subscribe registers a hotkey notifying its channel C on completion.
+func (h *Hotkeys) Subscribe(spec string) (*Hotkey, error) {
+	c := make(chan time.Time, 1)
+	return h.add(spec, &Hotkey{C: c, c: c})
+}
+
+func (h *Hotkeys) add(spec string, hk *Hotkey) (*Hotkey, error) {
+	sequence, err := ParseHotkey(spec)
+	if err != nil {
+		return nil, err
+	}
+	hk.Sequence = sequence
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.hotkeys = append(h.hotkeys, hk)
+	return hk, nil
+}
+
+// Unregister removes a hotkey.
+func (h *Hotkeys) Unregister(hk *Hotkey) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i, other := range h.hotkeys {
+		if other == hk {
+			h.hotkeys = append(h.hotkeys[:i], h.hotkeys[i+1:]...)
+			break
+		}
+	}
+	delete(h.progress, hk)
+}
+
+// Feed passes an event to the registry, notifying the hotkeys it completes.
+// It reports whether the event belongs to a suppressed hotkey.
+func (h *Hotkeys) Feed(ev *InputEvent) bool {
+	if ev.Type != EV_KEY {
+		return false
+	}
+
+	h.mu.Lock()
+	completed, suppress := h.match(ev)
+	h.mu.Unlock()
+
+	for _, hk := range completed {
+		if hk.fn != nil {
+			hk.fn()
+		}
+		if hk.c != nil {
+			select {
+			case hk.c <- ev.Timestamp():
+			default:
+			}
+		}
+	}
+
+	return suppress
+}
+
+// Process implements Filter, dropping the events of suppressed hotkeys.
+func (h *Hotkeys) Process(ev InputEvent) []InputEvent {
+	if h.Feed(&ev) {
+		return nil
+	}
+
+	return []InputEvent{ev}
+}
+
+// Advance the sequences of the hotkeys with a key event. The hotkeys
+// completed are returned, along with whether the event is suppressed.
+func (h *Hotkeys) match(ev *InputEvent) ([]*Hotkey, bool) {
+	if mod := ModifierOf(ev.Code); mod != 0 {
+		if ev.Value == EvValue(KeyUp) {
+			h.mods &^= mod
+		} else {
+			h.mods |= mod
+		}
+		return nil, false
+	}
+
+	if ev.Value != EvValue(KeyDown) {
+		swallowed := h.swallowed[ev.Code]
+		if ev.Value == EvValue(KeyUp) {
+			delete(h.swallowed, ev.Code)
+		}
+		return nil, swallowed
+	}
+
+	t := ev.Timestamp()
+	if t.Sub(h.last) > h.SequenceTimeout {
+		h.progress = make(map[*Hotkey]int)
+	}
+	h.last = t
+
+	completed := make([]*Hotkey, 0)
+	suppress := false
+	for _, hk := range h.hotkeys {
+		step := h.progress[hk]
+		if !hk.Sequence[step].Matches(h.mods, ev.Code) {
+			// a failed sequence may start over with this chord
+			if step == 0 || !hk.Sequence[0].Matches(h.mods, ev.Code) {
+				delete(h.progress, hk)
+				continue
+			}
+			step = 0
+		}
+
+		step++
+		suppress = suppress || hk.Suppress
+		if step < len(hk.Sequence) {
+			h.progress[hk] = step
+			continue
+		}
+		delete(h.progress, hk)
+		completed = append(completed, hk)
+	}
+
+	if suppress {
+		h.swallowed[ev.Code] = true
+	}
+
+	return completed, suppress
+}
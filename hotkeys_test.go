@@ -0,0 +1,81 @@
+package evdev
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHotkeys(t *testing.T) {
+	h := NewHotkeys()
+
+	terminals := 0
+	if _, err := h.Register("CTRL+ALT+T", func() { terminals++ }); err != nil {
+		t.Fatal(err)
+	}
+	comment, err := h.Subscribe("ctrl+k ctrl+c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	comment.Suppress = true
+
+	now := time.Unix(1000, 0)
+	passed := make([]InputEvent, 0)
+	press := func(d time.Duration, codes ...EvCode) {
+		now = now.Add(d)
+		for _, value := range []int{int(KeyDown), int(KeyUp)} {
+			for _, code := range codes {
+				passed = append(passed, h.Process(NewInputEvent(now, EV_KEY, code, EvValue(value)))...)
+			}
+		}
+	}
+
+	press(0, KEY_LEFTCTRL, KEY_RIGHTALT, KEY_T)
+	if terminals != 1 || len(passed) != 6 {
+		t.Errorf("expected ctrl+alt+t to fire and pass, got %d, %v", terminals, passed)
+	}
+
+	// the sequence is suppressed, modifiers excepted
+	passed = passed[:0]
+	press(time.Second, KEY_LEFTCTRL, KEY_K)
+	press(100*time.Millisecond, KEY_RIGHTCTRL, KEY_C)
+	if len(passed) != 4 {
+		t.Errorf("expected only modifiers to pass, got %v", passed)
+	}
+	select {
+	case <-comment.C:
+	default:
+		t.Error("expected the sequence to notify its channel")
+	}
+
+	// too slow
+	press(time.Second, KEY_LEFTCTRL, KEY_K)
+	press(2*time.Second, KEY_LEFTCTRL, KEY_C)
+	select {
+	case <-comment.C:
+		t.Error("expected the sequence to time out")
+	default:
+	}
+
+	// restarting a sequence
+	press(time.Second, KEY_LEFTCTRL, KEY_K)
+	press(100*time.Millisecond, KEY_LEFTCTRL, KEY_K)
+	press(100*time.Millisecond, KEY_LEFTCTRL, KEY_C)
+	select {
+	case <-comment.C:
+	default:
+		t.Error("expected the restarted sequence to complete")
+	}
+
+	h.Unregister(comment)
+	passed = passed[:0]
+	press(time.Second, KEY_LEFTCTRL, KEY_K)
+	if len(passed) != 4 {
+		t.Errorf("expected all keys to pass after unregistering, got %v", passed)
+	}
+
+	for _, spec := range []string{"", "ctrl", "ctrl+x ctrl+nokey"} {
+		if _, err := ParseHotkey(spec); err == nil {
+			t.Errorf("expected error parsing %q", spec)
+		}
+	}
+}
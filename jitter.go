@@ -0,0 +1,152 @@
+//go:build linux
+
+package evdev
+
+import (
+	"context"
+	"time"
+)
+
+// JitterBuffer delays the records of a netstream by a target delay, so that
+// they are replayed with their original relative timing although their
+// transit times vary. Records are stamped with the time they were sent on
+// the clock of the sender (CaptureRecord.Offset). The offset between the
+// clocks of the peers is estimated from the fastest transit seen: the
+// smallest difference between arrival and sending time. Records are then
+// due Delay after the time they would have arrived with that transit. To
+// follow clocks drifting apart, the estimate only keeps the transits of
+// the last two windows of Window.
+type JitterBuffer struct {
+	Delay  time.Duration
+	Window time.Duration
+
+	base        time.Time // arrival time of sending time 0 at the fastest transit
+	cur, prev   time.Time // fastest base of the current and previous window
+	windowStart time.Time
+
+	queue []*CaptureRecord
+}
+
+// NewJitterBuffer creates a buffer delaying records by delay, estimating the
+// clock offset over windows of 10s.
+func NewJitterBuffer(delay time.Duration) *JitterBuffer {
+	return &JitterBuffer{Delay: delay, Window: 10 * time.Second}
+}
+
+// Push adds a record that arrived at the given time.
+func (b *JitterBuffer) Push(rec *CaptureRecord, arrival time.Time) {
+	base := arrival.Add(-rec.Offset)
+
+	if b.windowStart.IsZero() || arrival.Sub(b.windowStart) >= b.Window {
+		b.prev, b.cur = b.cur, base
+		b.windowStart = arrival
+	} else if base.Before(b.cur) {
+		b.cur = base
+	}
+
+	b.base = b.cur
+	if !b.prev.IsZero() && b.prev.Before(b.base) {
+		b.base = b.prev
+	}
+
+	b.queue = append(b.queue, rec)
+}
+
+// Offset returns the estimated time of arrival of records sent at time 0 of
+// the sender, without delay.
+func (b *JitterBuffer) Offset() time.Time {
+	return b.base
+}
+
+// Due returns the time the next record is due, false if there is none.
+func (b *JitterBuffer) Due() (time.Time, bool) {
+	if len(b.queue) == 0 {
+		return time.Time{}, false
+	}
+
+	return b.due(b.queue[0]), true
+}
+
+func (b *JitterBuffer) due(rec *CaptureRecord) time.Time {
+	return b.base.Add(rec.Offset + b.Delay)
+}
+
+// Pop removes and returns the records due at now, in the order they were
+// sent. Records arriving too late for their time are due at once.
+func (b *JitterBuffer) Pop(now time.Time) []*CaptureRecord {
+	n := 0
+	for n < len(b.queue) && !b.due(b.queue[n]).After(now) {
+		n++
+	}
+
+	due := b.queue[:n:n]
+	b.queue = b.queue[n:]
+	return due
+}
+
+// Len returns the number of records waiting.
+func (b *JitterBuffer) Len() int {
+	return len(b.queue)
+}
+
+// Replay reads the stream until ctx is done or reading or fn fails, passing
+// the records to fn when they are due in the jitter buffer b. A read still
+// pending on return ends when the client is closed.
+func (c *NetstreamClient) Replay(ctx context.Context, b *JitterBuffer, fn func(rec *CaptureRecord) error) error {
+	type arrival struct {
+		rec *CaptureRecord
+		at  time.Time
+		err error
+	}
+	arrivals := make(chan arrival, 64)
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		for {
+			rec, err := c.Next()
+			select {
+			case arrivals <- arrival{rec, time.Now(), err}:
+			case <-done:
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		for _, rec := range b.Pop(time.Now()) {
+			if err := fn(rec); err != nil {
+				return err
+			}
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		if due, ok := b.Due(); ok {
+			timer.Reset(time.Until(due))
+		} else {
+			timer.Reset(time.Hour)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case a := <-arrivals:
+			if a.err != nil {
+				return a.err
+			}
+			b.Push(a.rec, a.at)
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,69 @@
+//go:build linux
+
+package evdev
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJitterBuffer(t *testing.T) {
+	b := NewJitterBuffer(30 * time.Millisecond)
+	t0 := time.Unix(1000, 0)
+	ms := time.Millisecond
+
+	// sent 10ms apart, the second one held up in transit
+	recs := []*CaptureRecord{{Offset: 0}, {Offset: 10 * ms}, {Offset: 20 * ms}}
+	b.Push(recs[0], t0.Add(5*ms))
+	b.Push(recs[1], t0.Add(30*ms))
+	b.Push(recs[2], t0.Add(31*ms))
+
+	if !b.Offset().Equal(t0.Add(5 * ms)) {
+		t.Errorf("offset estimated at %v, want the fastest transit", b.Offset().Sub(t0))
+	}
+	if due, ok := b.Due(); !ok || !due.Equal(t0.Add(35*ms)) {
+		t.Errorf("first record due at %v", due.Sub(t0))
+	}
+
+	for _, c := range []struct {
+		at   time.Duration
+		want int
+	}{
+		{34 * ms, 0},
+		{35 * ms, 1},
+		{50 * ms, 1},
+		{60 * ms, 1},
+	} {
+		if got := b.Pop(t0.Add(c.at)); len(got) != c.want {
+			t.Errorf("at %v: got %d records, want %d", c.at, len(got), c.want)
+		}
+	}
+	if b.Len() != 0 {
+		t.Errorf("%d records left", b.Len())
+	}
+}
+
+func TestJitterBufferDrift(t *testing.T) {
+	b := NewJitterBuffer(0)
+	b.Window = 100 * time.Millisecond
+	t0 := time.Unix(1000, 0)
+	ms := time.Millisecond
+
+	b.Push(&CaptureRecord{Offset: 0}, t0.Add(5*ms))
+
+	// the clocks drift apart, the fastest transit is now 50ms
+	b.Push(&CaptureRecord{Offset: 150 * ms}, t0.Add(200*ms))
+	if !b.Offset().Equal(t0.Add(5 * ms)) {
+		t.Errorf("offset changed within two windows: %v", b.Offset().Sub(t0))
+	}
+	b.Push(&CaptureRecord{Offset: 350 * ms}, t0.Add(400*ms))
+	if !b.Offset().Equal(t0.Add(50 * ms)) {
+		t.Errorf("offset not following the drift: %v", b.Offset().Sub(t0))
+	}
+
+	// late records are due at once, in order
+	got := b.Pop(t0.Add(400 * ms))
+	if len(got) != 3 || got[0].Offset != 0 || got[2].Offset != 350*ms {
+		t.Errorf("unexpected records %v", got)
+	}
+}
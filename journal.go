@@ -0,0 +1,318 @@
+package evdev
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// JournalEntry is an event recorded in a journal.
+type JournalEntry struct {
+	Time   time.Time `json:"time"`
+	Device string    `json:"device"`
+	Type   EvType    `json:"type"`
+	Code   EvCode    `json:"code"`
+	Value  EvValue   `json:"value"`
+	Name   string    `json:"name"` // name of the code, for reading the journal by eye
+}
+
+// Event returns the journaled event.
+func (e *JournalEntry) Event() InputEvent {
+	return NewInputEvent(e.Time, e.Type, e.Code, e.Value)
+}
+
+// Journal is a persistent audit trail of selected input events, such as
+// power button presses and lid switches. Entries are appended as JSON lines
+// to files in Dir, which are rotated once they exceed MaxSize bytes or
+// MaxAge; only the newest MaxFiles files are kept. It is safe for
+// concurrent use.
+//
+// All times of the journal are event timestamps: entries, the names of the
+// files and their age. Events should thus be timestamped with the wall
+// clock, the default CLOCK_REALTIME, for the journal to be ordered across
+// reboots and queried by the time of day.
+type Journal struct {
+	Dir      string
+	MaxSize  int64         // 0 for no size limit
+	MaxAge   time.Duration // 0 for no age limit
+	MaxFiles int           // 0 to keep all files
+
+	// Select chooses the events to record. If nil, DefaultJournalSelect is used.
+	Select func(ev *InputEvent) bool
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	opened time.Time // timestamp of the first event of the file
+}
+
+const (
+	journalPrefix = "journal-"
+	journalSuffix = ".jsonl"
+	journalLayout = "20060102-150405.000000000"
+)
+
+// DefaultJournalSelect selects switch changes and presses of the power,
+// sleep, suspend and wakeup keys.
+func DefaultJournalSelect(ev *InputEvent) bool {
+	switch ev.Type {
+	case EV_SW:
+		return true
+	case EV_KEY:
+		switch ev.Code {
+		case KEY_POWER, KEY_SLEEP, KEY_SUSPEND, KEY_WAKEUP:
+			return ev.Value == EvValue(KeyDown)
+		}
+	}
+
+	return false
+}
+
+// OpenJournal opens the journal in dir, creating the directory if needed.
+// Files are rotated daily or at 10 MiB, and 30 files are kept.
+func OpenJournal(dir string) (*Journal, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	return &Journal{
+		Dir:      dir,
+		MaxSize:  10 << 20,
+		MaxAge:   24 * time.Hour,
+		MaxFiles: 30,
+	}, nil
+}
+
+// Record appends an event of the named device to the journal if it is
+// selected.
+func (j *Journal) Record(device string, ev *InputEvent) error {
+	sel := j.Select
+	if sel == nil {
+		sel = DefaultJournalSelect
+	}
+	if !sel(ev) {
+		return nil
+	}
+
+	at := ev.Timestamp()
+	entry := JournalEntry{
+		Time:   at,
+		Device: device,
+		Type:   ev.Type,
+		Code:   ev.Code,
+		Value:  ev.Value,
+		Name:   ev.Code.Name(ev.Type),
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err = j.rotate(int64(len(line)), at); err != nil {
+		return err
+	}
+
+	n, err := j.file.Write(line)
+	j.size += int64(n)
+
+	return err
+}
+
+// Start a new file if there is none yet or the current one is full or too
+// old for an entry of next bytes at the given time.
+func (j *Journal) rotate(next int64, at time.Time) error {
+	if j.file != nil {
+		full := j.MaxSize > 0 && j.size+next > j.MaxSize
+		old := j.MaxAge > 0 && at.Sub(j.opened) >= j.MaxAge
+		if !full && !old {
+			return nil
+		}
+
+		if err := j.file.Close(); err != nil {
+			return err
+		}
+		j.file = nil
+	}
+
+	name := filepath.Join(j.Dir, journalPrefix+at.UTC().Format(journalLayout)+journalSuffix)
+
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	j.file, j.size, j.opened = f, 0, at
+
+	if j.MaxFiles > 0 {
+		files, err := journalFiles(j.Dir)
+		if err != nil {
+			return err
+		}
+		for len(files) > j.MaxFiles {
+			os.Remove(files[0])
+			files = files[1:]
+		}
+	}
+
+	return nil
+}
+
+// Close closes the current journal file.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return nil
+	}
+
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
+
+// ReadJournal calls fn for every entry of the journal in dir, oldest first,
+// until fn returns false.
+func ReadJournal(dir string, fn func(entry *JournalEntry) bool) error {
+	files, err := journalFiles(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range files {
+		more, err := readJournalFile(name, fn)
+		if err != nil {
+			return err
+		}
+		if !more {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func readJournalFile(name string, fn func(entry *JournalEntry) bool) (bool, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		entry := JournalEntry{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// skip lines torn by a crash
+			continue
+		}
+		if !fn(&entry) {
+			return false, nil
+		}
+	}
+
+	return true, scanner.Err()
+}
+
+// Get the journal files in dir, oldest first.
+func journalFiles(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, journalPrefix+"*"+journalSuffix))
+	if err != nil {
+		return nil, err
+	}
+
+	// the names sort by the time of their first entry
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// JournalQuery selects journal entries. Zero fields match everything.
+type JournalQuery struct {
+	Since, Until time.Time // time window, inclusive
+	Device       string
+	Types        []EvType
+	Codes        []EvCode
+	Values       []EvValue // e.g. 1 for key presses only
+}
+
+// Matches reports whether an entry is selected by the query.
+func (q *JournalQuery) Matches(e *JournalEntry) bool {
+	switch {
+	case !q.Since.IsZero() && e.Time.Before(q.Since):
+		return false
+	case !q.Until.IsZero() && e.Time.After(q.Until):
+		return false
+	case q.Device != "" && e.Device != q.Device:
+		return false
+	}
+
+	return (len(q.Types) == 0 || containsType(q.Types, e.Type)) &&
+		(len(q.Codes) == 0 || containsCode(q.Codes, e.Code)) &&
+		(len(q.Values) == 0 || containsValue(q.Values, e.Value))
+}
+
+// QueryJournal returns the entries of the journal in dir matching q, oldest
+// first. For example, all power button presses of the last day:
+//
+//	entries, err := evdev.QueryJournal(dir, evdev.JournalQuery{
+//		Since:  time.Now().Add(-24 * time.Hour),
+//		Types:  []evdev.EvType{evdev.EV_KEY},
+//		Codes:  []evdev.EvCode{evdev.KEY_POWER},
+//		Values: []evdev.EvValue{1},
+//	})
+func QueryJournal(dir string, q JournalQuery) ([]JournalEntry, error) {
+	files, err := journalFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]JournalEntry, 0)
+	for _, name := range files {
+		_, err := readJournalFile(name, func(e *JournalEntry) bool {
+			if q.Matches(e) {
+				entries = append(entries, *e)
+			}
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return entries, nil
+}
+
+func containsType(types []EvType, t EvType) bool {
+	for _, x := range types {
+		if x == t {
+			return true
+		}
+	}
+	return false
+}
+
+func containsCode(codes []EvCode, c EvCode) bool {
+	for _, x := range codes {
+		if x == c {
+			return true
+		}
+	}
+	return false
+}
+
+func containsValue(values []EvValue, v EvValue) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
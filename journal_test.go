@@ -0,0 +1,81 @@
+package evdev
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJournal(t *testing.T) {
+	j, err := OpenJournal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	j.MaxSize = 200
+
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		power := NewInputEvent(now.Add(time.Duration(i)*time.Second), EV_KEY, KEY_POWER, 1)
+		other := NewInputEvent(now, EV_KEY, KEY_A, 1)
+		j.Record("kbd", &power)
+		j.Record("kbd", &other)
+	}
+	j.Close()
+
+	files, _ := journalFiles(j.Dir)
+	if len(files) < 2 {
+		t.Errorf("expected rotated files, got %d", len(files))
+	}
+
+	entries := make([]*JournalEntry, 0)
+	ReadJournal(j.Dir, func(e *JournalEntry) bool {
+		entries = append(entries, e)
+		return true
+	})
+
+	if len(entries) != 5 {
+		t.Fatalf("expected 5 entries, got %d", len(entries))
+	}
+	if e := entries[4]; e.Code != KEY_POWER || e.Name != named("KEY_POWER", "KEY_0x074") || e.Device != "kbd" {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+
+	found, err := QueryJournal(j.Dir, JournalQuery{
+		Since: now.Add(2 * time.Second),
+		Codes: []EvCode{KEY_POWER},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 3 {
+		t.Errorf("expected 3 entries in window, got %d", len(found))
+	}
+}
+
+func TestJournalEventTime(t *testing.T) {
+	j, err := OpenJournal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	j.MaxAge = time.Hour
+
+	// files are named and aged by the timestamps of the events
+	t0 := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, d := range []time.Duration{0, 30 * time.Minute, 2 * time.Hour} {
+		ev := NewInputEvent(t0.Add(d), EV_SW, SW_LID, 1)
+		if err := j.Record("lid", &ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	j.Close()
+
+	files, _ := journalFiles(j.Dir)
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		names = append(names, filepath.Base(f))
+	}
+	want := []string{"journal-20200102-030405.000000000.jsonl", "journal-20200102-050405.000000000.jsonl"}
+	if len(names) != len(want) || names[0] != want[0] || names[1] != want[1] {
+		t.Errorf("got files %v, want %v", names, want)
+	}
+}
@@ -0,0 +1,129 @@
+package evdev
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// LEDClassDir is where the kernel lists LED class devices.
+var LEDClassDir = "/sys/class/leds"
+
+// KbdBacklightAction is the action requested by a keyboard backlight key.
+type KbdBacklightAction int
+
+const (
+	KbdBacklightUp KbdBacklightAction = iota
+	KbdBacklightDown
+	KbdBacklightToggle
+)
+
+// Keyboard backlight keys and their actions.
+var kbdBacklightKeys = map[EvCode]KbdBacklightAction{
+	KEY_KBDILLUMUP:     KbdBacklightUp,
+	KEY_KBDILLUMDOWN:   KbdBacklightDown,
+	KEY_KBDILLUMTOGGLE: KbdBacklightToggle,
+}
+
+// FindKbdBacklight returns the sysfs directory of the first keyboard
+// backlight LED, e.g. /sys/class/leds/tpacpi::kbd_backlight.
+func FindKbdBacklight() (string, error) {
+	matches, err := filepath.Glob(filepath.Join(LEDClassDir, "*::kbd_backlight"))
+	if err != nil {
+		return "", err
+	}
+	if len(matches) == 0 {
+		return "", errors.New("evdev: no keyboard backlight found")
+	}
+
+	return matches[0], nil
+}
+
+// KbdBacklight handles the keyboard backlight keys. If LED is set to the
+// sysfs directory of the backlight, the keys adjust its brightness by Step;
+// OnKey, if set, is called for every press with the action and the new
+// brightness (-1 if LED is not set).
+type KbdBacklight struct {
+	LED   string
+	Step  int
+	OnKey func(action KbdBacklightAction, brightness int)
+
+	saved int // brightness before toggling off
+}
+
+// Feed passes a key event to the handler and reports whether it was
+// a keyboard backlight key. Presses and repeats are handled.
+func (b *KbdBacklight) Feed(ev *InputEvent) (bool, error) {
+	action, ok := kbdBacklightKeys[ev.Code]
+	if ev.Type != EV_KEY || !ok {
+		return false, nil
+	}
+	if ev.Value == EvValue(KeyUp) || ev.Value == EvValue(KeyHold) && action == KbdBacklightToggle {
+		return true, nil
+	}
+
+	brightness := -1
+	if b.LED != "" {
+		var err error
+		if brightness, err = b.adjust(action); err != nil {
+			return true, err
+		}
+	}
+
+	if b.OnKey != nil {
+		b.OnKey(action, brightness)
+	}
+
+	return true, nil
+}
+
+func (b *KbdBacklight) adjust(action KbdBacklightAction) (int, error) {
+	current, err := readSysfsInt(filepath.Join(b.LED, "brightness"))
+	if err != nil {
+		return 0, err
+	}
+	max, err := readSysfsInt(filepath.Join(b.LED, "max_brightness"))
+	if err != nil {
+		return 0, err
+	}
+
+	step := b.Step
+	if step <= 0 {
+		step = 1
+	}
+
+	next := current
+	switch action {
+	case KbdBacklightUp:
+		next += step
+	case KbdBacklightDown:
+		next -= step
+	case KbdBacklightToggle:
+		if current > 0 {
+			b.saved, next = current, 0
+		} else if next = b.saved; next == 0 {
+			next = max
+		}
+	}
+
+	if next < 0 {
+		next = 0
+	}
+	if next > max {
+		next = max
+	}
+
+	err = os.WriteFile(filepath.Join(b.LED, "brightness"), []byte(strconv.Itoa(next)), 0644)
+	return next, err
+}
+
+func readSysfsInt(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
@@ -0,0 +1,83 @@
+package evdev
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestKbdBacklight(t *testing.T) {
+	defer func(dir string) { LEDClassDir = dir }(LEDClassDir)
+	LEDClassDir = t.TempDir()
+
+	if _, err := FindKbdBacklight(); err == nil {
+		t.Error("expected no backlight")
+	}
+
+	led := filepath.Join(LEDClassDir, "tpacpi::kbd_backlight")
+	os.MkdirAll(led, 0755)
+	os.WriteFile(filepath.Join(led, "brightness"), []byte("1\n"), 0644)
+	os.WriteFile(filepath.Join(led, "max_brightness"), []byte("2\n"), 0644)
+	if found, err := FindKbdBacklight(); found != led || err != nil {
+		t.Fatalf("got %q, %v", found, err)
+	}
+
+	actions := make([]KbdBacklightAction, 0)
+	b := &KbdBacklight{LED: led, OnKey: func(action KbdBacklightAction, brightness int) {
+		actions = append(actions, action)
+	}}
+	now := time.Unix(1000, 0)
+
+	tests := []struct {
+		code       EvCode
+		value      KeyEventState
+		handled    bool
+		brightness string
+	}{
+		{KEY_KBDILLUMUP, KeyDown, true, "2"},
+		{KEY_KBDILLUMUP, KeyHold, true, "2"}, // at the maximum
+		{KEY_KBDILLUMUP, KeyUp, true, "2"},
+		{KEY_KBDILLUMDOWN, KeyDown, true, "1"},
+		{KEY_KBDILLUMTOGGLE, KeyDown, true, "0"},
+		{KEY_KBDILLUMTOGGLE, KeyHold, true, "0"}, // toggles don't repeat
+		{KEY_KBDILLUMTOGGLE, KeyDown, true, "1"}, // restored
+		{KEY_KBDILLUMDOWN, KeyDown, true, "0"},
+		{KEY_KBDILLUMDOWN, KeyDown, true, "0"},   // at the minimum
+		{KEY_KBDILLUMTOGGLE, KeyDown, true, "1"}, // the brightness toggled off last
+		{KEY_A, KeyDown, false, "1"},
+	}
+	for i, tt := range tests {
+		ev := NewInputEvent(now, EV_KEY, tt.code, EvValue(tt.value))
+		handled, err := b.Feed(&ev)
+		if handled != tt.handled || err != nil {
+			t.Errorf("%d: got %v, %v", i, handled, err)
+		}
+		if data, _ := os.ReadFile(filepath.Join(led, "brightness")); string(data) != tt.brightness {
+			t.Errorf("%d: brightness %q, want %q", i, data, tt.brightness)
+		}
+	}
+	if len(actions) != 8 {
+		t.Errorf("expected 8 actions, got %v", actions)
+	}
+
+	// toggling on without a saved brightness turns the backlight fully on
+	os.WriteFile(filepath.Join(led, "brightness"), []byte("0\n"), 0644)
+	b = &KbdBacklight{LED: led}
+	ev := NewInputEvent(now, EV_KEY, KEY_KBDILLUMTOGGLE, EvValue(KeyDown))
+	b.Feed(&ev)
+	if data, _ := os.ReadFile(filepath.Join(led, "brightness")); string(data) != "2" {
+		t.Errorf("brightness %q, want 2", data)
+	}
+
+	// without LED, only OnKey is called
+	b = &KbdBacklight{OnKey: func(action KbdBacklightAction, brightness int) {
+		if action != KbdBacklightUp || brightness != -1 {
+			t.Errorf("unexpected action %d, brightness %d", action, brightness)
+		}
+	}}
+	ev = NewInputEvent(now, EV_KEY, KEY_KBDILLUMUP, EvValue(KeyDown))
+	if handled, err := b.Feed(&ev); !handled || err != nil {
+		t.Errorf("got %v, %v", handled, err)
+	}
+}
@@ -0,0 +1,108 @@
+package evdev
+
+import "sort"
+
+// KeyboardState tracks the state of a keyboard from its events: the keys
+// held, the modifiers held, left and right apart, and the caps, num and
+// scroll lock toggles. Locks toggle when their key goes down; LED events,
+// where the device reports them, override the toggled state. After
+// SYN_DROPPED the state may be stale until synced with Sync.
+type KeyboardState struct {
+	pressed map[EvCode]bool
+	mods    Modifiers
+
+	capsLock, numLock, scrollLock bool
+}
+
+// NewKeyboardState creates a state with no keys held and all locks off.
+func NewKeyboardState() *KeyboardState {
+	return &KeyboardState{pressed: make(map[EvCode]bool)}
+}
+
+// Feed updates the state with an event. Events other than EV_KEY and
+// EV_LED are ignored.
+func (s *KeyboardState) Feed(ev *InputEvent) {
+	switch ev.Type {
+	case EV_LED:
+		s.setLock(ev.Code, ev.Value != 0)
+	case EV_KEY:
+		s.key(ev.Code, ev.Value)
+	}
+}
+
+func (s *KeyboardState) key(code EvCode, value EvValue) {
+	switch value {
+	case EvValue(KeyDown):
+		if s.pressed[code] {
+			return
+		}
+		s.pressed[code] = true
+		s.mods |= ModifierOf(code)
+
+		switch code {
+		case KEY_CAPSLOCK:
+			s.capsLock = !s.capsLock
+		case KEY_NUMLOCK:
+			s.numLock = !s.numLock
+		case KEY_SCROLLLOCK:
+			s.scrollLock = !s.scrollLock
+		}
+	case EvValue(KeyUp):
+		delete(s.pressed, code)
+		s.mods &^= ModifierOf(code)
+	}
+}
+
+func (s *KeyboardState) setLock(led EvCode, on bool) {
+	switch led {
+	case LED_CAPSL:
+		s.capsLock = on
+	case LED_NUML:
+		s.numLock = on
+	case LED_SCROLLL:
+		s.scrollLock = on
+	}
+}
+
+// IsPressed reports whether a key or button is held.
+func (s *KeyboardState) IsPressed(code EvCode) bool {
+	return s.pressed[code]
+}
+
+// Pressed returns the keys and buttons held, in ascending order.
+func (s *KeyboardState) Pressed() []EvCode {
+	keys := make([]EvCode, 0, len(s.pressed))
+	for code := range s.pressed {
+		keys = append(keys, code)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	return keys
+}
+
+// Modifiers returns the modifiers held. Use Sideless to compare them
+// regardless of side.
+func (s *KeyboardState) Modifiers() Modifiers {
+	return s.mods
+}
+
+// CapsLock reports whether caps lock is on.
+func (s *KeyboardState) CapsLock() bool {
+	return s.capsLock
+}
+
+// NumLock reports whether num lock is on.
+func (s *KeyboardState) NumLock() bool {
+	return s.numLock
+}
+
+// ScrollLock reports whether scroll lock is on.
+func (s *KeyboardState) ScrollLock() bool {
+	return s.scrollLock
+}
+
+// Reset releases all keys, keeping the locks.
+func (s *KeyboardState) Reset() {
+	s.pressed = make(map[EvCode]bool)
+	s.mods = 0
+}
@@ -0,0 +1,58 @@
+package evdev
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestKeyboardState(t *testing.T) {
+	s := NewKeyboardState()
+	now := time.Unix(1000, 0)
+	feed := func(evType EvType, code EvCode, value int) {
+		ev := NewInputEvent(now, evType, code, EvValue(value))
+		s.Feed(&ev)
+	}
+
+	feed(EV_KEY, KEY_RIGHTCTRL, int(KeyDown))
+	feed(EV_KEY, KEY_LEFTSHIFT, int(KeyDown))
+	feed(EV_KEY, KEY_A, int(KeyDown))
+	feed(EV_KEY, KEY_A, int(KeyHold))
+
+	if mods := s.Modifiers(); mods != ModRightCtrl|ModLeftShift {
+		t.Errorf("unexpected modifiers %b", mods)
+	}
+	if !s.IsPressed(KEY_A) || s.IsPressed(KEY_B) {
+		t.Error("expected only KEY_A pressed")
+	}
+	if keys := s.Pressed(); !reflect.DeepEqual(keys, []EvCode{KEY_A, KEY_LEFTSHIFT, KEY_RIGHTCTRL}) {
+		t.Errorf("unexpected pressed keys %v", keys)
+	}
+
+	feed(EV_KEY, KEY_RIGHTCTRL, int(KeyUp))
+	if mods := s.Modifiers(); mods != ModLeftShift {
+		t.Errorf("unexpected modifiers %b after release", mods)
+	}
+
+	// locks toggle on press, LEDs override
+	feed(EV_KEY, KEY_CAPSLOCK, int(KeyDown))
+	feed(EV_KEY, KEY_CAPSLOCK, int(KeyUp))
+	feed(EV_KEY, KEY_NUMLOCK, int(KeyDown))
+	feed(EV_KEY, KEY_NUMLOCK, int(KeyUp))
+	feed(EV_KEY, KEY_NUMLOCK, int(KeyDown))
+	feed(EV_KEY, KEY_NUMLOCK, int(KeyUp))
+	if !s.CapsLock() || s.NumLock() || s.ScrollLock() {
+		t.Error("expected caps lock on only")
+	}
+
+	feed(EV_LED, LED_CAPSL, 0)
+	feed(EV_LED, LED_SCROLLL, 1)
+	if s.CapsLock() || !s.ScrollLock() {
+		t.Error("expected LEDs to set the locks")
+	}
+
+	s.Reset()
+	if s.Modifiers() != 0 || len(s.Pressed()) != 0 || !s.ScrollLock() {
+		t.Error("expected reset to release keys and keep locks")
+	}
+}
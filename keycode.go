@@ -0,0 +1,148 @@
+//go:build linux
+
+package evdev
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// KeymapEntry is a single entry of a device's scancode to keycode table.
+type KeymapEntry struct {
+	Index    uint16 // position of the entry in the table
+	Scancode []byte // scancode in native byte order (up to 32 bytes)
+	Keycode  uint32 // KEY_* or BTN_* code the scancode is mapped to
+}
+
+// Corresponds to the input_keymap_entry struct.
+type keymapEntry struct {
+	flags    uint8
+	len      uint8
+	index    uint16
+	keycode  uint32
+	scancode [32]byte
+}
+
+// GetKeycode returns the keycode a scancode is mapped to, using the legacy
+// EVIOCGKEYCODE with 32-bit scancodes. For HID devices, scancodes are HID
+// usages (see HIDUsage.Scancode).
+func (dev *InputDevice) GetKeycode(scancode uint32) (uint32, error) {
+	codes := [2]uint32{scancode, 0}
+
+	if err := ioctl(dev.File.Fd(), uintptr(EVIOCGKEYCODE), unsafe.Pointer(&codes)); err != 0 {
+		return 0, err
+	}
+
+	return codes[1], nil
+}
+
+// SetKeycode maps a scancode to a keycode, e.g. to rebind the extra buttons
+// of a mouse in the kernel, using the legacy EVIOCSKEYCODE.
+func (dev *InputDevice) SetKeycode(scancode, keycode uint32) error {
+	codes := [2]uint32{scancode, keycode}
+
+	if err := ioctl(dev.File.Fd(), uintptr(EVIOCSKEYCODE), unsafe.Pointer(&codes)); err != 0 {
+		return err
+	}
+
+	return nil
+}
+
+// GetKeymapEntry looks up the entry of a scancode of arbitrary length (up to
+// 32 bytes, native byte order) with EVIOCGKEYCODE_V2.
+func (dev *InputDevice) GetKeymapEntry(scancode []byte) (KeymapEntry, error) {
+	entry, err := newKeymapEntry(scancode, 0)
+	if err != nil {
+		return KeymapEntry{}, err
+	}
+
+	return dev.getKeymapEntry(&entry)
+}
+
+// GetKeymapEntryByIndex returns the entry at a position of the keymap with
+// EVIOCGKEYCODE_V2. Indexes past the end of the keymap fail with EINVAL.
+func (dev *InputDevice) GetKeymapEntryByIndex(index uint16) (KeymapEntry, error) {
+	entry := keymapEntryAt(index)
+	return dev.getKeymapEntry(&entry)
+}
+
+// Encode the lookup of the entry at a position of the keymap.
+func keymapEntryAt(index uint16) keymapEntry {
+	return keymapEntry{flags: INPUT_KEYMAP_BY_INDEX, index: index}
+}
+
+// Encode the lookup of a scancode, or its mapping to a keycode.
+func newKeymapEntry(scancode []byte, keycode uint32) (keymapEntry, error) {
+	if len(scancode) == 0 || len(scancode) > 32 {
+		return keymapEntry{}, syscall.EINVAL
+	}
+
+	entry := keymapEntry{len: uint8(len(scancode)), keycode: keycode}
+	copy(entry.scancode[:], scancode)
+
+	return entry, nil
+}
+
+// Decode an entry filled in by the kernel.
+func (entry *keymapEntry) decode() KeymapEntry {
+	scancode := make([]byte, entry.len)
+	copy(scancode, entry.scancode[:])
+
+	return KeymapEntry{Index: entry.index, Scancode: scancode, Keycode: entry.keycode}
+}
+
+func (dev *InputDevice) getKeymapEntry(entry *keymapEntry) (KeymapEntry, error) {
+	if err := ioctl(dev.File.Fd(), uintptr(EVIOCGKEYCODE_V2), unsafe.Pointer(entry)); err != 0 {
+		return KeymapEntry{}, err
+	}
+
+	return entry.decode(), nil
+}
+
+// SetKeymapEntry maps the scancode of an entry to its keycode with
+// EVIOCSKEYCODE_V2. The index of the entry is ignored.
+func (dev *InputDevice) SetKeymapEntry(e KeymapEntry) error {
+	entry, err := newKeymapEntry(e.Scancode, e.Keycode)
+	if err != nil {
+		return err
+	}
+
+	if err := ioctl(dev.File.Fd(), uintptr(EVIOCSKEYCODE_V2), unsafe.Pointer(&entry)); err != 0 {
+		return err
+	}
+
+	return nil
+}
+
+// DumpKeycodeTable exports the full scancode to keycode table of the device
+// by walking it index by index with EVIOCGKEYCODE_V2.
+func (dev *InputDevice) DumpKeycodeTable() ([]KeymapEntry, error) {
+	table := make([]KeymapEntry, 0)
+
+	for index := 0; index <= 0xffff; index++ {
+		entry, err := dev.GetKeymapEntryByIndex(uint16(index))
+		if err == syscall.EINVAL {
+			// walked past the end of the table
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		table = append(table, entry)
+	}
+
+	return table, nil
+}
+
+// ApplyKeycodeTable imports a table as returned by DumpKeycodeTable. Entries
+// are looked up by scancode, their index is ignored.
+func (dev *InputDevice) ApplyKeycodeTable(table []KeymapEntry) error {
+	for _, e := range table {
+		if err := dev.SetKeymapEntry(e); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
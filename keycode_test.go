@@ -0,0 +1,58 @@
+//go:build linux
+
+package evdev
+
+import (
+	"reflect"
+	"syscall"
+	"testing"
+	"unsafe"
+)
+
+func TestKeymapEntryEncoding(t *testing.T) {
+	// layout of struct input_keymap_entry
+	var entry keymapEntry
+	if size := unsafe.Sizeof(entry); size != 40 {
+		t.Errorf("size %d, want 40", size)
+	}
+	if off := unsafe.Offsetof(entry.keycode); off != 4 {
+		t.Errorf("keycode at %d, want 4", off)
+	}
+	if off := unsafe.Offsetof(entry.scancode); off != 8 {
+		t.Errorf("scancode at %d, want 8", off)
+	}
+
+	tests := []struct {
+		name     string
+		scancode []byte
+		err      error
+	}{
+		{"empty", []byte{}, syscall.EINVAL},
+		{"hid usage", []byte{0x04, 0x00, 0x07, 0x00}, nil},
+		{"longest", make([]byte, 32), nil},
+		{"too long", make([]byte, 33), syscall.EINVAL},
+	}
+	for _, tt := range tests {
+		entry, err := newKeymapEntry(tt.scancode, KEY_A)
+		if err != tt.err {
+			t.Errorf("%s: got error %v, want %v", tt.name, err, tt.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if entry.flags != 0 || int(entry.len) != len(tt.scancode) || entry.keycode != KEY_A {
+			t.Errorf("%s: unexpected entry %+v", tt.name, entry)
+		}
+		want := KeymapEntry{Scancode: tt.scancode, Keycode: KEY_A}
+		if got := entry.decode(); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: decoded %+v, want %+v", tt.name, got, want)
+		}
+	}
+
+	// lookups by index carry the flag and no scancode
+	entry = keymapEntryAt(7)
+	if entry.flags != INPUT_KEYMAP_BY_INDEX || entry.index != 7 || entry.len != 0 {
+		t.Errorf("unexpected index lookup %+v", entry)
+	}
+}
@@ -0,0 +1,196 @@
+package evdev
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// ErrCommandLimit is reported when a binding is not run because the limit
+// of concurrently running commands is reached.
+var ErrCommandLimit = errors.New("evdev: too many commands running")
+
+// KeyCommandEnv describes the key press that triggered a binding. Commands
+// receive its fields as the EVDEV_DEVICE, EVDEV_KEY and EVDEV_CHORD
+// environment variables, and can refer to them as template actions, e.g.
+// "notify-send {{.Chord}}". The actions expand to quoted references to the
+// variables, such as "$EVDEV_CHORD", never to the values themselves: device
+// names are chosen by the hardware and must not be parsed by the shell.
+type KeyCommandEnv struct {
+	Device string // name of the device, as passed to Feed
+	Key    string // name of the pressed key, e.g. "t"
+	Chord  string // canonical chord, e.g. "ctrl+alt+t"
+}
+
+// KeyBinding binds a chord to a shell command or a Go callback.
+type KeyBinding struct {
+	Chord    Chord
+	Command  string                    // run with sh -c, after template expansion
+	Func     func(KeyCommandEnv) error // run instead of Command if set
+	Debounce time.Duration             // presses within this time of the last run are ignored
+
+	command *template.Template
+	last    time.Time
+}
+
+// KeyCommander runs commands bound to chords, the core of a hotkey daemon.
+// Bindings run in their own goroutine, so that slow commands don't hold up
+// event processing.
+type KeyCommander struct {
+	// MaxConcurrent limits the number of bindings running at once, 0 for
+	// no limit. Presses beyond the limit are dropped with ErrCommandLimit.
+	MaxConcurrent int
+
+	// OnError is called with the errors of bindings, may be nil.
+	OnError func(b *KeyBinding, err error)
+
+	mu       sync.Mutex
+	bindings []*KeyBinding
+	mods     Modifiers
+	running  int
+	wg       sync.WaitGroup
+}
+
+// NewKeyCommander creates a commander running at most maxConcurrent
+// bindings at once.
+func NewKeyCommander(maxConcurrent int) *KeyCommander {
+	return &KeyCommander{MaxConcurrent: maxConcurrent}
+}
+
+// Bind binds a chord such as "ctrl+alt+t" to a shell command.
+func (k *KeyCommander) Bind(chord, command string) (*KeyBinding, error) {
+	c, err := ParseChord(chord)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := template.New(chord).Parse(command)
+	if err != nil {
+		return nil, err
+	}
+
+	return k.add(&KeyBinding{Chord: c, Command: command, command: tmpl}), nil
+}
+
+// BindFunc binds a chord to a Go callback.
+func (k *KeyCommander) BindFunc(chord string, fn func(KeyCommandEnv) error) (*KeyBinding, error) {
+	c, err := ParseChord(chord)
+	if err != nil {
+		return nil, err
+	}
+
+	return k.add(&KeyBinding{Chord: c, Func: fn}), nil
+}
+
+func (k *KeyCommander) add(b *KeyBinding) *KeyBinding {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.bindings = append(k.bindings, b)
+	return b
+}
+
+// Feed passes a key event of the named device, running the bindings whose
+// chord it completes.
+func (k *KeyCommander) Feed(device string, ev *InputEvent) {
+	if ev.Type != EV_KEY || ev.Value == EvValue(KeyHold) {
+		return
+	}
+
+	k.mu.Lock()
+	limited := k.match(device, ev.Code, ev.Value)
+	k.mu.Unlock()
+
+	for _, b := range limited {
+		k.report(b, ErrCommandLimit)
+	}
+}
+
+// Track modifiers and start the bindings matched by a key event. The
+// bindings dropped for the concurrency limit are returned.
+func (k *KeyCommander) match(device string, code EvCode, value EvValue) []*KeyBinding {
+	if mod := ModifierOf(code); mod != 0 {
+		if value == EvValue(KeyDown) {
+			k.mods |= mod
+		} else {
+			k.mods &^= mod
+		}
+		return nil
+	}
+	if value != EvValue(KeyDown) {
+		return nil
+	}
+
+	limited := make([]*KeyBinding, 0)
+	now := time.Now()
+	for _, b := range k.bindings {
+		if !b.Chord.Matches(k.mods, code) || now.Sub(b.last) < b.Debounce {
+			continue
+		}
+		b.last = now
+
+		if k.MaxConcurrent > 0 && k.running >= k.MaxConcurrent {
+			limited = append(limited, b)
+			continue
+		}
+		k.running++
+		k.wg.Add(1)
+
+		env := KeyCommandEnv{Device: device, Key: keyName(code), Chord: b.Chord.String()}
+		go k.run(b, env)
+	}
+
+	return limited
+}
+
+func (k *KeyCommander) run(b *KeyBinding, env KeyCommandEnv) {
+	defer k.wg.Done()
+
+	var err error
+	if b.Func != nil {
+		err = b.Func(env)
+	} else {
+		err = runKeyCommand(b, env)
+	}
+
+	k.mu.Lock()
+	k.running--
+	k.mu.Unlock()
+
+	if err != nil {
+		k.report(b, err)
+	}
+}
+
+func (k *KeyCommander) report(b *KeyBinding, err error) {
+	if k.OnError != nil {
+		k.OnError(b, err)
+	}
+}
+
+func runKeyCommand(b *KeyBinding, env KeyCommandEnv) error {
+	// the values only reach the shell through the environment
+	refs := KeyCommandEnv{Device: `"$EVDEV_DEVICE"`, Key: `"$EVDEV_KEY"`, Chord: `"$EVDEV_CHORD"`}
+	command := new(strings.Builder)
+	if err := b.command.Execute(command, refs); err != nil {
+		return err
+	}
+
+	cmd := exec.Command("sh", "-c", command.String())
+	cmd.Env = append(os.Environ(),
+		"EVDEV_DEVICE="+env.Device,
+		"EVDEV_KEY="+env.Key,
+		"EVDEV_CHORD="+env.Chord,
+	)
+
+	return cmd.Run()
+}
+
+// Wait waits for all running bindings to finish.
+func (k *KeyCommander) Wait() {
+	k.wg.Wait()
+}
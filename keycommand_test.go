@@ -0,0 +1,102 @@
+package evdev
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestKeyCommander(t *testing.T) {
+	k := NewKeyCommander(1)
+
+	var mu sync.Mutex
+	runs := make([]KeyCommandEnv, 0)
+	errs := make([]error, 0)
+	release := make(chan struct{})
+
+	b, err := k.BindFunc("ctrl+alt+t", func(env KeyCommandEnv) error {
+		<-release
+		mu.Lock()
+		runs = append(runs, env)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := k.BindFunc("shift+q", func(KeyCommandEnv) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	k.OnError = func(_ *KeyBinding, err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	}
+
+	now := time.Now()
+	press := func(codes ...EvCode) {
+		for _, code := range codes {
+			ev := NewInputEvent(now, EV_KEY, code, 1)
+			k.Feed("kbd", &ev)
+		}
+		for i := len(codes) - 1; i >= 0; i-- {
+			ev := NewInputEvent(now, EV_KEY, codes[i], 0)
+			k.Feed("kbd", &ev)
+		}
+	}
+
+	press(KEY_LEFTCTRL, KEY_RIGHTALT, KEY_T)
+	press(KEY_LEFTSHIFT, KEY_Q) // dropped, the first binding still runs
+	press(KEY_T)                // no modifiers, no match
+
+	close(release)
+	k.Wait()
+
+	if len(runs) != 1 || runs[0] != (KeyCommandEnv{Device: "kbd", Key: named("t", "0x014"), Chord: named("ctrl+alt+t", "ctrl+alt+0x014")}) {
+		t.Errorf("unexpected runs %+v", runs)
+	}
+	if len(errs) != 1 || errs[0] != ErrCommandLimit {
+		t.Errorf("expected one limit error, got %v", errs)
+	}
+
+	// debounced presses are ignored
+	b.Debounce = time.Hour
+	press(KEY_LEFTCTRL, KEY_LEFTALT, KEY_T)
+	k.Wait()
+	if len(runs) != 1 {
+		t.Errorf("expected debounced press to be ignored, got %d runs", len(runs))
+	}
+}
+
+func TestKeyCommandQuoting(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+
+	k := NewKeyCommander(0)
+	if _, err := k.Bind("ctrl+x", "cd "+dir+" && echo {{.Device}} {{.Chord}} > "+out); err != nil {
+		t.Fatal(err)
+	}
+	k.OnError = func(_ *KeyBinding, err error) { t.Error(err) }
+
+	now := time.Now()
+	for _, ev := range []InputEvent{
+		NewInputEvent(now, EV_KEY, KEY_LEFTCTRL, 1),
+		NewInputEvent(now, EV_KEY, KEY_X, 1),
+	} {
+		k.Feed("x; touch pwned", &ev)
+	}
+	k.Wait()
+
+	if _, err := os.Stat(filepath.Join(dir, "pwned")); err == nil {
+		t.Error("device name was run by the shell")
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != named("x; touch pwned ctrl+x\n", "x; touch pwned ctrl+0x02d\n") {
+		t.Errorf("unexpected output %q", data)
+	}
+}
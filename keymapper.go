@@ -0,0 +1,202 @@
+//go:build linux
+
+package evdev
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ModAltGr is the modifier selecting the third level of a layout, the
+// right alt key.
+const ModAltGr = ModRightAlt
+
+// Keyboard layouts can be loaded from a table with a line per key: the key,
+// followed by the characters it types alone, with shift, with AltGr and
+// with shift and AltGr. Trailing levels may be omitted, "-" skips a level,
+// # starts a comment:
+//
+//	q      q  Q  @
+//	e      e  E  €
+//	2      2  "  ²
+//	grave  ^  °  -  ′
+//
+// Keys are named as in chords (see ParseChord). Characters are written as
+// themselves, as U+XXXX, or as one of space, tab, enter and hash.
+
+// Character names of layout tables.
+var layoutCharNames = map[string]rune{
+	"space": ' ',
+	"tab":   '\t',
+	"enter": '\n',
+	"hash":  '#',
+}
+
+// Modifiers of the levels of layout tables.
+var layoutLevels = []Modifiers{0, ModShift, ModAltGr, ModShift | ModAltGr}
+
+// ParseKeyboardLayout parses a layout table. A character typed by several
+// keys is typed with the first of them.
+func ParseKeyboardLayout(src string) (KeyboardLayout, error) {
+	layout := make(KeyboardLayout)
+
+	for i, line := range strings.Split(src, "\n") {
+		if c := strings.IndexByte(line, '#'); c >= 0 {
+			line = line[:c]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) > 1+len(layoutLevels) {
+			return nil, fmt.Errorf("evdev: layout line %d: more than %d levels", i+1, len(layoutLevels))
+		}
+
+		code, ok := parseKeyName(fields[0])
+		if !ok {
+			return nil, fmt.Errorf("evdev: layout line %d: unknown key %q", i+1, fields[0])
+		}
+
+		for level, field := range fields[1:] {
+			if field == "-" {
+				continue
+			}
+			r, err := parseLayoutChar(field)
+			if err != nil {
+				return nil, fmt.Errorf("evdev: layout line %d: %v", i+1, err)
+			}
+			if _, ok := layout[r]; !ok {
+				layout[r] = Chord{Modifiers: layoutLevels[level], Key: code}
+			}
+		}
+	}
+
+	return layout, nil
+}
+
+// LoadKeyboardLayout reads a layout table from a file.
+func LoadKeyboardLayout(path string) (KeyboardLayout, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseKeyboardLayout(string(data))
+}
+
+func parseLayoutChar(s string) (rune, error) {
+	if r, ok := layoutCharNames[strings.ToLower(s)]; ok {
+		return r, nil
+	}
+	if strings.HasPrefix(s, "U+") {
+		n, err := strconv.ParseUint(s[2:], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(n)) {
+			return 0, fmt.Errorf("invalid character %q", s)
+		}
+		return rune(n), nil
+	}
+
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError || size != len(s) {
+		return 0, fmt.Errorf("invalid character %q", s)
+	}
+
+	return r, nil
+}
+
+// Characters of the keypad, the digits and dot only with num lock on.
+var keypadChars = map[EvCode]rune{
+	KEY_KPSLASH: '/', KEY_KPASTERISK: '*', KEY_KPMINUS: '-', KEY_KPPLUS: '+',
+	KEY_KPENTER: '\n', KEY_KPEQUAL: '=',
+}
+
+var keypadNumChars = map[EvCode]rune{
+	KEY_KP0: '0', KEY_KP1: '1', KEY_KP2: '2', KEY_KP3: '3', KEY_KP4: '4',
+	KEY_KP5: '5', KEY_KP6: '6', KEY_KP7: '7', KEY_KP8: '8', KEY_KP9: '9',
+	KEY_KPDOT: '.',
+}
+
+// KeyMapper translates key presses into the characters they type with a
+// layout, the reverse of typing text with a KeyboardLayout, e.g. to
+// reconstruct the codes read by a barcode scanner that presents itself as a
+// keyboard. Caps lock shifts letters, num lock enables the keypad digits,
+// and keys pressed with ctrl, left alt or meta type nothing.
+type KeyMapper struct {
+	State *KeyboardState // state used by Feed
+
+	chars map[Chord]rune
+}
+
+// NewKeyMapper creates a mapper for a layout, e.g. USKeyboardLayout() or a
+// layout loaded with LoadKeyboardLayout.
+func NewKeyMapper(layout KeyboardLayout) *KeyMapper {
+	m := &KeyMapper{State: NewKeyboardState(), chars: make(map[Chord]rune)}
+
+	// the smallest character wins if a chord types several
+	runes := make([]rune, 0, len(layout))
+	for r := range layout {
+		runes = append(runes, r)
+	}
+	sort.Slice(runes, func(i, j int) bool { return runes[i] > runes[j] })
+
+	for _, r := range runes {
+		chord := layout[r]
+		chord.Modifiers = layoutModifiers(chord.Modifiers)
+		m.chars[chord] = r
+	}
+
+	return m
+}
+
+// Canonical modifiers of layout chords: shift of either side and AltGr.
+func layoutModifiers(mods Modifiers) Modifiers {
+	canonical := mods & ModAltGr
+	if mods&ModShift != 0 {
+		canonical |= ModShift
+	}
+
+	return canonical
+}
+
+// Rune returns the character typed by pressing a key with modifiers held
+// and the given lock states, and false if it types none.
+func (m *KeyMapper) Rune(code EvCode, mods Modifiers, capsLock, numLock bool) (rune, bool) {
+	if mods&(ModCtrl|ModLeftAlt|ModMeta) != 0 {
+		return 0, false
+	}
+
+	if r, ok := keypadChars[code]; ok {
+		return r, true
+	}
+	if r, ok := keypadNumChars[code]; ok && numLock {
+		return r, true
+	}
+
+	mods = layoutModifiers(mods)
+	if capsLock {
+		// caps lock only affects keys typing letters with case
+		if r, ok := m.chars[Chord{mods &^ ModShift, code}]; ok && unicode.IsLetter(r) && unicode.ToUpper(r) != unicode.ToLower(r) {
+			mods ^= ModShift
+		}
+	}
+
+	r, ok := m.chars[Chord{mods, code}]
+	return r, ok
+}
+
+// Feed passes an event to the mapper, tracking the keyboard state in State.
+// It returns the character typed by a key press or autorepeat, if any.
+func (m *KeyMapper) Feed(ev *InputEvent) (rune, bool) {
+	m.State.Feed(ev)
+	if ev.Type != EV_KEY || ev.Value == EvValue(KeyUp) {
+		return 0, false
+	}
+
+	s := m.State
+	return m.Rune(ev.Code, s.Modifiers(), s.CapsLock(), s.NumLock())
+}
@@ -0,0 +1,98 @@
+//go:build linux
+
+package evdev
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKeyMapper(t *testing.T) {
+	m := NewKeyMapper(USKeyboardLayout())
+
+	tests := []struct {
+		code            EvCode
+		mods            Modifiers
+		capsLock, numOn bool
+		want            rune
+		ok              bool
+	}{
+		{KEY_A, 0, false, false, 'a', true},
+		{KEY_A, ModRightShift, false, false, 'A', true},
+		{KEY_A, 0, true, false, 'A', true},
+		{KEY_A, ModLeftShift, true, false, 'a', true},
+		{KEY_1, ModLeftShift, true, false, '!', true},
+		{KEY_1, 0, true, false, '1', true},
+		{KEY_A, ModLeftCtrl, false, false, 0, false},
+		{KEY_KP5, 0, false, false, 0, false},
+		{KEY_KP5, 0, false, true, '5', true},
+		{KEY_KPENTER, 0, false, false, '\n', true},
+		{KEY_F1, 0, false, false, 0, false},
+	}
+	for _, tt := range tests {
+		r, ok := m.Rune(tt.code, tt.mods, tt.capsLock, tt.numOn)
+		if r != tt.want || ok != tt.ok {
+			t.Errorf("%s with %b: got %q %v, want %q %v", keyName(tt.code), tt.mods, r, ok, tt.want, tt.ok)
+		}
+	}
+
+	// a scanner typing "Ab1" followed by enter
+	now := time.Unix(1000, 0)
+	got := ""
+	for _, step := range []struct {
+		code  EvCode
+		value int
+	}{
+		{KEY_LEFTSHIFT, 1}, {KEY_A, 1}, {KEY_A, 0}, {KEY_LEFTSHIFT, 0},
+		{KEY_B, 1}, {KEY_B, 0}, {KEY_1, 1}, {KEY_1, 0}, {KEY_ENTER, 1}, {KEY_ENTER, 0},
+	} {
+		ev := NewInputEvent(now, EV_KEY, step.code, EvValue(step.value))
+		if r, ok := m.Feed(&ev); ok {
+			got += string(r)
+		}
+	}
+	if got != "Ab1\n" {
+		t.Errorf("got %q, want %q", got, "Ab1\n")
+	}
+}
+
+func TestParseKeyboardLayout(t *testing.T) {
+	layout, err := ParseKeyboardLayout(`
+# German, in part
+q      q  Q  @
+e      e  E  U+20AC
+2      2  "  ²
+grave  ^  °  -  ′   # dead keys in practice
+space  space
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[rune]Chord{
+		'@': {ModAltGr, KEY_Q},
+		'€': {ModAltGr, KEY_E},
+		'"': {ModShift, KEY_2},
+		'′': {ModShift | ModAltGr, KEY_GRAVE},
+		' ': {0, KEY_SPACE},
+	}
+	for r, chord := range want {
+		if layout[r] != chord {
+			t.Errorf("%q: got %v, want %v", r, layout[r], chord)
+		}
+	}
+
+	m := NewKeyMapper(layout)
+	if r, ok := m.Rune(KEY_E, ModRightAlt, false, false); r != '€' || !ok {
+		t.Errorf("expected € with AltGr, got %q", r)
+	}
+	if r, ok := m.Rune(KEY_E, ModLeftAlt, false, false); ok {
+		t.Errorf("expected nothing with left alt, got %q", r)
+	}
+
+	for _, src := range []string{"nokey a", "a a A b B c", "a ab"} {
+		if _, err := ParseKeyboardLayout(src); err == nil {
+			t.Errorf("expected error parsing %q", src)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+//go:build linux
+
+package evdev
+
+import "unsafe"
+
+// KeyState returns the set of keys and buttons currently held down, so that
+// the key state is known without waiting for the next key event.
+func (dev *InputDevice) KeyState() (Bitset, error) {
+	return dev.stateBits(uintptr(EVIOCGKEY))
+}
+
+// LEDState returns the set of LEDs currently lit, e.g. LED_CAPSL.
+func (dev *InputDevice) LEDState() (Bitset, error) {
+	return dev.stateBits(uintptr(EVIOCGLED))
+}
+
+// SwitchState returns the set of switches currently on, e.g. SW_LID.
+func (dev *InputDevice) SwitchState() (Bitset, error) {
+	return dev.stateBits(uintptr(EVIOCGSW))
+}
+
+// ActiveKeys returns the codes of the keys and buttons currently held down,
+// in ascending order.
+func (dev *InputDevice) ActiveKeys() ([]int, error) {
+	keys, err := dev.KeyState()
+	if err != nil {
+		return nil, err
+	}
+
+	return keys.Codes(), nil
+}
+
+// IsKeyPressed reports whether a key or button is currently held down.
+func (dev *InputDevice) IsKeyPressed(code int) (bool, error) {
+	keys, err := dev.KeyState()
+	if err != nil {
+		return false, err
+	}
+
+	return keys.Has(code), nil
+}
+
+// Get a state bitmap with EVIOCGKEY, EVIOCGLED, EVIOCGSND or EVIOCGSW.
+func (dev *InputDevice) stateBits(request uintptr) (Bitset, error) {
+	bits := make(Bitset, MAX_NAME_SIZE)
+
+	if err := ioctl(dev.File.Fd(), request, unsafe.Pointer(&bits[0])); err != 0 {
+		return nil, err
+	}
+
+	return bits, nil
+}
+
+// Sync sets a keyboard state to the keys held on dev and its lock LEDs,
+// e.g. when starting to read from the device or after SYN_DROPPED.
+func (s *KeyboardState) Sync(dev *InputDevice) error {
+	keys, err := dev.KeyState()
+	if err != nil {
+		return err
+	}
+
+	s.Reset()
+	for _, code := range keys.Codes() {
+		s.pressed[EvCode(code)] = true
+		s.mods |= ModifierOf(EvCode(code))
+	}
+
+	if !dev.HasCode(EV_LED, LED_CAPSL) {
+		return nil
+	}
+	leds, err := dev.LEDState()
+	if err != nil {
+		return err
+	}
+	for _, led := range []EvCode{LED_CAPSL, LED_NUML, LED_SCROLLL} {
+		s.setLock(led, leds.Has(int(led)))
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+//go:build linux
+
+package evdev
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// DeviceLabels maps device fingerprints to user assigned labels, so that
+// identical devices can be told apart by their role ("left kiosk panel").
+// Labels are stored as a JSON object in a file.
+type DeviceLabels map[string]string
+
+// LoadDeviceLabels reads labels from a file. A missing file yields an empty
+// set of labels.
+func LoadDeviceLabels(path string) (DeviceLabels, error) {
+	labels := make(DeviceLabels)
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return labels, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(data, &labels); err != nil {
+		return nil, err
+	}
+
+	return labels, nil
+}
+
+// Save writes the labels to a file.
+func (l DeviceLabels) Save(path string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Set assigns a label to a device. An empty label removes it.
+func (l DeviceLabels) Set(dev *InputDevice, label string) {
+	if label == "" {
+		delete(l, dev.Fingerprint())
+	} else {
+		l[dev.Fingerprint()] = label
+	}
+	dev.Label = label
+}
+
+// Apply sets the Label field of every device that has a label.
+func (l DeviceLabels) Apply(devices ...*InputDevice) {
+	for _, dev := range devices {
+		dev.Label = l[dev.Fingerprint()]
+	}
+}
@@ -0,0 +1,81 @@
+//go:build linux
+
+package evdev
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDeviceLabels(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "labels.json")
+
+	labels, err := LoadDeviceLabels(path)
+	if err != nil || len(labels) != 0 {
+		t.Fatalf("expected no labels without a file, got %v, %v", labels, err)
+	}
+
+	// identical panels told apart by their location
+	left := &InputDevice{BusType: BUS_USB, Vendor: 0x0eef, Product: 0x0001, Phys: "usb-0000:00:14.0-1/input0"}
+	right := &InputDevice{BusType: BUS_USB, Vendor: 0x0eef, Product: 0x0001, Phys: "usb-0000:00:14.0-2/input0"}
+	other := &InputDevice{BusType: BUS_USB, Vendor: 0x046d, Product: 0xc31c, Ident: "serial"}
+
+	labels.Set(left, "left kiosk panel")
+	labels.Set(right, "right kiosk panel")
+	labels.Set(other, "spare")
+	labels.Set(other, "")
+	if left.Label != "left kiosk panel" || other.Label != "" || len(labels) != 2 {
+		t.Errorf("unexpected labels %v", labels)
+	}
+
+	if err := labels.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadDeviceLabels(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		dev  *InputDevice
+		want string
+	}{
+		{&InputDevice{BusType: BUS_USB, Vendor: 0x0eef, Product: 0x0001, Phys: "usb-0000:00:14.0-1/input0"}, "left kiosk panel"},
+		{&InputDevice{BusType: BUS_USB, Vendor: 0x0eef, Product: 0x0001, Phys: "usb-0000:00:14.0-2/input0"}, "right kiosk panel"},
+		{&InputDevice{BusType: BUS_USB, Vendor: 0x046d, Product: 0xc31c, Ident: "serial", Label: "stale"}, ""},
+	}
+	for _, tt := range tests {
+		loaded.Apply(tt.dev)
+		if tt.dev.Label != tt.want {
+			t.Errorf("%s: got label %q, want %q", tt.dev.Fingerprint(), tt.dev.Label, tt.want)
+		}
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadDeviceLabels(path); err == nil {
+		t.Error("expected an error for a corrupt file")
+	}
+}
+
+func TestDeviceLabelString(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	dev := &InputDevice{Fn: "/dev/input/event3", File: r, Name: "touchscreen"}
+	if s := dev.String(); strings.Contains(s, "label") {
+		t.Errorf("unexpected label in %q", s)
+	}
+
+	dev.Label = "left kiosk panel"
+	if s := dev.String(); !strings.Contains(s, "\n  label left kiosk panel\n  name touchscreen\n") {
+		t.Errorf("expected the label before the name in %q", s)
+	}
+}
@@ -0,0 +1,166 @@
+//go:build linux
+
+package evdev
+
+import (
+	"encoding/binary"
+	"io"
+	"runtime"
+	"syscall"
+)
+
+// EventLayout describes the binary layout of struct input_event on an
+// architecture. The timestamp fields of the struct are kernel longs, so
+// events are 16 bytes on 32-bit and 24 bytes on 64-bit architectures.
+type EventLayout struct {
+	Arch      string // GOARCH name of the architecture
+	LongSize  int    // size of the timestamp fields, 4 or 8
+	ByteOrder binary.ByteOrder
+}
+
+// Event layouts of the architectures supported by Go, by GOARCH name.
+var eventLayouts = map[string]EventLayout{
+	"386":      {"386", 4, binary.LittleEndian},
+	"arm":      {"arm", 4, binary.LittleEndian},
+	"mips":     {"mips", 4, binary.BigEndian},
+	"mipsle":   {"mipsle", 4, binary.LittleEndian},
+	"amd64":    {"amd64", 8, binary.LittleEndian},
+	"arm64":    {"arm64", 8, binary.LittleEndian},
+	"loong64":  {"loong64", 8, binary.LittleEndian},
+	"mips64":   {"mips64", 8, binary.BigEndian},
+	"mips64le": {"mips64le", 8, binary.LittleEndian},
+	"ppc64":    {"ppc64", 8, binary.BigEndian},
+	"ppc64le":  {"ppc64le", 8, binary.LittleEndian},
+	"riscv64":  {"riscv64", 8, binary.LittleEndian},
+	"s390x":    {"s390x", 8, binary.BigEndian},
+}
+
+// NativeEventLayout is the event layout of the running architecture.
+var NativeEventLayout = nativeEventLayout()
+
+func nativeEventLayout() EventLayout {
+	if layout, ok := eventLayouts[runtime.GOARCH]; ok {
+		return layout
+	}
+
+	return EventLayout{runtime.GOARCH, (eventsize - 8) / 2, binary.LittleEndian}
+}
+
+// EventLayoutFor returns the event layout of an architecture given by its
+// GOARCH name, e.g. "arm" for an armv7 device.
+func EventLayoutFor(arch string) (EventLayout, bool) {
+	layout, ok := eventLayouts[arch]
+	return layout, ok
+}
+
+// Size returns the size of an event in bytes.
+func (l EventLayout) Size() int {
+	return 2*l.LongSize + 8
+}
+
+// Decode converts an event of this layout to a native one. b must hold at
+// least Size bytes.
+func (l EventLayout) Decode(b []byte) InputEvent {
+	var sec, usec int64
+	if l.LongSize == 4 {
+		sec, usec = int64(int32(l.ByteOrder.Uint32(b))), int64(int32(l.ByteOrder.Uint32(b[4:])))
+	} else {
+		sec, usec = int64(l.ByteOrder.Uint64(b)), int64(l.ByteOrder.Uint64(b[8:]))
+	}
+	b = b[2*l.LongSize:]
+
+	return InputEvent{
+		Time:  syscall.NsecToTimeval(sec*1e9 + usec*1e3),
+		Type:  EvType(l.ByteOrder.Uint16(b)),
+		Code:  EvCode(l.ByteOrder.Uint16(b[2:])),
+		Value: EvValue(int32(l.ByteOrder.Uint32(b[4:]))),
+	}
+}
+
+// Encode converts a native event to this layout. b must hold at least Size
+// bytes.
+func (l EventLayout) Encode(b []byte, ev *InputEvent) {
+	if l.LongSize == 4 {
+		l.ByteOrder.PutUint32(b, uint32(ev.Time.Sec))
+		l.ByteOrder.PutUint32(b[4:], uint32(ev.Time.Usec))
+	} else {
+		l.ByteOrder.PutUint64(b, uint64(ev.Time.Sec))
+		l.ByteOrder.PutUint64(b[8:], uint64(ev.Time.Usec))
+	}
+	b = b[2*l.LongSize:]
+
+	l.ByteOrder.PutUint16(b, uint16(ev.Type))
+	l.ByteOrder.PutUint16(b[2:], uint16(ev.Code))
+	l.ByteOrder.PutUint32(b[4:], uint32(ev.Value))
+}
+
+// RawEventReader reads raw input_event structs of any layout, e.g. a dump of
+// a device node taken with cat on another machine, and converts them to
+// native events.
+type RawEventReader struct {
+	r      io.Reader
+	layout EventLayout
+	buffer []byte
+
+	decoder *EventDecoder // set in resilient mode
+	events  []InputEvent  // decoded, not returned yet
+}
+
+// NewRawEventReader reads events of the given layout from r.
+func NewRawEventReader(r io.Reader, layout EventLayout) *RawEventReader {
+	return &RawEventReader{r: r, layout: layout, buffer: make([]byte, layout.Size())}
+}
+
+// NewResilientEventReader reads events of the given layout from r,
+// resynchronizing on corrupted input instead of failing (see EventDecoder).
+func NewResilientEventReader(r io.Reader, layout EventLayout) *RawEventReader {
+	return &RawEventReader{
+		r:       r,
+		layout:  layout,
+		buffer:  make([]byte, 64*layout.Size()),
+		decoder: NewEventDecoder(layout),
+	}
+}
+
+// Next returns the next event, or io.EOF at the end of the input. A
+// truncated event at the end yields io.ErrUnexpectedEOF, unless the reader
+// is resilient.
+func (rr *RawEventReader) Next() (InputEvent, error) {
+	if rr.decoder != nil {
+		return rr.nextResilient()
+	}
+
+	if _, err := io.ReadFull(rr.r, rr.buffer); err != nil {
+		return InputEvent{}, err
+	}
+
+	return rr.layout.Decode(rr.buffer), nil
+}
+
+func (rr *RawEventReader) nextResilient() (InputEvent, error) {
+	for len(rr.events) == 0 {
+		n, err := rr.r.Read(rr.buffer)
+		rr.events = rr.decoder.Decode(rr.buffer[:n])
+
+		if err == io.EOF && len(rr.events) == 0 {
+			rr.decoder.Flush()
+			return InputEvent{}, io.EOF
+		}
+		if err != nil && err != io.EOF {
+			return InputEvent{}, err
+		}
+	}
+
+	ev := rr.events[0]
+	rr.events = rr.events[1:]
+	return ev, nil
+}
+
+// Anomalies returns the irregularities met so far by a resilient reader.
+func (rr *RawEventReader) Anomalies() StreamAnomalies {
+	if rr.decoder == nil {
+		return StreamAnomalies{}
+	}
+
+	return rr.decoder.Anomalies
+}
@@ -0,0 +1,92 @@
+//go:build linux
+
+package evdev
+
+import (
+	"io"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+// SetLowLatency switches the device to low-latency reads, for rhythm games
+// and similar uses where microseconds matter. Read and ReadContext then
+// hand out each kernel read as soon as it completes, in a slice reused by
+// all reads: the kernel writes the events directly into it, bypassing all
+// internal buffering and copying. The events are only valid until the next
+// read. Pre-roll events and resilient mode are still honoured.
+func (dev *InputDevice) SetLowLatency(on bool) {
+	switch {
+	case !on:
+		dev.lowLatency = nil
+	case dev.lowLatency == nil:
+		dev.lowLatency = make([]InputEvent, 64)
+	}
+}
+
+// ReadLowLatency reads in low-latency mode (see SetLowLatency), handing the
+// events of each read to fn on the calling goroutine locked to its OS
+// thread. The slice is only valid until fn returns. ReadLowLatency returns
+// when fn returns false or a read fails.
+func (dev *InputDevice) ReadLowLatency(fn func(events []InputEvent) bool) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if dev.lowLatency == nil {
+		dev.SetLowLatency(true)
+		defer dev.SetLowLatency(false)
+	}
+
+	for {
+		events, err := dev.Read()
+		if err != nil {
+			return err
+		}
+
+		if !fn(events) {
+			return nil
+		}
+	}
+}
+
+// Read into the low-latency buffer with read.
+func (dev *InputDevice) readLowLatency(read func(buffer []byte) (int, error)) ([]InputEvent, error) {
+	buffer := unsafe.Slice((*byte)(unsafe.Pointer(&dev.lowLatency[0])), len(dev.lowLatency)*eventsize)
+
+	n, err := read(buffer)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, io.EOF
+	}
+	if dev.decoder != nil {
+		return dev.decoder.Decode(buffer[:n]), nil
+	}
+
+	return dev.lowLatency[:n/eventsize], nil
+}
+
+// Read from the device, waiting for events if the file is in non-blocking
+// mode.
+func (dev *InputDevice) readBlocking(buffer []byte) (int, error) {
+	fd := dev.File.Fd()
+	if fd == ^uintptr(0) {
+		return 0, ErrClosed
+	}
+
+	for {
+		n, err := syscall.Read(int(fd), buffer)
+		switch err {
+		case nil:
+			return n, nil
+		case syscall.EINTR:
+		case syscall.EAGAIN:
+			if _, err = waitReadable(fd, -1); err != nil {
+				return 0, err
+			}
+		default:
+			return 0, err
+		}
+	}
+}
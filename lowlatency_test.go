@@ -0,0 +1,114 @@
+//go:build linux
+
+package evdev
+
+import (
+	"context"
+	"io"
+	"os"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+func TestReadLowLatency(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	dev := &InputDevice{File: r}
+
+	now := time.Unix(1000, 0)
+	frames := [][]InputEvent{
+		{NewInputEvent(now, EV_KEY, KEY_A, 1), NewInputEvent(now, EV_SYN, SYN_REPORT, 0)},
+		{NewInputEvent(now, EV_KEY, KEY_A, 0), NewInputEvent(now, EV_SYN, SYN_REPORT, 0)},
+	}
+	for _, frame := range frames {
+		w.Write(unsafe.Slice((*byte)(unsafe.Pointer(&frame[0])), len(frame)*eventsize))
+	}
+	w.Close()
+
+	got := make([]InputEvent, 0)
+	err = dev.ReadLowLatency(func(events []InputEvent) bool {
+		got = append(got, events...)
+		return true
+	})
+	if err != io.EOF {
+		t.Errorf("expected EOF, got %v", err)
+	}
+	if want := append(frames[0], frames[1]...); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestLowLatencyRead(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	now := time.Unix(1000, 0)
+	write := func(events ...InputEvent) {
+		w.Write(unsafe.Slice((*byte)(unsafe.Pointer(&events[0])), len(events)*eventsize))
+	}
+	frame := []InputEvent{NewInputEvent(now, EV_KEY, KEY_A, 1), NewInputEvent(now, EV_SYN, SYN_REPORT, 0)}
+
+	dev := &InputDevice{File: r}
+	dev.SetLowLatency(true)
+
+	// pre-roll events come first
+	dev.pending = []InputEvent{NewInputEvent(now, EV_KEY, KEY_B, 1)}
+	write(frame...)
+	got, err := dev.Read()
+	if err != nil || len(got) != 1 || got[0].Code != KEY_B {
+		t.Fatalf("expected the pre-roll first, got %v, %v", got, err)
+	}
+	if got, err = dev.Read(); err != nil || !reflect.DeepEqual(got, frame) {
+		t.Errorf("got %v, %v, want %v", got, err, frame)
+	}
+
+	// the file in non-blocking mode behind the back of os.File, reads
+	// wait for events
+	var p [2]int
+	if err := syscall.Pipe(p[:]); err != nil {
+		t.Fatal(err)
+	}
+	r, w = os.NewFile(uintptr(p[0]), "r"), os.NewFile(uintptr(p[1]), "w")
+	defer r.Close()
+	defer w.Close()
+	if err := syscall.SetNonblock(p[0], true); err != nil {
+		t.Fatal(err)
+	}
+	dev.File = r
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		write(frame...)
+	}()
+	if got, err = dev.ReadContext(context.Background()); err != nil || !reflect.DeepEqual(got, frame) {
+		t.Errorf("got %v, %v, want %v", got, err, frame)
+	}
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		write(frame...)
+	}()
+	if got, err = dev.Read(); err != nil || !reflect.DeepEqual(got, frame) {
+		t.Errorf("got %v, %v, want %v", got, err, frame)
+	}
+
+	// resilient mode drops the events of unknown types
+	dev.SetResilient()
+	write(NewInputEvent(now, 0x1d, 0, 0), frame[0], frame[1])
+	if got, err = dev.Read(); err != nil || !reflect.DeepEqual(got, frame) {
+		t.Errorf("got %v, %v, want %v", got, err, frame)
+	}
+
+	dev.SetLowLatency(false)
+	if dev.lowLatency != nil {
+		t.Error("expected low-latency mode off")
+	}
+}
@@ -0,0 +1,70 @@
+//go:build linux
+
+package evdev
+
+import (
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+// Corresponds to the input_mask struct.
+type inputMask struct {
+	evType    uint32
+	codesSize uint32
+	codesPtr  uint64
+}
+
+// SetEventMask makes the kernel deliver only the given codes of an event
+// type to this handle, using EVIOCSMASK. Events filtered this way never wake
+// the process, which matters for high-rate devices such as 8kHz gaming mice.
+// Masking EV_SYN selects the event types to deliver. Masks are per handle,
+// other readers of the device are not affected.
+func (dev *InputDevice) SetEventMask(evType int, codes []int) error {
+	max := CodeMax(evType)
+	if max < 0 {
+		return syscall.EINVAL
+	}
+
+	bits := NewBitset(max)
+	for _, code := range codes {
+		if code < 0 || code > max {
+			return syscall.EINVAL
+		}
+		bits.Set(code)
+	}
+
+	return dev.eventMask(uintptr(EVIOCSMASK), evType, bits)
+}
+
+// GetEventMask returns the codes of an event type delivered to this handle,
+// in ascending order. All codes are delivered unless masked by SetEventMask.
+func (dev *InputDevice) GetEventMask(evType int) ([]int, error) {
+	max := CodeMax(evType)
+	if max < 0 {
+		return nil, syscall.EINVAL
+	}
+
+	bits := NewBitset(max)
+	if err := dev.eventMask(uintptr(EVIOCGMASK), evType, bits); err != nil {
+		return nil, err
+	}
+
+	return bits.Codes(), nil
+}
+
+func (dev *InputDevice) eventMask(request uintptr, evType int, bits Bitset) error {
+	mask := inputMask{
+		evType:    uint32(evType),
+		codesSize: uint32(len(bits)),
+		codesPtr:  uint64(uintptr(unsafe.Pointer(&bits[0]))),
+	}
+
+	err := ioctl(dev.File.Fd(), request, unsafe.Pointer(&mask))
+	runtime.KeepAlive(bits)
+	if err != 0 {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package evdev
+
+import "time"
+
+// MediaRepeatFilter replaces the autorepeat of held media keys with a ramped
+// pattern: after Delay the key repeats every SlowPeriod, and once it has
+// been held for Ramp every FastPeriod. Kernel repeats of these keys are
+// dropped, so the pattern is the same whether the device repeats too fast
+// or not at all. Tick must be called at least every FastPeriod.
+type MediaRepeatFilter struct {
+	Keys       map[EvCode]bool
+	Delay      time.Duration
+	SlowPeriod time.Duration
+	Ramp       time.Duration
+	FastPeriod time.Duration
+
+	held map[EvCode]*mediaRepeat
+}
+
+type mediaRepeat struct {
+	pressed time.Time // when the key was pressed
+	next    time.Time // when the next repeat is due
+}
+
+// NewMediaRepeatFilter creates a filter for the volume and brightness keys
+// repeating every 200ms after 400ms and every 50ms after two seconds.
+func NewMediaRepeatFilter() *MediaRepeatFilter {
+	return &MediaRepeatFilter{
+		Keys: map[EvCode]bool{
+			KEY_VOLUMEUP:       true,
+			KEY_VOLUMEDOWN:     true,
+			KEY_BRIGHTNESSUP:   true,
+			KEY_BRIGHTNESSDOWN: true,
+		},
+		Delay:      400 * time.Millisecond,
+		SlowPeriod: 200 * time.Millisecond,
+		Ramp:       2 * time.Second,
+		FastPeriod: 50 * time.Millisecond,
+		held:       make(map[EvCode]*mediaRepeat),
+	}
+}
+
+// Process passes events through, dropping the kernel repeats of the keys.
+func (f *MediaRepeatFilter) Process(ev InputEvent) []InputEvent {
+	if ev.Type != EV_KEY || !f.Keys[ev.Code] {
+		return []InputEvent{ev}
+	}
+
+	switch ev.Value {
+	case EvValue(KeyDown):
+		// created here as well, so that a zero MediaRepeatFilter works
+		if f.held == nil {
+			f.held = make(map[EvCode]*mediaRepeat)
+		}
+		t := ev.Timestamp()
+		f.held[ev.Code] = &mediaRepeat{pressed: t, next: t.Add(f.Delay)}
+	case EvValue(KeyUp):
+		delete(f.held, ev.Code)
+	default:
+		return nil
+	}
+
+	return []InputEvent{ev}
+}
+
+// Tick emits the repeats that are due.
+func (f *MediaRepeatFilter) Tick(now time.Time) []InputEvent {
+	events := make([]InputEvent, 0)
+
+	for code, r := range f.held {
+		if now.Before(r.next) {
+			continue
+		}
+
+		period := f.SlowPeriod
+		if now.Sub(r.pressed) >= f.Ramp {
+			period = f.FastPeriod
+		}
+		r.next = now.Add(period)
+
+		events = append(events, NewInputEvent(now, EV_KEY, code, EvValue(KeyHold)))
+	}
+
+	if len(events) > 0 {
+		events = append(events, NewInputEvent(now, EV_SYN, SYN_REPORT, 0))
+	}
+
+	return events
+}
@@ -0,0 +1,57 @@
+package evdev
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMediaRepeatFilter(t *testing.T) {
+	ms := time.Millisecond
+	t0 := time.Unix(1000, 0)
+
+	tests := []struct {
+		name   string
+		filter *MediaRepeatFilter
+	}{
+		{"default", NewMediaRepeatFilter()},
+		{"zero", &MediaRepeatFilter{
+			Keys:       map[EvCode]bool{KEY_VOLUMEUP: true},
+			Delay:      400 * ms,
+			SlowPeriod: 200 * ms,
+			Ramp:       2 * time.Second,
+			FastPeriod: 50 * ms,
+		}},
+	}
+
+	for _, tt := range tests {
+		f := tt.filter
+
+		if got := f.Process(NewInputEvent(t0, EV_KEY, KEY_VOLUMEUP, EvValue(KeyDown))); len(got) != 1 {
+			t.Errorf("%s: expected the press to pass, got %v", tt.name, got)
+		}
+		if got := f.Process(NewInputEvent(t0.Add(250*ms), EV_KEY, KEY_VOLUMEUP, EvValue(KeyHold))); len(got) != 0 {
+			t.Errorf("%s: expected the kernel repeat to be dropped, got %v", tt.name, got)
+		}
+		if got := f.Process(NewInputEvent(t0, EV_KEY, KEY_A, EvValue(KeyHold))); len(got) != 1 {
+			t.Errorf("%s: expected other keys to pass, got %v", tt.name, got)
+		}
+
+		// repeats at 400, 600, ..., 2000ms, then every 50ms
+		repeats := 0
+		for d := time.Duration(0); d <= 2200*ms; d += 10 * ms {
+			for _, ev := range f.Tick(t0.Add(d)) {
+				if ev.Type == EV_KEY && ev.Value == EvValue(KeyHold) {
+					repeats++
+				}
+			}
+		}
+		if repeats != 9+4 {
+			t.Errorf("%s: expected 13 repeats, got %d", tt.name, repeats)
+		}
+
+		f.Process(NewInputEvent(t0.Add(2200*ms), EV_KEY, KEY_VOLUMEUP, EvValue(KeyUp)))
+		if got := f.Tick(t0.Add(3 * time.Second)); len(got) != 0 {
+			t.Errorf("%s: expected no repeats after the release, got %v", tt.name, got)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package evdev
+
+import "time"
+
+// MorseAlphabet maps International Morse Code sequences to characters.
+var MorseAlphabet = map[string]string{
+	".-": "A", "-...": "B", "-.-.": "C", "-..": "D", ".": "E", "..-.": "F",
+	"--.": "G", "....": "H", "..": "I", ".---": "J", "-.-": "K", ".-..": "L",
+	"--": "M", "-.": "N", "---": "O", ".--.": "P", "--.-": "Q", ".-.": "R",
+	"...": "S", "-": "T", "..-": "U", "...-": "V", ".--": "W", "-..-": "X",
+	"-.--": "Y", "--..": "Z",
+	"-----": "0", ".----": "1", "..---": "2", "...--": "3", "....-": "4",
+	".....": "5", "-....": "6", "--...": "7", "---..": "8", "----.": "9",
+	".-.-.-": ".", "--..--": ",", "..--..": "?", "-..-.": "/", ".--.-.": "@",
+}
+
+// MorseDecoder turns short and long presses of a single button or switch
+// into text, for accessibility switches and one-button interfaces. Presses
+// shorter than Dash are dots, longer ones dashes. A pause of LetterGap ends
+// a character and a pause of WordGap a word. The Alphabet may map sequences
+// to arbitrary strings, e.g. command names. Tick must be called regularly
+// so that the last character is decoded without waiting for the next press.
+type MorseDecoder struct {
+	Type   EvType // EV_KEY or EV_SW
+	Button EvCode
+
+	Dash      time.Duration
+	LetterGap time.Duration
+	WordGap   time.Duration
+	Alphabet  map[string]string
+	Unknown   string // emitted for sequences missing from the alphabet
+
+	symbols  string    // dots and dashes of the current character
+	pressed  time.Time // when the button was pressed, zero if released
+	released time.Time // when the button was last released
+	inWord   bool      // characters were emitted since the last word gap
+}
+
+// NewMorseDecoder creates a decoder for the standard Morse timings with the
+// given dot length: dashes are three dots long, characters are three and
+// words seven dots apart. The thresholds lie halfway between them, so that
+// human timing may be off by a dot either way.
+func NewMorseDecoder(evType EvType, button EvCode, dot time.Duration) *MorseDecoder {
+	return &MorseDecoder{
+		Type:      evType,
+		Button:    button,
+		Dash:      2 * dot,
+		LetterGap: 2 * dot,
+		WordGap:   5 * dot,
+		Alphabet:  MorseAlphabet,
+		Unknown:   "?",
+	}
+}
+
+// Feed passes an event to the decoder. It returns the decoded text and true
+// when a press completes a pause that ended a character or word.
+func (d *MorseDecoder) Feed(ev *InputEvent) (string, bool) {
+	if ev.Type != d.Type || ev.Code != d.Button {
+		return "", false
+	}
+	t := ev.Timestamp()
+
+	switch {
+	case ev.Value == 1 && d.pressed.IsZero():
+		text, ok := d.Tick(t)
+		d.pressed = t
+		return text, ok
+	case ev.Value == 0 && !d.pressed.IsZero():
+		if t.Sub(d.pressed) < d.Dash {
+			d.symbols += "."
+		} else {
+			d.symbols += "-"
+		}
+		d.pressed = time.Time{}
+		d.released = t
+	}
+
+	return "", false
+}
+
+// Tick decodes the pending character or ends the word if the button has
+// been released for long enough.
+func (d *MorseDecoder) Tick(now time.Time) (string, bool) {
+	if !d.pressed.IsZero() || d.released.IsZero() {
+		return "", false
+	}
+	pause := now.Sub(d.released)
+
+	text := ""
+	if d.symbols != "" && pause >= d.LetterGap {
+		if s, ok := d.Alphabet[d.symbols]; ok {
+			text = s
+		} else {
+			text = d.Unknown
+		}
+		d.symbols = ""
+		d.inWord = true
+	}
+	if d.inWord && pause >= d.WordGap {
+		text += " "
+		d.inWord = false
+	}
+
+	return text, text != ""
+}
@@ -0,0 +1,79 @@
+package evdev
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMorseDecoder(t *testing.T) {
+	dot := 100 * time.Millisecond
+	d := NewMorseDecoder(EV_KEY, KEY_SPACE, dot)
+	now := time.Unix(1700000000, 0)
+	text := ""
+
+	press := func(length, pause time.Duration) {
+		down := NewInputEvent(now, EV_KEY, KEY_SPACE, 1)
+		if s, ok := d.Feed(&down); ok {
+			text += s
+		}
+		now = now.Add(length)
+		up := NewInputEvent(now, EV_KEY, KEY_SPACE, 0)
+		d.Feed(&up)
+		now = now.Add(pause)
+	}
+
+	// "SOS" then a word gap, decoded partly by presses and partly by Tick
+	for _, length := range []time.Duration{dot, dot, dot, 3 * dot, 3 * dot, 3 * dot, dot, dot, dot} {
+		press(length, dot)
+		if len(d.symbols) == 3 {
+			now = now.Add(2 * dot)
+		}
+	}
+	if s, ok := d.Tick(now.Add(6 * dot)); ok {
+		text += s
+	}
+
+	if text != "SOS " {
+		t.Errorf("got %q, want %q", text, "SOS ")
+	}
+}
+
+func TestMorseDecoderTolerance(t *testing.T) {
+	dot := 100 * time.Millisecond
+	now := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		pause time.Duration // after a dot, followed by a dash
+		want  string
+	}{
+		{dot * 3 / 2, "A"},    // a long symbol gap
+		{dot * 5 / 2, "ET"},   // a short letter gap
+		{dot * 9 / 2, "ET"},   // a long letter gap
+		{dot * 11 / 2, "E T"}, // a short word gap
+	}
+	for _, tt := range tests {
+		d := NewMorseDecoder(EV_KEY, KEY_SPACE, dot)
+		text := ""
+		feed := func(value EvValue) {
+			ev := NewInputEvent(now, EV_KEY, KEY_SPACE, value)
+			if s, ok := d.Feed(&ev); ok {
+				text += s
+			}
+		}
+
+		feed(1)
+		now = now.Add(dot)
+		feed(0)
+		now = now.Add(tt.pause)
+		feed(1)
+		now = now.Add(3 * dot)
+		feed(0)
+		if s, ok := d.Tick(now.Add(3 * dot)); ok {
+			text += s
+		}
+
+		if text != tt.want {
+			t.Errorf("pause of %v: got %q, want %q", tt.pause, text, tt.want)
+		}
+	}
+}
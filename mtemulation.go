@@ -0,0 +1,128 @@
+package evdev
+
+// SingleTouchEmulator is a Filter producing single-touch events from
+// a multi-touch protocol B stream, for consumers that can't handle slots. Like
+// the kernel's pointer emulation, ABS_X, ABS_Y and ABS_PRESSURE follow the
+// oldest contact, BTN_TOUCH is set while any contact is down and
+// BTN_TOOL_FINGER to BTN_TOOL_QUINTTAP report the number of contacts. As
+// there is no tool for more contacts, none is set for six or more. The
+// emulated events are inserted before each SYN_REPORT, and only when they
+// change.
+type SingleTouchEmulator struct {
+	StripMT bool // drop the multi-touch events themselves
+
+	slots  map[EvValue]*stContact
+	slot   EvValue
+	serial int                // order of the contacts
+	state  map[[2]int]EvValue // emitted type/code -> value, initially 0
+}
+
+type stContact struct {
+	serial         int // when the contact started, 0 if inactive
+	x, y, pressure EvValue
+	hasPressure    bool
+}
+
+// Contact count tools, indexed by the number of contacts minus one.
+var stTools = []EvCode{BTN_TOOL_FINGER, BTN_TOOL_DOUBLETAP, BTN_TOOL_TRIPLETAP, BTN_TOOL_QUADTAP, BTN_TOOL_QUINTTAP}
+
+// NewSingleTouchEmulator creates an emulator passing the multi-touch events
+// through.
+func NewSingleTouchEmulator() *SingleTouchEmulator {
+	return &SingleTouchEmulator{
+		slots: make(map[EvValue]*stContact),
+		state: make(map[[2]int]EvValue),
+	}
+}
+
+// Process tracks the contacts and emits the emulated events at SYN_REPORT.
+func (e *SingleTouchEmulator) Process(ev InputEvent) []InputEvent {
+	if ev.Type == EV_SYN && ev.Code == SYN_REPORT {
+		return append(e.emulate(ev), ev)
+	}
+	if ev.Type != EV_ABS || ev.Code < ABS_MT_SLOT {
+		return []InputEvent{ev}
+	}
+
+	if ev.Code == ABS_MT_SLOT {
+		e.slot = ev.Value
+	} else {
+		c, ok := e.slots[e.slot]
+		if !ok {
+			c = &stContact{}
+			e.slots[e.slot] = c
+		}
+
+		switch ev.Code {
+		case ABS_MT_TRACKING_ID:
+			if ev.Value < 0 {
+				c.serial = 0
+			} else if c.serial == 0 {
+				e.serial++
+				c.serial = e.serial
+			}
+		case ABS_MT_POSITION_X:
+			c.x = ev.Value
+		case ABS_MT_POSITION_Y:
+			c.y = ev.Value
+		case ABS_MT_PRESSURE:
+			c.pressure = ev.Value
+			c.hasPressure = true
+		}
+	}
+
+	if e.StripMT {
+		return nil
+	}
+	return []InputEvent{ev}
+}
+
+func (e *SingleTouchEmulator) emulate(syn InputEvent) []InputEvent {
+	var oldest *stContact
+	count := 0
+
+	for _, c := range e.slots {
+		if c.serial == 0 {
+			continue
+		}
+		count++
+		if oldest == nil || c.serial < oldest.serial {
+			oldest = c
+		}
+	}
+
+	events := make([]InputEvent, 0)
+	set := func(evType EvType, code EvCode, value EvValue) {
+		key := [2]int{int(evType), int(code)}
+		if e.state[key] == value {
+			return
+		}
+		e.state[key] = value
+		events = append(events, NewInputEvent(syn.Timestamp(), evType, code, value))
+	}
+
+	touching := EvValue(0)
+	if count > 0 {
+		touching = 1
+	}
+	set(EV_KEY, BTN_TOUCH, touching)
+	for i, tool := range stTools {
+		value := EvValue(0)
+		if count == i+1 {
+			value = 1
+		}
+		set(EV_KEY, tool, value)
+	}
+
+	if oldest != nil {
+		set(EV_ABS, ABS_X, oldest.x)
+		set(EV_ABS, ABS_Y, oldest.y)
+		if oldest.hasPressure {
+			set(EV_ABS, ABS_PRESSURE, oldest.pressure)
+		}
+	} else {
+		set(EV_ABS, ABS_PRESSURE, 0)
+	}
+
+	return events
+}
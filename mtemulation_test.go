@@ -0,0 +1,56 @@
+package evdev
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSingleTouchEmulatorTools(t *testing.T) {
+	e := NewSingleTouchEmulator()
+	now := time.Unix(1000, 0)
+
+	// touch down one more contact per frame, returning the tools held
+	held := make(map[EvCode]bool)
+	touch := func(slot int) []EvCode {
+		for _, ev := range []InputEvent{
+			NewInputEvent(now, EV_ABS, ABS_MT_SLOT, EvValue(slot)),
+			NewInputEvent(now, EV_ABS, ABS_MT_TRACKING_ID, EvValue(slot+10)),
+			NewInputEvent(now, EV_SYN, SYN_REPORT, 0),
+		} {
+			for _, out := range e.Process(ev) {
+				if out.Type == EV_KEY && out.Code != BTN_TOUCH {
+					held[out.Code] = out.Value == 1
+				}
+			}
+		}
+
+		tools := make([]EvCode, 0)
+		for _, code := range stTools {
+			if held[code] {
+				tools = append(tools, code)
+			}
+		}
+		return tools
+	}
+
+	tests := []struct {
+		contacts int
+		want     []EvCode
+	}{
+		{1, []EvCode{BTN_TOOL_FINGER}},
+		{2, []EvCode{BTN_TOOL_DOUBLETAP}},
+		{3, []EvCode{BTN_TOOL_TRIPLETAP}},
+		{4, []EvCode{BTN_TOOL_QUADTAP}},
+		{5, []EvCode{BTN_TOOL_QUINTTAP}},
+		{6, []EvCode{}},
+		{7, []EvCode{}},
+	}
+
+	for _, tt := range tests {
+		got := touch(tt.contacts - 1)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%d contacts: got tools %v, want %v", tt.contacts, got, tt.want)
+		}
+	}
+}
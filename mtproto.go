@@ -0,0 +1,174 @@
+package evdev
+
+import (
+	"sort"
+	"time"
+)
+
+// ProtocolAConverter is a Filter converting multi-touch protocol A streams
+// (contacts separated by SYN_MT_REPORT, no slots) into protocol B, so that
+// the rest of the multi-touch stack only has to deal with slots. Contacts
+// are matched between frames by ABS_MT_TRACKING_ID if the device reports
+// one, and otherwise by distance: each contact continues the nearest one of
+// the previous frame within MaxDistance. Every new contact gets a free slot
+// and a new tracking id.
+type ProtocolAConverter struct {
+	MaxDistance int32 // maximum movement of a contact between two frames
+
+	frame    []InputEvent       // non-multi-touch events of the current frame
+	contact  map[EvCode]EvValue // values of the contact being reported
+	contacts []map[EvCode]EvValue
+	active   map[int]*mtaContact // slot -> contact of the previous frame
+	nextID   EvValue
+}
+
+type mtaContact struct {
+	id     EvValue            // protocol A tracking id, -1 if none
+	values map[EvCode]EvValue // last reported values
+}
+
+// NewProtocolAConverter creates a converter matching contacts that moved
+// up to maxDistance units between frames.
+func NewProtocolAConverter(maxDistance int32) *ProtocolAConverter {
+	return &ProtocolAConverter{
+		MaxDistance: maxDistance,
+		contact:     make(map[EvCode]EvValue),
+		active:      make(map[int]*mtaContact),
+	}
+}
+
+// Process collects the events of a frame and emits the converted frame at
+// SYN_REPORT.
+func (c *ProtocolAConverter) Process(ev InputEvent) []InputEvent {
+	switch {
+	case ev.Type == EV_ABS && ev.Code > ABS_MT_SLOT:
+		c.contact[ev.Code] = ev.Value
+		return nil
+	case ev.Type == EV_SYN && ev.Code == SYN_MT_REPORT:
+		if len(c.contact) > 0 {
+			c.contacts = append(c.contacts, c.contact)
+			c.contact = make(map[EvCode]EvValue)
+		}
+		return nil
+	case ev.Type == EV_SYN && ev.Code == SYN_REPORT:
+		events := append(c.convert(ev), c.frame...)
+		c.frame = nil
+		return append(events, ev)
+	}
+
+	c.frame = append(c.frame, ev)
+	return nil
+}
+
+func (c *ProtocolAConverter) convert(syn InputEvent) []InputEvent {
+	t := syn.Timestamp()
+	events := make([]InputEvent, 0)
+	matched := make(map[int]map[EvCode]EvValue)
+	unmatched := make([]map[EvCode]EvValue, 0)
+
+	for _, values := range c.contacts {
+		if slot, ok := c.match(values, matched); ok {
+			matched[slot] = values
+		} else {
+			unmatched = append(unmatched, values)
+		}
+	}
+	c.contacts = nil
+
+	slots := make([]int, 0, len(c.active))
+	for slot := range c.active {
+		slots = append(slots, slot)
+	}
+	sort.Ints(slots)
+
+	// lifted contacts, then moved ones
+	for _, slot := range slots {
+		if _, ok := matched[slot]; !ok {
+			delete(c.active, slot)
+			events = append(events,
+				NewInputEvent(t, EV_ABS, ABS_MT_SLOT, EvValue(slot)),
+				NewInputEvent(t, EV_ABS, ABS_MT_TRACKING_ID, -1))
+		}
+	}
+	for _, slot := range slots {
+		if values, ok := matched[slot]; ok {
+			events = append(events, c.update(t, slot, values, false)...)
+		}
+	}
+
+	// new contacts in the lowest free slots
+	slot := 0
+	for _, values := range unmatched {
+		for c.active[slot] != nil {
+			slot++
+		}
+
+		id := EvValue(-1)
+		if v, ok := values[ABS_MT_TRACKING_ID]; ok {
+			id = v
+		}
+		c.active[slot] = &mtaContact{id: id, values: make(map[EvCode]EvValue)}
+		events = append(events, c.update(t, slot, values, true)...)
+	}
+
+	return events
+}
+
+// Find the slot of the previous frame continued by a contact.
+func (c *ProtocolAConverter) match(values map[EvCode]EvValue, matched map[int]map[EvCode]EvValue) (int, bool) {
+	if id, ok := values[ABS_MT_TRACKING_ID]; ok {
+		for slot, prev := range c.active {
+			if prev.id == id && matched[slot] == nil {
+				return slot, true
+			}
+		}
+		return 0, false
+	}
+
+	best, bestDist := -1, int64(c.MaxDistance)*int64(c.MaxDistance)
+	for slot, prev := range c.active {
+		if matched[slot] != nil {
+			continue
+		}
+
+		dx := int64(values[ABS_MT_POSITION_X] - prev.values[ABS_MT_POSITION_X])
+		dy := int64(values[ABS_MT_POSITION_Y] - prev.values[ABS_MT_POSITION_Y])
+		if dist := dx*dx + dy*dy; dist <= bestDist {
+			best, bestDist = slot, dist
+		}
+	}
+
+	return best, best >= 0
+}
+
+// Emit the changed values of a slot, starting a new contact if fresh.
+func (c *ProtocolAConverter) update(t time.Time, slot int, values map[EvCode]EvValue, fresh bool) []InputEvent {
+	contact := c.active[slot]
+	events := []InputEvent{NewInputEvent(t, EV_ABS, ABS_MT_SLOT, EvValue(slot))}
+
+	if fresh {
+		events = append(events, NewInputEvent(t, EV_ABS, ABS_MT_TRACKING_ID, c.nextID))
+		c.nextID = (c.nextID + 1) & 0xffff
+	}
+
+	codes := make([]int, 0, len(values))
+	for code := range values {
+		if code != ABS_MT_TRACKING_ID {
+			codes = append(codes, int(code))
+		}
+	}
+	sort.Ints(codes)
+
+	for _, code := range codes {
+		value := values[EvCode(code)]
+		if old, ok := contact.values[EvCode(code)]; fresh || !ok || old != value {
+			events = append(events, NewInputEvent(t, EV_ABS, EvCode(code), value))
+		}
+		contact.values[EvCode(code)] = value
+	}
+
+	if len(events) == 1 {
+		return nil
+	}
+	return events
+}
@@ -0,0 +1,49 @@
+package evdev
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProtocolAConverter(t *testing.T) {
+	c := NewProtocolAConverter(50)
+	now := time.Now()
+
+	frame := func(contacts ...[2]EvValue) []InputEvent {
+		out := make([]InputEvent, 0)
+		for _, pos := range contacts {
+			out = append(out, c.Process(NewInputEvent(now, EV_ABS, ABS_MT_POSITION_X, pos[0]))...)
+			out = append(out, c.Process(NewInputEvent(now, EV_ABS, ABS_MT_POSITION_Y, pos[1]))...)
+			out = append(out, c.Process(NewInputEvent(now, EV_SYN, SYN_MT_REPORT, 0))...)
+		}
+		return append(out, c.Process(NewInputEvent(now, EV_SYN, SYN_REPORT, 0))...)
+	}
+	check := func(got []InputEvent, want ...[3]int) {
+		t.Helper()
+		if len(got) != len(want) {
+			t.Fatalf("got %d events, want %d: %v", len(got), len(want), got)
+		}
+		for i, w := range want {
+			if int(got[i].Type) != w[0] || int(got[i].Code) != w[1] || int(got[i].Value) != w[2] {
+				t.Errorf("event %d: got %v, want %v", i, &got[i], w)
+			}
+		}
+	}
+
+	check(frame([2]EvValue{100, 100}, [2]EvValue{500, 500}),
+		[3]int{EV_ABS, ABS_MT_SLOT, 0}, [3]int{EV_ABS, ABS_MT_TRACKING_ID, 0},
+		[3]int{EV_ABS, ABS_MT_POSITION_X, 100}, [3]int{EV_ABS, ABS_MT_POSITION_Y, 100},
+		[3]int{EV_ABS, ABS_MT_SLOT, 1}, [3]int{EV_ABS, ABS_MT_TRACKING_ID, 1},
+		[3]int{EV_ABS, ABS_MT_POSITION_X, 500}, [3]int{EV_ABS, ABS_MT_POSITION_Y, 500},
+		[3]int{EV_SYN, SYN_REPORT, 0})
+
+	// reported in a different order, the first contact lifted
+	check(frame([2]EvValue{510, 500}),
+		[3]int{EV_ABS, ABS_MT_SLOT, 0}, [3]int{EV_ABS, ABS_MT_TRACKING_ID, -1},
+		[3]int{EV_ABS, ABS_MT_SLOT, 1}, [3]int{EV_ABS, ABS_MT_POSITION_X, 510},
+		[3]int{EV_SYN, SYN_REPORT, 0})
+
+	check(frame(),
+		[3]int{EV_ABS, ABS_MT_SLOT, 1}, [3]int{EV_ABS, ABS_MT_TRACKING_ID, -1},
+		[3]int{EV_SYN, SYN_REPORT, 0})
+}
@@ -0,0 +1,102 @@
+//go:build linux
+
+package evdev
+
+import (
+	"time"
+	"unsafe"
+)
+
+// MTSlotValues returns the current values of a multi-touch axis, e.g.
+// ABS_MT_TRACKING_ID or ABS_MT_POSITION_X, for every slot of the device,
+// as queried with EVIOCGMTSLOTS.
+func (dev *InputDevice) MTSlotValues(code int) ([]int32, error) {
+	slots, err := dev.AbsInfo(ABS_MT_SLOT)
+	if err != nil {
+		return nil, err
+	}
+
+	// the code goes in, followed by one value per slot
+	values := make([]int32, slots.Max+2)
+	values[0] = int32(code)
+
+	if err := ioctl(dev.File.Fd(), uintptr(EVIOCGMTSLOTS(len(values)*4)), unsafe.Pointer(&values[0])); err != 0 {
+		return nil, err
+	}
+
+	return values[1:], nil
+}
+
+// Axes tracked by MTState, other than the tracking id.
+var mtStateAxes = []EvCode{
+	ABS_MT_POSITION_X,
+	ABS_MT_POSITION_Y,
+	ABS_MT_PRESSURE,
+	ABS_MT_TOUCH_MAJOR,
+	ABS_MT_TOUCH_MINOR,
+}
+
+// NewMTStateFor creates a tracker for the multi-touch protocol of dev.
+// Devices without slots use protocol A; their contacts are matched between
+// frames if they moved less than a tenth of the width of the device.
+func NewMTStateFor(dev *InputDevice) *MTState {
+	s := NewMTState()
+	if !dev.HasCode(EV_ABS, ABS_MT_SLOT) {
+		x := dev.AbsInfos[ABS_MT_POSITION_X]
+		s.ProtocolA = NewProtocolAConverter((x.Max - x.Min) / 10)
+	}
+
+	return s
+}
+
+// Sync updates the tracker with the contacts currently on dev, e.g. when
+// starting to read from a device or after SYN_DROPPED. The differences to
+// the tracked state are reported through the callbacks as one frame.
+// Protocol A devices have no slot state to query; Sync does nothing for
+// them.
+func (s *MTState) Sync(dev *InputDevice) error {
+	if s.ProtocolA != nil {
+		return nil
+	}
+
+	ids, err := dev.MTSlotValues(ABS_MT_TRACKING_ID)
+	if err != nil {
+		return err
+	}
+
+	values := make(map[EvCode][]int32)
+	for _, code := range mtStateAxes {
+		if !dev.HasCode(EV_ABS, int(code)) {
+			continue
+		}
+		if values[code], err = dev.MTSlotValues(int(code)); err != nil {
+			return err
+		}
+	}
+
+	current, err := dev.AbsInfo(ABS_MT_SLOT)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	feed := func(evType EvType, code EvCode, value int32) {
+		ev := NewInputEvent(now, evType, code, EvValue(value))
+		s.Feed(&ev)
+	}
+
+	s.dropping = false
+	for slot, id := range ids {
+		feed(EV_ABS, ABS_MT_SLOT, int32(slot))
+		feed(EV_ABS, ABS_MT_TRACKING_ID, id)
+		for _, code := range mtStateAxes {
+			if v, ok := values[code]; ok {
+				feed(EV_ABS, code, v[slot])
+			}
+		}
+	}
+	feed(EV_ABS, ABS_MT_SLOT, current.Value)
+	feed(EV_SYN, SYN_REPORT, 0)
+
+	return nil
+}
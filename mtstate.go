@@ -0,0 +1,178 @@
+package evdev
+
+import "sort"
+
+// Contact is the state of a multi-touch contact.
+type Contact struct {
+	Slot       int
+	TrackingID int32
+	X, Y       int32
+	Pressure   int32
+	TouchMajor int32
+	TouchMinor int32
+}
+
+// MTState tracks the contacts of a multi-touch protocol B stream, decoding
+// the slot machinery: values apply to the slot last selected with
+// ABS_MT_SLOT, a tracking id starts a contact and a tracking id of -1 ends
+// it. At every SYN_REPORT the callbacks are called for the contacts that
+// ended, started or moved during the frame, in this order and by slot. A
+// contact that ends and is replaced in the same frame is reported as up
+// and then down.
+//
+// Protocol A streams, which report every contact in every frame without
+// slots, are tracked the same way by setting ProtocolA; their contacts are
+// assigned slots and tracking ids by the converter.
+type MTState struct {
+	OnTouchDown func(c Contact) // may be nil
+	OnTouchMove func(c Contact) // may be nil
+	OnTouchUp   func(c Contact) // may be nil, called with the last values
+
+	ProtocolA *ProtocolAConverter // converts protocol A streams if set
+
+	slot     int
+	slots    map[int]*mtSlot
+	dropping bool
+}
+
+type mtSlot struct {
+	Contact
+	active  bool
+	started bool     // contact started in the current frame
+	moved   bool     // values changed in the current frame
+	ended   *Contact // contact ended in the current frame
+}
+
+// NewMTState creates a tracker without contacts.
+func NewMTState() *MTState {
+	return &MTState{slots: make(map[int]*mtSlot)}
+}
+
+// Feed passes an event to the tracker. Events after a SYN_DROPPED are
+// ignored up to the next SYN_REPORT; the state may be stale afterwards
+// until it is synced with the device (see Sync).
+func (s *MTState) Feed(ev *InputEvent) {
+	if s.ProtocolA == nil {
+		s.feed(ev)
+		return
+	}
+
+	for _, converted := range s.ProtocolA.Process(*ev) {
+		s.feed(&converted)
+	}
+}
+
+func (s *MTState) feed(ev *InputEvent) {
+	switch {
+	case ev.Type == EV_SYN && ev.Code == SYN_DROPPED:
+		s.dropping = true
+		return
+	case ev.Type == EV_SYN && ev.Code == SYN_REPORT:
+		if s.dropping {
+			s.dropping = false
+			return
+		}
+		s.report()
+		return
+	case s.dropping || ev.Type != EV_ABS || ev.Code < ABS_MT_SLOT:
+		return
+	}
+
+	if ev.Code == ABS_MT_SLOT {
+		s.slot = int(ev.Value)
+		return
+	}
+
+	sl, ok := s.slots[s.slot]
+	if !ok {
+		sl = &mtSlot{Contact: Contact{Slot: s.slot, TrackingID: -1}}
+		s.slots[s.slot] = sl
+	}
+
+	value := int32(ev.Value)
+	var field *int32
+	switch ev.Code {
+	case ABS_MT_TRACKING_ID:
+		s.track(sl, value)
+		return
+	case ABS_MT_POSITION_X:
+		field = &sl.X
+	case ABS_MT_POSITION_Y:
+		field = &sl.Y
+	case ABS_MT_PRESSURE:
+		field = &sl.Pressure
+	case ABS_MT_TOUCH_MAJOR:
+		field = &sl.TouchMajor
+	case ABS_MT_TOUCH_MINOR:
+		field = &sl.TouchMinor
+	default:
+		return
+	}
+
+	if *field != value {
+		*field = value
+		sl.moved = true
+	}
+}
+
+// Start or end the contact of a slot.
+func (s *MTState) track(sl *mtSlot, id int32) {
+	if sl.active && id != sl.TrackingID {
+		ended := sl.Contact
+		sl.ended = &ended
+		sl.active, sl.started = false, false
+	}
+	if id >= 0 && !sl.active {
+		sl.active, sl.started = true, true
+	}
+	sl.TrackingID = id
+}
+
+func (s *MTState) report() {
+	slots := make([]int, 0, len(s.slots))
+	for slot := range s.slots {
+		slots = append(slots, slot)
+	}
+	sort.Ints(slots)
+
+	for _, slot := range slots {
+		if sl := s.slots[slot]; sl.ended != nil {
+			s.call(s.OnTouchUp, *sl.ended)
+		}
+	}
+	for _, slot := range slots {
+		if sl := s.slots[slot]; sl.started {
+			s.call(s.OnTouchDown, sl.Contact)
+		}
+	}
+	for _, slot := range slots {
+		if sl := s.slots[slot]; sl.active && sl.moved && !sl.started {
+			s.call(s.OnTouchMove, sl.Contact)
+		}
+	}
+
+	for _, sl := range s.slots {
+		sl.started, sl.moved, sl.ended = false, false, nil
+	}
+}
+
+func (s *MTState) call(fn func(Contact), c Contact) {
+	if fn != nil {
+		fn(c)
+	}
+}
+
+// Contacts returns the active contacts by slot, including the changes of
+// the frame being read.
+func (s *MTState) Contacts() []Contact {
+	contacts := make([]Contact, 0)
+
+	for _, sl := range s.slots {
+		if sl.active {
+			contacts = append(contacts, sl.Contact)
+		}
+	}
+	sort.Slice(contacts, func(i, j int) bool { return contacts[i].Slot < contacts[j].Slot })
+
+	return contacts
+}
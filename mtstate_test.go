@@ -0,0 +1,98 @@
+package evdev
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMTState(t *testing.T) {
+	s := NewMTState()
+	now := time.Now()
+
+	calls := make([]string, 0)
+	record := func(kind string) func(Contact) {
+		return func(c Contact) {
+			calls = append(calls, fmt.Sprintf("%s %d/%d %d,%d", kind, c.Slot, c.TrackingID, c.X, c.Y))
+		}
+	}
+	s.OnTouchDown, s.OnTouchMove, s.OnTouchUp = record("down"), record("move"), record("up")
+
+	frame := func(events ...[2]int) []string {
+		calls = calls[:0]
+		for _, e := range events {
+			ev := NewInputEvent(now, EV_ABS, EvCode(e[0]), EvValue(e[1]))
+			s.Feed(&ev)
+		}
+		syn := NewInputEvent(now, EV_SYN, SYN_REPORT, 0)
+		s.Feed(&syn)
+		return append([]string(nil), calls...)
+	}
+	check := func(got []string, want ...string) {
+		t.Helper()
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+
+	// two fingers down; the initial slot is 0
+	check(frame(
+		[2]int{ABS_MT_TRACKING_ID, 10}, [2]int{ABS_MT_POSITION_X, 100}, [2]int{ABS_MT_POSITION_Y, 200},
+		[2]int{ABS_MT_SLOT, 1}, [2]int{ABS_MT_TRACKING_ID, 11}, [2]int{ABS_MT_POSITION_X, 300}, [2]int{ABS_MT_POSITION_Y, 400},
+	), "down 0/10 100,200", "down 1/11 300,400")
+
+	// values apply to the last selected slot
+	check(frame([2]int{ABS_MT_POSITION_X, 310}), "move 1/11 310,400")
+
+	// slot 0 lifted while slot 1 is replaced by a new contact
+	check(frame(
+		[2]int{ABS_MT_SLOT, 0}, [2]int{ABS_MT_TRACKING_ID, -1},
+		[2]int{ABS_MT_SLOT, 1}, [2]int{ABS_MT_TRACKING_ID, 12}, [2]int{ABS_MT_POSITION_X, 50},
+	), "up 0/10 100,200", "up 1/11 310,400", "down 1/12 50,400")
+
+	if c := s.Contacts(); len(c) != 1 || c[0].TrackingID != 12 {
+		t.Errorf("unexpected contacts %+v", c)
+	}
+
+	// events after SYN_DROPPED are ignored up to the next SYN_REPORT
+	dropped := NewInputEvent(now, EV_SYN, SYN_DROPPED, 0)
+	s.Feed(&dropped)
+	check(frame([2]int{ABS_MT_POSITION_X, 60}))
+	check(frame([2]int{ABS_MT_POSITION_X, 70}), "move 1/12 70,400")
+}
+
+func TestMTStateProtocolA(t *testing.T) {
+	s := NewMTState()
+	s.ProtocolA = NewProtocolAConverter(50)
+	now := time.Now()
+
+	downs, ups := 0, 0
+	s.OnTouchDown = func(Contact) { downs++ }
+	s.OnTouchUp = func(Contact) { ups++ }
+
+	frame := func(contacts ...[2]EvValue) {
+		for _, pos := range contacts {
+			for _, ev := range []InputEvent{
+				NewInputEvent(now, EV_ABS, ABS_MT_POSITION_X, pos[0]),
+				NewInputEvent(now, EV_ABS, ABS_MT_POSITION_Y, pos[1]),
+				NewInputEvent(now, EV_SYN, SYN_MT_REPORT, 0),
+			} {
+				s.Feed(&ev)
+			}
+		}
+		syn := NewInputEvent(now, EV_SYN, SYN_REPORT, 0)
+		s.Feed(&syn)
+	}
+
+	frame([2]EvValue{100, 100}, [2]EvValue{500, 500})
+	frame([2]EvValue{505, 500}, [2]EvValue{110, 100})
+	if c := s.Contacts(); downs != 2 || len(c) != 2 || c[0].X != 110 || c[1].X != 505 {
+		t.Errorf("unexpected contacts %+v after %d downs", c, downs)
+	}
+
+	frame()
+	if ups != 2 || len(s.Contacts()) != 0 {
+		t.Errorf("expected all contacts up, got %d ups", ups)
+	}
+}
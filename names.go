@@ -0,0 +1,52 @@
+package evdev
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TypeName returns the name of an event type, e.g. "EV_KEY". Unknown event
+// types are named after their number.
+func TypeName(evType int) string {
+	if name, ok := EV[evType]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("EV_%#02x", evType)
+}
+
+// CodeName returns the canonical name of an event code of the given type,
+// e.g. "KEY_A" or "BTN_LEFT" for EV_KEY codes. Where several names share a
+// code the preferred one is returned (KEY_MUTE rather than
+// KEY_MIN_INTERESTING). Codes without a name are named after their number,
+// e.g. "KEY_0x2f0", so the result is never empty.
+func CodeName(evType, code int) string {
+	if name, ok := ByEventType[evType][code]; ok {
+		return name
+	}
+
+	prefix := strings.TrimPrefix(TypeName(evType), "EV_")
+	return fmt.Sprintf("%s_%#03x", prefix, code)
+}
+
+// Look up the value of a key, button or axis name such as "KEY_A", also
+// accepting the names of codes without a name returned by CodeName, e.g.
+// "KEY_0x2f0". Names are looked up in inputCodes, which is kept in
+// evdev_minimal builds.
+func inputCodeByName(name string) (int, bool) {
+	if code, ok := inputCodes[name]; ok {
+		return code, true
+	}
+
+	i := strings.IndexByte(name, '_')
+	if i < 0 || !strings.HasPrefix(name[i+1:], "0x") && !strings.HasPrefix(name[i+1:], "0X") {
+		return 0, false
+	}
+	code, err := strconv.ParseUint(name[i+1:], 0, 16)
+	if err != nil {
+		return 0, false
+	}
+
+	return int(code), true
+}
@@ -0,0 +1,26 @@
+//go:build evdev_minimal
+
+package evdev
+
+import "testing"
+
+func named(full, minimal string) string {
+	return minimal
+}
+
+func TestMinimalNames(t *testing.T) {
+	if name := CodeName(EV_KEY, KEY_A); name != "KEY_0x01e" {
+		t.Errorf("unexpected code name %q", name)
+	}
+	if name := TypeName(EV_KEY); name != "EV_KEY" {
+		t.Errorf("unexpected type name %q", name)
+	}
+
+	// names are parsed, and so are the numeric names
+	for _, s := range []string{"ctrl+a", "ctrl+0x01e", "ctrl+KEY_0x01e"} {
+		chord, err := ParseChord(s)
+		if err != nil || chord.Key != KEY_A {
+			t.Errorf("%s: got %v, %v", s, chord, err)
+		}
+	}
+}
@@ -0,0 +1,373 @@
+//go:build linux
+
+package evdev
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A netstream forwards the events of input devices across a network, e.g.
+// to share a keyboard between machines. A NetstreamServer streams the
+// events of local devices to NetstreamClients as a capture (see
+// CaptureWriter). Connections use TLS with mutual authentication, both
+// peers presenting a certificate signed by a CA the other trusts, and the
+// client then proves knowledge of a shared token. A stream of keystrokes is
+// thereby neither readable nor injectable by others on the network.
+
+// ErrNetstreamAuth is returned when a peer fails the token handshake.
+var ErrNetstreamAuth = errors.New("evdev: netstream authentication failed")
+
+const (
+	netstreamHello = "EVNET1 " // followed by the token and a newline
+	netstreamOK    = "OK\n"
+)
+
+// NetstreamConfig configures the security of a netstream peer.
+type NetstreamConfig struct {
+	// TLS holds the certificate of the peer and the CAs it trusts for the
+	// other peer: ClientCAs on servers, RootCAs (or the system roots) on
+	// clients. Servers always require and verify client certificates.
+	TLS *tls.Config
+
+	// Token is a secret shared by server and clients.
+	Token string
+
+	// HandshakeTimeout limits the time to authenticate, 10s if 0.
+	HandshakeTimeout time.Duration
+}
+
+// Check the configuration and get the TLS configuration to use.
+func (c *NetstreamConfig) tlsConfig(server bool) (*tls.Config, error) {
+	if c.TLS == nil || len(c.TLS.Certificates) == 0 && c.TLS.GetCertificate == nil && c.TLS.GetClientCertificate == nil {
+		return nil, errors.New("evdev: netstream requires a TLS certificate")
+	}
+	if c.Token == "" {
+		return nil, errors.New("evdev: netstream requires a token")
+	}
+
+	cfg := c.TLS.Clone()
+	if cfg.MinVersion < tls.VersionTLS12 {
+		cfg.MinVersion = tls.VersionTLS12
+	}
+	if server {
+		if cfg.ClientCAs == nil {
+			return nil, errors.New("evdev: netstream server requires client CAs")
+		}
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return cfg, nil
+}
+
+func (c *NetstreamConfig) handshakeTimeout() time.Duration {
+	if c.HandshakeTimeout <= 0 {
+		return 10 * time.Second
+	}
+
+	return c.HandshakeTimeout
+}
+
+// NetstreamServer streams the events of local devices to authenticated
+// clients. Events are passed to Send, e.g. from a Poller reading the
+// devices, and reach the clients connected at that time.
+type NetstreamServer struct {
+	Config NetstreamConfig
+
+	// WriteTimeout limits the time to send a frame to a client; slower
+	// clients are disconnected rather than holding up the others.
+	WriteTimeout time.Duration
+
+	// OnError is called with the errors of clients failing to connect or
+	// disconnected, may be nil.
+	OnError func(addr net.Addr, err error)
+
+	devices []*InputDevice
+
+	mu      sync.Mutex
+	clients map[*netstreamPeer]bool
+}
+
+// A client connected to a server. Events are encoded into buf and each
+// complete frame is queued to a goroutine writing it to conn, so that slow
+// clients hold up neither Send nor each other.
+type netstreamPeer struct {
+	conn   net.Conn
+	buf    bytes.Buffer
+	cw     *CaptureWriter
+	ids    map[*InputDevice]uint16
+	frames chan []byte
+}
+
+// Frames queued for a client before it is considered too slow.
+const netstreamQueue = 64
+
+// ErrNetstreamSlow is reported for clients disconnected because they did not
+// keep up with the events.
+var ErrNetstreamSlow = errors.New("evdev: netstream client too slow")
+
+// NewNetstreamServer creates a server streaming the events of devices, with
+// a write timeout of a second.
+func NewNetstreamServer(cfg NetstreamConfig, devices ...*InputDevice) *NetstreamServer {
+	return &NetstreamServer{
+		Config:       cfg,
+		WriteTimeout: time.Second,
+		devices:      devices,
+		clients:      make(map[*netstreamPeer]bool),
+	}
+}
+
+// Serve accepts clients on ln until ctx is done or accepting fails. The
+// listener is closed on return, connected clients are not (see Close).
+func (s *NetstreamServer) Serve(ctx context.Context, ln net.Listener) error {
+	cfg, err := s.Config.tlsConfig(true)
+	if err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		ln.Close()
+	}()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+
+		go func() {
+			if err := s.accept(tls.Server(conn, cfg)); err != nil {
+				conn.Close()
+				s.report(conn.RemoteAddr(), err)
+			}
+		}()
+	}
+}
+
+// Authenticate a client and describe the devices to it.
+func (s *NetstreamServer) accept(conn *tls.Conn) error {
+	conn.SetDeadline(time.Now().Add(s.Config.handshakeTimeout()))
+	if err := conn.Handshake(); err != nil {
+		return err
+	}
+
+	// the token line is short, don't read more of a misbehaving client
+	line, err := bufio.NewReader(io.LimitReader(conn, 1024)).ReadString('\n')
+	if err != nil || !strings.HasPrefix(line, netstreamHello) {
+		return ErrNetstreamAuth
+	}
+	token := strings.TrimSuffix(strings.TrimPrefix(line, netstreamHello), "\n")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(s.Config.Token)) != 1 {
+		return ErrNetstreamAuth
+	}
+	if _, err = io.WriteString(conn, netstreamOK); err != nil {
+		return err
+	}
+
+	peer := &netstreamPeer{
+		conn:   conn,
+		ids:    make(map[*InputDevice]uint16),
+		frames: make(chan []byte, netstreamQueue),
+	}
+	if peer.cw, err = NewCaptureWriter(&peer.buf); err != nil {
+		return err
+	}
+	for _, dev := range s.devices {
+		if peer.ids[dev], err = peer.cw.AddDevice(dev); err != nil {
+			return err
+		}
+	}
+	if err = peer.cw.Flush(); err != nil {
+		return err
+	}
+	if _, err = peer.buf.WriteTo(conn); err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Time{})
+
+	s.mu.Lock()
+	s.clients[peer] = true
+	s.mu.Unlock()
+
+	go s.write(peer)
+	return nil
+}
+
+// Write the frames queued for a client until it is disconnected.
+func (s *NetstreamServer) write(peer *netstreamPeer) {
+	for frame := range peer.frames {
+		peer.conn.SetWriteDeadline(time.Now().Add(s.WriteTimeout))
+		if _, err := peer.conn.Write(frame); err != nil {
+			s.mu.Lock()
+			dropped := s.drop(peer)
+			s.mu.Unlock()
+
+			// not an error if the client was disconnected meanwhile
+			if dropped {
+				s.report(peer.conn.RemoteAddr(), err)
+			}
+			return
+		}
+	}
+}
+
+// Disconnect a client, with s.mu held. Reports whether it was connected.
+func (s *NetstreamServer) drop(peer *netstreamPeer) bool {
+	if !s.clients[peer] {
+		return false
+	}
+
+	delete(s.clients, peer)
+	close(peer.frames)
+	peer.conn.Close()
+
+	return true
+}
+
+// Send streams an event of one of the devices of the server to all
+// clients. Frames are sent once complete, with their SYN_REPORT, in a
+// single write.
+func (s *NetstreamServer) Send(dev *InputDevice, ev *InputEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for peer := range s.clients {
+		id, ok := peer.ids[dev]
+		if !ok {
+			continue
+		}
+
+		err := peer.cw.WriteEvent(id, ev)
+		if err == nil && ev.Type == EV_SYN {
+			err = peer.cw.Flush()
+		}
+		if err == nil && ev.Type == EV_SYN {
+			frame := append([]byte(nil), peer.buf.Bytes()...)
+			peer.buf.Reset()
+
+			select {
+			case peer.frames <- frame:
+			default:
+				err = ErrNetstreamSlow
+			}
+		}
+		if err != nil {
+			s.drop(peer)
+			go s.report(peer.conn.RemoteAddr(), err)
+		}
+	}
+}
+
+// Clients returns the number of connected clients.
+func (s *NetstreamServer) Clients() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.clients)
+}
+
+// Close disconnects all clients.
+func (s *NetstreamServer) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for peer := range s.clients {
+		s.drop(peer)
+	}
+
+	return nil
+}
+
+func (s *NetstreamServer) report(addr net.Addr, err error) {
+	if s.OnError != nil {
+		s.OnError(addr, err)
+	}
+}
+
+// NetstreamClient receives the events streamed by a NetstreamServer.
+type NetstreamClient struct {
+	conn net.Conn
+	cr   *CaptureReader
+}
+
+// DialNetstream connects to a netstream server at addr and authenticates.
+func DialNetstream(ctx context.Context, addr string, cfg NetstreamConfig) (*NetstreamClient, error) {
+	tlsCfg, err := cfg.tlsConfig(false)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.handshakeTimeout())
+	defer cancel()
+
+	d := tls.Dialer{Config: tlsCfg}
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := authenticate(conn, cfg)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Present the token and start reading the capture.
+func authenticate(conn net.Conn, cfg NetstreamConfig) (*NetstreamClient, error) {
+	conn.SetDeadline(time.Now().Add(cfg.handshakeTimeout()))
+
+	if _, err := io.WriteString(conn, netstreamHello+cfg.Token+"\n"); err != nil {
+		return nil, err
+	}
+
+	r := bufio.NewReader(conn)
+	reply, err := r.ReadString('\n')
+	if err != nil || reply != netstreamOK {
+		return nil, ErrNetstreamAuth
+	}
+
+	cr, err := NewCaptureReader(r)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+
+	return &NetstreamClient{conn: conn, cr: cr}, nil
+}
+
+// Next returns the next record of the stream: the description of a device
+// or one of its events.
+func (c *NetstreamClient) Next() (*CaptureRecord, error) {
+	return c.cr.Next()
+}
+
+// Device returns the description of a device of the stream.
+func (c *NetstreamClient) Device(id uint16) *CaptureDevice {
+	return c.cr.Device(id)
+}
+
+// Close disconnects from the server.
+func (c *NetstreamClient) Close() error {
+	return c.conn.Close()
+}
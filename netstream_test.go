@@ -0,0 +1,210 @@
+//go:build linux
+
+package evdev
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+)
+
+// Create a CA and certificates signed by it for the server and a client.
+func netstreamCerts(t *testing.T) (pool *x509.CertPool, server, client tls.Certificate) {
+	key := func() *ecdsa.PrivateKey {
+		k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return k
+	}
+	caKey := key()
+	ca := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, ca, ca, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, _ = x509.ParseCertificate(caDER)
+	pool = x509.NewCertPool()
+	pool.AddCert(ca)
+
+	leaf := func(serial int64, usage x509.ExtKeyUsage) tls.Certificate {
+		k := key()
+		tmpl := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: "peer"},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+			IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, &k.PublicKey, caKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: k}
+	}
+
+	return pool, leaf(2, x509.ExtKeyUsageServerAuth), leaf(3, x509.ExtKeyUsageClientAuth)
+}
+
+func TestNetstream(t *testing.T) {
+	pool, serverCert, clientCert := netstreamCerts(t)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("no loopback network:", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	kbd := &InputDevice{Name: "kbd", Capabilities: map[CapabilityType][]CapabilityCode{
+		{EV_KEY, "EV_KEY"}: {{KEY_A, "KEY_A"}},
+	}}
+	s := NewNetstreamServer(NetstreamConfig{
+		TLS:   &tls.Config{Certificates: []tls.Certificate{serverCert}, ClientCAs: pool},
+		Token: "secret",
+	}, kbd)
+	defer s.Close()
+	go s.Serve(ctx, ln)
+
+	dial := func(token string, certs ...tls.Certificate) (*NetstreamClient, error) {
+		return DialNetstream(ctx, ln.Addr().String(), NetstreamConfig{
+			TLS:   &tls.Config{Certificates: certs, RootCAs: pool},
+			Token: token,
+		})
+	}
+
+	if _, err := dial("guess", clientCert); err != ErrNetstreamAuth {
+		t.Errorf("expected ErrNetstreamAuth for a wrong token, got %v", err)
+	}
+	if _, err := dial("secret"); err == nil {
+		t.Error("expected an error without a client certificate")
+	}
+
+	c, err := dial("secret", clientCert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	rec, err := c.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Kind != CaptureDeviceRecord || rec.Device.Name != "kbd" {
+		t.Fatalf("expected the device first, got %+v", rec)
+	}
+
+	for deadline := time.Now().Add(5 * time.Second); s.Clients() == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("client not registered")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	now := time.Unix(1000, 0)
+	for _, ev := range []InputEvent{
+		NewInputEvent(now, EV_KEY, KEY_A, 1),
+		NewInputEvent(now, EV_SYN, SYN_REPORT, 0),
+	} {
+		s.Send(kbd, &ev)
+	}
+
+	// replayed through a jitter buffer
+	rctx, stop := context.WithTimeout(ctx, 5*time.Second)
+	defer stop()
+	got := make([]InputEvent, 0)
+	err = c.Replay(rctx, NewJitterBuffer(5*time.Millisecond), func(rec *CaptureRecord) error {
+		got = append(got, rec.Event)
+		if len(got) == 2 {
+			stop()
+		}
+		return nil
+	})
+	if err != context.Canceled {
+		t.Fatalf("replay ended with %v", err)
+	}
+	if got[0].Code != KEY_A || got[0].Value != 1 || got[1].Code != SYN_REPORT {
+		t.Errorf("unexpected events %v", got)
+	}
+}
+
+func TestNetstreamLargeFrame(t *testing.T) {
+	pool, serverCert, clientCert := netstreamCerts(t)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("no loopback network:", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	kbd := &InputDevice{Name: "kbd"}
+	s := NewNetstreamServer(NetstreamConfig{
+		TLS:   &tls.Config{Certificates: []tls.Certificate{serverCert}, ClientCAs: pool},
+		Token: "secret",
+	}, kbd)
+	s.WriteTimeout = 50 * time.Millisecond
+	defer s.Close()
+	go s.Serve(ctx, ln)
+
+	c, err := DialNetstream(ctx, ln.Addr().String(), NetstreamConfig{
+		TLS:   &tls.Config{Certificates: []tls.Certificate{clientCert}, RootCAs: pool},
+		Token: "secret",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if _, err := c.Next(); err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); s.Clients() == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("client not registered")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// frames far apart, the second larger than any write buffer
+	now := time.Unix(1000, 0)
+	for _, n := range []int{1, 500} {
+		for i := 0; i < n; i++ {
+			ev := NewInputEvent(now, EV_MSC, MSC_SCAN, EvValue(i))
+			s.Send(kbd, &ev)
+		}
+		syn := NewInputEvent(now, EV_SYN, SYN_REPORT, 0)
+		s.Send(kbd, &syn)
+
+		for i := 0; i <= n; i++ {
+			rec, err := c.Next()
+			if err != nil {
+				t.Fatalf("frame of %d events: record %d: %v", n, i, err)
+			}
+			if i < n && rec.Event.Value != EvValue(i) {
+				t.Fatalf("frame of %d events: unexpected record %d %+v", n, i, rec)
+			}
+		}
+		time.Sleep(2 * s.WriteTimeout)
+	}
+	if s.Clients() != 1 {
+		t.Error("expected the client still connected")
+	}
+}
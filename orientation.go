@@ -0,0 +1,136 @@
+//go:build linux
+
+package evdev
+
+import "math"
+
+// Orientation is the orientation of a screen, named after the edge of the
+// screen that points up.
+type Orientation int
+
+const (
+	OrientationUndefined Orientation = iota // lying flat or not known yet
+	OrientationNormal
+	OrientationBottomUp
+	OrientationLeftUp
+	OrientationRightUp
+)
+
+var orientationNames = map[Orientation]string{
+	OrientationUndefined: "undefined",
+	OrientationNormal:    "normal",
+	OrientationBottomUp:  "bottom-up",
+	OrientationLeftUp:    "left-up",
+	OrientationRightUp:   "right-up",
+}
+
+func (o Orientation) String() string {
+	return orientationNames[o]
+}
+
+// IsAccelerometer reports whether the device is an accelerometer: it has
+// INPUT_PROP_ACCELEROMETER, or it looks like one with ABS_X, ABS_Y and ABS_Z
+// axes but no multi-touch axes and no keys.
+func (dev *InputDevice) IsAccelerometer() bool {
+	if dev.HasProperty(INPUT_PROP_ACCELEROMETER) {
+		return true
+	}
+	if dev.CodesFor(EV_KEY) != nil {
+		return false
+	}
+
+	axes := 0
+	for _, code := range dev.CodesFor(EV_ABS) {
+		switch {
+		case code >= ABS_MT_SLOT:
+			return false
+		case code == ABS_X || code == ABS_Y || code == ABS_Z:
+			axes++
+		}
+	}
+
+	return axes == 3
+}
+
+// OrientationDetector derives the orientation of a screen from the events
+// of an accelerometer mounted with it. Gravity pulling towards +Y is the
+// normal orientation; InvertX and InvertY adapt other mountings. The
+// orientation only changes once the tilt exceeds 45 degrees by Hysteresis
+// degrees, and is kept while the device lies flat.
+type OrientationDetector struct {
+	InvertX, InvertY bool
+	Hysteresis       float64 // degrees
+	FlatAngle        float64 // tilt from horizontal below which the device is flat, in degrees
+
+	accel       [3]float64
+	orientation Orientation
+}
+
+// NewOrientationDetector creates a detector with a hysteresis of 10 degrees
+// and a flat angle of 20 degrees.
+func NewOrientationDetector() *OrientationDetector {
+	return &OrientationDetector{Hysteresis: 10, FlatAngle: 20}
+}
+
+// Orientation returns the current orientation.
+func (d *OrientationDetector) Orientation() Orientation {
+	return d.orientation
+}
+
+// Feed passes an accelerometer event to the detector. At a SYN_REPORT that
+// changes the orientation it returns the new orientation and true.
+func (d *OrientationDetector) Feed(ev *InputEvent) (Orientation, bool) {
+	switch {
+	case ev.Type == EV_ABS && ev.Code <= ABS_Z:
+		d.accel[ev.Code] = float64(ev.Value)
+	case ev.Type == EV_SYN && ev.Code == SYN_REPORT:
+		if o := d.detect(); o != d.orientation && o != OrientationUndefined {
+			d.orientation = o
+			return o, true
+		}
+	}
+
+	return d.orientation, false
+}
+
+func (d *OrientationDetector) detect() Orientation {
+	x, y, z := d.accel[0], d.accel[1], d.accel[2]
+	if d.InvertX {
+		x = -x
+	}
+	if d.InvertY {
+		y = -y
+	}
+
+	// tilt of the screen plane against the horizontal
+	tilt := math.Atan2(math.Hypot(x, y), math.Abs(z)) * 180 / math.Pi
+	if tilt < d.FlatAngle {
+		return OrientationUndefined
+	}
+
+	// 0 degrees with gravity along +Y, counter-clockwise
+	angle := math.Atan2(x, y) * 180 / math.Pi
+	candidates := []struct {
+		orientation Orientation
+		center      float64
+	}{
+		{OrientationNormal, 0},
+		{OrientationRightUp, 90},
+		{OrientationBottomUp, 180},
+		{OrientationLeftUp, -90},
+	}
+
+	for _, c := range candidates {
+		diff := math.Abs(math.Remainder(angle-c.center, 360))
+
+		limit := 45.0
+		if d.orientation != OrientationUndefined && c.orientation != d.orientation {
+			limit -= d.Hysteresis
+		}
+		if diff <= limit {
+			return c.orientation
+		}
+	}
+
+	return d.orientation
+}
@@ -0,0 +1,193 @@
+//go:build linux
+
+package evdev
+
+import (
+	"fmt"
+	"time"
+)
+
+// KeyboardLayout maps characters to the chords typing them with a given
+// keymap, so that text can be typed on a virtual keyboard.
+type KeyboardLayout map[rune]Chord
+
+// USKeyboardLayout returns the layout of the US keymap.
+func USKeyboardLayout() KeyboardLayout {
+	layout := KeyboardLayout{
+		' ': {0, KEY_SPACE}, '\n': {0, KEY_ENTER}, '\t': {0, KEY_TAB},
+		'0': {0, KEY_0}, ')': {ModShift, KEY_0},
+		'-': {0, KEY_MINUS}, '_': {ModShift, KEY_MINUS},
+		'=': {0, KEY_EQUAL}, '+': {ModShift, KEY_EQUAL},
+		'[': {0, KEY_LEFTBRACE}, '{': {ModShift, KEY_LEFTBRACE},
+		']': {0, KEY_RIGHTBRACE}, '}': {ModShift, KEY_RIGHTBRACE},
+		';': {0, KEY_SEMICOLON}, ':': {ModShift, KEY_SEMICOLON},
+		'\'': {0, KEY_APOSTROPHE}, '"': {ModShift, KEY_APOSTROPHE},
+		'`': {0, KEY_GRAVE}, '~': {ModShift, KEY_GRAVE},
+		'\\': {0, KEY_BACKSLASH}, '|': {ModShift, KEY_BACKSLASH},
+		',': {0, KEY_COMMA}, '<': {ModShift, KEY_COMMA},
+		'.': {0, KEY_DOT}, '>': {ModShift, KEY_DOT},
+		'/': {0, KEY_SLASH}, '?': {ModShift, KEY_SLASH},
+	}
+
+	for i, r := range "!@#$%^&*(" {
+		layout['1'+rune(i)] = Chord{0, EvCode(KEY_1 + i)}
+		layout[r] = Chord{ModShift, EvCode(KEY_1 + i)}
+	}
+	for r := 'a'; r <= 'z'; r++ {
+		code, _ := parseKeyName(string(r))
+		layout[r] = Chord{0, code}
+		layout[r-'a'+'A'] = Chord{ModShift, code}
+	}
+
+	return layout
+}
+
+// OnScreenKeyboard drives a virtual keyboard on behalf of a GUI, e.g. the
+// on-screen keyboard of a touchscreen kiosk. Keys are pressed by name and
+// modifiers are sticky: tapping a modifier latches it for the next key,
+// tapping it again unlatches it. Events are written to Writer, typically a
+// uinput device.
+type OnScreenKeyboard struct {
+	Writer EventWriter
+	Layout KeyboardLayout // layout used by TypeText
+
+	latched Modifiers
+}
+
+// NewOnScreenKeyboard creates an on-screen keyboard writing to w, typing
+// text with the US layout.
+func NewOnScreenKeyboard(w EventWriter) *OnScreenKeyboard {
+	return &OnScreenKeyboard{Writer: w, Layout: USKeyboardLayout()}
+}
+
+// Latched returns the latched modifiers, for the GUI to highlight them.
+func (k *OnScreenKeyboard) Latched() Modifiers {
+	return k.latched
+}
+
+// Press presses the named key, e.g. "a", "enter" or "KEY_F1".
+func (k *OnScreenKeyboard) Press(name string) error {
+	code, err := k.parse(name)
+	if err != nil {
+		return err
+	}
+	return k.write(code, EvValue(KeyDown))
+}
+
+// Release releases the named key.
+func (k *OnScreenKeyboard) Release(name string) error {
+	code, err := k.parse(name)
+	if err != nil {
+		return err
+	}
+	return k.write(code, EvValue(KeyUp))
+}
+
+// Tap presses and releases the named key. Tapping a modifier toggles its
+// latch; tapping any other key applies and then clears the latched modifiers.
+func (k *OnScreenKeyboard) Tap(name string) error {
+	code, err := k.parse(name)
+	if err != nil {
+		return err
+	}
+
+	if mod := ModifierOf(code); mod != 0 {
+		k.latched ^= mod
+		return nil
+	}
+
+	mods := k.latched
+	k.latched = 0
+	return k.tapChord(Chord{Modifiers: mods, Key: code})
+}
+
+// TypeText types text according to Layout, together with any latched
+// modifiers for the first character.
+func (k *OnScreenKeyboard) TypeText(text string) error {
+	for _, r := range text {
+		chord, ok := k.Layout[r]
+		if !ok {
+			return fmt.Errorf("evdev: no key for %q in layout", r)
+		}
+
+		chord.Modifiers |= k.latched
+		k.latched = 0
+		if err := k.tapChord(chord); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (k *OnScreenKeyboard) tapChord(chord Chord) error {
+	mods := modifierCodes(chord.Modifiers)
+
+	for _, code := range mods {
+		if err := k.write(code, EvValue(KeyDown)); err != nil {
+			return err
+		}
+	}
+	if err := k.write(chord.Key, EvValue(KeyDown)); err != nil {
+		return err
+	}
+	if err := k.write(chord.Key, EvValue(KeyUp)); err != nil {
+		return err
+	}
+	for i := len(mods) - 1; i >= 0; i-- {
+		if err := k.write(mods[i], EvValue(KeyUp)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (k *OnScreenKeyboard) parse(name string) (EvCode, error) {
+	code, ok := parseKeyName(name)
+	if !ok {
+		return 0, fmt.Errorf("evdev: unknown key %q", name)
+	}
+	return code, nil
+}
+
+// Write a key event followed by a SYN_REPORT.
+func (k *OnScreenKeyboard) write(code EvCode, value EvValue) error {
+	now := time.Now()
+
+	ev := NewInputEvent(now, EV_KEY, code, value)
+	if err := k.Writer.WriteEvent(&ev); err != nil {
+		return err
+	}
+
+	syn := NewInputEvent(now, EV_SYN, SYN_REPORT, 0)
+	return k.Writer.WriteEvent(&syn)
+}
+
+// Get the keys to press for a set of modifiers, preferring the left key of
+// modifiers held on either side.
+func modifierCodes(mods Modifiers) []EvCode {
+	codes := make([]EvCode, 0)
+
+	for _, n := range modifierNames {
+		left := n.mod & (ModLeftShift | ModLeftCtrl | ModLeftAlt | ModLeftMeta)
+		switch {
+		case mods&n.mod == 0:
+		case mods&n.mod == n.mod&^left:
+			codes = append(codes, modifierKey(n.mod&^left))
+		default:
+			codes = append(codes, modifierKey(left))
+		}
+	}
+
+	return codes
+}
+
+func modifierKey(mod Modifiers) EvCode {
+	for code, m := range modifierKeys {
+		if m == mod {
+			return code
+		}
+	}
+	return 0
+}
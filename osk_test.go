@@ -0,0 +1,75 @@
+//go:build linux
+
+package evdev
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOnScreenKeyboard(t *testing.T) {
+	sink := new(eventRecorder)
+	k := NewOnScreenKeyboard(sink)
+
+	// key events as codes, negative for releases
+	keys := func() []int {
+		codes := make([]int, 0)
+		for _, ev := range *sink {
+			switch {
+			case ev.Type != EV_KEY:
+			case ev.Value == EvValue(KeyUp):
+				codes = append(codes, -int(ev.Code))
+			default:
+				codes = append(codes, int(ev.Code))
+			}
+		}
+		*sink = (*sink)[:0]
+		return codes
+	}
+
+	tests := []struct {
+		name string
+		do   func() error
+		want []int
+	}{
+		{"tap", func() error { return k.Tap("a") }, []int{KEY_A, -KEY_A}},
+		{"latch", func() error { return k.Tap("leftshift") }, []int{}},
+		{"latched tap", func() error { return k.Tap("b") }, []int{KEY_LEFTSHIFT, KEY_B, -KEY_B, -KEY_LEFTSHIFT}},
+		{"unlatched", func() error { return k.Tap("b") }, []int{KEY_B, -KEY_B}},
+		{"press", func() error { return k.Press("KEY_F1") }, []int{KEY_F1}},
+		{"release", func() error { return k.Release("KEY_F1") }, []int{-KEY_F1}},
+		{"text", func() error { return k.TypeText("Hi!") }, []int{
+			KEY_LEFTSHIFT, KEY_H, -KEY_H, -KEY_LEFTSHIFT,
+			KEY_I, -KEY_I,
+			KEY_LEFTSHIFT, KEY_1, -KEY_1, -KEY_LEFTSHIFT,
+		}},
+	}
+	for _, tt := range tests {
+		if err := tt.do(); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+		if got := keys(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// tapping a latched modifier again unlatches it
+	k.Tap("leftctrl")
+	if k.Latched() != ModLeftCtrl {
+		t.Errorf("expected ctrl to be latched, got %v", k.Latched())
+	}
+	k.Tap("leftctrl")
+	if k.Latched() != 0 {
+		t.Errorf("expected no latched modifiers, got %v", k.Latched())
+	}
+
+	if err := k.Tap("nosuchkey"); err == nil {
+		t.Error("expected an error for an unknown key")
+	}
+	if err := k.TypeText("é"); err == nil {
+		t.Error("expected an error for a character missing from the layout")
+	}
+	if got := keys(); len(got) != 0 {
+		t.Errorf("expected nothing typed, got %v", got)
+	}
+}
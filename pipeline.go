@@ -0,0 +1,109 @@
+//go:build linux
+
+package evdev
+
+import "time"
+
+// FrameFunc transforms a frame of events, the events between two
+// SYN_REPORTs, without the SYN_REPORT itself. Returning no events drops the
+// frame.
+type FrameFunc func(frame []InputEvent) []InputEvent
+
+// Event pipeline of a Proxy: events pass through a filter one by one, are
+// collected into frames, pass through a frame function and are written to
+// a sink. Frames are always terminated by a SYN_REPORT and empty frames are
+// dropped.
+type eventPipeline struct {
+	filter  Filter
+	frameFn FrameFunc
+	sink    EventWriter
+
+	frame []InputEvent
+	down  map[EvCode]bool // keys held on the sink
+}
+
+func newEventPipeline(filter Filter, frameFn FrameFunc, sink EventWriter) *eventPipeline {
+	return &eventPipeline{filter: filter, frameFn: frameFn, sink: sink, down: make(map[EvCode]bool)}
+}
+
+// Pass an event through the pipeline.
+func (p *eventPipeline) process(ev InputEvent) error {
+	if p.filter == nil {
+		return p.forward([]InputEvent{ev})
+	}
+
+	return p.forward(p.filter.Process(ev))
+}
+
+// Tick a timed filter, passing the events it generates on.
+func (p *eventPipeline) tick(now time.Time) error {
+	tf, ok := p.filter.(TimedFilter)
+	if !ok {
+		return nil
+	}
+
+	return p.forward(tf.Tick(now))
+}
+
+// Collect events into frames, writing each completed frame.
+func (p *eventPipeline) forward(events []InputEvent) error {
+	for _, ev := range events {
+		if ev.Type != EV_SYN || ev.Code != SYN_REPORT {
+			p.frame = append(p.frame, ev)
+			continue
+		}
+
+		frame := p.frame
+		p.frame = nil
+		if p.frameFn != nil {
+			frame = p.frameFn(frame)
+		}
+		if err := p.write(frame, ev); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Write a frame followed by syn, unless it is empty.
+func (p *eventPipeline) write(frame []InputEvent, syn InputEvent) error {
+	if len(frame) == 0 {
+		return nil
+	}
+
+	for i := range frame {
+		if err := p.sink.WriteEvent(&frame[i]); err != nil {
+			return err
+		}
+		if frame[i].Type == EV_KEY {
+			p.down[frame[i].Code] = frame[i].Value != EvValue(KeyUp)
+		}
+	}
+
+	return p.sink.WriteEvent(&syn)
+}
+
+// Get the keys held on the sink.
+func (p *eventPipeline) heldKeys() []EvCode {
+	held := make([]EvCode, 0)
+	for code, down := range p.down {
+		if down {
+			held = append(held, code)
+		}
+	}
+
+	return held
+}
+
+// Release keys on the sink.
+func (p *eventPipeline) release(keys []EvCode) error {
+	now := time.Now()
+
+	releases := make([]InputEvent, 0, len(keys))
+	for _, code := range keys {
+		releases = append(releases, NewInputEvent(now, EV_KEY, code, EvValue(KeyUp)))
+	}
+
+	return p.write(releases, NewInputEvent(now, EV_SYN, SYN_REPORT, 0))
+}
@@ -0,0 +1,159 @@
+//go:build linux
+
+package evdev
+
+import (
+	"context"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+func TestEventPipeline(t *testing.T) {
+	now := time.Now()
+	key := func(code EvCode, value EvValue) InputEvent { return NewInputEvent(now, EV_KEY, code, value) }
+	syn := NewInputEvent(now, EV_SYN, SYN_REPORT, 0)
+
+	// KEY_X is dropped, KEY_Y becomes KEY_Z; frames with KEY_Q are dropped
+	filter := FilterFunc(func(ev InputEvent) []InputEvent {
+		switch {
+		case ev.Type == EV_KEY && ev.Code == KEY_X:
+			return nil
+		case ev.Type == EV_KEY && ev.Code == KEY_Y:
+			ev.Code = KEY_Z
+		}
+		return []InputEvent{ev}
+	})
+	frameFn := func(frame []InputEvent) []InputEvent {
+		for _, ev := range frame {
+			if ev.Code == KEY_Q {
+				return nil
+			}
+		}
+		return frame
+	}
+
+	tests := []struct {
+		name string
+		in   []InputEvent
+		want [][3]int
+		held []EvCode
+	}{
+		{
+			"frame",
+			[]InputEvent{key(KEY_A, 1), key(KEY_B, 1), syn},
+			[][3]int{{EV_KEY, KEY_A, 1}, {EV_KEY, KEY_B, 1}, {EV_SYN, SYN_REPORT, 0}},
+			[]EvCode{KEY_A, KEY_B},
+		},
+		{
+			"open frame",
+			[]InputEvent{key(KEY_A, 1)},
+			[][3]int{},
+			nil,
+		},
+		{
+			"filtered empty",
+			[]InputEvent{key(KEY_X, 1), syn},
+			[][3]int{},
+			nil,
+		},
+		{
+			"dropped by frame function",
+			[]InputEvent{key(KEY_Q, 1), key(KEY_A, 1), syn},
+			[][3]int{},
+			nil,
+		},
+		{
+			"transformed",
+			[]InputEvent{key(KEY_Y, 1), syn, key(KEY_Y, 2), syn, key(KEY_Y, 0), syn},
+			[][3]int{
+				{EV_KEY, KEY_Z, 1}, {EV_SYN, SYN_REPORT, 0},
+				{EV_KEY, KEY_Z, 2}, {EV_SYN, SYN_REPORT, 0},
+				{EV_KEY, KEY_Z, 0}, {EV_SYN, SYN_REPORT, 0},
+			},
+			nil,
+		},
+		{
+			"repeat holds",
+			[]InputEvent{key(KEY_A, 2), syn},
+			[][3]int{{EV_KEY, KEY_A, 2}, {EV_SYN, SYN_REPORT, 0}},
+			[]EvCode{KEY_A},
+		},
+	}
+	for _, tt := range tests {
+		sink := new(eventRecorder)
+		p := newEventPipeline(filter, frameFn, sink)
+		for _, ev := range tt.in {
+			if err := p.process(ev); err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+		}
+
+		if got := eventValues(*sink); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: wrote %v, want %v", tt.name, got, tt.want)
+		}
+		held := make(map[EvCode]bool)
+		for _, code := range p.heldKeys() {
+			held[code] = true
+		}
+		want := make(map[EvCode]bool)
+		for _, code := range tt.held {
+			want[code] = true
+		}
+		if !reflect.DeepEqual(held, want) {
+			t.Errorf("%s: held %v, want %v", tt.name, p.heldKeys(), tt.held)
+		}
+
+		// releasing writes a frame of releases, or nothing
+		*sink = nil
+		if err := p.release(p.heldKeys()); err != nil {
+			t.Fatal(err)
+		}
+		if n := len(*sink); n != 0 && n != len(tt.held)+1 || len(p.heldKeys()) != 0 {
+			t.Errorf("%s: released %v", tt.name, *sink)
+		}
+	}
+}
+
+func TestProxyDroppedEvents(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	now := time.Now()
+	events := []InputEvent{
+		NewInputEvent(now, EV_KEY, KEY_A, 1),
+		NewInputEvent(now, EV_SYN, SYN_REPORT, 0),
+		NewInputEvent(now, EV_KEY, KEY_B, 1),
+		NewInputEvent(now, EV_SYN, SYN_DROPPED, 0),
+		NewInputEvent(now, EV_KEY, KEY_C, 1),
+		NewInputEvent(now, EV_SYN, SYN_REPORT, 0),
+		NewInputEvent(now, EV_KEY, KEY_D, 1),
+		NewInputEvent(now, EV_SYN, SYN_REPORT, 0),
+	}
+	w.Write(unsafe.Slice((*byte)(unsafe.Pointer(&events[0])), len(events)*eventsize))
+	w.Close()
+
+	sink := new(eventRecorder)
+	p := NewProxy(&InputDevice{File: r}, sink)
+	p.pipe = newEventPipeline(nil, nil, sink)
+	if err := p.forward(context.Background()); err != io.EOF {
+		t.Errorf("expected EOF, got %v", err)
+	}
+
+	// the open frame and the events up to the next SYN_REPORT are dropped,
+	// and the source holding no keys, KEY_A is released on resync
+	want := [][3]int{
+		{EV_KEY, KEY_A, 1}, {EV_SYN, SYN_REPORT, 0},
+		{EV_KEY, KEY_A, 0}, {EV_SYN, SYN_REPORT, 0},
+		{EV_KEY, KEY_D, 1}, {EV_SYN, SYN_REPORT, 0},
+	}
+	if got := eventValues(*sink); !reflect.DeepEqual(got, want) {
+		t.Errorf("wrote %v, want %v", got, want)
+	}
+}
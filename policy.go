@@ -0,0 +1,79 @@
+//go:build linux
+
+package evdev
+
+// PolicyDecision is what a DevicePolicy decides to do with a device.
+type PolicyDecision int
+
+const (
+	PolicyIgnore PolicyDecision = iota // close the device
+	PolicyOpen                         // keep the device open
+	PolicyGrab                         // keep the device open and grab it
+	PolicyBlock                        // grab the device and discard its input
+)
+
+// DevicePolicy decides per device whether it may be used, based on its
+// identity (fingerprint, name, ids) and capabilities.
+type DevicePolicy func(dev *InputDevice) PolicyDecision
+
+// AllowList is a DevicePolicy keyed by device fingerprint. Devices that are
+// not listed get the Default decision, e.g. PolicyBlock for an input
+// security appliance only accepting known keyboards.
+type AllowList struct {
+	Devices map[string]PolicyDecision
+	Default PolicyDecision
+}
+
+// Decide returns the decision for dev.
+func (a AllowList) Decide(dev *InputDevice) PolicyDecision {
+	if decision, ok := a.Devices[dev.Fingerprint()]; ok {
+		return decision
+	}
+	return a.Default
+}
+
+// ApplyPolicy consults policy about an opened device and acts on the
+// decision: ignored devices are closed, grabbed and blocked devices are
+// grabbed. Blocked devices stay open so the grab holds; their events must
+// be read and discarded by the caller.
+func ApplyPolicy(dev *InputDevice, policy DevicePolicy) (PolicyDecision, error) {
+	decision := policy(dev)
+
+	switch decision {
+	case PolicyIgnore:
+		return decision, dev.Close()
+	case PolicyGrab, PolicyBlock:
+		return decision, dev.Grab()
+	}
+
+	return decision, nil
+}
+
+// OpenDevicesWithPolicy opens the devices matched by deviceGlob one at a
+// time and applies policy to each as soon as it is opened, so no device is
+// left open without a decision. The devices that are kept are returned with
+// their decisions. ListInputDevices itself never consults a policy.
+func OpenDevicesWithPolicy(deviceGlob string, policy DevicePolicy) (map[*InputDevice]PolicyDecision, error) {
+	fns, err := ListInputDevicePaths(deviceGlob)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := make(map[*InputDevice]PolicyDecision)
+	for _, fn := range fns {
+		dev, err := Open(fn)
+		if err != nil {
+			continue
+		}
+		decision, err := ApplyPolicy(dev, policy)
+		if err != nil {
+			dev.Close()
+			continue
+		}
+		if decision != PolicyIgnore {
+			kept[dev] = decision
+		}
+	}
+
+	return kept, nil
+}
@@ -0,0 +1,65 @@
+//go:build linux
+
+package evdev
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestAllowList(t *testing.T) {
+	kbd := &InputDevice{Name: "kbd", Vendor: 0x046d, Phys: "usb-1"}
+	other := &InputDevice{Name: "ducky", Vendor: 0x1234, Phys: "usb-2"}
+	policy := AllowList{
+		Devices: map[string]PolicyDecision{kbd.Fingerprint(): PolicyGrab},
+		Default: PolicyBlock,
+	}
+
+	tests := []struct {
+		dev  *InputDevice
+		want PolicyDecision
+	}{
+		{kbd, PolicyGrab},
+		{other, PolicyBlock},
+		{&InputDevice{Name: "kbd", Vendor: 0x046d, Phys: "usb-3"}, PolicyBlock},
+	}
+	for _, tt := range tests {
+		if got := policy.Decide(tt.dev); got != tt.want {
+			t.Errorf("%s at %s: got %d, want %d", tt.dev.Name, tt.dev.Phys, got, tt.want)
+		}
+	}
+}
+
+func TestApplyPolicy(t *testing.T) {
+	device := func() *InputDevice {
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Close()
+		return &InputDevice{File: r}
+	}
+
+	tests := []struct {
+		decision PolicyDecision
+		closed   bool
+		err      error
+	}{
+		{PolicyIgnore, true, nil},
+		{PolicyOpen, false, nil},
+		{PolicyGrab, false, syscall.ENOTTY}, // pipes can't be grabbed
+		{PolicyBlock, false, syscall.ENOTTY},
+	}
+	for _, tt := range tests {
+		dev := device()
+		decision, err := ApplyPolicy(dev, func(*InputDevice) PolicyDecision { return tt.decision })
+		if decision != tt.decision || err != tt.err {
+			t.Errorf("decision %d: got %d, %v", tt.decision, decision, err)
+		}
+		if closed := errors.Is(dev.File.Close(), os.ErrClosed); closed != tt.closed {
+			t.Errorf("decision %d: device closed %v, want %v", tt.decision, closed, tt.closed)
+		}
+	}
+}
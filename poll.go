@@ -0,0 +1,111 @@
+//go:build linux
+
+package evdev
+
+import (
+	"context"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// Corresponds to the pollfd struct.
+type pollFd struct {
+	fd      int32
+	events  int16
+	revents int16
+}
+
+const (
+	_POLLIN  = 0x1
+	_POLLERR = 0x8
+	_POLLHUP = 0x10
+)
+
+// Wait until fd becomes readable or the timeout expires. A negative timeout
+// waits forever. Errors and hangups count as readable, so that the following
+// read reports them.
+func waitReadable(fd uintptr, timeout time.Duration) (bool, error) {
+	pfds := []pollFd{{fd: int32(fd), events: _POLLIN}}
+	return poll(pfds, timeout)
+}
+
+// Wait until one of fds becomes readable, see waitReadable. The revents of
+// the pollFds are set.
+func poll(pfds []pollFd, timeout time.Duration) (bool, error) {
+	var ts *syscall.Timespec
+	if timeout >= 0 {
+		t := syscall.NsecToTimespec(int64(timeout))
+		ts = &t
+	}
+
+	for {
+		n, _, err := syscall.Syscall6(syscall.SYS_PPOLL, uintptr(unsafe.Pointer(&pfds[0])), uintptr(len(pfds)),
+			uintptr(unsafe.Pointer(ts)), 0, 0, 0)
+		if err == syscall.EINTR {
+			continue
+		}
+		if err != 0 {
+			return false, err
+		}
+
+		return n > 0, nil
+	}
+}
+
+// Wait until fd becomes readable or ctx is done, in which case ctx.Err() is
+// returned. Cancellation wakes the poll through a pipe.
+func waitReadableContext(ctx context.Context, fd uintptr) error {
+	if ctx.Done() == nil {
+		_, err := waitReadable(fd, -1)
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var p [2]int
+	if err := syscall.Pipe2(p[:], syscall.O_CLOEXEC|syscall.O_NONBLOCK); err != nil {
+		return err
+	}
+	defer syscall.Close(p[0])
+	defer syscall.Close(p[1])
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	defer func() {
+		close(stop)
+		<-done
+	}()
+	go func() {
+		defer close(done)
+		select {
+		case <-ctx.Done():
+			syscall.Write(p[1], []byte{0})
+		case <-stop:
+		}
+	}()
+
+	pfds := []pollFd{{fd: int32(fd), events: _POLLIN}, {fd: int32(p[0]), events: _POLLIN}}
+	if _, err := poll(pfds, -1); err != nil {
+		return err
+	}
+	if pfds[0].revents == 0 {
+		return ctx.Err()
+	}
+
+	return nil
+}
+
+// Read from fd once it was reported readable. The mode of the file, shared
+// with every other handle of the open file, is left alone: should another
+// reader take the events meanwhile, a blocking file waits for more and a
+// non-blocking one fails with syscall.EAGAIN.
+func readReady(fd uintptr, buf []byte) (int, error) {
+	for {
+		n, err := syscall.Read(int(fd), buf)
+		if err != syscall.EINTR {
+			return n, err
+		}
+	}
+}
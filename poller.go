@@ -0,0 +1,257 @@
+//go:build linux
+
+package evdev
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// DeviceEvent is an event tagged with the device it was read from.
+type DeviceEvent struct {
+	Device *InputDevice
+	Event  InputEvent
+}
+
+// PollMode selects how a Poller learns about readable devices.
+//
+// With LevelTriggered, the default, every Wait reads one buffer of events
+// from each readable device; events left over make the device readable
+// again for the next Wait. This keeps devices from starving each other.
+//
+// With EdgeTriggered, a device is only reported when new events arrive and
+// Wait drains it completely. High-rate devices (8kHz mice, touchscreens)
+// then cause fewer wakeups and system calls, at the cost of one busy device
+// delaying the events of the others within a Wait.
+type PollMode int
+
+const (
+	LevelTriggered PollMode = iota
+	EdgeTriggered
+)
+
+// Poller reads the events of many devices with a single epoll instance,
+// instead of one goroutine per device. Wait returns the events of all
+// devices that became readable, ordered by timestamp.
+//
+//	p, _ := evdev.NewPoller()
+//	p.Add(kbd)
+//	p.Add(mouse)
+//	for {
+//		events, err := p.Wait(-1)
+//		...
+//	}
+type Poller struct {
+	// OnError is called when reading a device fails, e.g. because it was
+	// unplugged. The device is removed from the poller before, so OnError
+	// may call the methods of the poller. If OnError is nil, Wait returns
+	// the first error instead, along with the events read from the other
+	// devices.
+	OnError func(dev *InputDevice, err error)
+
+	// Mode applies to the devices added afterwards.
+	Mode PollMode
+
+	epfd    int
+	mu      sync.Mutex
+	devices map[int32]*InputDevice
+	edge    map[int32]bool // devices registered edge-triggered
+}
+
+// NewPoller creates a poller without devices.
+func NewPoller() (*Poller, error) {
+	epfd, err := syscall.EpollCreate1(syscall.EPOLL_CLOEXEC)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Poller{
+		epfd:    epfd,
+		devices: make(map[int32]*InputDevice),
+		edge:    make(map[int32]bool),
+	}, nil
+}
+
+// Add registers a device with the poller.
+func (p *Poller) Add(dev *InputDevice) error {
+	fd := int32(dev.File.Fd())
+	ev := syscall.EpollEvent{Events: syscall.EPOLLIN, Fd: fd}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	edge := p.Mode == EdgeTriggered
+	if edge {
+		ev.Events |= syscall.EPOLLET & 0xffffffff
+	}
+
+	if err := syscall.EpollCtl(p.epfd, syscall.EPOLL_CTL_ADD, int(fd), &ev); err != nil {
+		return err
+	}
+	p.devices[fd] = dev
+	p.edge[fd] = edge
+
+	return nil
+}
+
+// Remove unregisters a device from the poller.
+func (p *Poller) Remove(dev *InputDevice) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.remove(dev)
+}
+
+func (p *Poller) remove(dev *InputDevice) error {
+	for fd, d := range p.devices {
+		if d == dev {
+			delete(p.devices, fd)
+			delete(p.edge, fd)
+			return syscall.EpollCtl(p.epfd, syscall.EPOLL_CTL_DEL, int(fd), nil)
+		}
+	}
+
+	return nil
+}
+
+// Devices returns the registered devices.
+func (p *Poller) Devices() []*InputDevice {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	devices := make([]*InputDevice, 0, len(p.devices))
+	for _, dev := range p.devices {
+		devices = append(devices, dev)
+	}
+
+	return devices
+}
+
+// Wait waits until at least one device is readable or the timeout expires,
+// and returns the events read from all readable devices. A negative timeout
+// waits forever. ErrTimeout is returned when the timeout expires. Pre-roll
+// events queued with PreRoll are returned first, without waiting.
+func (p *Poller) Wait(timeout time.Duration) ([]DeviceEvent, error) {
+	if events := p.preRolled(); len(events) > 0 {
+		return events, nil
+	}
+
+	msec := -1
+	if timeout >= 0 {
+		msec = int((timeout + time.Millisecond - 1) / time.Millisecond)
+	}
+
+	ready := make([]syscall.EpollEvent, 32)
+	for {
+		n, err := syscall.EpollWait(p.epfd, ready, msec)
+		if err == syscall.EINTR {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			return nil, ErrTimeout
+		}
+		ready = ready[:n]
+		break
+	}
+
+	events := make([]DeviceEvent, 0)
+	buffer := make([]byte, eventsize*64)
+	type failure struct {
+		dev *InputDevice
+		err error
+	}
+	failed := make([]failure, 0)
+
+	p.mu.Lock()
+	for _, r := range ready {
+		dev, ok := p.devices[r.Fd]
+		if !ok {
+			continue
+		}
+
+		for {
+			read, err := p.read(dev, buffer)
+			if err == syscall.EAGAIN {
+				break
+			}
+			if err != nil {
+				p.remove(dev)
+				failed = append(failed, failure{dev, err})
+				break
+			}
+
+			for _, ev := range read {
+				events = append(events, DeviceEvent{dev, ev})
+			}
+
+			// edge-triggered devices must be drained, a short read did
+			if !p.edge[r.Fd] || len(read)*eventsize < len(buffer) {
+				break
+			}
+			if more, _ := waitReadable(uintptr(r.Fd), 0); !more {
+				break
+			}
+		}
+	}
+	p.mu.Unlock()
+
+	sort.SliceStable(events, func(i, j int) bool {
+		a, b := events[i].Event.Time, events[j].Event.Time
+		return a.Sec < b.Sec || a.Sec == b.Sec && a.Usec < b.Usec
+	})
+
+	// the whole batch is read first: edge-triggered devices not drained
+	// would not be reported again
+	if p.OnError == nil && len(failed) > 0 {
+		return events, failed[0].err
+	}
+
+	// called without the lock, OnError may remove or add devices
+	for _, f := range failed {
+		p.OnError(f.dev, f.err)
+	}
+
+	return events, nil
+}
+
+// Take the queued pre-roll events of all devices.
+func (p *Poller) preRolled() []DeviceEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	events := make([]DeviceEvent, 0)
+	for _, dev := range p.devices {
+		for _, ev := range dev.takePending(len(dev.pending)) {
+			events = append(events, DeviceEvent{dev, ev})
+		}
+	}
+
+	return events
+}
+
+func (p *Poller) read(dev *InputDevice, buffer []byte) ([]InputEvent, error) {
+	n, err := readReady(dev.File.Fd(), buffer)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, io.EOF
+	}
+
+	events := make([]InputEvent, n/eventsize)
+	err = binary.Read(bytes.NewBuffer(buffer[:n]), binary.LittleEndian, &events)
+	return events, err
+}
+
+// Close closes the epoll instance. The devices are not closed.
+func (p *Poller) Close() error {
+	return syscall.Close(p.epfd)
+}
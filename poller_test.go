@@ -0,0 +1,57 @@
+//go:build linux
+
+package evdev
+
+import (
+	"os"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+func TestPollerDrain(t *testing.T) {
+	for _, mode := range []PollMode{LevelTriggered, EdgeTriggered} {
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		dev := &InputDevice{File: r}
+
+		p, err := NewPoller()
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.Mode = mode
+		if err := p.Add(dev); err != nil {
+			t.Fatal(err)
+		}
+
+		// more events than a single read takes
+		events := make([]InputEvent, 100)
+		for i := range events {
+			events[i] = NewInputEvent(time.Unix(1000, int64(i)*1000), EV_MSC, MSC_SCAN, EvValue(i))
+		}
+		w.Write(unsafe.Slice((*byte)(unsafe.Pointer(&events[0])), len(events)*eventsize))
+
+		got, err := p.Wait(time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := 64
+		if mode == EdgeTriggered {
+			want = len(events)
+		}
+		if len(got) != want {
+			t.Errorf("mode %d: read %d events, want %d", mode, len(got), want)
+		}
+		for i, de := range got {
+			if de.Device != dev || de.Event.Value != EvValue(i) {
+				t.Fatalf("mode %d: unexpected event %d %v", mode, i, de.Event)
+			}
+		}
+
+		p.Close()
+		r.Close()
+		w.Close()
+	}
+}
@@ -0,0 +1,129 @@
+//go:build linux
+
+package evdev
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// PollingRate is the measured report rate of a device.
+type PollingRate struct {
+	Reports      int           // report intervals measured
+	Rate         float64       // reports per second while moving, in Hz
+	MeanInterval time.Duration // mean time between reports
+	Jitter       time.Duration // standard deviation of the time between reports
+	MinInterval  time.Duration
+	MaxInterval  time.Duration
+}
+
+// String summarizes the measurement, e.g. "998 Hz (1.002ms ± 12µs, 4012
+// reports)".
+func (r PollingRate) String() string {
+	return fmt.Sprintf("%.0f Hz (%v ± %v, %d reports)", r.Rate, r.MeanInterval, r.Jitter, r.Reports)
+}
+
+// PollingRateMeter measures the effective report rate of a device with
+// relative axes, such as a mouse, from the timestamps of the frames that
+// carry EV_REL events. Mice only report while moving, so intervals longer
+// than MaxGap are taken as pauses and left out. The timestamps are those of
+// the kernel, so the measurement is not affected by scheduling of the
+// reader.
+type PollingRateMeter struct {
+	MaxGap time.Duration
+
+	moved     bool      // the open frame has EV_REL events
+	last      time.Time // time of the last report
+	intervals []time.Duration
+}
+
+// NewPollingRateMeter creates a meter taking intervals longer than 50ms as
+// pauses, enough for mice polled at 125 Hz or more.
+func NewPollingRateMeter() *PollingRateMeter {
+	return &PollingRateMeter{MaxGap: 50 * time.Millisecond}
+}
+
+// Feed passes an event of the device to the meter.
+func (m *PollingRateMeter) Feed(ev *InputEvent) {
+	switch {
+	case ev.Type == EV_REL:
+		m.moved = true
+	case ev.Type == EV_SYN && ev.Code == SYN_DROPPED:
+		// the interval over the lost events is not a report interval
+		m.last = time.Time{}
+	case ev.Type == EV_SYN && ev.Code == SYN_REPORT && m.moved:
+		t := ev.Timestamp()
+		if !m.last.IsZero() {
+			if d := t.Sub(m.last); d > 0 && d <= m.MaxGap {
+				m.intervals = append(m.intervals, d)
+			}
+		}
+		m.last = t
+		m.moved = false
+	}
+}
+
+// Result returns the measurement so far.
+func (m *PollingRateMeter) Result() PollingRate {
+	r := PollingRate{Reports: len(m.intervals)}
+	if r.Reports == 0 {
+		return r
+	}
+
+	var sum time.Duration
+	r.MinInterval = m.intervals[0]
+	for _, d := range m.intervals {
+		sum += d
+		if d < r.MinInterval {
+			r.MinInterval = d
+		}
+		if d > r.MaxInterval {
+			r.MaxInterval = d
+		}
+	}
+	mean := float64(sum) / float64(r.Reports)
+
+	var variance float64
+	for _, d := range m.intervals {
+		variance += (float64(d) - mean) * (float64(d) - mean)
+	}
+	variance /= float64(r.Reports)
+
+	r.MeanInterval = time.Duration(mean)
+	r.Jitter = time.Duration(math.Sqrt(variance))
+	r.Rate = float64(time.Second) / mean
+
+	return r
+}
+
+// Reset starts a new measurement.
+func (m *PollingRateMeter) Reset() {
+	m.moved = false
+	m.last = time.Time{}
+	m.intervals = nil
+}
+
+// MeasurePollingRate measures the report rate of the device over window,
+// or until ctx is done. The user should keep moving the device meanwhile.
+// The events read are consumed.
+func (dev *InputDevice) MeasurePollingRate(ctx context.Context, window time.Duration) (PollingRate, error) {
+	ctx, cancel := context.WithTimeout(ctx, window)
+	defer cancel()
+
+	m := NewPollingRateMeter()
+	for {
+		events, err := dev.ReadContext(ctx)
+		if err == context.DeadlineExceeded {
+			return m.Result(), nil
+		}
+		if err != nil {
+			return m.Result(), err
+		}
+
+		for i := range events {
+			m.Feed(&events[i])
+		}
+	}
+}
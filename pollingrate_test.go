@@ -0,0 +1,51 @@
+//go:build linux
+
+package evdev
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPollingRateMeter(t *testing.T) {
+	m := NewPollingRateMeter()
+	now := time.Unix(1000, 0)
+
+	report := func(d time.Duration, evType EvType, code EvCode) {
+		now = now.Add(d)
+		for _, ev := range []InputEvent{
+			NewInputEvent(now, evType, code, 1),
+			NewInputEvent(now, EV_SYN, SYN_REPORT, 0),
+		} {
+			m.Feed(&ev)
+		}
+	}
+
+	// 1kHz, alternating between 900µs and 1100µs
+	for i := 0; i < 100; i++ {
+		report(900*time.Microsecond, EV_REL, REL_X)
+		report(1100*time.Microsecond, EV_REL, REL_Y)
+	}
+	// a pause, and button reports without movement
+	report(time.Second, EV_REL, REL_X)
+	report(500*time.Microsecond, EV_KEY, BTN_LEFT)
+
+	r := m.Result()
+	if r.Reports != 199 {
+		t.Errorf("expected 199 intervals, got %d", r.Reports)
+	}
+	if r.Rate < 999 || r.Rate > 1001 {
+		t.Errorf("expected 1000 Hz, got %v", r.Rate)
+	}
+	if r.Jitter < 99*time.Microsecond || r.Jitter > 101*time.Microsecond {
+		t.Errorf("expected a jitter of 100µs, got %v", r.Jitter)
+	}
+	if r.MinInterval != 900*time.Microsecond || r.MaxInterval != 1100*time.Microsecond {
+		t.Errorf("unexpected interval range %v to %v", r.MinInterval, r.MaxInterval)
+	}
+
+	m.Reset()
+	if r := m.Result(); r.Reports != 0 || r.Rate != 0 {
+		t.Errorf("expected no result after reset, got %v", r)
+	}
+}
@@ -0,0 +1,95 @@
+//go:build linux
+
+package evdev
+
+// StateEvents synthesizes events describing the current state of the device:
+// a key down event for every held key, the values of the absolute axes and
+// the states of all switches and LEDs, followed by a SYN_REPORT. Multitouch
+// axes are left out, as their values depend on the slot. Feeding these
+// events to a consumer brings it to the state it would have reached had it
+// seen the device from the start.
+func (dev *InputDevice) StateEvents() ([]InputEvent, error) {
+	now := dev.Now()
+	events := make([]InputEvent, 0)
+
+	if dev.HasCode(EV_SYN, EV_KEY) {
+		keys, err := dev.KeyState()
+		if err != nil {
+			return nil, err
+		}
+		keys.Iterate(func(code int) bool {
+			events = append(events, NewInputEvent(now, EV_KEY, EvCode(code), EvValue(KeyDown)))
+			return true
+		})
+	}
+
+	for _, axis := range dev.CodesFor(EV_ABS) {
+		if axis >= ABS_MT_SLOT && axis <= ABS_MT_TOOL_Y {
+			continue
+		}
+
+		info, err := dev.AbsInfo(axis)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, NewInputEvent(now, EV_ABS, EvCode(axis), EvValue(info.Value)))
+	}
+
+	for _, s := range []struct {
+		evType int
+		state  func() (Bitset, error)
+	}{
+		{EV_SW, dev.SwitchState},
+		{EV_LED, dev.LEDState},
+	} {
+		if !dev.HasCode(EV_SYN, s.evType) {
+			continue
+		}
+
+		bits, err := s.state()
+		if err != nil {
+			return nil, err
+		}
+		for _, code := range dev.CodesFor(s.evType) {
+			value := EvValue(0)
+			if bits.Has(code) {
+				value = 1
+			}
+			events = append(events, NewInputEvent(now, EvType(s.evType), EvCode(code), value))
+		}
+	}
+
+	return append(events, NewInputEvent(now, EV_SYN, SYN_REPORT, 0)), nil
+}
+
+// PreRoll queues the events returned by StateEvents, so that the next reads
+// return them before any events from the kernel, and so does Poller.Wait.
+// Consumers then need no separate state query phase. Events queued in the
+// kernel before the call follow the pre-roll and may repeat state changes
+// it already reflects.
+func (dev *InputDevice) PreRoll() error {
+	events, err := dev.StateEvents()
+	if err != nil {
+		return err
+	}
+	dev.pending = append(dev.pending, events...)
+
+	return nil
+}
+
+// Take up to max queued pre-roll events.
+func (dev *InputDevice) takePending(max int) []InputEvent {
+	n := len(dev.pending)
+	if n > max {
+		n = max
+	}
+
+	events := make([]InputEvent, n)
+	copy(events, dev.pending)
+	dev.pending = dev.pending[n:]
+	if len(dev.pending) == 0 {
+		dev.pending = nil
+	}
+
+	return events
+}
@@ -0,0 +1,133 @@
+//go:build linux
+
+package evdev
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+func TestPreRoll(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	// without state to describe, the pre-roll is a lone report
+	dev := &InputDevice{File: r}
+	if err := dev.PreRoll(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := dev.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []InputEvent{NewInputEvent(time.Unix(1000, 0), EV_SYN, SYN_REPORT, 0)}; !sameEvents(got, want) {
+		t.Errorf("got pre-roll %v, want %v", got, want)
+	}
+
+	// a failing state query queues nothing
+	types, keys := NewBitset(EV_MAX), NewBitset(KEY_MAX)
+	types.Set(EV_KEY)
+	keys.Set(KEY_A)
+	kbd := &InputDevice{File: r, RawCapabilities: map[int]Bitset{EV_SYN: types, EV_KEY: keys}}
+	if err := kbd.PreRoll(); err != syscall.ENOTTY {
+		t.Errorf("expected ENOTTY from a pipe, got %v", err)
+	}
+	if len(kbd.pending) != 0 {
+		t.Errorf("expected nothing queued, got %v", kbd.pending)
+	}
+
+	// queued events are read in chunks before the kernel's
+	now := time.Unix(1000, 0)
+	for i := 0; i < 20; i++ {
+		dev.pending = append(dev.pending, NewInputEvent(now, EV_MSC, MSC_SERIAL, EvValue(i)))
+	}
+	ev := NewInputEvent(now, EV_KEY, KEY_B, 1)
+	w.Write(unsafe.Slice((*byte)(unsafe.Pointer(&ev)), eventsize))
+
+	tests := []struct {
+		read  func() ([]InputEvent, error)
+		first EvValue
+		n     int
+	}{
+		{dev.Read, 0, 16},
+		{func() ([]InputEvent, error) {
+			one, err := dev.ReadOne()
+			if err != nil {
+				return nil, err
+			}
+			return []InputEvent{*one}, nil
+		}, 16, 1},
+		{func() ([]InputEvent, error) { return dev.ReadTimeout(time.Second) }, 17, 3},
+	}
+	for i, tt := range tests {
+		got, err := tt.read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != tt.n || got[0].Code != MSC_SERIAL || got[0].Value != tt.first {
+			t.Errorf("read %d: got %v, want %d events from %d", i, got, tt.n, tt.first)
+		}
+	}
+	if dev.pending != nil {
+		t.Errorf("expected the queue released, got %v", dev.pending)
+	}
+
+	got, err = dev.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Code != KEY_B {
+		t.Errorf("expected the kernel's event after the pre-roll, got %v", got)
+	}
+}
+
+func TestPollerPreRoll(t *testing.T) {
+	p, err := NewPoller()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	now := time.Unix(1000, 0)
+	devices := make([]*InputDevice, 2)
+	for i := range devices {
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Close()
+		defer w.Close()
+
+		devices[i] = &InputDevice{File: r, pending: []InputEvent{
+			NewInputEvent(now, EV_KEY, KEY_A, 1),
+			NewInputEvent(now, EV_SYN, SYN_REPORT, 0),
+		}}
+		if err := p.Add(devices[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// returned at once, although no device is readable
+	events, err := p.Wait(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := make(map[*InputDevice]int)
+	for _, de := range events {
+		count[de.Device]++
+	}
+	if len(events) != 4 || count[devices[0]] != 2 || count[devices[1]] != 2 {
+		t.Errorf("unexpected pre-roll events %v", events)
+	}
+
+	if _, err := p.Wait(10 * time.Millisecond); err != ErrTimeout {
+		t.Errorf("expected ErrTimeout once the pre-roll is taken, got %v", err)
+	}
+}
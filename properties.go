@@ -0,0 +1,15 @@
+//go:build linux
+
+package evdev
+
+// Properties returns the INPUT_PROP_* properties of the device in ascending
+// order. They tell devices apart that have the same capabilities, e.g.
+// touchscreens (INPUT_PROP_DIRECT) from touchpads (INPUT_PROP_POINTER).
+func (dev *InputDevice) Properties() []int {
+	return dev.properties.Codes()
+}
+
+// HasProperty reports whether the device has an INPUT_PROP_* property.
+func (dev *InputDevice) HasProperty(prop int) bool {
+	return dev.properties.Has(prop)
+}
@@ -0,0 +1,54 @@
+//go:build linux
+
+package evdev
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProperties(t *testing.T) {
+	abs := func(codes ...int) []CapabilityCode {
+		caps := make([]CapabilityCode, 0, len(codes))
+		for _, code := range codes {
+			caps = append(caps, CapabilityCode{code, CodeName(EV_ABS, code)})
+		}
+		return caps
+	}
+	props := func(codes ...int) []byte {
+		b := make([]byte, INPUT_PROP_MAX/8+1)
+		for _, code := range codes {
+			b[code/8] |= 1 << uint(code%8)
+		}
+		return b
+	}
+
+	tests := []struct {
+		name  string
+		caps  map[CapabilityType][]CapabilityCode
+		props []byte
+		accel bool
+	}{
+		{"iio", map[CapabilityType][]CapabilityCode{{EV_ABS, "EV_ABS"}: abs(ABS_X, ABS_Y, ABS_Z)}, nil, true},
+		{"flagged", map[CapabilityType][]CapabilityCode{{EV_ABS, "EV_ABS"}: abs(ABS_X, ABS_Y)}, props(INPUT_PROP_ACCELEROMETER), true},
+		{"joystick", map[CapabilityType][]CapabilityCode{
+			{EV_ABS, "EV_ABS"}: abs(ABS_X, ABS_Y, ABS_Z),
+			{EV_KEY, "EV_KEY"}: {{BTN_TRIGGER, "BTN_TRIGGER"}},
+		}, nil, false},
+		{"touchscreen", map[CapabilityType][]CapabilityCode{{EV_ABS, "EV_ABS"}: abs(ABS_X, ABS_Y, ABS_Z, ABS_MT_SLOT)}, props(INPUT_PROP_DIRECT), false},
+	}
+	for _, tt := range tests {
+		dev := &InputDevice{Capabilities: tt.caps, properties: tt.props}
+		if got := dev.IsAccelerometer(); got != tt.accel {
+			t.Errorf("%s: accelerometer %v, want %v", tt.name, got, tt.accel)
+		}
+	}
+
+	dev := &InputDevice{properties: props(INPUT_PROP_POINTER, INPUT_PROP_BUTTONPAD)}
+	if got := dev.Properties(); !reflect.DeepEqual(got, []int{INPUT_PROP_POINTER, INPUT_PROP_BUTTONPAD}) {
+		t.Errorf("unexpected properties %v", got)
+	}
+	if !dev.HasProperty(INPUT_PROP_BUTTONPAD) || dev.HasProperty(INPUT_PROP_DIRECT) || dev.HasProperty(100) {
+		t.Error("unexpected HasProperty results")
+	}
+}
@@ -0,0 +1,235 @@
+//go:build linux
+
+package evdev
+
+import (
+	"context"
+	"errors"
+	"syscall"
+	"time"
+)
+
+// Proxy grabs a source device and forwards its events to a sink, typically
+// a clone of the source created with CloneDevice, transforming them on the
+// way. Events pass through Filter one by one and are then collected into
+// frames, which pass through Frame before they are written. Frames are
+// always terminated by a SYN_REPORT and empty frames are dropped.
+//
+// When the kernel reports dropped events (SYN_DROPPED), the incomplete frame
+// is discarded and the state of the source is queried and replayed through
+// the pipeline, releasing keys on the sink that were released meanwhile.
+// When Run returns, the grab is released along with all keys still held on
+// the sink, so that no key stays stuck.
+//
+// With Standby, the proxy outlives the source: when the source disappears,
+// e.g. because it was unplugged, the keys held on the sink are released and
+// the proxy waits for a device with the same fingerprint to reappear. It
+// then grabs it and resumes forwarding, so that consumers of the sink never
+// see the device vanish.
+type Proxy struct {
+	Source *InputDevice
+	Sink   EventWriter
+
+	Filter Filter    // per event transform, may be nil
+	Frame  FrameFunc // per frame transform, may be nil
+
+	// Filters implementing TimedFilter are ticked at this interval, on the
+	// clock of the source, whether or not events arrive. It also bounds the
+	// time Run takes to notice that ctx is done.
+	TickInterval time.Duration
+
+	// Standby keeps the sink when the source disappears, checking for its
+	// return every StandbyInterval. Source is replaced by the device found.
+	Standby         bool
+	StandbyInterval time.Duration
+
+	pipe     *eventPipeline
+	nextTick time.Time
+}
+
+// NewProxy creates a proxy forwarding the events of src to sink unchanged,
+// ticking timed filters every 10ms and, in standby, checking for the return
+// of the source every second.
+func NewProxy(src *InputDevice, sink EventWriter) *Proxy {
+	return &Proxy{
+		Source:          src,
+		Sink:            sink,
+		TickInterval:    10 * time.Millisecond,
+		StandbyInterval: time.Second,
+	}
+}
+
+// Run grabs the source and forwards its events until ctx is done or reading
+// or writing fails. In standby, the source disappearing is no failure.
+func (p *Proxy) Run(ctx context.Context) error {
+	p.pipe = newEventPipeline(p.Filter, p.Frame, p.Sink)
+
+	if err := p.Source.Grab(); err != nil {
+		return err
+	}
+	defer func() { p.Source.Release() }()
+	defer func() { p.pipe.release(p.pipe.heldKeys()) }()
+
+	for {
+		err := p.forward(ctx)
+		if !p.Standby || !errors.Is(err, syscall.ENODEV) {
+			return err
+		}
+		if err = p.standby(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// Forward the events of the source until reading or writing fails.
+func (p *Proxy) forward(ctx context.Context) error {
+	dropping := false
+	for {
+		events, err := p.read(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, ev := range events {
+			switch {
+			case dropping:
+				if ev.Type == EV_SYN && ev.Code == SYN_REPORT {
+					dropping = false
+					if err = p.resync(); err != nil {
+						return err
+					}
+				}
+				continue
+			case ev.Type == EV_SYN && ev.Code == SYN_DROPPED:
+				dropping = true
+				p.pipe.frame = nil
+				continue
+			}
+
+			if err = p.pipe.process(ev); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Read the next events, ticking timed filters whenever a tick is due.
+func (p *Proxy) read(ctx context.Context) ([]InputEvent, error) {
+	_, timed := p.Filter.(TimedFilter)
+	if !timed || p.TickInterval <= 0 {
+		return p.Source.ReadContext(ctx)
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		// ticks wait for the open frame to complete, so as not to split it
+		wait := time.Until(p.nextTick)
+		if wait <= 0 && len(p.pipe.frame) == 0 {
+			p.nextTick = time.Now().Add(p.TickInterval)
+			if err := p.pipe.tick(p.Source.Now()); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if wait <= 0 {
+			wait = p.TickInterval
+		}
+
+		events, err := p.Source.ReadTimeout(wait)
+		if err != ErrTimeout {
+			return events, err
+		}
+	}
+}
+
+// Wait for the source to return after it disappeared, releasing the keys
+// held on the sink meanwhile.
+func (p *Proxy) standby(ctx context.Context) error {
+	p.pipe.frame = nil
+	if err := p.pipe.release(p.pipe.heldKeys()); err != nil {
+		return err
+	}
+
+	fingerprint := p.Source.Fingerprint()
+	p.Source.Close()
+
+	// a clone of the source shares its fingerprint
+	sinkNode := ""
+	if u, ok := p.Sink.(*UInputDevice); ok {
+		sinkNode, _ = u.Devnode()
+	}
+
+	ticker := time.NewTicker(p.StandbyInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		dev := openByFingerprint(fingerprint, sinkNode)
+		if dev == nil {
+			continue
+		}
+		if err := dev.Grab(); err != nil {
+			dev.Close()
+			continue
+		}
+
+		p.Source = dev
+		return p.resync()
+	}
+}
+
+// Open the device with the given fingerprint, other than the one at skip.
+func openByFingerprint(fingerprint, skip string) *InputDevice {
+	devices, _ := ListInputDevices()
+
+	var found *InputDevice
+	for _, dev := range devices {
+		if found == nil && dev.Fn != skip && dev.Fingerprint() == fingerprint {
+			found = dev
+			continue
+		}
+		dev.Close()
+	}
+
+	return found
+}
+
+// Replay the state of the source after dropped events.
+func (p *Proxy) resync() error {
+	state, err := p.Source.StateEvents()
+	if err != nil {
+		return err
+	}
+
+	return p.replay(state)
+}
+
+// Pass a state of the source through the pipeline, releasing the keys held
+// on the sink that are not part of it.
+func (p *Proxy) replay(state []InputEvent) error {
+	held := p.pipe.heldKeys()
+	p.pipe.down = make(map[EvCode]bool)
+	for _, ev := range state {
+		if err := p.pipe.process(ev); err != nil {
+			return err
+		}
+	}
+
+	// release the keys released while events were dropped
+	stale := make([]EvCode, 0)
+	for _, code := range held {
+		if !p.pipe.down[code] {
+			stale = append(stale, code)
+		}
+	}
+
+	return p.pipe.release(stale)
+}
@@ -0,0 +1,133 @@
+//go:build linux
+
+package evdev
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Strip the timestamps of events, for comparison.
+func eventValues(events []InputEvent) [][3]int {
+	values := make([][3]int, 0, len(events))
+	for _, ev := range events {
+		values = append(values, [3]int{int(ev.Type), int(ev.Code), int(ev.Value)})
+	}
+
+	return values
+}
+
+func TestProxyStandby(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	sink := new(eventRecorder)
+	p := NewProxy(&InputDevice{File: r}, sink)
+	p.StandbyInterval = time.Hour
+	p.pipe = newEventPipeline(nil, nil, sink)
+
+	now := time.Now()
+	for _, ev := range []InputEvent{
+		NewInputEvent(now, EV_KEY, KEY_LEFTSHIFT, 1),
+		NewInputEvent(now, EV_KEY, KEY_A, 1),
+		NewInputEvent(now, EV_SYN, SYN_REPORT, 0),
+		NewInputEvent(now, EV_KEY, KEY_A, 0),
+		NewInputEvent(now, EV_SYN, SYN_REPORT, 0),
+		NewInputEvent(now, EV_KEY, KEY_B, 1),
+	} {
+		if err := p.pipe.process(ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	*sink = nil
+
+	// the source is gone: the open frame is dropped and held keys released
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := p.standby(ctx); err != context.Canceled {
+		t.Errorf("expected the standby to end with ctx, got %v", err)
+	}
+	want := [][3]int{{EV_KEY, KEY_LEFTSHIFT, 0}, {EV_SYN, SYN_REPORT, 0}}
+	if got := eventValues(*sink); !reflect.DeepEqual(got, want) {
+		t.Errorf("released %v, want %v", got, want)
+	}
+	if len(p.pipe.frame) != 0 || len(p.pipe.heldKeys()) != 0 {
+		t.Errorf("unexpected frame %v and held keys %v", p.pipe.frame, p.pipe.heldKeys())
+	}
+	if _, err := r.Stat(); err == nil {
+		t.Error("expected the source closed")
+	}
+}
+
+func TestProxyReplay(t *testing.T) {
+	now := time.Now()
+	key := func(code EvCode, value EvValue) InputEvent { return NewInputEvent(now, EV_KEY, code, value) }
+	syn := NewInputEvent(now, EV_SYN, SYN_REPORT, 0)
+
+	tests := []struct {
+		name  string
+		held  []EvCode
+		state []InputEvent
+		want  [][3]int
+	}{
+		{
+			"returned with a key held",
+			nil,
+			[]InputEvent{key(KEY_B, 1), syn},
+			[][3]int{{EV_KEY, KEY_B, 1}, {EV_SYN, SYN_REPORT, 0}},
+		},
+		{
+			"key released meanwhile",
+			[]EvCode{KEY_A, KEY_B},
+			[]InputEvent{key(KEY_B, 1), syn},
+			[][3]int{{EV_KEY, KEY_B, 1}, {EV_SYN, SYN_REPORT, 0}, {EV_KEY, KEY_A, 0}, {EV_SYN, SYN_REPORT, 0}},
+		},
+		{
+			"nothing held",
+			nil,
+			[]InputEvent{syn},
+			[][3]int{},
+		},
+	}
+	for _, tt := range tests {
+		sink := new(eventRecorder)
+		p := NewProxy(nil, sink)
+		p.pipe = newEventPipeline(nil, nil, sink)
+		for _, code := range tt.held {
+			p.pipe.down[code] = true
+		}
+
+		if err := p.replay(tt.state); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := eventValues(*sink); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: wrote %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// a source with no state to report releases everything on resync
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	sink := new(eventRecorder)
+	p := NewProxy(&InputDevice{File: r}, sink)
+	p.pipe = newEventPipeline(nil, nil, sink)
+	p.pipe.down[KEY_A] = true
+	if err := p.resync(); err != nil {
+		t.Fatal(err)
+	}
+	want := [][3]int{{EV_KEY, KEY_A, 0}, {EV_SYN, SYN_REPORT, 0}}
+	if got := eventValues(*sink); !reflect.DeepEqual(got, want) {
+		t.Errorf("resync wrote %v, want %v", got, want)
+	}
+}
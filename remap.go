@@ -0,0 +1,76 @@
+//go:build linux
+
+package evdev
+
+// KeyRemap is a Filter that replaces the codes of key events, e.g. to turn
+// a physical QWERTY keyboard into a Colemak one. Keys that are not in the
+// map pass through unchanged.
+type KeyRemap map[EvCode]EvCode
+
+// Process remaps a single event.
+func (r KeyRemap) Process(ev InputEvent) []InputEvent {
+	if ev.Type == EV_KEY {
+		if code, ok := r[ev.Code]; ok {
+			ev.Code = code
+		}
+	}
+
+	return []InputEvent{ev}
+}
+
+// ColemakRemap returns a remap table emulating the Colemak layout on
+// a keyboard with a US QWERTY keymap.
+func ColemakRemap() KeyRemap {
+	return KeyRemap{
+		KEY_E:         KEY_F,
+		KEY_R:         KEY_P,
+		KEY_T:         KEY_G,
+		KEY_Y:         KEY_J,
+		KEY_U:         KEY_L,
+		KEY_I:         KEY_U,
+		KEY_O:         KEY_Y,
+		KEY_P:         KEY_SEMICOLON,
+		KEY_S:         KEY_R,
+		KEY_D:         KEY_S,
+		KEY_F:         KEY_T,
+		KEY_G:         KEY_D,
+		KEY_J:         KEY_N,
+		KEY_K:         KEY_E,
+		KEY_L:         KEY_I,
+		KEY_SEMICOLON: KEY_O,
+		KEY_N:         KEY_K,
+		KEY_CAPSLOCK:  KEY_BACKSPACE,
+	}
+}
+
+// SplitKeymap assigns remap tables to physical keyboards, identified by
+// their fingerprint, so that several keyboards with different layouts can
+// be merged onto a single virtual keyboard.
+type SplitKeymap struct {
+	Tables  map[string]KeyRemap // device fingerprint -> remap table
+	Default KeyRemap            // table for devices without an own table, may be nil
+}
+
+// NewSplitKeymap creates an empty split keymap.
+func NewSplitKeymap() *SplitKeymap {
+	return &SplitKeymap{Tables: make(map[string]KeyRemap)}
+}
+
+// Assign sets the remap table of a device.
+func (s *SplitKeymap) Assign(dev *InputDevice, table KeyRemap) {
+	s.Tables[dev.Fingerprint()] = table
+}
+
+// For returns the filter to apply to the events of dev.
+func (s *SplitKeymap) For(dev *InputDevice) Filter {
+	if table, ok := s.Tables[dev.Fingerprint()]; ok {
+		return table
+	}
+
+	return s.Default
+}
+
+// Process remaps an event read from dev.
+func (s *SplitKeymap) Process(dev *InputDevice, ev InputEvent) []InputEvent {
+	return s.For(dev).Process(ev)
+}
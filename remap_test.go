@@ -0,0 +1,85 @@
+//go:build linux
+
+package evdev
+
+import (
+	"testing"
+	"time"
+)
+
+func TestColemakRemap(t *testing.T) {
+	r := ColemakRemap()
+	now := time.Unix(1000, 0)
+
+	tests := []struct {
+		evType EvType
+		code   EvCode
+		want   EvCode
+	}{
+		{EV_KEY, KEY_E, KEY_F},
+		{EV_KEY, KEY_SEMICOLON, KEY_O},
+		{EV_KEY, KEY_CAPSLOCK, KEY_BACKSPACE},
+		{EV_KEY, KEY_A, KEY_A},     // same in both layouts
+		{EV_KEY, KEY_ESC, KEY_ESC}, // not a letter
+		{EV_MSC, KEY_E, KEY_E},     // not a key event
+	}
+	for _, tt := range tests {
+		out := r.Process(NewInputEvent(now, tt.evType, tt.code, 1))
+		if len(out) != 1 || out[0].Code != tt.want || out[0].Type != tt.evType || out[0].Value != 1 {
+			t.Errorf("type %d code %d: got %v, want code %d", tt.evType, tt.code, out, tt.want)
+		}
+	}
+
+	// the letters are only rearranged
+	seen := make(map[EvCode]bool)
+	for from, to := range r {
+		if from == KEY_CAPSLOCK {
+			continue
+		}
+		if seen[to] {
+			t.Errorf("%d is the target of several keys", to)
+		}
+		seen[to] = true
+		if _, ok := r[to]; !ok {
+			t.Errorf("%d is typed by two keys", to)
+		}
+	}
+}
+
+func TestSplitKeymap(t *testing.T) {
+	internal := &InputDevice{BusType: BUS_I8042, Vendor: 1, Product: 1, Version: 0xab41, Phys: "isa0060/serio0/input0"}
+	external := &InputDevice{BusType: BUS_USB, Vendor: 0x046d, Product: 0xc31c, Version: 0x110, Phys: "usb-0000:00:14.0-1/input0"}
+
+	if fp := internal.Fingerprint(); fp != "0011:0001:0001:ab41:isa0060/serio0/input0" {
+		t.Errorf("unexpected fingerprint %q", fp)
+	}
+
+	s := NewSplitKeymap()
+	s.Assign(internal, ColemakRemap())
+
+	// the table follows the keyboard across reconnects
+	replugged := *internal
+	replugged.Fn = "/dev/input/event9"
+
+	now := time.Unix(1000, 0)
+	tests := []struct {
+		name string
+		dev  *InputDevice
+		want EvCode
+	}{
+		{"internal", internal, KEY_F},
+		{"replugged", &replugged, KEY_F},
+		{"external", external, KEY_E},
+	}
+	for _, tt := range tests {
+		out := s.Process(tt.dev, NewInputEvent(now, EV_KEY, KEY_E, 1))
+		if len(out) != 1 || out[0].Code != tt.want {
+			t.Errorf("%s: got %v, want code %d", tt.name, out, tt.want)
+		}
+	}
+
+	s.Default = KeyRemap{KEY_E: KEY_Z}
+	if out := s.Process(external, NewInputEvent(now, EV_KEY, KEY_E, 1)); out[0].Code != KEY_Z {
+		t.Errorf("expected the default table for the external keyboard, got %v", out)
+	}
+}
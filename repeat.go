@@ -0,0 +1,66 @@
+//go:build linux
+
+package evdev
+
+import "time"
+
+// RepeatFilter synthesizes key autorepeat, which a device grabbed and
+// forwarded to a sink without EV_REP no longer gets from the kernel. Like
+// the kernel, it repeats the key pressed last, starting after Delay and
+// then every Period, until that key is released or another key pressed.
+// Buttons are not repeated, and repeats of the source are dropped so that
+// only the synthesized ones remain. Tick must be called at least every
+// Period.
+type RepeatFilter struct {
+	RepeatSettings
+
+	held bool
+	code EvCode    // key repeating
+	next time.Time // when its next repeat is due
+}
+
+// NewRepeatFilter creates a filter repeating with the given settings, e.g.
+// those of the source device (see InputDevice.RepeatSettings).
+func NewRepeatFilter(rs RepeatSettings) *RepeatFilter {
+	return &RepeatFilter{RepeatSettings: rs}
+}
+
+// Process passes events through, dropping the repeats of the source.
+func (f *RepeatFilter) Process(ev InputEvent) []InputEvent {
+	if ev.Type != EV_KEY || isButton(ev.Code) {
+		return []InputEvent{ev}
+	}
+
+	switch ev.Value {
+	case EvValue(KeyDown):
+		f.held = true
+		f.code = ev.Code
+		f.next = ev.Timestamp().Add(f.Delay)
+	case EvValue(KeyUp):
+		if f.held && ev.Code == f.code {
+			f.held = false
+		}
+	default:
+		return nil
+	}
+
+	return []InputEvent{ev}
+}
+
+// Tick emits the repeats that are due.
+func (f *RepeatFilter) Tick(now time.Time) []InputEvent {
+	if !f.held || f.Period <= 0 || now.Before(f.next) {
+		return nil
+	}
+
+	// a late tick emits a single repeat rather than catching up
+	f.next = f.next.Add(f.Period)
+	if !f.next.After(now) {
+		f.next = now.Add(f.Period)
+	}
+
+	return []InputEvent{
+		NewInputEvent(now, EV_KEY, f.code, EvValue(KeyHold)),
+		NewInputEvent(now, EV_SYN, SYN_REPORT, 0),
+	}
+}
@@ -0,0 +1,63 @@
+//go:build linux
+
+package evdev
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRepeatFilter(t *testing.T) {
+	f := NewRepeatFilter(RepeatSettings{Delay: 250 * time.Millisecond, Period: 50 * time.Millisecond})
+	t0 := time.Unix(1000, 0)
+	ms := time.Millisecond
+
+	var got []InputEvent
+	process := func(d time.Duration, code EvCode, value KeyEventState) {
+		got = append(got, f.Process(NewInputEvent(t0.Add(d), EV_KEY, code, EvValue(value)))...)
+	}
+	tick := func(from, to time.Duration) {
+		for d := from; d <= to; d += 10 * ms {
+			got = append(got, f.Tick(t0.Add(d))...)
+		}
+	}
+	repeats := func() []EvCode {
+		codes := make([]EvCode, 0)
+		for _, ev := range got {
+			if ev.Type == EV_KEY && ev.Value == EvValue(KeyHold) {
+				codes = append(codes, ev.Code)
+			}
+		}
+		got = nil
+		return codes
+	}
+
+	process(0, KEY_A, KeyDown)
+	process(100*ms, KEY_A, KeyHold) // dropped
+	tick(0, 240*ms)
+	if r := repeats(); len(r) != 0 {
+		t.Errorf("expected no repeats before the delay, got %v", r)
+	}
+
+	tick(250*ms, 390*ms)
+	if r := repeats(); len(r) != 3 {
+		t.Errorf("expected 3 repeats, got %v", r)
+	}
+
+	// the key pressed last repeats, mouse buttons never
+	process(400*ms, KEY_B, KeyDown)
+	process(410*ms, BTN_LEFT, KeyDown)
+	tick(400*ms, 690*ms)
+	if r := repeats(); len(r) != 1 || r[0] != KEY_B {
+		t.Errorf("expected KEY_B to repeat once, got %v", r)
+	}
+
+	// releasing another key does not stop the repeat
+	process(700*ms, KEY_A, KeyUp)
+	tick(700*ms, 700*ms)
+	process(710*ms, KEY_B, KeyUp)
+	tick(710*ms, 1000*ms)
+	if r := repeats(); len(r) != 1 {
+		t.Errorf("expected repeats to stop on release, got %v", r)
+	}
+}
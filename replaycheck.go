@@ -0,0 +1,242 @@
+//go:build linux
+
+package evdev
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// ReplayFrame is a frame of events, delimited by SYN_REPORT, and its time
+// relative to the first frame of its stream.
+type ReplayFrame struct {
+	Events []InputEvent // without the SYN_REPORT
+	Time   time.Duration
+}
+
+// ReplayMismatch is a frame of the output of a pipeline that differs from
+// the expected frame.
+type ReplayMismatch struct {
+	Index     int
+	Got, Want *ReplayFrame // nil if missing
+}
+
+// String describes the mismatch.
+func (m ReplayMismatch) String() string {
+	format := func(f *ReplayFrame) string {
+		if f == nil {
+			return "no frame"
+		}
+		return fmt.Sprintf("%v at %v", f.Events, f.Time)
+	}
+
+	return fmt.Sprintf("frame %d: got %s, want %s", m.Index, format(m.Got), format(m.Want))
+}
+
+// ReplayCheck replays a recording through a proxy pipeline (see Proxy) and
+// compares the output with an expected recording, e.g. one captured from
+// the virtual device of a proxy known to work. Timed filters are ticked on
+// a simulated clock following the event timestamps of the recording, so
+// checks are deterministic and run faster than real time.
+type ReplayCheck struct {
+	Filter Filter    // per event transform, may be nil
+	Frame  FrameFunc // per frame transform, may be nil
+
+	TickInterval time.Duration // simulated tick interval of timed filters
+	Drain        time.Duration // time ticked after the last event
+
+	// Frames may be off their expected time, relative to the first frame,
+	// by up to Tolerance.
+	Tolerance time.Duration
+}
+
+// Replay-time sink collecting the frames written by a pipeline.
+type replaySink struct {
+	now    time.Time
+	frames []ReplayFrame
+	times  []time.Time // when each frame was written
+	frame  []InputEvent
+}
+
+func (s *replaySink) WriteEvent(ev *InputEvent) error {
+	if ev.Type != EV_SYN || ev.Code != SYN_REPORT {
+		s.frame = append(s.frame, *ev)
+		return nil
+	}
+
+	s.frames = append(s.frames, ReplayFrame{Events: s.frame})
+	s.times = append(s.times, s.now)
+	s.frame = nil
+	return nil
+}
+
+// Get the frames written, timed relative to the first one.
+func (s *replaySink) relativeFrames() []ReplayFrame {
+	for i := range s.frames {
+		s.frames[i].Time = s.times[i].Sub(s.times[0])
+	}
+
+	return s.frames
+}
+
+// Output replays the events of a recording through the pipeline and
+// returns the frames it writes.
+func (c *ReplayCheck) Output(input *CaptureReader) ([]ReplayFrame, error) {
+	sink := &replaySink{}
+	pipe := newEventPipeline(c.Filter, c.Frame, sink)
+
+	_, timed := c.Filter.(TimedFilter)
+	ticking := timed && c.TickInterval > 0
+	var next time.Time
+
+	tickUntil := func(t time.Time) error {
+		for ticking && !next.After(t) {
+			sink.now = next
+			if err := pipe.tick(next); err != nil {
+				return err
+			}
+			next = next.Add(c.TickInterval)
+		}
+		return nil
+	}
+
+	for {
+		rec, err := input.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if rec.Kind != CaptureEventRecord || rec.Event.Code == SYN_DROPPED && rec.Event.Type == EV_SYN {
+			continue
+		}
+
+		t := rec.Event.Timestamp()
+		if next.IsZero() {
+			next = t.Add(c.TickInterval)
+		}
+		if err = tickUntil(t); err != nil {
+			return nil, err
+		}
+
+		sink.now = t
+		if err = pipe.process(rec.Event); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tickUntil(sink.now.Add(c.Drain)); err != nil {
+		return nil, err
+	}
+
+	return sink.relativeFrames(), nil
+}
+
+// ReadReplayFrames reads the frames of the events of a recording.
+func ReadReplayFrames(r *CaptureReader) ([]ReplayFrame, error) {
+	sink := &replaySink{}
+
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			return sink.relativeFrames(), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if rec.Kind != CaptureEventRecord {
+			continue
+		}
+
+		sink.now = rec.Event.Timestamp()
+		sink.WriteEvent(&rec.Event)
+	}
+}
+
+// Compare replays input through the pipeline and returns the frames that
+// differ from expected, in events or in time.
+func (c *ReplayCheck) Compare(input, expected *CaptureReader) ([]ReplayMismatch, error) {
+	got, err := c.Output(input)
+	if err != nil {
+		return nil, err
+	}
+	want, err := ReadReplayFrames(expected)
+	if err != nil {
+		return nil, err
+	}
+
+	mismatches := make([]ReplayMismatch, 0)
+	for i := 0; i < len(got) || i < len(want); i++ {
+		m := ReplayMismatch{Index: i}
+		if i < len(got) {
+			m.Got = &got[i]
+		}
+		if i < len(want) {
+			m.Want = &want[i]
+		}
+
+		if m.Got != nil && m.Want != nil && sameEvents(m.Got.Events, m.Want.Events) {
+			offset := m.Got.Time - m.Want.Time
+			if offset <= c.Tolerance && -offset <= c.Tolerance {
+				continue
+			}
+		}
+		mismatches = append(mismatches, m)
+	}
+
+	return mismatches, nil
+}
+
+// ReplayTB is the part of testing.TB used by AssertReplay.
+type ReplayTB interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// AssertReplay replays the recording at inputPath through the pipeline of c
+// and reports the first mismatches with the recording at expectedPath as
+// test errors, e.g. with recordings stored in testdata. It reports whether
+// the output matches.
+func AssertReplay(t ReplayTB, c *ReplayCheck, inputPath, expectedPath string) bool {
+	t.Helper()
+
+	open := func(path string) *CaptureReader {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Errorf("%v", err)
+			return nil
+		}
+
+		cr, err := NewCaptureReader(bytes.NewReader(data))
+		if err != nil {
+			t.Errorf("%s: %v", path, err)
+		}
+		return cr
+	}
+
+	input, expected := open(inputPath), open(expectedPath)
+	if input == nil || expected == nil {
+		return false
+	}
+
+	mismatches, err := c.Compare(input, expected)
+	if err != nil {
+		t.Errorf("replaying %s: %v", inputPath, err)
+		return false
+	}
+
+	const maxReported = 5
+	for i, m := range mismatches {
+		if i == maxReported {
+			t.Errorf("%d more mismatches", len(mismatches)-maxReported)
+			break
+		}
+		t.Errorf("%s", m)
+	}
+
+	return len(mismatches) == 0
+}
@@ -0,0 +1,71 @@
+//go:build linux
+
+package evdev
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Write a capture of one device with a frame per key event.
+func writeKeyCapture(t *testing.T, path string, start time.Time, keys ...[2]int) {
+	buf := new(bytes.Buffer)
+	cw, _ := NewCaptureWriter(buf)
+	id, _ := cw.AddDevice(&InputDevice{Name: "keyboard"})
+
+	for _, k := range keys {
+		at := start.Add(time.Duration(k[1]) * time.Millisecond)
+		ev := NewInputEvent(at, EV_KEY, KEY_A, EvValue(k[0]))
+		cw.WriteEvent(id, &ev)
+		syn := NewInputEvent(at, EV_SYN, SYN_REPORT, 0)
+		cw.WriteEvent(id, &syn)
+	}
+	cw.Flush()
+
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAssertReplay(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.evcap")
+	expected := filepath.Join(dir, "expected.evcap")
+
+	// turbo turns a held key into press/release cycles; the output starts
+	// at another time, only relative timing counts
+	writeKeyCapture(t, input, time.Unix(1000, 0), [2]int{1, 0}, [2]int{0, 50})
+	writeKeyCapture(t, expected, time.Unix(2000, 0),
+		[2]int{1, 0}, [2]int{0, 20}, [2]int{1, 41}, [2]int{0, 50})
+
+	check := &ReplayCheck{
+		Filter:       NewTurboFilter(40*time.Millisecond, KEY_A),
+		TickInterval: 10 * time.Millisecond,
+		Tolerance:    2 * time.Millisecond,
+	}
+	AssertReplay(t, check, input, expected)
+
+	// the last frame is too late
+	writeKeyCapture(t, expected, time.Unix(2000, 0),
+		[2]int{1, 0}, [2]int{0, 20}, [2]int{1, 40}, [2]int{0, 60})
+	check.Filter = NewTurboFilter(40*time.Millisecond, KEY_A)
+
+	rec := &errorRecorder{}
+	if AssertReplay(rec, check, input, expected) || len(rec.errors) != 1 {
+		t.Errorf("expected one mismatch, got %q", rec.errors)
+	}
+}
+
+type errorRecorder struct {
+	errors []string
+}
+
+func (r *errorRecorder) Helper() {}
+
+func (r *errorRecorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,37 @@
+//go:build linux
+
+package evdev
+
+// SetResilient makes reads resynchronize on malformed input instead of
+// returning garbage, for devices backed by misbehaving FUSE or uinput
+// sources (see EventDecoder). Events of unknown types are dropped. Read and
+// its variants may then return no events when a read yields only garbage.
+func (dev *InputDevice) SetResilient() {
+	if dev.decoder == nil {
+		dev.decoder = NewEventDecoder(NativeEventLayout)
+	}
+}
+
+// Anomalies returns the irregularities met so far in resilient mode.
+func (dev *InputDevice) Anomalies() StreamAnomalies {
+	if dev.decoder == nil {
+		return StreamAnomalies{}
+	}
+
+	return dev.decoder.Anomalies
+}
+
+// Read events until there is at least one, returning the first and keeping
+// the others for the next reads.
+func (dev *InputDevice) readOneDecoded(read func() ([]InputEvent, error)) (*InputEvent, error) {
+	for {
+		events, err := read()
+		if err != nil {
+			return nil, err
+		}
+		if len(events) > 0 {
+			dev.pending = append(dev.pending, events[1:]...)
+			return &events[0], nil
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package evdev
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrRolloverAborted is returned when the user aborts a rollover test by
+// holding its abort key.
+var ErrRolloverAborted = errors.New("evdev: rollover test aborted")
+
+// RolloverResult is the outcome of one key combination of a rollover test.
+type RolloverResult struct {
+	Combo   []EvCode
+	Missing []EvCode // keys never reported while the others were held, blocked by the keyboard
+	Ghosts  []EvCode // keys reported that are not part of the combination
+}
+
+// OK reports whether the combination was reported correctly.
+func (r RolloverResult) OK() bool {
+	return len(r.Missing) == 0 && len(r.Ghosts) == 0
+}
+
+// RolloverReport summarizes a rollover test.
+type RolloverReport struct {
+	Results []RolloverResult
+
+	// Rollover is the size of the largest combination reported correctly.
+	Rollover int
+}
+
+// String lists the results, one combination per line.
+func (r *RolloverReport) String() string {
+	lines := make([]string, 0, len(r.Results)+1)
+	for _, res := range r.Results {
+		outcome := "ok"
+		if len(res.Missing) > 0 {
+			outcome = "blocked " + keyNames(res.Missing, " ")
+		}
+		if len(res.Ghosts) > 0 {
+			if outcome == "ok" {
+				outcome = ""
+			} else {
+				outcome += ", "
+			}
+			outcome += "ghosts " + keyNames(res.Ghosts, " ")
+		}
+		lines = append(lines, fmt.Sprintf("%-40s %s", keyNames(res.Combo, "+"), outcome))
+	}
+	lines = append(lines, fmt.Sprintf("rollover: %d keys", r.Rollover))
+
+	return strings.Join(lines, "\n")
+}
+
+func keyNames(codes []EvCode, sep string) string {
+	names := make([]string, 0, len(codes))
+	for _, code := range codes {
+		names = append(names, keyName(code))
+	}
+
+	return strings.Join(names, sep)
+}
+
+// DefaultRolloverCombos returns combinations testing growing numbers of
+// keys of the home row, common gaming combinations and modifiers.
+func DefaultRolloverCombos() [][]EvCode {
+	homeRow := []EvCode{KEY_A, KEY_S, KEY_D, KEY_F, KEY_J, KEY_K, KEY_L, KEY_SEMICOLON}
+
+	combos := make([][]EvCode, 0)
+	for n := 2; n <= len(homeRow); n++ {
+		combos = append(combos, append([]EvCode(nil), homeRow[:n]...))
+	}
+
+	return append(combos,
+		[]EvCode{KEY_W, KEY_A, KEY_SPACE},
+		[]EvCode{KEY_W, KEY_D, KEY_LEFTSHIFT, KEY_SPACE},
+		[]EvCode{KEY_Q, KEY_W, KEY_E, KEY_R},
+		[]EvCode{KEY_LEFTCTRL, KEY_LEFTSHIFT, KEY_LEFTALT, KEY_T},
+		[]EvCode{KEY_RIGHTCTRL, KEY_RIGHTSHIFT, KEY_UP, KEY_LEFT},
+	)
+}
+
+// RolloverTest guides a user through pressing key combinations, finding
+// those the keyboard cannot report: keys blocked while others are held, and
+// ghost keys reported although not pressed. For each combination in turn,
+// the user presses and holds all keys of Current, then releases them all.
+// Keys pressed by mistake count as ghosts, so users should retry a failed
+// combination with a new test before blaming the keyboard.
+//
+// As the keyboard is usually grabbed during the test, holding AbortKey for
+// AbortHold ends it early (see Aborted), unless the key is part of the
+// current combination.
+type RolloverTest struct {
+	Combos [][]EvCode
+
+	AbortKey  EvCode
+	AbortHold time.Duration
+
+	abortDown time.Time // when the abort key was pressed, zero if released
+	step      int
+	held      map[EvCode]bool
+	best      int             // most keys of the combination held at once
+	seen      map[EvCode]bool // keys of the combination held at that time
+	ghosts    map[EvCode]bool
+	results   []RolloverResult
+}
+
+// NewRolloverTest creates a test of the given combinations, e.g.
+// DefaultRolloverCombos().
+func NewRolloverTest(combos [][]EvCode) *RolloverTest {
+	t := &RolloverTest{Combos: combos, AbortKey: KEY_ESC, AbortHold: 2 * time.Second}
+	t.reset()
+
+	return t
+}
+
+func (t *RolloverTest) reset() {
+	t.held = make(map[EvCode]bool)
+	t.best = 0
+	t.seen = make(map[EvCode]bool)
+	t.ghosts = make(map[EvCode]bool)
+}
+
+// Done reports whether all combinations were tested.
+func (t *RolloverTest) Done() bool {
+	return t.step >= len(t.Combos)
+}
+
+// Current returns the combination to press, nil when done.
+func (t *RolloverTest) Current() []EvCode {
+	if t.Done() {
+		return nil
+	}
+
+	return t.Combos[t.step]
+}
+
+// Prompt describes the combination to press, e.g. "press and hold a+s+d,
+// then release".
+func (t *RolloverTest) Prompt() string {
+	if t.Done() {
+		return "done"
+	}
+
+	return fmt.Sprintf("press and hold %s, then release", keyNames(t.Current(), "+"))
+}
+
+// Feed passes a key event to the test. It reports whether the current
+// combination was completed, by releasing all keys.
+func (t *RolloverTest) Feed(ev *InputEvent) bool {
+	if t.Done() || ev.Type != EV_KEY {
+		return false
+	}
+	if ev.Code == t.AbortKey && !t.inCombo(ev.Code) {
+		switch ev.Value {
+		case EvValue(KeyDown):
+			t.abortDown = ev.Timestamp()
+		case EvValue(KeyUp):
+			t.abortDown = time.Time{}
+		}
+		return false
+	}
+	if ev.Value == EvValue(KeyHold) {
+		return false
+	}
+
+	if ev.Value == EvValue(KeyUp) {
+		delete(t.held, ev.Code)
+		if len(t.held) > 0 || t.best == 0 && len(t.ghosts) == 0 {
+			return false
+		}
+		t.finish()
+		return true
+	}
+
+	t.held[ev.Code] = true
+
+	n := 0
+	for _, code := range t.Current() {
+		if t.held[code] {
+			n++
+		}
+	}
+	if !t.inCombo(ev.Code) {
+		t.ghosts[ev.Code] = true
+	}
+	if n > t.best {
+		t.best = n
+		for _, code := range t.Current() {
+			t.seen[code] = t.held[code]
+		}
+	}
+
+	return false
+}
+
+// Aborted reports whether the abort key has been held for AbortHold at now,
+// a time on the clock of the events.
+func (t *RolloverTest) Aborted(now time.Time) bool {
+	return !t.abortDown.IsZero() && now.Sub(t.abortDown) >= t.AbortHold
+}
+
+// AbortDue returns the time the test is aborted if the abort key stays
+// held, false if it is not held.
+func (t *RolloverTest) AbortDue() (time.Time, bool) {
+	if t.abortDown.IsZero() {
+		return time.Time{}, false
+	}
+
+	return t.abortDown.Add(t.AbortHold), true
+}
+
+// Skip gives up the current combination, counting its keys not held at once
+// as missing.
+func (t *RolloverTest) Skip() {
+	if !t.Done() {
+		t.finish()
+	}
+}
+
+func (t *RolloverTest) inCombo(code EvCode) bool {
+	for _, c := range t.Current() {
+		if c == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Record the result of the current combination and move on.
+func (t *RolloverTest) finish() {
+	res := RolloverResult{Combo: t.Current()}
+	for _, code := range res.Combo {
+		if !t.seen[code] {
+			res.Missing = append(res.Missing, code)
+		}
+	}
+	for code := range t.ghosts {
+		res.Ghosts = append(res.Ghosts, code)
+	}
+	sort.Slice(res.Ghosts, func(i, j int) bool { return res.Ghosts[i] < res.Ghosts[j] })
+
+	t.results = append(t.results, res)
+	t.step++
+	t.reset()
+}
+
+// Report returns the results of the combinations tested so far.
+func (t *RolloverTest) Report() *RolloverReport {
+	report := &RolloverReport{Results: t.results}
+	for _, res := range t.results {
+		if res.OK() && len(res.Combo) > report.Rollover {
+			report.Rollover = len(res.Combo)
+		}
+	}
+
+	return report
+}
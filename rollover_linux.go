@@ -0,0 +1,47 @@
+//go:build linux
+
+package evdev
+
+import "context"
+
+// RunRolloverTest grabs the device, so that the test keys type nothing, and
+// runs a rollover test on it, calling prompt with the instructions for each
+// combination. It returns the report once all combinations were tested, or
+// the report so far along with ctx.Err() when ctx is done, or with
+// ErrRolloverAborted when the user holds the abort key of the test. The grab
+// is released before returning.
+func (dev *InputDevice) RunRolloverTest(ctx context.Context, t *RolloverTest, prompt func(string)) (*RolloverReport, error) {
+	if err := dev.Grab(); err != nil {
+		return nil, err
+	}
+	defer dev.Release()
+
+	prompt(t.Prompt())
+	for !t.Done() {
+		var events []InputEvent
+		var err error
+		if due, ok := t.AbortDue(); ok {
+			// the abort key is held, wake up when it has been for long enough
+			events, err = dev.ReadTimeout(due.Sub(dev.Now()))
+		} else {
+			events, err = dev.ReadContext(ctx)
+		}
+		if t.Aborted(dev.Now()) {
+			return t.Report(), ErrRolloverAborted
+		}
+		if err == ErrTimeout {
+			err = ctx.Err()
+		}
+		if err != nil {
+			return t.Report(), err
+		}
+
+		for i := range events {
+			if t.Feed(&events[i]) && !t.Done() {
+				prompt(t.Prompt())
+			}
+		}
+	}
+
+	return t.Report(), nil
+}
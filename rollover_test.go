@@ -0,0 +1,119 @@
+package evdev
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRolloverTest(t *testing.T) {
+	rt := NewRolloverTest([][]EvCode{
+		{KEY_A, KEY_S, KEY_D},
+		{KEY_W, KEY_A, KEY_SPACE},
+		{KEY_Q, KEY_W, KEY_E, KEY_R},
+	})
+
+	now := time.Unix(1000, 0)
+	feed := func(code EvCode, value KeyEventState) bool {
+		ev := NewInputEvent(now, EV_KEY, code, EvValue(value))
+		return rt.Feed(&ev)
+	}
+	combo := func(down, up []EvCode) bool {
+		done := false
+		for _, code := range down {
+			feed(code, KeyDown)
+		}
+		for _, code := range up {
+			done = feed(code, KeyUp)
+		}
+		return done
+	}
+
+	if p := rt.Prompt(); p != named("press and hold a+s+d, then release", "press and hold 0x01e+0x01f+0x020, then release") {
+		t.Errorf("unexpected prompt %q", p)
+	}
+
+	// all keys reported
+	if !combo([]EvCode{KEY_A, KEY_S, KEY_D}, []EvCode{KEY_S, KEY_A, KEY_D}) {
+		t.Error("expected the combination to complete")
+	}
+
+	// space is blocked while w and a are held
+	if !combo([]EvCode{KEY_W, KEY_A}, []EvCode{KEY_W, KEY_A}) {
+		t.Error("expected the combination to complete")
+	}
+
+	// a ghost key appears, r is blocked
+	if !combo([]EvCode{KEY_Q, KEY_W, KEY_E, KEY_T}, []EvCode{KEY_Q, KEY_W, KEY_E, KEY_T}) {
+		t.Error("expected the combination to complete")
+	}
+
+	if !rt.Done() || rt.Current() != nil {
+		t.Error("expected the test to be done")
+	}
+
+	report := rt.Report()
+	want := []RolloverResult{
+		{Combo: []EvCode{KEY_A, KEY_S, KEY_D}},
+		{Combo: []EvCode{KEY_W, KEY_A, KEY_SPACE}, Missing: []EvCode{KEY_SPACE}},
+		{Combo: []EvCode{KEY_Q, KEY_W, KEY_E, KEY_R}, Missing: []EvCode{KEY_R}, Ghosts: []EvCode{KEY_T}},
+	}
+	if !reflect.DeepEqual(report.Results, want) {
+		t.Errorf("got %v, want %v", report.Results, want)
+	}
+	if report.Rollover != 3 {
+		t.Errorf("expected a rollover of 3, got %d", report.Rollover)
+	}
+	if s := report.String(); !strings.Contains(s, named("blocked r, ghosts t", "blocked 0x013, ghosts 0x014")) {
+		t.Errorf("unexpected report:\n%s", s)
+	}
+}
+
+func TestRolloverTestAbort(t *testing.T) {
+	rt := NewRolloverTest([][]EvCode{{KEY_A, KEY_S}, {KEY_ESC, KEY_A}})
+
+	now := time.Unix(1000, 0)
+	feed := func(d time.Duration, code EvCode, value KeyEventState) {
+		ev := NewInputEvent(now.Add(d), EV_KEY, code, EvValue(value))
+		rt.Feed(&ev)
+	}
+
+	// a short press of the abort key is no ghost and doesn't abort
+	feed(0, KEY_ESC, KeyDown)
+	if due, ok := rt.AbortDue(); !ok || !due.Equal(now.Add(2*time.Second)) {
+		t.Errorf("unexpected abort time %v, %v", due, ok)
+	}
+	feed(time.Second, KEY_ESC, KeyUp)
+	if rt.Aborted(now.Add(3 * time.Second)) {
+		t.Error("expected a short press not to abort")
+	}
+	if _, ok := rt.AbortDue(); ok {
+		t.Error("expected no abort pending after the release")
+	}
+
+	feed(4*time.Second, KEY_A, KeyDown)
+	feed(4*time.Second, KEY_S, KeyDown)
+	feed(5*time.Second, KEY_A, KeyUp)
+	feed(5*time.Second, KEY_S, KeyUp)
+	if res := rt.Report().Results; len(res) != 1 || !res[0].OK() {
+		t.Errorf("unexpected results %v", res)
+	}
+
+	// the abort key is tested like any other key when part of the combination
+	feed(6*time.Second, KEY_ESC, KeyDown)
+	if rt.Aborted(now.Add(10 * time.Second)) {
+		t.Error("expected the abort key of the combination not to abort")
+	}
+	feed(6*time.Second, KEY_ESC, KeyUp)
+
+	rt = NewRolloverTest([][]EvCode{{KEY_A, KEY_S}})
+	feed(0, KEY_ESC, KeyDown)
+	feed(500*time.Millisecond, KEY_ESC, KeyHold)
+	if rt.Aborted(now.Add(time.Second)) {
+		t.Error("expected no abort before AbortHold")
+	}
+	if !rt.Aborted(now.Add(2 * time.Second)) {
+		t.Error("expected the test to be aborted")
+	}
+}
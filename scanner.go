@@ -0,0 +1,109 @@
+//go:build linux
+
+package evdev
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// ScannerReader reads the codes scanned by a barcode or RFID scanner that
+// presents itself as a keyboard. It grabs the device, so that scans don't
+// type into other programs, translates the keystrokes with Mapper and
+// delivers each code terminated by enter on C, without the enter. A scan
+// not terminated within Timeout of its first keystroke is discarded, so
+// that a scan cut short doesn't corrupt the next one.
+type ScannerReader struct {
+	Device  *InputDevice
+	Mapper  *KeyMapper
+	Timeout time.Duration
+
+	// OnDiscard is called with the text of a discarded scan, may be nil.
+	OnDiscard func(partial string)
+
+	// C receives the scanned codes. It is closed when Run returns.
+	C <-chan string
+
+	c     chan string
+	text  strings.Builder
+	start time.Time // time of the first keystroke of the scan
+}
+
+// NewScannerReader creates a reader of dev, decoding keystrokes with
+// layout, e.g. USKeyboardLayout(), and discarding scans that take longer
+// than 500ms.
+func NewScannerReader(dev *InputDevice, layout KeyboardLayout) *ScannerReader {
+	c := make(chan string, 16)
+
+	return &ScannerReader{
+		Device:  dev,
+		Mapper:  NewKeyMapper(layout),
+		Timeout: 500 * time.Millisecond,
+		C:       c,
+		c:       c,
+	}
+}
+
+// Run grabs the device and delivers its scans until ctx is done or reading
+// fails. The grab is released before returning.
+func (s *ScannerReader) Run(ctx context.Context) error {
+	defer close(s.c)
+
+	if err := s.Device.Grab(); err != nil {
+		return err
+	}
+	defer s.Device.Release()
+
+	for {
+		events, err := s.Device.ReadContext(ctx)
+		if err != nil {
+			return err
+		}
+
+		for i := range events {
+			code, ok := s.feed(&events[i])
+			if !ok {
+				continue
+			}
+
+			select {
+			case s.c <- code:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// Accumulate a keystroke, returning the code once terminated.
+func (s *ScannerReader) feed(ev *InputEvent) (string, bool) {
+	r, ok := s.Mapper.Feed(ev)
+	if !ok || ev.Value != EvValue(KeyDown) {
+		return "", false
+	}
+
+	t := ev.Timestamp()
+	if s.text.Len() > 0 && t.Sub(s.start) > s.Timeout {
+		s.discard()
+	}
+	if s.text.Len() == 0 {
+		s.start = t
+	}
+
+	if r != '\n' {
+		s.text.WriteRune(r)
+		return "", false
+	}
+
+	code := s.text.String()
+	s.text.Reset()
+	return code, code != ""
+}
+
+func (s *ScannerReader) discard() {
+	if s.OnDiscard != nil {
+		s.OnDiscard(s.text.String())
+	}
+	s.text.Reset()
+}
@@ -0,0 +1,56 @@
+//go:build linux
+
+package evdev
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScannerReader(t *testing.T) {
+	s := NewScannerReader(nil, USKeyboardLayout())
+	discarded := make([]string, 0)
+	s.OnDiscard = func(partial string) { discarded = append(discarded, partial) }
+
+	now := time.Unix(1000, 0)
+	scanned := make([]string, 0)
+	// keys typed 1ms apart after a pause
+	keys := func(pause time.Duration, codes ...EvCode) {
+		now = now.Add(pause)
+		for _, code := range codes {
+			now = now.Add(time.Millisecond)
+			for _, value := range []KeyEventState{KeyDown, KeyUp} {
+				ev := NewInputEvent(now, EV_KEY, code, EvValue(value))
+				if text, ok := s.feed(&ev); ok {
+					scanned = append(scanned, text)
+				}
+			}
+		}
+	}
+	shifted := func(code EvCode) {
+		for _, value := range []KeyEventState{KeyDown, KeyUp} {
+			ev := NewInputEvent(now, EV_KEY, KEY_LEFTSHIFT, EvValue(value))
+			s.feed(&ev)
+			if value == KeyDown {
+				keys(0, code)
+			}
+		}
+	}
+
+	shifted(KEY_A)
+	keys(0, KEY_1, KEY_2, KEY_MINUS, KEY_KPENTER)
+
+	// cut short, then a complete scan
+	keys(time.Second, KEY_9, KEY_8)
+	keys(time.Second, KEY_4, KEY_2, KEY_ENTER)
+
+	// a lone enter is no scan
+	keys(time.Second, KEY_ENTER)
+
+	if len(scanned) != 2 || scanned[0] != "A12-" || scanned[1] != "42" {
+		t.Errorf("unexpected scans %q", scanned)
+	}
+	if len(discarded) != 1 || discarded[0] != "98" {
+		t.Errorf("unexpected discarded scans %q", discarded)
+	}
+}
@@ -0,0 +1,251 @@
+//go:build linux
+
+package evdev
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A script drives virtual devices with a small text language, so that
+// automation can be written and shared without Go code. Statements are
+// separated by semicolons or newlines, # starts a comment:
+//
+//	press KEY          hold a key down, e.g. "press ctrl"
+//	release KEY        release a held key
+//	tap CHORD          press and release a chord, e.g. "tap ctrl+c"
+//	type "TEXT"        type text with the US layout
+//	wait DURATION      pause, e.g. "wait 100ms"
+//	move rel DX DY     move a relative pointer
+//	move abs X Y       move an absolute pointer
+//	scroll DY [DX]     scroll by wheel clicks
+//
+// Keys are named as in chords (see ParseChord). Modifier names press the
+// left key, and left, right and middle name the mouse buttons.
+
+// ScriptStep is a single parsed statement of a script.
+type ScriptStep struct {
+	Line  int    // line of the statement in the source
+	Op    string // press, release, tap, type, wait, move or scroll
+	Code  EvCode // key of press and release
+	Chord Chord  // chord of tap
+	Text  string // text of type
+	Delay time.Duration
+	Abs   bool // move abs rather than move rel
+	X, Y  int  // coordinates of move, wheel clicks of scroll
+}
+
+// Script is a parsed script.
+type Script []ScriptStep
+
+// Mouse button names of scripts.
+var scriptButtons = map[string]EvCode{
+	"left":   BTN_LEFT,
+	"right":  BTN_RIGHT,
+	"middle": BTN_MIDDLE,
+}
+
+// ParseScript parses the source of a script.
+func ParseScript(src string) (Script, error) {
+	script := make(Script, 0)
+
+	for i, line := range strings.Split(src, "\n") {
+		statements, err := splitStatements(line)
+		if err != nil {
+			return nil, fmt.Errorf("evdev: script line %d: %v", i+1, err)
+		}
+
+		for _, stmt := range statements {
+			step, err := parseScriptStep(stmt)
+			if err != nil {
+				return nil, fmt.Errorf("evdev: script line %d: %v", i+1, err)
+			}
+			step.Line = i + 1
+			script = append(script, step)
+		}
+	}
+
+	return script, nil
+}
+
+// Split a line into statements at semicolons outside of quotes, dropping
+// comments and empty statements.
+func splitStatements(line string) ([]string, error) {
+	statements := make([]string, 0)
+	quoted, escaped := false, false
+	start := 0
+
+	end := func(i int) {
+		if stmt := strings.TrimSpace(line[start:i]); stmt != "" {
+			statements = append(statements, stmt)
+		}
+		start = i + 1
+	}
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && c == '\\':
+			escaped = true
+		case c == '"':
+			quoted = !quoted
+		case !quoted && c == ';':
+			end(i)
+		case !quoted && c == '#':
+			end(i)
+			return statements, nil
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("unterminated string")
+	}
+	end(len(line))
+
+	return statements, nil
+}
+
+func parseScriptStep(stmt string) (ScriptStep, error) {
+	fields := strings.Fields(stmt)
+	step := ScriptStep{Op: strings.ToLower(fields[0])}
+	args := fields[1:]
+
+	var err error
+	switch step.Op {
+	case "press", "release":
+		if len(args) != 1 {
+			return step, fmt.Errorf("%s takes a key", step.Op)
+		}
+		step.Code, err = parseScriptKey(args[0])
+	case "tap":
+		if len(args) != 1 {
+			return step, fmt.Errorf("tap takes a chord")
+		}
+		if code, ok := scriptButtons[strings.ToLower(args[0])]; ok {
+			step.Chord = Chord{Key: code}
+		} else {
+			step.Chord, err = ParseChord(args[0])
+		}
+	case "type":
+		text := strings.TrimSpace(stmt[len(fields[0]):])
+		if step.Text, err = strconv.Unquote(text); err != nil {
+			return step, fmt.Errorf("type takes a quoted string")
+		}
+	case "wait":
+		if len(args) != 1 {
+			return step, fmt.Errorf("wait takes a duration")
+		}
+		step.Delay, err = time.ParseDuration(args[0])
+	case "move":
+		if len(args) != 3 || (args[0] != "rel" && args[0] != "abs") {
+			return step, fmt.Errorf("move takes rel or abs and two coordinates")
+		}
+		step.Abs = args[0] == "abs"
+		step.X, step.Y, err = parseScriptInts(args[1], args[2])
+	case "scroll":
+		if len(args) == 1 {
+			args = append(args, "0")
+		}
+		if len(args) != 2 {
+			return step, fmt.Errorf("scroll takes one or two amounts")
+		}
+		step.Y, step.X, err = parseScriptInts(args[0], args[1])
+	default:
+		return step, fmt.Errorf("unknown statement %q", fields[0])
+	}
+
+	return step, err
+}
+
+func parseScriptKey(name string) (EvCode, error) {
+	name = strings.ToLower(name)
+	if code, ok := scriptButtons[name]; ok {
+		return code, nil
+	}
+	if mod := parseModifier(name); mod != 0 {
+		return modifierCodes(mod)[0], nil
+	}
+	if code, ok := parseKeyName(name); ok {
+		return code, nil
+	}
+
+	return 0, fmt.Errorf("unknown key %q", name)
+}
+
+func parseScriptInts(a, b string) (int, int, error) {
+	x, err := strconv.Atoi(a)
+	if err != nil {
+		return 0, 0, err
+	}
+	y, err := strconv.Atoi(b)
+	return x, y, err
+}
+
+// Run executes the script, writing events to w, typically a uinput device.
+// Every action is followed by a SYN_REPORT. Run stops early when ctx is
+// done, leaving keys pressed by the script held.
+func (s Script) Run(ctx context.Context, w EventWriter) error {
+	k := &OnScreenKeyboard{Writer: w, Layout: USKeyboardLayout()}
+
+	for _, step := range s {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var err error
+		switch step.Op {
+		case "press":
+			err = k.write(step.Code, EvValue(KeyDown))
+		case "release":
+			err = k.write(step.Code, EvValue(KeyUp))
+		case "tap":
+			err = k.tapChord(step.Chord)
+		case "type":
+			err = k.TypeText(step.Text)
+		case "wait":
+			err = sleepUntil(ctx, time.Now().Add(step.Delay))
+		case "move":
+			if step.Abs {
+				err = writeFrame(w, EV_ABS, []EvCode{ABS_X, ABS_Y}, []int{step.X, step.Y}, true)
+			} else {
+				err = writeFrame(w, EV_REL, []EvCode{REL_X, REL_Y}, []int{step.X, step.Y}, false)
+			}
+		case "scroll":
+			err = writeFrame(w, EV_REL, []EvCode{REL_HWHEEL, REL_WHEEL}, []int{step.X, step.Y}, false)
+		}
+		if err != nil {
+			return fmt.Errorf("evdev: script line %d: %v", step.Line, err)
+		}
+	}
+
+	return nil
+}
+
+// Write a frame setting codes to values, leaving out zero values unless all
+// are wanted. Nothing is written if no values are left.
+func writeFrame(w EventWriter, evType EvType, codes []EvCode, values []int, all bool) error {
+	now := time.Now()
+	written := false
+
+	for i, code := range codes {
+		if values[i] == 0 && !all {
+			continue
+		}
+
+		ev := NewInputEvent(now, evType, code, EvValue(values[i]))
+		if err := w.WriteEvent(&ev); err != nil {
+			return err
+		}
+		written = true
+	}
+	if !written {
+		return nil
+	}
+
+	syn := NewInputEvent(now, EV_SYN, SYN_REPORT, 0)
+	return w.WriteEvent(&syn)
+}
@@ -0,0 +1,64 @@
+//go:build linux
+
+package evdev
+
+import (
+	"context"
+	"testing"
+)
+
+func TestScript(t *testing.T) {
+	script, err := ParseScript(`# copy and paste
+press ctrl; tap c; release ctrl
+wait 1ms; tap ctrl+v   # paste
+type "a;B"
+move rel 10 0; move abs 5 7; scroll -1
+tap left`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(script) != 10 || script[4].Line != 3 || script[5].Text != "a;B" {
+		t.Fatalf("unexpected script %+v", script)
+	}
+
+	rec := &eventRecorder{}
+	if err := script.Run(context.Background(), rec); err != nil {
+		t.Fatal(err)
+	}
+
+	type kv struct {
+		evType EvType
+		code   EvCode
+		value  EvValue
+	}
+	want := []kv{
+		{EV_KEY, KEY_LEFTCTRL, 1}, {EV_KEY, KEY_C, 1}, {EV_KEY, KEY_C, 0}, {EV_KEY, KEY_LEFTCTRL, 0},
+		{EV_KEY, KEY_LEFTCTRL, 1}, {EV_KEY, KEY_V, 1}, {EV_KEY, KEY_V, 0}, {EV_KEY, KEY_LEFTCTRL, 0},
+		{EV_KEY, KEY_A, 1}, {EV_KEY, KEY_A, 0},
+		{EV_KEY, KEY_SEMICOLON, 1}, {EV_KEY, KEY_SEMICOLON, 0},
+		{EV_KEY, KEY_LEFTSHIFT, 1}, {EV_KEY, KEY_B, 1}, {EV_KEY, KEY_B, 0}, {EV_KEY, KEY_LEFTSHIFT, 0},
+		{EV_REL, REL_X, 10}, {EV_ABS, ABS_X, 5}, {EV_ABS, ABS_Y, 7}, {EV_REL, REL_WHEEL, -1},
+		{EV_KEY, BTN_LEFT, 1}, {EV_KEY, BTN_LEFT, 0},
+	}
+
+	got := make([]kv, 0)
+	for _, ev := range *rec {
+		if ev.Type != EV_SYN {
+			got = append(got, kv{ev.Type, ev.Code, ev.Value})
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d events, want %d: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("event %d: got %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	for _, src := range []string{"press nokey", "wait soon", "move up 1 2", `type "open`, "jump"} {
+		if _, err := ParseScript(src); err == nil {
+			t.Errorf("expected error for %q", src)
+		}
+	}
+}
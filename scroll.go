@@ -0,0 +1,86 @@
+package evdev
+
+// Hi-res wheel units per wheel detent.
+const wheelHiResPerDetent = 120
+
+// ScrollDelta is the scroll motion of one frame. Positive values scroll down
+// and to the right, as in libinput.
+type ScrollDelta struct {
+	DX, DY             int // motion in pixels
+	DetentsX, DetentsY int // completed wheel detents (clicks)
+}
+
+// ScrollAccumulator converts wheel events into pixel deltas. Devices with
+// high-resolution wheels report fractions of a detent, which are
+// accumulated so that no motion is lost to rounding. When a device emits
+// both REL_WHEEL_HI_RES and the legacy REL_WHEEL events, only the former are
+// used.
+type ScrollAccumulator struct {
+	PixelsPerDetent float64 // pixels scrolled per wheel detent
+
+	hiRes  bool       // device emits hi-res wheel events
+	frame  [2]int     // hi-res units of the current frame (x, y)
+	units  [2]int     // hi-res units towards the next detent
+	pixels [2]float64 // fractional pixels not reported yet
+}
+
+// NewScrollAccumulator creates an accumulator scrolling pixelsPerDetent
+// pixels per wheel detent.
+func NewScrollAccumulator(pixelsPerDetent float64) *ScrollAccumulator {
+	return &ScrollAccumulator{PixelsPerDetent: pixelsPerDetent}
+}
+
+// Feed passes an event to the accumulator. At every SYN_REPORT following
+// wheel motion it returns the scroll delta of the frame and true.
+func (a *ScrollAccumulator) Feed(ev *InputEvent) (ScrollDelta, bool) {
+	switch {
+	case ev.Type == EV_REL:
+		a.addMotion(ev.Code, int(ev.Value))
+	case ev.Type == EV_SYN && ev.Code == SYN_REPORT:
+		if a.frame != [2]int{} {
+			return a.flush(), true
+		}
+	}
+
+	return ScrollDelta{}, false
+}
+
+func (a *ScrollAccumulator) addMotion(code EvCode, value int) {
+	switch code {
+	case REL_WHEEL_HI_RES:
+		a.hiRes = true
+		a.frame[1] -= value
+	case REL_HWHEEL_HI_RES:
+		a.hiRes = true
+		a.frame[0] += value
+	case REL_WHEEL:
+		if !a.hiRes {
+			a.frame[1] -= value * wheelHiResPerDetent
+		}
+	case REL_HWHEEL:
+		if !a.hiRes {
+			a.frame[0] += value * wheelHiResPerDetent
+		}
+	}
+}
+
+func (a *ScrollAccumulator) flush() ScrollDelta {
+	var pixels, detents [2]int
+
+	for i, units := range a.frame {
+		a.pixels[i] += float64(units) * a.PixelsPerDetent / wheelHiResPerDetent
+		pixels[i] = int(a.pixels[i])
+		a.pixels[i] -= float64(pixels[i])
+
+		// a change of direction discards the partial detent
+		if (a.units[i] < 0 && units > 0) || (a.units[i] > 0 && units < 0) {
+			a.units[i] = 0
+		}
+		a.units[i] += units
+		detents[i] = a.units[i] / wheelHiResPerDetent
+		a.units[i] -= detents[i] * wheelHiResPerDetent
+	}
+	a.frame = [2]int{}
+
+	return ScrollDelta{DX: pixels[0], DY: pixels[1], DetentsX: detents[0], DetentsY: detents[1]}
+}
@@ -0,0 +1,35 @@
+package evdev
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScrollAccumulator(t *testing.T) {
+	a := NewScrollAccumulator(15)
+	now := time.Now()
+	syn := NewInputEvent(now, EV_SYN, SYN_REPORT, 0)
+
+	// quarter detents down, with fractional pixels carried over between
+	// frames; the legacy event must not count twice
+	for i, want := range []ScrollDelta{{0, 3, 0, 0}, {0, 4, 0, 0}, {0, 4, 0, 0}, {0, 4, 0, 1}} {
+		hiRes := NewInputEvent(now, EV_REL, REL_WHEEL_HI_RES, -30)
+		a.Feed(&hiRes)
+		if i == 3 {
+			legacy := NewInputEvent(now, EV_REL, REL_WHEEL, -1)
+			a.Feed(&legacy)
+		}
+
+		delta, ok := a.Feed(&syn)
+		if !ok {
+			t.Fatal("no delta at SYN_REPORT")
+		}
+		if delta != want {
+			t.Errorf("frame %d: got %+v, want %+v", i, delta, want)
+		}
+	}
+
+	if _, ok := a.Feed(&syn); ok {
+		t.Error("delta reported for frame without motion")
+	}
+}
@@ -0,0 +1,163 @@
+//go:build linux
+
+package evdev
+
+import "time"
+
+// VirtualSeat is a virtual keyboard and pointer under one handle, e.g. for
+// remote desktop style injectors. Both may be one uinput device, or two
+// separate ones for consumers that expect keyboards and pointers to be
+// distinct devices. Events written to the seat are routed by kind: buttons
+// and relative motion go to the pointer, all other events to the keyboard.
+type VirtualSeat struct {
+	Keyboard *UInputDevice
+	Pointer  *UInputDevice // same as Keyboard for combined seats
+
+	dirty map[*UInputDevice]bool // devices written to since the last SYN_REPORT
+}
+
+// SeatKeyboardCapabilities returns the capabilities of the keyboard of a
+// seat: the keys up to KEY_MICMUTE, with kernel autorepeat. Buttons are
+// left out, as they would make udev and libinput take the keyboard for a
+// mouse, joystick or tablet.
+func SeatKeyboardCapabilities() CapabilitySet {
+	caps := CapabilitySet{}
+	for code := KEY_ESC; code <= KEY_MICMUTE; code++ {
+		if !isButton(EvCode(code)) {
+			caps.Add(EV_KEY, code)
+		}
+	}
+	caps.Add(EV_REP)
+
+	return caps
+}
+
+// SeatPointerCapabilities returns the capabilities of the pointer of a seat:
+// five buttons, relative motion and both wheels, also in high resolution.
+func SeatPointerCapabilities() CapabilitySet {
+	caps := CapabilitySet{}
+	caps.Add(EV_KEY, BTN_LEFT, BTN_RIGHT, BTN_MIDDLE, BTN_SIDE, BTN_EXTRA)
+	caps.Add(EV_REL, REL_X, REL_Y, REL_WHEEL, REL_HWHEEL, REL_WHEEL_HI_RES, REL_HWHEEL_HI_RES)
+
+	return caps
+}
+
+// CreateSeat creates a virtual seat named name. A combined seat is a single
+// device with the capabilities of both; otherwise the keyboard and pointer
+// are created as name+" keyboard" and name+" pointer".
+func CreateSeat(name string, combined bool) (*VirtualSeat, error) {
+	if combined {
+		caps := SeatKeyboardCapabilities()
+		for evType, codes := range SeatPointerCapabilities() {
+			caps.Add(evType, codes...)
+		}
+
+		dev, err := CreateDevice(UInputSetup{Name: name, BusType: BUS_VIRTUAL, Capabilities: caps})
+		if err != nil {
+			return nil, err
+		}
+		return newSeat(dev, dev), nil
+	}
+
+	kbd, err := CreateDevice(UInputSetup{
+		Name:         name + " keyboard",
+		BusType:      BUS_VIRTUAL,
+		Capabilities: SeatKeyboardCapabilities(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ptr, err := CreateDevice(UInputSetup{
+		Name:         name + " pointer",
+		BusType:      BUS_VIRTUAL,
+		Capabilities: SeatPointerCapabilities(),
+	})
+	if err != nil {
+		kbd.Close()
+		return nil, err
+	}
+
+	return newSeat(kbd, ptr), nil
+}
+
+func newSeat(kbd, ptr *UInputDevice) *VirtualSeat {
+	return &VirtualSeat{Keyboard: kbd, Pointer: ptr, dirty: make(map[*UInputDevice]bool)}
+}
+
+// Get the device an event is routed to.
+func (s *VirtualSeat) route(ev *InputEvent) *UInputDevice {
+	switch {
+	case ev.Type == EV_REL:
+		return s.Pointer
+	case ev.Type == EV_KEY && isButton(ev.Code):
+		return s.Pointer
+	}
+
+	return s.Keyboard
+}
+
+// WriteEvent writes an event to the keyboard or pointer. SYN_REPORTs are
+// written to the devices written to since the last one.
+func (s *VirtualSeat) WriteEvent(ev *InputEvent) error {
+	if ev.Type != EV_SYN || ev.Code != SYN_REPORT {
+		dev := s.route(ev)
+		s.dirty[dev] = true
+		return dev.WriteEvent(ev)
+	}
+
+	for dev := range s.dirty {
+		delete(s.dirty, dev)
+		if err := dev.WriteEvent(ev); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Emit writes an event with the given type, code and value.
+func (s *VirtualSeat) Emit(evType EvType, code EvCode, value EvValue) error {
+	ev := NewInputEvent(time.Now(), evType, code, value)
+	return s.WriteEvent(&ev)
+}
+
+// KeyPress presses and releases a key.
+func (s *VirtualSeat) KeyPress(code EvCode) error {
+	return s.Keyboard.KeyPress(code)
+}
+
+// TypeString types text with the US layout, pressing shift as needed.
+func (s *VirtualSeat) TypeString(text string) error {
+	return s.Keyboard.TypeString(text)
+}
+
+// Click presses and releases a mouse button, e.g. BTN_LEFT.
+func (s *VirtualSeat) Click(button EvCode) error {
+	return s.Pointer.Click(button)
+}
+
+// MoveRel moves the pointer.
+func (s *VirtualSeat) MoveRel(dx, dy int) error {
+	return s.Pointer.MoveRel(dx, dy)
+}
+
+// Scroll scrolls by wheel clicks, positive dy scrolling up and positive dx
+// scrolling right. The high resolution wheels scroll along.
+func (s *VirtualSeat) Scroll(dx, dy int) error {
+	return writeFrame(s.Pointer, EV_REL,
+		[]EvCode{REL_HWHEEL, REL_WHEEL, REL_HWHEEL_HI_RES, REL_WHEEL_HI_RES},
+		[]int{dx, dy, dx * wheelHiResPerDetent, dy * wheelHiResPerDetent}, false)
+}
+
+// Close destroys the devices of the seat.
+func (s *VirtualSeat) Close() error {
+	err := s.Keyboard.Close()
+	if s.Pointer != s.Keyboard {
+		if perr := s.Pointer.Close(); err == nil {
+			err = perr
+		}
+	}
+
+	return err
+}
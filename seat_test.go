@@ -0,0 +1,37 @@
+//go:build linux
+
+package evdev
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSeatRouting(t *testing.T) {
+	caps := SeatKeyboardCapabilities()
+	for _, code := range caps[EV_KEY] {
+		if isButton(EvCode(code)) {
+			t.Errorf("keyboard has button %s", CodeName(EV_KEY, code))
+		}
+	}
+	if !caps.Has(EV_KEY, KEY_A) || !caps.Has(EV_KEY, KEY_MICMUTE) {
+		t.Error("keyboard lacks keys")
+	}
+
+	s := newSeat(&UInputDevice{}, &UInputDevice{})
+	for _, c := range []struct {
+		code    EvCode
+		pointer bool
+	}{
+		{KEY_A, false},
+		{KEY_OK, false},
+		{BTN_LEFT, true},
+		{BTN_DPAD_UP, true},
+		{BTN_TRIGGER_HAPPY1, true},
+	} {
+		ev := NewInputEvent(time.Unix(1000, 0), EV_KEY, c.code, 1)
+		if (s.route(&ev) == s.Pointer) != c.pointer {
+			t.Errorf("%s routed to the wrong device", CodeName(EV_KEY, int(c.code)))
+		}
+	}
+}
@@ -0,0 +1,156 @@
+//go:build linux
+
+package evdev
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// SelfTestCheck is the outcome of a single check of a device self-test.
+type SelfTestCheck struct {
+	Name string
+	Err  error // nil if the check passed
+}
+
+// SelfTestReport is the result of InputDevice.SelfTest.
+type SelfTestReport struct {
+	Device     string          // path to the tested device
+	Checks     []SelfTestCheck // individual checks, in the order they were run
+	EventsRead int             // events received during the read check
+}
+
+// OK reports whether all checks passed.
+func (r *SelfTestReport) OK() bool {
+	for _, c := range r.Checks {
+		if c.Err != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r *SelfTestReport) String() string {
+	s := fmt.Sprintf("self-test of %s:", r.Device)
+
+	for _, c := range r.Checks {
+		result := "ok"
+		if c.Err != nil {
+			result = c.Err.Error()
+		}
+		s += fmt.Sprintf("\n  %-12s %s", c.Name, result)
+	}
+
+	return s
+}
+
+// SelfTest runs a series of health checks on the device: that its node
+// still exists, that it responds to basic ioctls, that its absolute axes
+// have sane ranges and that events read from it within readTimeout are
+// correctly framed. Events consumed by the read check are discarded.
+func (dev *InputDevice) SelfTest(readTimeout time.Duration) *SelfTestReport {
+	report := &SelfTestReport{Device: dev.Fn}
+	check := func(name string, err error) {
+		report.Checks = append(report.Checks, SelfTestCheck{name, err})
+	}
+
+	check("node", dev.checkNode())
+
+	info := deviceInfo{}
+	check("EVIOCGID", errnoErr(ioctl(dev.File.Fd(), uintptr(EVIOCGID), unsafe.Pointer(&info))))
+
+	version := new(int32)
+	check("EVIOCGVERSION", errnoErr(ioctl(dev.File.Fd(), uintptr(EVIOCGVERSION), unsafe.Pointer(version))))
+
+	name := new([MAX_NAME_SIZE]byte)
+	check("EVIOCGNAME", errnoErr(ioctl(dev.File.Fd(), uintptr(EVIOCGNAME), unsafe.Pointer(name))))
+
+	check("abs ranges", dev.checkAbsRanges())
+
+	n, err := dev.checkFraming(readTimeout)
+	report.EventsRead = n
+	check("read", err)
+
+	return report
+}
+
+// Check that the device node still exists and refers to the opened device.
+func (dev *InputDevice) checkNode() error {
+	var node, opened syscall.Stat_t
+
+	if err := syscall.Stat(dev.Fn, &node); err != nil {
+		return err
+	}
+	if err := syscall.Fstat(int(dev.File.Fd()), &opened); err != nil {
+		return err
+	}
+
+	if node.Rdev != opened.Rdev {
+		return errors.New("node refers to a different device")
+	}
+
+	return nil
+}
+
+func (dev *InputDevice) checkAbsRanges() error {
+	for evType, codes := range dev.Capabilities {
+		if evType.Type != EV_ABS {
+			continue
+		}
+
+		for _, code := range codes {
+			info, err := dev.AbsInfo(code.Code)
+			if err != nil {
+				return fmt.Errorf("%s: %v", code.Name, err)
+			}
+			if err := checkAbsRange(info); err != nil {
+				return fmt.Errorf("%s: %v", code.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// An axis with min == max is valid: the kernel reports such fixed ranges
+// for e.g. unused hat or pressure axes.
+func checkAbsRange(info AbsInfo) error {
+	if info.Min > info.Max {
+		return fmt.Errorf("invalid range [%d, %d]", info.Min, info.Max)
+	}
+	return nil
+}
+
+// Read whatever arrives within timeout and verify that the read data
+// consists of whole input events.
+func (dev *InputDevice) checkFraming(timeout time.Duration) (int, error) {
+	ready, err := waitReadable(dev.File.Fd(), timeout)
+	if err != nil || !ready {
+		return 0, err
+	}
+
+	buffer := make([]byte, eventsize*64)
+	n, err := dev.File.Read(buffer)
+	if err != nil {
+		return 0, err
+	}
+
+	if n%eventsize != 0 {
+		return n / eventsize, fmt.Errorf("read %d bytes, not a multiple of %d", n, eventsize)
+	}
+
+	return n / eventsize, nil
+}
+
+// Convert an ioctl result to an error, mapping success to nil.
+func errnoErr(err syscall.Errno) error {
+	if err != 0 {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+//go:build linux
+
+package evdev
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+func TestSelfTest(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	// a pipe passes the node check, but none of the ioctls
+	dev := &InputDevice{Fn: fmt.Sprintf("/proc/self/fd/%d", r.Fd()), File: r}
+
+	ev := NewInputEvent(time.Unix(1000, 0), EV_KEY, KEY_A, 1)
+	w.Write(unsafe.Slice((*byte)(unsafe.Pointer(&ev)), eventsize))
+	w.Write([]byte{1, 2, 3})
+
+	report := dev.SelfTest(time.Second)
+	if report.OK() {
+		t.Error("expected the self-test to fail")
+	}
+	if report.EventsRead != 1 {
+		t.Errorf("expected 1 event read, got %d", report.EventsRead)
+	}
+
+	tests := []struct {
+		name  string
+		errno syscall.Errno
+		fails bool
+	}{
+		{"node", 0, false},
+		{"EVIOCGID", syscall.ENOTTY, true},
+		{"EVIOCGVERSION", syscall.ENOTTY, true},
+		{"EVIOCGNAME", syscall.ENOTTY, true},
+		{"abs ranges", 0, false},
+		{"read", 0, true},
+	}
+	if len(report.Checks) != len(tests) {
+		t.Fatalf("expected %d checks, got %v", len(tests), report.Checks)
+	}
+	for i, tt := range tests {
+		c := report.Checks[i]
+		switch {
+		case c.Name != tt.name:
+			t.Errorf("check %d is %q, want %q", i, c.Name, tt.name)
+		case (c.Err != nil) != tt.fails:
+			t.Errorf("%s: unexpected result %v", c.Name, c.Err)
+		case tt.errno != 0 && !errors.Is(c.Err, tt.errno):
+			t.Errorf("%s: expected %v, got %v", c.Name, tt.errno, c.Err)
+		}
+	}
+
+	if s := report.String(); !strings.Contains(s, "node         ok") || !strings.Contains(s, "not a multiple of") {
+		t.Errorf("unexpected report:\n%s", s)
+	}
+}
+
+func TestSelfTestMissingNode(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	dev := &InputDevice{Fn: "/nonexistent/event0", File: r}
+	report := dev.SelfTest(time.Millisecond)
+	if c := report.Checks[0]; c.Name != "node" || !errors.Is(c.Err, syscall.ENOENT) {
+		t.Errorf("expected a missing node, got %+v", c)
+	}
+	if c := report.Checks[len(report.Checks)-1]; c.Err != nil || report.EventsRead != 0 {
+		t.Errorf("expected an idle read to pass, got %+v", c)
+	}
+}
+
+func TestCheckAbsRange(t *testing.T) {
+	tests := []struct {
+		min, max int32
+		fails    bool
+	}{
+		{0, 255, false},
+		{-32768, 32767, false},
+		{0, 0, false},
+		{1, 0, true},
+	}
+	for _, tt := range tests {
+		err := checkAbsRange(AbsInfo{Min: tt.min, Max: tt.max})
+		if (err != nil) != tt.fails {
+			t.Errorf("[%d, %d]: unexpected result %v", tt.min, tt.max, err)
+		}
+	}
+}
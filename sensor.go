@@ -0,0 +1,98 @@
+//go:build linux
+
+package evdev
+
+import "time"
+
+// SensorSample holds the values of all axes of a sensor at one point in time.
+type SensorSample struct {
+	Time   time.Time
+	Values map[EvCode]float64 // scaled and smoothed value per ABS_* axis
+}
+
+// Sensor streams the axes of an EV_ABS-only device such as an accelerometer
+// or a rudder. Raw values are divided by the resolution of their axis (e.g.
+// units per g), averaged over the last Window frames and only every
+// Decimate-th frame yields a sample.
+type Sensor struct {
+	Scale    map[EvCode]float64 // factor applied to the raw values per axis
+	Window   int                // number of frames averaged, 1 for no smoothing
+	Decimate int                // frames per sample, 1 for every frame
+
+	raw     map[EvCode]EvValue
+	history map[EvCode][]float64
+	frames  int
+}
+
+// NewSensor creates a sensor for the absolute axes of dev, scaled by their
+// resolution where the device reports one.
+func NewSensor(dev *InputDevice) (*Sensor, error) {
+	s := &Sensor{
+		Scale:    make(map[EvCode]float64),
+		Window:   1,
+		Decimate: 1,
+		raw:      make(map[EvCode]EvValue),
+		history:  make(map[EvCode][]float64),
+	}
+
+	for _, axis := range dev.CodesFor(EV_ABS) {
+		info, err := dev.AbsInfo(axis)
+		if err != nil {
+			return nil, err
+		}
+
+		s.Scale[EvCode(axis)] = 1
+		if info.Resolution > 0 {
+			s.Scale[EvCode(axis)] = 1 / float64(info.Resolution)
+		}
+		s.raw[EvCode(axis)] = EvValue(info.Value)
+	}
+
+	return s, nil
+}
+
+// Feed passes an event of the device to the sensor. It returns a sample and
+// true at the SYN_REPORTs selected by Decimate.
+func (s *Sensor) Feed(ev *InputEvent) (SensorSample, bool) {
+	switch {
+	case ev.Type == EV_ABS:
+		if _, ok := s.Scale[ev.Code]; ok {
+			s.raw[ev.Code] = ev.Value
+		}
+	case ev.Type == EV_SYN && ev.Code == SYN_REPORT:
+		return s.frame(ev.Timestamp())
+	}
+
+	return SensorSample{}, false
+}
+
+func (s *Sensor) frame(t time.Time) (SensorSample, bool) {
+	window := s.Window
+	if window < 1 {
+		window = 1
+	}
+
+	for axis, value := range s.raw {
+		h := append(s.history[axis], float64(value)*s.Scale[axis])
+		if len(h) > window {
+			h = h[len(h)-window:]
+		}
+		s.history[axis] = h
+	}
+
+	s.frames++
+	if s.Decimate > 1 && s.frames%s.Decimate != 0 {
+		return SensorSample{}, false
+	}
+
+	sample := SensorSample{Time: t, Values: make(map[EvCode]float64, len(s.history))}
+	for axis, h := range s.history {
+		sum := 0.0
+		for _, v := range h {
+			sum += v
+		}
+		sample.Values[axis] = sum / float64(len(h))
+	}
+
+	return sample, true
+}
@@ -0,0 +1,61 @@
+//go:build linux
+
+package evdev
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSensor(t *testing.T) {
+	// an accelerometer reporting 100 units per g on X and Y, unscaled Z
+	s := &Sensor{
+		Scale:    map[EvCode]float64{ABS_X: 0.01, ABS_Y: 0.01, ABS_Z: 1},
+		Window:   2,
+		Decimate: 2,
+		raw:      map[EvCode]EvValue{ABS_X: 0, ABS_Y: 0, ABS_Z: 5},
+		history:  make(map[EvCode][]float64),
+	}
+	t0 := time.Unix(1000, 0)
+
+	frames := []struct {
+		x, y   int
+		sample bool
+		want   map[EvCode]float64
+	}{
+		{100, -50, false, nil},
+		{300, -50, true, map[EvCode]float64{ABS_X: 2, ABS_Y: -0.5, ABS_Z: 5}},
+		{500, 50, false, nil},
+		{500, 150, true, map[EvCode]float64{ABS_X: 5, ABS_Y: 1, ABS_Z: 5}},
+	}
+	for i, f := range frames {
+		at := t0.Add(time.Duration(i) * 10 * time.Millisecond)
+		for _, ev := range []InputEvent{
+			NewInputEvent(at, EV_ABS, ABS_X, EvValue(f.x)),
+			NewInputEvent(at, EV_ABS, ABS_Y, EvValue(f.y)),
+			NewInputEvent(at, EV_ABS, ABS_RX, 1000), // not an axis of the sensor
+		} {
+			if _, ok := s.Feed(&ev); ok {
+				t.Fatalf("frame %d: sample before SYN_REPORT", i)
+			}
+		}
+
+		syn := NewInputEvent(at, EV_SYN, SYN_REPORT, 0)
+		sample, ok := s.Feed(&syn)
+		if ok != f.sample {
+			t.Fatalf("frame %d: got a sample %v, want %v", i, ok, f.sample)
+		}
+		if !ok {
+			continue
+		}
+		if !sample.Time.Equal(at) || len(sample.Values) != len(f.want) {
+			t.Errorf("frame %d: unexpected sample %+v", i, sample)
+		}
+		for axis, want := range f.want {
+			if got := sample.Values[axis]; math.Abs(got-want) > 1e-9 {
+				t.Errorf("frame %d: axis %d is %v, want %v", i, axis, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,102 @@
+//go:build linux
+
+package evdev
+
+import "sort"
+
+// CapabilitySet holds the sorted codes of every supported event type.
+type CapabilitySet map[int][]int
+
+// CapabilitySet returns a copy of the capabilities of the device.
+func (dev *InputDevice) CapabilitySet() CapabilitySet {
+	caps := make(CapabilitySet)
+	for _, evType := range dev.EventTypes() {
+		caps[evType] = dev.CodesFor(evType)
+	}
+
+	return caps
+}
+
+// Has reports whether a code of an event type is supported.
+func (s CapabilitySet) Has(evType, code int) bool {
+	codes := s[evType]
+	i := sort.SearchInts(codes, code)
+
+	return i < len(codes) && codes[i] == code
+}
+
+// Add adds codes of an event type to the set.
+func (s CapabilitySet) Add(evType int, codes ...int) {
+	for _, code := range codes {
+		if !s.Has(evType, code) {
+			s[evType] = append(s[evType], code)
+			sort.Ints(s[evType])
+		}
+	}
+	if _, ok := s[evType]; !ok {
+		s[evType] = []int{}
+	}
+}
+
+func (s CapabilitySet) clone() CapabilitySet {
+	c := make(CapabilitySet, len(s))
+	for evType, codes := range s {
+		c[evType] = append([]int{}, codes...)
+	}
+
+	return c
+}
+
+// CapabilityShim makes a virtual clone of a device present capabilities
+// a legacy consumer expects but the device lacks. Apply adds the emulated
+// capabilities and Filter, if not nil, generates the emulated events from
+// the events of the device.
+type CapabilityShim struct {
+	Name   string
+	Apply  func(caps CapabilitySet)
+	Filter Filter
+}
+
+// RepeatShim advertises EV_REP, for consumers that only treat devices with
+// autorepeat as keyboards. Virtual devices with EV_REP get autorepeat from
+// the kernel, so no filter is needed.
+func RepeatShim() CapabilityShim {
+	return CapabilityShim{
+		Name: "repeat",
+		Apply: func(caps CapabilitySet) {
+			caps.Add(EV_REP, REP_DELAY, REP_PERIOD)
+		},
+	}
+}
+
+// ApplyShims returns the capabilities extended by the shims and a filter
+// chaining the filters of the shims.
+func ApplyShims(caps CapabilitySet, shims ...CapabilityShim) (CapabilitySet, Filter) {
+	caps = caps.clone()
+	chain := make(Chain, 0)
+
+	for _, shim := range shims {
+		shim.Apply(caps)
+		if shim.Filter != nil {
+			chain = append(chain, shim.Filter)
+		}
+	}
+
+	return caps, chain
+}
+
+// SingleTouchShim advertises single-touch axes and buttons emulated from the
+// multi-touch data of the device, see SingleTouchEmulator.
+func SingleTouchShim() CapabilityShim {
+	return CapabilityShim{
+		Name: "single-touch",
+		Apply: func(caps CapabilitySet) {
+			caps.Add(EV_ABS, ABS_X, ABS_Y)
+			if caps.Has(EV_ABS, ABS_MT_PRESSURE) {
+				caps.Add(EV_ABS, ABS_PRESSURE)
+			}
+			caps.Add(EV_KEY, BTN_TOUCH, BTN_TOOL_FINGER, BTN_TOOL_DOUBLETAP, BTN_TOOL_TRIPLETAP, BTN_TOOL_QUADTAP, BTN_TOOL_QUINTTAP)
+		},
+		Filter: NewSingleTouchEmulator(),
+	}
+}
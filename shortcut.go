@@ -0,0 +1,45 @@
+//go:build linux
+
+package evdev
+
+import "time"
+
+// RecordChord grabs the device and records the next chord the user presses
+// on it, for settings dialogs that let users bind custom shortcuts. It
+// returns ErrTimeout if no chord is completed within timeout. The grab is
+// released before returning.
+func (dev *InputDevice) RecordChord(timeout time.Duration) (Chord, error) {
+	if err := dev.Grab(); err != nil {
+		return Chord{}, err
+	}
+	defer dev.Release()
+
+	recorder := ChordRecorder{}
+	deadline := time.Now().Add(timeout)
+
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return Chord{}, ErrTimeout
+		}
+
+		ready, err := waitReadable(dev.File.Fd(), remaining)
+		if err != nil {
+			return Chord{}, err
+		}
+		if !ready {
+			continue
+		}
+
+		events, err := dev.Read()
+		if err != nil {
+			return Chord{}, err
+		}
+
+		for i := range events {
+			if chord, ok := recorder.Feed(&events[i]); ok {
+				return chord, nil
+			}
+		}
+	}
+}
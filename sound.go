@@ -0,0 +1,36 @@
+//go:build linux
+
+package evdev
+
+import "time"
+
+// SoundState returns the set of EV_SND codes currently playing.
+func (dev *InputDevice) SoundState() (Bitset, error) {
+	return dev.stateBits(uintptr(EVIOCGSND))
+}
+
+// ActiveSounds returns the EV_SND codes currently playing, e.g. SND_TONE.
+func (dev *InputDevice) ActiveSounds() ([]int, error) {
+	sounds, err := dev.SoundState()
+	if err != nil {
+		return nil, err
+	}
+
+	return sounds.Codes(), nil
+}
+
+// Beep drives the sound output of a PC speaker style device. For SND_TONE
+// the value is the frequency in Hz, for SND_BELL and SND_CLICK it turns the
+// sound on (1) or off (0); a value of 0 always stops the sound. The device
+// must have been opened for writing.
+func (dev *InputDevice) Beep(sound EvCode, value EvValue) error {
+	now := time.Now()
+
+	ev := NewInputEvent(now, EV_SND, sound, value)
+	if err := dev.WriteEvent(&ev); err != nil {
+		return err
+	}
+
+	syn := NewInputEvent(now, EV_SYN, SYN_REPORT, 0)
+	return dev.WriteEvent(&syn)
+}
@@ -0,0 +1,46 @@
+//go:build linux
+
+package evdev
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestBeep(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	dev := &InputDevice{File: w}
+	defer dev.Close()
+
+	if err := dev.Beep(SND_TONE, 440); err != nil {
+		t.Fatal(err)
+	}
+	if err := dev.Beep(SND_TONE, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := (&InputDevice{File: r}).ReadTimeout(time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []InputEvent{
+		NewInputEvent(time.Now(), EV_SND, SND_TONE, 440),
+		NewInputEvent(time.Now(), EV_SYN, SYN_REPORT, 0),
+		NewInputEvent(time.Now(), EV_SND, SND_TONE, 0),
+		NewInputEvent(time.Now(), EV_SYN, SYN_REPORT, 0),
+	}
+	if !sameEvents(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// pipes have no sound state
+	if _, err := dev.ActiveSounds(); err != syscall.ENOTTY {
+		t.Errorf("expected ENOTTY, got %v", err)
+	}
+}
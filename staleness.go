@@ -0,0 +1,42 @@
+package evdev
+
+import "time"
+
+// StalenessMonitor tells latency-sensitive consumers when they fall behind
+// the event stream, so they can shed work. Every delivered event is passed
+// to Check; when an event is older than Threshold, OnStale is called.
+type StalenessMonitor struct {
+	Threshold time.Duration
+	OnStale   func(ev *InputEvent, age time.Duration)
+
+	// Now returns the current time of the clock the device timestamps events
+	// with. It defaults to time.Now, matching the default CLOCK_REALTIME.
+	Now func() time.Time
+
+	stale int // number of stale events seen
+}
+
+// NewStalenessMonitor creates a monitor calling onStale for events older
+// than threshold.
+func NewStalenessMonitor(threshold time.Duration, onStale func(ev *InputEvent, age time.Duration)) *StalenessMonitor {
+	return &StalenessMonitor{Threshold: threshold, OnStale: onStale, Now: time.Now}
+}
+
+// Check returns the age of an event, calling OnStale if it is stale.
+func (m *StalenessMonitor) Check(ev *InputEvent) time.Duration {
+	age := ev.Age(m.Now())
+
+	if age > m.Threshold {
+		m.stale++
+		if m.OnStale != nil {
+			m.OnStale(ev, age)
+		}
+	}
+
+	return age
+}
+
+// Stale returns the number of stale events seen so far.
+func (m *StalenessMonitor) Stale() int {
+	return m.stale
+}
@@ -0,0 +1,39 @@
+package evdev
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStalenessMonitor(t *testing.T) {
+	t0 := time.Unix(1000, 0)
+	now := t0
+
+	var staleAge time.Duration
+	m := NewStalenessMonitor(10*time.Millisecond, func(ev *InputEvent, age time.Duration) { staleAge = age })
+	m.Now = func() time.Time { return now }
+
+	tests := []struct {
+		now   time.Duration
+		age   time.Duration
+		stale int
+	}{
+		{2 * time.Millisecond, 2 * time.Millisecond, 0},
+		{10 * time.Millisecond, 10 * time.Millisecond, 0},
+		{15 * time.Millisecond, 15 * time.Millisecond, 1},
+		{time.Second, time.Second, 2},
+	}
+	for _, tt := range tests {
+		now = t0.Add(tt.now)
+		ev := NewInputEvent(t0, EV_KEY, KEY_A, 1)
+		if age := m.Check(&ev); age != tt.age {
+			t.Errorf("got age %v, want %v", age, tt.age)
+		}
+		if m.Stale() != tt.stale {
+			t.Errorf("at %v: %d stale events, want %d", tt.now, m.Stale(), tt.stale)
+		}
+	}
+	if staleAge != time.Second {
+		t.Errorf("expected OnStale with the age of the last event, got %v", staleAge)
+	}
+}
@@ -0,0 +1,114 @@
+//go:build linux
+
+package evdev
+
+import "path/filepath"
+
+// DeviceStatus is a compact summary of a device for applets and status bars.
+type DeviceStatus struct {
+	Path        string  `json:"path"`
+	Name        string  `json:"name"`
+	Label       string  `json:"label,omitempty"`
+	Fingerprint string  `json:"fingerprint"`
+	Class       string  `json:"class"`      // keyboard, mouse, touchpad, touchscreen, joystick, ...
+	Grabbed     bool    `json:"grabbed"`    // grabbed by this process
+	EventRate   float64 `json:"event_rate"` // events per minute, 0 if unknown
+	Battery     int     `json:"battery"`    // charge in percent, -1 if unknown
+}
+
+// SnapshotAll returns the status of the given devices, or of all accessible
+// devices if none are given. Event rates are taken from activity, which may
+// be nil, by matching its device names against the devnode, fingerprint or
+// label of the devices. Passing already opened devices makes refreshing the
+// snapshot cheap, as only a few sysfs attributes are read.
+func SnapshotAll(activity *ActivitySummary, devices ...*InputDevice) ([]DeviceStatus, error) {
+	if len(devices) == 0 {
+		all, err := ListInputDevices()
+		if err != nil {
+			return nil, err
+		}
+		for _, dev := range all {
+			defer dev.Close()
+		}
+		devices = all
+	}
+
+	rates := make(map[string]float64)
+	if activity != nil {
+		for _, a := range activity.Devices {
+			rates[a.Device] = a.EventsPerMinute
+		}
+	}
+
+	statuses := make([]DeviceStatus, 0, len(devices))
+	for _, dev := range devices {
+		status := DeviceStatus{
+			Path:        dev.Fn,
+			Name:        dev.Name,
+			Label:       dev.Label,
+			Fingerprint: dev.Fingerprint(),
+			Class:       deviceClass(dev.CapabilitySet()),
+			Grabbed:     dev.grabbed,
+			Battery:     dev.battery(),
+		}
+
+		for _, key := range []string{dev.Fn, status.Fingerprint, dev.Label} {
+			if rate, ok := rates[key]; ok && key != "" {
+				status.EventRate = rate
+				break
+			}
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// Guess the kind of a device from its capabilities.
+func deviceClass(caps CapabilitySet) string {
+	_, hasKeys := caps[EV_KEY]
+
+	switch {
+	case caps.Has(EV_ABS, ABS_X) && caps.Has(EV_ABS, ABS_Y) && caps.Has(EV_ABS, ABS_Z) && !hasKeys:
+		return "accelerometer"
+	case caps.Has(EV_KEY, BTN_TOOL_FINGER) && caps.Has(EV_ABS, ABS_X):
+		return "touchpad"
+	case caps.Has(EV_KEY, BTN_TOUCH) && (caps.Has(EV_ABS, ABS_X) || caps.Has(EV_ABS, ABS_MT_POSITION_X)):
+		return "touchscreen"
+	case caps.Has(EV_REL, REL_X) && caps.Has(EV_REL, REL_Y) && caps.Has(EV_KEY, BTN_LEFT):
+		return "mouse"
+	case caps.Has(EV_KEY, BTN_SOUTH) || caps.Has(EV_KEY, BTN_TRIGGER):
+		return "joystick"
+	case caps.Has(EV_KEY, KEY_Q) && caps.Has(EV_KEY, KEY_ENTER):
+		return "keyboard"
+	case hasKeys:
+		return "buttons"
+	case len(caps[EV_SW]) > 0:
+		return "switch"
+	}
+
+	return "other"
+}
+
+// Get the battery charge of a device from the power supply registered by its
+// driver (e.g. HID wireless devices), or -1.
+func (dev *InputDevice) battery() int {
+	dir, err := filepath.EvalSymlinks(filepath.Join(SysfsInputDir, filepath.Base(dev.Fn), "device"))
+	if err != nil {
+		return -1
+	}
+
+	// climb through the parents, e.g. from .../0005:046D:B025.0001/input/input7
+	// to the HID device, up to /sys/devices
+	for ; dir != "/" && dir != "." && filepath.Base(dir) != "devices"; dir = filepath.Dir(dir) {
+		matches, _ := filepath.Glob(filepath.Join(dir, "power_supply", "*", "capacity"))
+		for _, path := range matches {
+			if capacity, err := readSysfsInt(path); err == nil {
+				return capacity
+			}
+		}
+	}
+
+	return -1
+}
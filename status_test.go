@@ -0,0 +1,86 @@
+//go:build linux
+
+package evdev
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDeviceClass(t *testing.T) {
+	tests := []struct {
+		caps CapabilitySet
+		want string
+	}{
+		{CapabilitySet{EV_ABS: {ABS_X, ABS_Y, ABS_Z}}, "accelerometer"},
+		{CapabilitySet{EV_KEY: {BTN_LEFT, BTN_TOOL_FINGER, BTN_TOUCH}, EV_ABS: {ABS_X, ABS_Y}}, "touchpad"},
+		{CapabilitySet{EV_KEY: {BTN_TOUCH}, EV_ABS: {ABS_MT_POSITION_X, ABS_MT_POSITION_Y}}, "touchscreen"},
+		{CapabilitySet{EV_KEY: {BTN_LEFT, BTN_RIGHT}, EV_REL: {REL_X, REL_Y}}, "mouse"},
+		{CapabilitySet{EV_KEY: {BTN_SOUTH, BTN_EAST}, EV_ABS: {ABS_X, ABS_Y}}, "joystick"},
+		{CapabilitySet{EV_KEY: {KEY_Q, KEY_ENTER}}, "keyboard"},
+		{CapabilitySet{EV_KEY: {KEY_POWER}}, "buttons"},
+		{CapabilitySet{EV_SW: {SW_LID}}, "switch"},
+		{CapabilitySet{EV_MSC: {MSC_SCAN}}, "other"},
+	}
+	for _, tt := range tests {
+		if got := deviceClass(tt.caps); got != tt.want {
+			t.Errorf("%v: got %s, want %s", tt.caps, got, tt.want)
+		}
+	}
+}
+
+func TestSnapshotAll(t *testing.T) {
+	defer func(dir string) { SysfsInputDir = dir }(SysfsInputDir)
+	root := t.TempDir()
+	SysfsInputDir = filepath.Join(root, "class", "input")
+
+	// a wireless mouse with a battery registered by its HID driver
+	hid := filepath.Join(root, "devices", "0005:046D:B025.0001")
+	input := filepath.Join(hid, "input", "input7")
+	for _, dir := range []string{filepath.Join(hid, "power_supply", "hidpp_battery_0"), input, filepath.Join(SysfsInputDir, "event7")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.WriteFile(filepath.Join(hid, "power_supply", "hidpp_battery_0", "capacity"), []byte("55\n"), 0644)
+	os.Symlink(input, filepath.Join(SysfsInputDir, "event7", "device"))
+
+	mouse := &InputDevice{
+		Fn:   "/dev/input/event7",
+		Name: "mouse",
+		Capabilities: map[CapabilityType][]CapabilityCode{
+			{EV_KEY, "EV_KEY"}: {{BTN_LEFT, "BTN_LEFT"}},
+			{EV_REL, "EV_REL"}: {{REL_X, "REL_X"}, {REL_Y, "REL_Y"}},
+		},
+	}
+	kbd := &InputDevice{
+		Fn:    "/dev/input/event3",
+		Name:  "kbd",
+		Label: "desk",
+		Capabilities: map[CapabilityType][]CapabilityCode{
+			{EV_KEY, "EV_KEY"}: {{KEY_Q, "KEY_Q"}, {KEY_ENTER, "KEY_ENTER"}},
+		},
+	}
+	activity := &ActivitySummary{Devices: []DeviceActivity{
+		{Device: "/dev/input/event7", EventsPerMinute: 600},
+		{Device: "desk", EventsPerMinute: 120},
+	}}
+
+	statuses, err := SnapshotAll(activity, mouse, kbd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DeviceStatus{
+		{Path: "/dev/input/event7", Name: "mouse", Fingerprint: mouse.Fingerprint(), Class: "mouse", EventRate: 600, Battery: 55},
+		{Path: "/dev/input/event3", Name: "kbd", Label: "desk", Fingerprint: kbd.Fingerprint(), Class: "keyboard", EventRate: 120, Battery: -1},
+	}
+	if len(statuses) != len(want) {
+		t.Fatalf("got %+v", statuses)
+	}
+	for i := range want {
+		if statuses[i] != want[i] {
+			t.Errorf("got %+v, want %+v", statuses[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package evdev
+
+import (
+	"sort"
+	"time"
+)
+
+// StuckKeyFilter tracks outstanding key presses so that they can be force
+// released when the stream they belong to ends abruptly - before releasing a
+// grab, closing the device or after a crash - or when a key has been held
+// for longer than MaxHold. Without this, a forwarded key-down whose key-up
+// is lost keeps repeating forever downstream.
+type StuckKeyFilter struct {
+	MaxHold time.Duration // maximum time a key may be held; 0 for no limit
+
+	down     map[EvCode]time.Time // held keys and when they were pressed
+	released map[EvCode]bool      // force-released keys still physically held
+}
+
+// NewStuckKeyFilter creates a filter force releasing keys held for longer
+// than maxHold.
+func NewStuckKeyFilter(maxHold time.Duration) *StuckKeyFilter {
+	return &StuckKeyFilter{
+		MaxHold:  maxHold,
+		down:     make(map[EvCode]time.Time),
+		released: make(map[EvCode]bool),
+	}
+}
+
+// Process tracks key state. Repeats and the eventual release of keys that
+// were already force released are dropped.
+func (f *StuckKeyFilter) Process(ev InputEvent) []InputEvent {
+	if ev.Type != EV_KEY {
+		return []InputEvent{ev}
+	}
+
+	if f.released[ev.Code] {
+		if ev.Value == EvValue(KeyUp) {
+			delete(f.released, ev.Code)
+		}
+		return nil
+	}
+
+	switch ev.Value {
+	case EvValue(KeyDown):
+		f.down[ev.Code] = ev.Timestamp()
+	case EvValue(KeyUp):
+		delete(f.down, ev.Code)
+	}
+
+	return []InputEvent{ev}
+}
+
+// Tick releases keys that have been held for longer than MaxHold.
+func (f *StuckKeyFilter) Tick(now time.Time) []InputEvent {
+	if f.MaxHold <= 0 {
+		return nil
+	}
+
+	expired := make([]EvCode, 0)
+	for code, t := range f.down {
+		if now.Sub(t) >= f.MaxHold {
+			expired = append(expired, code)
+		}
+	}
+
+	return f.release(now, expired)
+}
+
+// ReleaseAll returns key-up events for all keys that are currently held.
+func (f *StuckKeyFilter) ReleaseAll(now time.Time) []InputEvent {
+	return f.release(now, f.Held())
+}
+
+// Held returns the codes of all keys that are currently held, in ascending order.
+func (f *StuckKeyFilter) Held() []EvCode {
+	codes := make([]EvCode, 0, len(f.down))
+	for code := range f.down {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+
+	return codes
+}
+
+func (f *StuckKeyFilter) release(now time.Time, codes []EvCode) []InputEvent {
+	if len(codes) == 0 {
+		return nil
+	}
+
+	events := make([]InputEvent, 0, len(codes)+1)
+	for _, code := range codes {
+		delete(f.down, code)
+		f.released[code] = true
+		events = append(events, NewInputEvent(now, EV_KEY, code, EvValue(KeyUp)))
+	}
+
+	return append(events, NewInputEvent(now, EV_SYN, SYN_REPORT, 0))
+}
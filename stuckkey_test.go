@@ -0,0 +1,76 @@
+package evdev
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStuckKeyFilter(t *testing.T) {
+	f := NewStuckKeyFilter(time.Second)
+	t0 := time.Unix(1000, 0)
+
+	ev := func(d time.Duration, evType EvType, code EvCode, value int) InputEvent {
+		return NewInputEvent(t0.Add(d), evType, code, EvValue(value))
+	}
+	syn := func(d time.Duration) InputEvent {
+		return ev(d, EV_SYN, SYN_REPORT, 0)
+	}
+	process := func(in ...InputEvent) []InputEvent {
+		got := make([]InputEvent, 0)
+		for _, e := range in {
+			got = append(got, f.Process(e)...)
+		}
+		return got
+	}
+
+	in := []InputEvent{
+		ev(0, EV_KEY, KEY_A, 1), ev(0, EV_KEY, KEY_LEFTSHIFT, 1), syn(0),
+		ev(100*time.Millisecond, EV_KEY, KEY_LEFTSHIFT, 0), syn(100 * time.Millisecond),
+		ev(200*time.Millisecond, EV_KEY, KEY_B, 1), syn(200 * time.Millisecond),
+	}
+	if got := process(in...); !reflect.DeepEqual(got, in) {
+		t.Errorf("got %v, want %v", got, in)
+	}
+	if held := f.Held(); !reflect.DeepEqual(held, []EvCode{KEY_A, KEY_B}) {
+		t.Errorf("unexpected held keys %v", held)
+	}
+
+	// a is held too long and force released, b not yet
+	want := []InputEvent{ev(time.Second, EV_KEY, KEY_A, 0), syn(time.Second)}
+	if got := f.Tick(t0.Add(time.Second)); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := f.Tick(t0.Add(time.Second)); len(got) != 0 {
+		t.Errorf("expected nothing more to release, got %v", got)
+	}
+
+	// the repeats and release of a are dropped, a new press passes
+	got := process(
+		ev(1100*time.Millisecond, EV_KEY, KEY_A, 2), syn(1100*time.Millisecond),
+		ev(1200*time.Millisecond, EV_KEY, KEY_A, 0), syn(1200*time.Millisecond),
+		ev(1300*time.Millisecond, EV_KEY, KEY_A, 1), syn(1300*time.Millisecond),
+	)
+	want = []InputEvent{
+		syn(1100 * time.Millisecond),
+		syn(1200 * time.Millisecond),
+		ev(1300*time.Millisecond, EV_KEY, KEY_A, 1), syn(1300 * time.Millisecond),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	want = []InputEvent{ev(2*time.Second, EV_KEY, KEY_A, 0), ev(2*time.Second, EV_KEY, KEY_B, 0), syn(2 * time.Second)}
+	if got := f.ReleaseAll(t0.Add(2 * time.Second)); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if held := f.Held(); len(held) != 0 {
+		t.Errorf("expected no held keys, got %v", held)
+	}
+
+	f = NewStuckKeyFilter(0)
+	process(ev(0, EV_KEY, KEY_A, 1))
+	if got := f.Tick(t0.Add(time.Hour)); got != nil {
+		t.Errorf("expected no limit without MaxHold, got %v", got)
+	}
+}
@@ -0,0 +1,289 @@
+//go:build linux
+
+package evdev
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SupportBundleOptions selects what CollectSupportBundle collects.
+type SupportBundleOptions struct {
+	// Devices to describe, all accessible devices if empty.
+	Devices []*InputDevice
+
+	// Activity adds recent activity statistics, may be nil.
+	Activity *ActivitySummary
+
+	// Capture, if nonzero, records the events of the devices for this long.
+	// The events are consumed, so other readers of the handles miss them.
+	Capture time.Duration
+
+	// Bundles are anonymized unless Raw is set: the unique identifiers and
+	// physical paths of the devices are replaced by hashes, and in the
+	// capture the codes of all keys but buttons by KEY_UNKNOWN and scan
+	// codes by 0, so that typed text cannot be recovered. A raw capture of
+	// a keyboard is a keylog.
+	Raw bool
+}
+
+// SupportBundle describes the system and its devices for bug reports.
+type SupportBundle struct {
+	Created  time.Time             `json:"created"`
+	Kernel   string                `json:"kernel"`
+	Arch     string                `json:"arch"`
+	Devices  []SupportBundleDevice `json:"devices"`
+	Activity *ActivitySummary      `json:"activity,omitempty"`
+	Capture  string                `json:"capture,omitempty"` // name of the capture in the tarball
+}
+
+// SupportBundleDevice describes a device in a support bundle.
+type SupportBundleDevice struct {
+	Path         string              `json:"path"`
+	Name         string              `json:"name"`
+	Phys         string              `json:"phys"`
+	Ident        string              `json:"ident"`
+	Fingerprint  string              `json:"fingerprint"`
+	Class        string              `json:"class"`
+	EvdevVersion int                 `json:"evdev_version"`
+	Capabilities map[string][]string `json:"capabilities"`
+	AbsInfo      map[string]AbsInfo  `json:"abs_info,omitempty"`
+	Properties   []string            `json:"properties,omitempty"`
+	Features     string              `json:"features"`
+
+	// Quirks lists the workarounds applied to the handle, e.g. resilient
+	// decoding or a non-default clock.
+	Quirks    []string         `json:"quirks,omitempty"`
+	Anomalies *StreamAnomalies `json:"anomalies,omitempty"`
+}
+
+// CollectSupportBundle writes a gzipped tarball for bug reports of this
+// package and the tools built on it: bundle.json, a SupportBundle, and if
+// requested capture.evcap, a capture readable with NewCaptureReader.
+func CollectSupportBundle(w io.Writer, opts SupportBundleOptions) error {
+	devices := opts.Devices
+	if len(devices) == 0 {
+		all, err := ListInputDevices()
+		if err != nil {
+			return err
+		}
+		for _, dev := range all {
+			defer dev.Close()
+		}
+		devices = all
+	}
+
+	bundle := SupportBundle{
+		Created:  time.Now(),
+		Arch:     runtime.GOARCH,
+		Activity: opts.Activity,
+		Devices:  make([]SupportBundleDevice, 0, len(devices)),
+	}
+
+	if release, err := os.ReadFile("/proc/sys/kernel/osrelease"); err == nil {
+		bundle.Kernel = strings.TrimSpace(string(release))
+	}
+
+	described := make([]*InputDevice, 0, len(devices))
+	for _, dev := range devices {
+		if !opts.Raw {
+			dev = anonymizedDevice(dev)
+		}
+		described = append(described, dev)
+		bundle.Devices = append(bundle.Devices, describeDevice(dev))
+	}
+
+	var capture bytes.Buffer
+	if opts.Capture > 0 {
+		if err := captureBundle(&capture, devices, described, opts); err != nil {
+			return err
+		}
+		bundle.Capture = "capture.evcap"
+	}
+
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{"bundle.json", append(data, '\n')},
+		{bundle.Capture, capture.Bytes()},
+	}
+	for _, f := range files {
+		if f.name == "" {
+			continue
+		}
+		hdr := &tar.Header{Name: f.name, Mode: 0644, Size: int64(len(f.data)), ModTime: bundle.Created}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(f.data); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func describeDevice(dev *InputDevice) SupportBundleDevice {
+	d := SupportBundleDevice{
+		Path:         dev.Fn,
+		Name:         dev.Name,
+		Phys:         dev.Phys,
+		Ident:        dev.Ident,
+		Fingerprint:  dev.Fingerprint(),
+		Class:        deviceClass(dev.CapabilitySet()),
+		EvdevVersion: dev.EvdevVersion,
+		Capabilities: make(map[string][]string),
+		AbsInfo:      make(map[string]AbsInfo),
+		Features:     dev.Features().String(),
+	}
+
+	for evType, codes := range dev.CapabilitySet() {
+		names := make([]string, 0, len(codes))
+		for _, code := range codes {
+			names = append(names, CodeName(evType, code))
+		}
+		d.Capabilities[TypeName(evType)] = names
+	}
+	for axis, info := range dev.AbsInfos {
+		d.AbsInfo[CodeName(EV_ABS, axis)] = info
+	}
+	for _, prop := range dev.Properties() {
+		d.Properties = append(d.Properties, propertyName(prop))
+	}
+
+	if dev.decoder != nil {
+		d.Quirks = append(d.Quirks, "resilient decoding")
+		anomalies := dev.decoder.Anomalies
+		d.Anomalies = &anomalies
+	}
+	if dev.clockID != CLOCK_REALTIME {
+		d.Quirks = append(d.Quirks, "clock "+clockName(dev.clockID))
+	}
+	if dev.grabbed {
+		d.Quirks = append(d.Quirks, "grabbed")
+	}
+
+	return d
+}
+
+// Record the events of devices for opts.Capture, described as the
+// corresponding entries of described.
+func captureBundle(w io.Writer, devices, described []*InputDevice, opts SupportBundleOptions) error {
+	cw, err := NewCaptureWriter(w)
+	if err != nil {
+		return err
+	}
+
+	p, err := NewPoller()
+	if err != nil {
+		return err
+	}
+	defer p.Close()
+	// a failing device ends its part of the capture only
+	p.OnError = func(dev *InputDevice, err error) {}
+
+	ids := make(map[*InputDevice]uint16)
+	for i, dev := range devices {
+		if ids[dev], err = cw.AddDevice(described[i]); err != nil {
+			return err
+		}
+		if err = p.Add(dev); err != nil {
+			return err
+		}
+	}
+
+	deadline := time.Now().Add(opts.Capture)
+	for remaining := opts.Capture; remaining > 0; remaining = time.Until(deadline) {
+		events, err := p.Wait(remaining)
+		if err == ErrTimeout {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, de := range events {
+			ev := de.Event
+			if !opts.Raw {
+				anonymizeEvent(&ev)
+			}
+			if err := cw.WriteEvent(ids[de.Device], &ev); err != nil {
+				return err
+			}
+		}
+	}
+
+	return cw.Flush()
+}
+
+// Get a copy of a device with its unique identifier and physical path
+// replaced by hashes.
+func anonymizedDevice(dev *InputDevice) *InputDevice {
+	anon := *dev
+	anon.Ident = anonymize(dev.Ident)
+	anon.Phys = anonymize(dev.Phys)
+
+	return &anon
+}
+
+func anonymize(s string) string {
+	if s == "" {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(s))
+	return "anon-" + hex.EncodeToString(sum[:4])
+}
+
+// Hide the key typed by an event.
+func anonymizeEvent(ev *InputEvent) {
+	switch {
+	case ev.Type == EV_KEY && !isButton(ev.Code):
+		ev.Code = KEY_UNKNOWN
+	case ev.Type == EV_MSC && ev.Code == MSC_SCAN:
+		ev.Value = 0
+	}
+}
+
+func propertyName(prop int) string {
+	if name, ok := INPUT_PROP[prop]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("INPUT_PROP_%#02x", prop)
+}
+
+func clockName(clockid int32) string {
+	switch clockid {
+	case CLOCK_REALTIME:
+		return "CLOCK_REALTIME"
+	case CLOCK_MONOTONIC:
+		return "CLOCK_MONOTONIC"
+	case CLOCK_BOOTTIME:
+		return "CLOCK_BOOTTIME"
+	}
+
+	return strconv.Itoa(int(clockid))
+}
@@ -0,0 +1,116 @@
+//go:build linux
+
+package evdev
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAnonymizeEvent(t *testing.T) {
+	tests := []struct {
+		ev   InputEvent
+		want InputEvent
+	}{
+		{InputEvent{Type: EV_KEY, Code: KEY_A, Value: 1}, InputEvent{Type: EV_KEY, Code: KEY_UNKNOWN, Value: 1}},
+		{InputEvent{Type: EV_KEY, Code: KEY_NUMERIC_0, Value: 1}, InputEvent{Type: EV_KEY, Code: KEY_UNKNOWN, Value: 1}},
+		{InputEvent{Type: EV_KEY, Code: KEY_MACRO1, Value: 0}, InputEvent{Type: EV_KEY, Code: KEY_UNKNOWN, Value: 0}},
+		{InputEvent{Type: EV_KEY, Code: BTN_LEFT, Value: 1}, InputEvent{Type: EV_KEY, Code: BTN_LEFT, Value: 1}},
+		{InputEvent{Type: EV_KEY, Code: BTN_TRIGGER_HAPPY1, Value: 1}, InputEvent{Type: EV_KEY, Code: BTN_TRIGGER_HAPPY1, Value: 1}},
+		{InputEvent{Type: EV_MSC, Code: MSC_SCAN, Value: 0x70004}, InputEvent{Type: EV_MSC, Code: MSC_SCAN, Value: 0}},
+		{InputEvent{Type: EV_REL, Code: REL_X, Value: -3}, InputEvent{Type: EV_REL, Code: REL_X, Value: -3}},
+	}
+
+	for _, tt := range tests {
+		ev := tt.ev
+		anonymizeEvent(&ev)
+		if ev != tt.want {
+			t.Errorf("anonymized %v to %v, want %v", &tt.ev, &ev, &tt.want)
+		}
+	}
+}
+
+func TestSupportBundle(t *testing.T) {
+	features := Features(0)
+	dev := &InputDevice{
+		Fn:    "/dev/input/event3",
+		Name:  "kbd",
+		Phys:  "usb-0000:00:14.0-2/input0",
+		Ident: "serial-1234",
+		Capabilities: map[CapabilityType][]CapabilityCode{
+			{EV_KEY, "EV_KEY"}: {{KEY_A, "KEY_A"}},
+		},
+		features: &features,
+	}
+
+	anon := anonymizedDevice(dev)
+	if anon == dev || dev.Ident != "serial-1234" {
+		t.Fatal("expected a copy of the device")
+	}
+	if !strings.HasPrefix(anon.Ident, "anon-") || !strings.HasPrefix(anon.Phys, "anon-") || anon.Ident == anon.Phys {
+		t.Errorf("unexpected anonymized identifiers %q, %q", anon.Ident, anon.Phys)
+	}
+	if anonymize("") != "" {
+		t.Error("expected empty strings to stay empty")
+	}
+
+	collect := func(opts SupportBundleOptions) SupportBundle {
+		var buf strings.Builder
+		if err := CollectSupportBundle(&buf, opts); err != nil {
+			t.Fatal(err)
+		}
+
+		gz, err := gzip.NewReader(strings.NewReader(buf.String()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		tr := tar.NewReader(gz)
+		names := make([]string, 0)
+		var bundle SupportBundle
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			names = append(names, hdr.Name)
+			if hdr.Name == "bundle.json" {
+				if err := json.NewDecoder(tr).Decode(&bundle); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+
+		if len(names) != 1 || names[0] != "bundle.json" {
+			t.Fatalf("unexpected files %v", names)
+		}
+		if bundle.Capture != "" || time.Since(bundle.Created) > time.Minute {
+			t.Errorf("unexpected bundle %+v", bundle)
+		}
+		if len(bundle.Devices) != 1 {
+			t.Fatalf("unexpected devices %+v", bundle.Devices)
+		}
+		return bundle
+	}
+
+	// anonymized by default
+	d := collect(SupportBundleOptions{Devices: []*InputDevice{dev}}).Devices[0]
+	if d.Name != "kbd" || d.Ident != anon.Ident || d.Phys != anon.Phys || strings.Contains(d.Fingerprint, "serial") {
+		t.Errorf("device not anonymized: %+v", d)
+	}
+	if keys := d.Capabilities["EV_KEY"]; len(keys) != 1 || keys[0] != named("KEY_A", "KEY_0x01e") {
+		t.Errorf("unexpected capabilities %v", d.Capabilities)
+	}
+
+	d = collect(SupportBundleOptions{Devices: []*InputDevice{dev}, Raw: true}).Devices[0]
+	if d.Ident != dev.Ident || d.Phys != dev.Phys {
+		t.Errorf("raw device anonymized: %+v", d)
+	}
+}
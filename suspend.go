@@ -0,0 +1,104 @@
+//go:build linux
+
+package evdev
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// SuspendGuard makes event streams aware of system suspend. Suspend is
+// detected from CLOCK_BOOTTIME running ahead of CLOCK_MONOTONIC, which does
+// not advance while the system sleeps; a logind PrepareForSleep handler can
+// report resumes directly with NotifyResume instead. After a resume, keys
+// still held are released and a SYN_DROPPED marks the gap, so that
+// downstream state is resynced and hold timers don't fire spuriously.
+type SuspendGuard struct {
+	Threshold time.Duration             // minimum sleep time detected as suspend
+	OnResume  func(slept time.Duration) // called after a resume, may be nil
+
+	mu      sync.Mutex
+	offset  time.Duration // last seen difference of the two clocks
+	resumed bool          // resume reported with NotifyResume
+	held    map[EvCode]bool
+}
+
+// NewSuspendGuard creates a guard detecting suspends of at least a second.
+func NewSuspendGuard() *SuspendGuard {
+	return &SuspendGuard{
+		Threshold: time.Second,
+		offset:    clockGettime(CLOCK_BOOTTIME) - clockGettime(CLOCK_MONOTONIC),
+		held:      make(map[EvCode]bool),
+	}
+}
+
+// NotifyResume reports a resume detected by other means; the stream is
+// resynced with the next event or Tick.
+func (g *SuspendGuard) NotifyResume() {
+	g.mu.Lock()
+	g.resumed = true
+	g.mu.Unlock()
+}
+
+// Process passes an event through, preceded by the resync events if the
+// system has resumed since the last call.
+func (g *SuspendGuard) Process(ev InputEvent) []InputEvent {
+	events := g.check(ev.Timestamp())
+
+	if ev.Type == EV_KEY {
+		g.mu.Lock()
+		g.held[ev.Code] = ev.Value != EvValue(KeyUp)
+		g.mu.Unlock()
+	}
+
+	return append(events, ev)
+}
+
+// Tick emits the resync events if the system has resumed.
+func (g *SuspendGuard) Tick(now time.Time) []InputEvent {
+	return g.check(now)
+}
+
+func (g *SuspendGuard) check(now time.Time) []InputEvent {
+	g.mu.Lock()
+
+	offset := clockGettime(CLOCK_BOOTTIME) - clockGettime(CLOCK_MONOTONIC)
+	slept := offset - g.offset
+	g.offset = offset
+
+	if slept < g.Threshold && !g.resumed {
+		g.mu.Unlock()
+		return nil
+	}
+	g.resumed = false
+
+	codes := make([]int, 0)
+	for code, down := range g.held {
+		if down {
+			codes = append(codes, int(code))
+		}
+	}
+	sort.Ints(codes)
+	g.held = make(map[EvCode]bool)
+	g.mu.Unlock()
+
+	// consumers discard up to the next SYN_REPORT after SYN_DROPPED, so the
+	// releases go in a frame of their own
+	events := []InputEvent{
+		NewInputEvent(now, EV_SYN, SYN_DROPPED, 0),
+		NewInputEvent(now, EV_SYN, SYN_REPORT, 0),
+	}
+	for _, code := range codes {
+		events = append(events, NewInputEvent(now, EV_KEY, EvCode(code), EvValue(KeyUp)))
+	}
+	if len(codes) > 0 {
+		events = append(events, NewInputEvent(now, EV_SYN, SYN_REPORT, 0))
+	}
+
+	if g.OnResume != nil {
+		g.OnResume(slept)
+	}
+
+	return events
+}
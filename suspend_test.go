@@ -0,0 +1,44 @@
+//go:build linux
+
+package evdev
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSuspendGuard(t *testing.T) {
+	g := NewSuspendGuard()
+	t0 := time.Unix(1000, 0)
+
+	var out []InputEvent
+	out = append(out, g.Process(NewInputEvent(t0, EV_KEY, KEY_A, EvValue(KeyDown)))...)
+	out = append(out, g.Process(NewInputEvent(t0, EV_KEY, KEY_B, EvValue(KeyDown)))...)
+	out = append(out, g.Process(NewInputEvent(t0, EV_KEY, KEY_B, EvValue(KeyUp)))...)
+	out = append(out, g.Process(NewInputEvent(t0, EV_SYN, SYN_REPORT, 0))...)
+
+	g.NotifyResume()
+	out = append(out, g.Tick(t0.Add(time.Second))...)
+
+	// read the stream like a consumer honoring SYN_DROPPED
+	state := NewKeyboardState()
+	dropping := false
+	for i := range out {
+		ev := &out[i]
+		switch {
+		case ev.Type == EV_SYN && ev.Code == SYN_DROPPED:
+			dropping = true
+		case ev.Type == EV_SYN && ev.Code == SYN_REPORT:
+			dropping = false
+		case !dropping:
+			state.Feed(ev)
+		}
+	}
+	if pressed := state.Pressed(); len(pressed) != 0 {
+		t.Errorf("keys still pressed after resume: %v", pressed)
+	}
+
+	if events := g.Tick(t0.Add(2 * time.Second)); len(events) != 0 {
+		t.Errorf("expected no events without a resume, got %v", events)
+	}
+}
@@ -0,0 +1,82 @@
+package evdev
+
+import "time"
+
+// SynRepairFilter inserts the SYN_REPORTs that buggy devices or firmware
+// omit, so that frame-based consumers still see proper frames. A frame is
+// considered complete when an event repeats a type and code already seen in
+// the open frame (per multi-touch slot; ABS_MT_SLOT itself may change any
+// number of times in a frame), when the timestamp jumps by more
+// than MaxGap, or when no event arrived for Timeout (detected by Tick).
+type SynRepairFilter struct {
+	MaxGap  time.Duration // maximum timestamp difference within a frame
+	Timeout time.Duration // time after which an open frame is closed
+
+	open  bool              // events have been passed since the last SYN_REPORT
+	first time.Time         // timestamp of the first event of the frame
+	last  time.Time         // timestamp of the last event of the frame
+	seen  map[[3]int32]bool // type, code and slot of the events of the frame
+	slot  EvValue           // current multi-touch slot
+}
+
+// NewSynRepairFilter creates a filter with a MaxGap of 2ms and a Timeout
+// of 20ms.
+func NewSynRepairFilter() *SynRepairFilter {
+	return &SynRepairFilter{
+		MaxGap:  2 * time.Millisecond,
+		Timeout: 20 * time.Millisecond,
+		seen:    make(map[[3]int32]bool),
+	}
+}
+
+// Process passes ev through, preceded by a SYN_REPORT if ev starts a new frame.
+func (f *SynRepairFilter) Process(ev InputEvent) []InputEvent {
+	if ev.Type == EV_SYN {
+		if ev.Code == SYN_REPORT || ev.Code == SYN_DROPPED {
+			f.reset()
+		}
+		return []InputEvent{ev}
+	}
+	t := ev.Timestamp()
+
+	slot := EvValue(-1)
+	isSlot := ev.Type == EV_ABS && ev.Code == ABS_MT_SLOT
+	if isSlot {
+		f.slot = ev.Value
+	} else if ev.Type == EV_ABS && ev.Code > ABS_MT_SLOT {
+		slot = f.slot
+	}
+	key := [3]int32{int32(ev.Type), int32(ev.Code), int32(slot)}
+
+	events := make([]InputEvent, 0, 2)
+	if f.open && (f.seen[key] && !isSlot || t.Sub(f.first) > f.MaxGap) {
+		events = append(events, NewInputEvent(f.last, EV_SYN, SYN_REPORT, 0))
+		f.reset()
+	}
+
+	if !f.open {
+		f.open = true
+		f.first = t
+	}
+	f.last = t
+	f.seen[key] = true
+
+	return append(events, ev)
+}
+
+// Tick closes the open frame if no event arrived for Timeout.
+func (f *SynRepairFilter) Tick(now time.Time) []InputEvent {
+	if !f.open || now.Sub(f.last) < f.Timeout {
+		return nil
+	}
+
+	last := f.last
+	f.reset()
+
+	return []InputEvent{NewInputEvent(last, EV_SYN, SYN_REPORT, 0)}
+}
+
+func (f *SynRepairFilter) reset() {
+	f.open = false
+	f.seen = make(map[[3]int32]bool)
+}
@@ -0,0 +1,79 @@
+package evdev
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSynRepairFilter(t *testing.T) {
+	t0 := time.Unix(1000, 0)
+	ms := time.Millisecond
+
+	ev := func(d time.Duration, evType EvType, code EvCode, value int) InputEvent {
+		return NewInputEvent(t0.Add(d), evType, code, EvValue(value))
+	}
+	syn := func(d time.Duration) InputEvent {
+		return ev(d, EV_SYN, SYN_REPORT, 0)
+	}
+
+	tests := []struct {
+		name string
+		in   []InputEvent
+		want []InputEvent
+	}{
+		{
+			"complete frames pass",
+			[]InputEvent{ev(0, EV_REL, REL_X, 1), ev(0, EV_REL, REL_Y, 1), syn(0), ev(ms, EV_REL, REL_X, 1), syn(ms)},
+			[]InputEvent{ev(0, EV_REL, REL_X, 1), ev(0, EV_REL, REL_Y, 1), syn(0), ev(ms, EV_REL, REL_X, 1), syn(ms)},
+		},
+		{
+			"repeated code",
+			[]InputEvent{ev(0, EV_REL, REL_X, 1), ev(0, EV_REL, REL_X, 2)},
+			[]InputEvent{ev(0, EV_REL, REL_X, 1), syn(0), ev(0, EV_REL, REL_X, 2)},
+		},
+		{
+			"timestamp gap",
+			[]InputEvent{ev(0, EV_KEY, KEY_A, 1), ev(5*ms, EV_KEY, KEY_B, 1)},
+			[]InputEvent{ev(0, EV_KEY, KEY_A, 1), syn(0), ev(5*ms, EV_KEY, KEY_B, 1)},
+		},
+		{
+			"slots",
+			[]InputEvent{
+				ev(0, EV_ABS, ABS_MT_SLOT, 0), ev(0, EV_ABS, ABS_MT_POSITION_X, 10),
+				ev(0, EV_ABS, ABS_MT_SLOT, 1), ev(0, EV_ABS, ABS_MT_POSITION_X, 20),
+				ev(0, EV_ABS, ABS_MT_SLOT, 0), ev(0, EV_ABS, ABS_MT_POSITION_Y, 10),
+				ev(0, EV_ABS, ABS_MT_POSITION_X, 30),
+			},
+			[]InputEvent{
+				ev(0, EV_ABS, ABS_MT_SLOT, 0), ev(0, EV_ABS, ABS_MT_POSITION_X, 10),
+				ev(0, EV_ABS, ABS_MT_SLOT, 1), ev(0, EV_ABS, ABS_MT_POSITION_X, 20),
+				ev(0, EV_ABS, ABS_MT_SLOT, 0), ev(0, EV_ABS, ABS_MT_POSITION_Y, 10),
+				syn(0), ev(0, EV_ABS, ABS_MT_POSITION_X, 30),
+			},
+		},
+	}
+	for _, tt := range tests {
+		f := NewSynRepairFilter()
+		got := make([]InputEvent, 0)
+		for _, e := range tt.in {
+			got = append(got, f.Process(e)...)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	f := NewSynRepairFilter()
+	f.Process(ev(0, EV_KEY, KEY_A, 1))
+	if got := f.Tick(t0.Add(10 * ms)); got != nil {
+		t.Errorf("expected the frame to stay open, got %v", got)
+	}
+	got := f.Tick(t0.Add(20 * ms))
+	if want := []InputEvent{syn(0)}; !reflect.DeepEqual(got, want) || !got[0].Timestamp().Equal(t0) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := f.Tick(t0.Add(time.Second)); got != nil {
+		t.Errorf("expected no open frame, got %v", got)
+	}
+}
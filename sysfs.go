@@ -0,0 +1,203 @@
+//go:build linux
+
+package evdev
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// SysfsInputDir is where the kernel lists the evdev nodes of all input devices.
+var SysfsInputDir = "/sys/class/input"
+
+// DeviceNumber identifies an evdev node by name and character device number,
+// as listed in sysfs. Unlike /dev/input, sysfs is usually visible inside
+// containers, so devices can be found even when their nodes are missing.
+type DeviceNumber struct {
+	Name  string // node name, e.g. event3
+	Major uint32
+	Minor uint32
+}
+
+// ListDeviceNumbers lists the evdev nodes known to the kernel, sorted by
+// event number (event2 before event10).
+func ListDeviceNumbers() ([]DeviceNumber, error) {
+	paths, err := filepath.Glob(filepath.Join(SysfsInputDir, "event*", "dev"))
+	if err != nil {
+		return nil, err
+	}
+
+	numbers := make([]DeviceNumber, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			// unplugged meanwhile
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		dn := DeviceNumber{Name: filepath.Base(filepath.Dir(path))}
+		_, err = fmt.Sscanf(strings.TrimSpace(string(data)), "%d:%d", &dn.Major, &dn.Minor)
+		if err != nil {
+			return nil, fmt.Errorf("evdev: parsing %s: %v", path, err)
+		}
+		numbers = append(numbers, dn)
+	}
+
+	sort.Slice(numbers, func(i, j int) bool {
+		a, b := eventNumber(numbers[i].Name), eventNumber(numbers[j].Name)
+		if a != b {
+			return a < b
+		}
+		return numbers[i].Name < numbers[j].Name
+	})
+	return numbers, nil
+}
+
+// Get the number of an eventN node name, -1 if it has none.
+func eventNumber(name string) int {
+	n, err := strconv.Atoi(strings.TrimPrefix(name, "event"))
+	if err != nil {
+		return -1
+	}
+
+	return n
+}
+
+// EnsureNode makes sure a character device node for dn exists in dir and
+// returns its path. A missing node is created with mknod, which requires
+// CAP_MKNOD and a device cgroup allowing access to the input major. When
+// the node can't be created, the returned error tells how to expose it to
+// the container instead.
+func (dn DeviceNumber) EnsureNode(dir string) (string, error) {
+	path := filepath.Join(dir, dn.Name)
+	rdev := int(dn.Major<<8 | dn.Minor&0xff | (dn.Minor&^0xff)<<12)
+
+	var st syscall.Stat_t
+	if err := syscall.Stat(path, &st); err == nil {
+		if st.Mode&syscall.S_IFMT != syscall.S_IFCHR || int(st.Rdev) != rdev {
+			return "", fmt.Errorf("evdev: %s exists but is not device %d:%d", path, dn.Major, dn.Minor)
+		}
+		return path, nil
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	err := syscall.Mknod(path, syscall.S_IFCHR|0660, rdev)
+	if err != nil {
+		return "", fmt.Errorf(
+			"evdev: mknod %s (%d:%d): %v; bind-mount /dev/input into the container "+
+				"or allow the device with e.g. --device-cgroup-rule='c %d:* rmw'",
+			path, dn.Major, dn.Minor, err, dn.Major)
+	}
+
+	return path, nil
+}
+
+// Open opens the device, creating its node in dir if needed.
+func (dn DeviceNumber) Open(dir string) (*InputDevice, error) {
+	path, err := dn.EnsureNode(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return Open(path)
+}
+
+// IsHID reports whether the device is driven by the HID subsystem, so that
+// its MSC_SCAN values are HID usages (see HIDUsageFromScancode).
+func (dev *InputDevice) IsHID() bool {
+	link := filepath.Join(SysfsInputDir, filepath.Base(dev.Fn), "device", "device", "subsystem")
+
+	target, err := os.Readlink(link)
+	return err == nil && filepath.Base(target) == "hid"
+}
+
+// ErrNoWakeup is returned for devices that can't be configured as wake-up
+// source.
+var ErrNoWakeup = errors.New("evdev: device is no wake-up source")
+
+// Wakeup reports whether the device may wake the system from suspend.
+func (dev *InputDevice) Wakeup() (bool, error) {
+	path, err := dev.wakeupPath()
+	if err != nil {
+		return false, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+
+	return strings.TrimSpace(string(data)) == "enabled", nil
+}
+
+// SetWakeup allows or forbids the device to wake the system from suspend.
+// This usually requires root.
+func (dev *InputDevice) SetWakeup(enabled bool) error {
+	path, err := dev.wakeupPath()
+	if err != nil {
+		return err
+	}
+
+	value := "disabled"
+	if enabled {
+		value = "enabled"
+	}
+
+	return os.WriteFile(path, []byte(value), 0644)
+}
+
+// Find the power/wakeup attribute of the device. It belongs to the hardware
+// device, so the sysfs tree is walked up from the input device past HID
+// devices and USB interfaces, which are part of the hardware of their
+// parent, to the nearest other device, e.g. the USB device. Its ancestors
+// are not considered: the wakeup setting of a USB hub or controller
+// applies to all devices plugged into it.
+func (dev *InputDevice) wakeupPath() (string, error) {
+	dir, err := filepath.EvalSymlinks(filepath.Join(SysfsInputDir, filepath.Base(dev.Fn), "device", "device"))
+	if err != nil {
+		return "", err
+	}
+
+	for sysfsSubsystem(dir) == "hid" || isUSBInterface(dir) {
+		dir = filepath.Dir(dir)
+	}
+
+	path := filepath.Join(dir, "power", "wakeup")
+	data, err := os.ReadFile(path)
+	if err != nil || len(strings.TrimSpace(string(data))) == 0 {
+		return "", ErrNoWakeup
+	}
+
+	return path, nil
+}
+
+// Get the name of the subsystem of a sysfs device directory.
+func sysfsSubsystem(dir string) string {
+	target, err := os.Readlink(filepath.Join(dir, "subsystem"))
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Base(target)
+}
+
+func isUSBInterface(dir string) bool {
+	if sysfsSubsystem(dir) != "usb" {
+		return false
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "uevent"))
+	return err == nil && strings.Contains(string(data), "DEVTYPE=usb_interface\n")
+}
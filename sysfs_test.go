@@ -0,0 +1,152 @@
+//go:build linux
+
+package evdev
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestListDeviceNumbers(t *testing.T) {
+	defer func(dir string) { SysfsInputDir = dir }(SysfsInputDir)
+	SysfsInputDir = t.TempDir()
+
+	// minors don't follow the event numbers after hotplugging
+	for name, dev := range map[string]string{"event10": "13:66\n", "event2": "13:74\n", "event9": "13:65\n", "mouse0": "13:32\n"} {
+		dir := filepath.Join(SysfsInputDir, name)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "dev"), []byte(dev), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// event11 is unplugged between listing and reading
+	if err := os.MkdirAll(filepath.Join(SysfsInputDir, "event11"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("gone", filepath.Join(SysfsInputDir, "event11", "dev")); err != nil {
+		t.Fatal(err)
+	}
+
+	numbers, err := ListDeviceNumbers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DeviceNumber{{"event2", 13, 74}, {"event9", 13, 65}, {"event10", 13, 66}}
+	if !reflect.DeepEqual(numbers, want) {
+		t.Errorf("got %v, want %v", numbers, want)
+	}
+
+	// an existing file that is not the device node is refused
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "event2"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := numbers[0].EnsureNode(dir); err == nil || !strings.Contains(err.Error(), "is not device 13:74") {
+		t.Errorf("expected the file to be refused, got %v", err)
+	}
+
+	os.WriteFile(filepath.Join(SysfsInputDir, "event2", "dev"), []byte("garbage"), 0644)
+	if _, err := ListDeviceNumbers(); err == nil {
+		t.Error("expected an error for a malformed device number")
+	}
+}
+
+func TestIsHID(t *testing.T) {
+	root := t.TempDir()
+	link := func(target, path string) {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(target, path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// event3 is a USB keyboard, event4 a PS/2 one
+	for node, bus := range map[string]string{"event3": "hid", "event4": "serio"} {
+		parent := filepath.Join(root, "devices", node+"-parent")
+		input := filepath.Join(parent, "input", "input"+node[len("event"):])
+		if err := os.MkdirAll(filepath.Join(root, "bus", bus), 0755); err != nil {
+			t.Fatal(err)
+		}
+		link(filepath.Join(root, "bus", bus), filepath.Join(parent, "subsystem"))
+		link(parent, filepath.Join(input, "device"))
+		link(input, filepath.Join(root, "class", node, "device"))
+	}
+
+	defer func(dir string) { SysfsInputDir = dir }(SysfsInputDir)
+	SysfsInputDir = filepath.Join(root, "class")
+
+	tests := []struct {
+		fn   string
+		want bool
+	}{
+		{"/dev/input/event3", true},
+		{"/dev/input/event4", false},
+		{"/dev/input/event5", false}, // gone
+	}
+	for _, tt := range tests {
+		if got := (&InputDevice{Fn: tt.fn}).IsHID(); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.fn, got, tt.want)
+		}
+	}
+}
+
+func TestWakeupPath(t *testing.T) {
+	root := t.TempDir()
+	hub := filepath.Join(root, "devices", "usb1")
+	usbDev := filepath.Join(hub, "1-1")
+	iface := filepath.Join(usbDev, "1-1:1.0")
+	hid := filepath.Join(iface, "0003:046D:C52B.0001")
+	input := filepath.Join(hid, "input", "input5")
+
+	for _, dir := range []string{filepath.Join(hub, "power"), filepath.Join(usbDev, "power"), input, filepath.Join(root, "bus", "usb"), filepath.Join(root, "bus", "hid"), filepath.Join(root, "class", "event3")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write := func(path, data string) {
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	link := func(target, path string) {
+		if err := os.Symlink(target, path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	link(filepath.Join(root, "bus", "usb"), filepath.Join(usbDev, "subsystem"))
+	link(filepath.Join(root, "bus", "usb"), filepath.Join(iface, "subsystem"))
+	link(filepath.Join(root, "bus", "hid"), filepath.Join(hid, "subsystem"))
+	link(input, filepath.Join(root, "class", "event3", "device"))
+	link(hid, filepath.Join(input, "device"))
+	write(filepath.Join(usbDev, "uevent"), "DEVTYPE=usb_device\n")
+	write(filepath.Join(iface, "uevent"), "DEVTYPE=usb_interface\n")
+	write(filepath.Join(hub, "power", "wakeup"), "enabled\n")
+
+	defer func(dir string) { SysfsInputDir = dir }(SysfsInputDir)
+	SysfsInputDir = filepath.Join(root, "class")
+	dev := &InputDevice{Fn: "/dev/input/event3"}
+
+	// the hub is not configured for the device
+	if _, err := dev.Wakeup(); err != ErrNoWakeup {
+		t.Errorf("expected ErrNoWakeup, got %v", err)
+	}
+
+	write(filepath.Join(usbDev, "power", "wakeup"), "disabled\n")
+	if err := dev.SetWakeup(true); err != nil {
+		t.Fatal(err)
+	}
+	if on, err := dev.Wakeup(); !on || err != nil {
+		t.Errorf("expected wakeup enabled, got %v, %v", on, err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(hub, "power", "wakeup")); string(data) != "enabled\n" {
+		t.Errorf("hub changed to %q", data)
+	}
+}
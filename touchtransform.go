@@ -0,0 +1,230 @@
+//go:build linux
+
+package evdev
+
+import (
+	"errors"
+	"math"
+)
+
+// TouchMatrix is an affine transformation of touch coordinates normalized to
+// 0..1 over the range of their axes, as the rows {a, b, c, d, e, f} of
+//
+//	x' = a*x + b*y + c
+//	y' = d*x + e*y + f
+//
+// This is the layout of the libinput calibration matrix and of the first
+// two rows of the X11 "Coordinate Transformation Matrix", so matrices can
+// be shared with them.
+type TouchMatrix [6]float64
+
+// IdentityMatrix leaves coordinates unchanged.
+var IdentityMatrix = TouchMatrix{1, 0, 0, 0, 1, 0}
+
+// SwapAxesMatrix swaps the x and y axes.
+var SwapAxesMatrix = TouchMatrix{0, 1, 0, 1, 0, 0}
+
+// InvertXMatrix mirrors the x axis.
+var InvertXMatrix = TouchMatrix{-1, 0, 1, 0, 1, 0}
+
+// InvertYMatrix mirrors the y axis.
+var InvertYMatrix = TouchMatrix{1, 0, 0, 0, -1, 1}
+
+// RotationMatrix returns the matrix turning touches by a multiple of 90
+// degrees clockwise, for a screen mounted rotated that way. Other angles
+// are rounded to the nearest multiple.
+func RotationMatrix(degrees int) TouchMatrix {
+	degrees = (degrees%360 + 360) % 360
+	switch (degrees + 45) / 90 % 4 {
+	case 1:
+		return TouchMatrix{0, -1, 1, 1, 0, 0}
+	case 2:
+		return TouchMatrix{-1, 0, 1, 0, -1, 1}
+	case 3:
+		return TouchMatrix{0, 1, 0, -1, 0, 1}
+	}
+
+	return IdentityMatrix
+}
+
+// ScaleOffsetMatrix scales the axes and then moves them by an offset, all in
+// normalized units.
+func ScaleOffsetMatrix(scaleX, scaleY, offsetX, offsetY float64) TouchMatrix {
+	return TouchMatrix{scaleX, 0, offsetX, 0, scaleY, offsetY}
+}
+
+// Then returns the transformation applying m, then n.
+func (m TouchMatrix) Then(n TouchMatrix) TouchMatrix {
+	return TouchMatrix{
+		n[0]*m[0] + n[1]*m[3], n[0]*m[1] + n[1]*m[4], n[0]*m[2] + n[1]*m[5] + n[2],
+		n[3]*m[0] + n[4]*m[3], n[3]*m[1] + n[4]*m[4], n[3]*m[2] + n[4]*m[5] + n[5],
+	}
+}
+
+// Apply transforms normalized coordinates.
+func (m TouchMatrix) Apply(x, y float64) (float64, float64) {
+	return m[0]*x + m[1]*y + m[2], m[3]*x + m[4]*y + m[5]
+}
+
+// ErrCalibrationPoints is returned when calibration points do not determine
+// a transformation, e.g. because they lie on a line.
+var ErrCalibrationPoints = errors.New("evdev: calibration points are collinear")
+
+// CalibrationMatrix computes the transformation that maps the points touched
+// to the points shown on the screen, e.g. crosshairs near three corners.
+// Both are given in normalized coordinates.
+func CalibrationMatrix(touched, shown [3][2]float64) (TouchMatrix, error) {
+	// solve a*tx + b*ty + c = sx for each point, likewise for y, by
+	// Cramer's rule
+	det3 := func(m [3][3]float64) float64 {
+		return m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) -
+			m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
+			m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])
+	}
+
+	var a [3][3]float64
+	for i, p := range touched {
+		a[i] = [3]float64{p[0], p[1], 1}
+	}
+	det := det3(a)
+	if math.Abs(det) < 1e-9 {
+		return TouchMatrix{}, ErrCalibrationPoints
+	}
+
+	var m TouchMatrix
+	for axis := 0; axis < 2; axis++ {
+		for col := 0; col < 3; col++ {
+			b := a
+			for i := range b {
+				b[i][col] = shown[i][axis]
+			}
+			m[axis*3+col] = det3(b) / det
+		}
+	}
+
+	return m, nil
+}
+
+// TouchTransform is a Filter applying a TouchMatrix to the positions of a
+// touchscreen or tablet, ABS_X/ABS_Y and the ABS_MT_POSITION_X/Y of every
+// slot. As x and y depend on each other, the transformed positions are
+// emitted once a slot or frame is complete, before its ABS_MT_SLOT or
+// SYN_REPORT event, and only if they changed.
+type TouchTransform struct {
+	Matrix   TouchMatrix
+	X, Y     AbsInfo // ranges of ABS_X and ABS_Y
+	MTX, MTY AbsInfo // ranges of ABS_MT_POSITION_X and ABS_MT_POSITION_Y
+
+	single *touchPoint
+	slots  map[int32]*touchPoint
+	slot   int32
+}
+
+// Raw and transformed position of a contact.
+type touchPoint struct {
+	raw, out [2]int32
+	dirty    bool
+}
+
+// NewTouchTransform creates a transform for axes with the given ranges,
+// shared by the single-touch and multi-touch axes.
+func NewTouchTransform(matrix TouchMatrix, x, y AbsInfo) *TouchTransform {
+	return &TouchTransform{
+		Matrix: matrix,
+		X:      x,
+		Y:      y,
+		MTX:    x,
+		MTY:    y,
+		single: newTouchPoint(),
+		slots:  make(map[int32]*touchPoint),
+	}
+}
+
+// NewTouchTransformFor creates a transform for the axes of dev, each with
+// its own range.
+func NewTouchTransformFor(dev *InputDevice, matrix TouchMatrix) *TouchTransform {
+	t := NewTouchTransform(matrix, dev.AbsInfos[ABS_X], dev.AbsInfos[ABS_Y])
+	t.MTX, t.MTY = dev.AbsInfos[ABS_MT_POSITION_X], dev.AbsInfos[ABS_MT_POSITION_Y]
+
+	return t
+}
+
+// Process implements Filter.
+func (t *TouchTransform) Process(ev InputEvent) []InputEvent {
+	if ev.Type == EV_SYN && ev.Code == SYN_REPORT {
+		out := t.flush(t.single, ev, ABS_X, ABS_Y, t.X, t.Y)
+		out = append(out, t.flush(t.slots[t.slot], ev, ABS_MT_POSITION_X, ABS_MT_POSITION_Y, t.MTX, t.MTY)...)
+		return append(out, ev)
+	}
+	if ev.Type != EV_ABS {
+		return []InputEvent{ev}
+	}
+
+	switch ev.Code {
+	case ABS_X, ABS_Y:
+		t.single.raw[ev.Code-ABS_X] = int32(ev.Value)
+		t.single.dirty = true
+	case ABS_MT_POSITION_X, ABS_MT_POSITION_Y:
+		p := t.point(t.slot)
+		p.raw[ev.Code-ABS_MT_POSITION_X] = int32(ev.Value)
+		p.dirty = true
+	case ABS_MT_SLOT:
+		out := t.flush(t.slots[t.slot], ev, ABS_MT_POSITION_X, ABS_MT_POSITION_Y, t.MTX, t.MTY)
+		t.slot = int32(ev.Value)
+		return append(out, ev)
+	default:
+		return []InputEvent{ev}
+	}
+
+	return nil
+}
+
+func (t *TouchTransform) point(slot int32) *touchPoint {
+	p, ok := t.slots[slot]
+	if !ok {
+		p = newTouchPoint()
+		t.slots[slot] = p
+	}
+
+	return p
+}
+
+// Create a point whose first position is always emitted.
+func newTouchPoint() *touchPoint {
+	return &touchPoint{out: [2]int32{math.MinInt32, math.MinInt32}}
+}
+
+// Emit the changed transformed coordinates of p on axes with the ranges
+// x and y, timed like ev.
+func (t *TouchTransform) flush(p *touchPoint, ev InputEvent, codeX, codeY EvCode, x, y AbsInfo) []InputEvent {
+	if p == nil || !p.dirty {
+		return nil
+	}
+	p.dirty = false
+
+	nx, ny := t.Matrix.Apply(normalize(p.raw[0], x), normalize(p.raw[1], y))
+	out := [2]int32{denormalize(nx, x), denormalize(ny, y)}
+
+	events := make([]InputEvent, 0, 2)
+	for i, code := range []EvCode{codeX, codeY} {
+		if out[i] != p.out[i] {
+			events = append(events, InputEvent{Time: ev.Time, Type: EV_ABS, Code: code, Value: EvValue(out[i])})
+		}
+	}
+	p.out = out
+
+	return events
+}
+
+func normalize(v int32, axis AbsInfo) float64 {
+	if axis.Max == axis.Min {
+		return 0
+	}
+	return float64(v-axis.Min) / float64(axis.Max-axis.Min)
+}
+
+// Scale a normalized value to the range of axis, clamped to it.
+func denormalize(v float64, axis AbsInfo) int32 {
+	v = math.Max(0, math.Min(1, v))
+	return axis.Min + int32(math.Round(v*float64(axis.Max-axis.Min)))
+}
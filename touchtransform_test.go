@@ -0,0 +1,143 @@
+//go:build linux
+
+package evdev
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestTouchMatrix(t *testing.T) {
+	x, y := RotationMatrix(90).Apply(1, 0)
+	if x != 1 || y != 1 {
+		t.Errorf("rotating top right corner clockwise: got %v,%v, want 1,1", x, y)
+	}
+
+	m := RotationMatrix(90).Then(RotationMatrix(270))
+	for i := range m {
+		if m[i] != IdentityMatrix[i] {
+			t.Fatalf("rotating back and forth: got %v", m)
+		}
+	}
+
+	for _, c := range []struct{ degrees, same int }{
+		{-90, 270},
+		{-180, 180},
+		{-270, 90},
+		{-360, 0},
+		{450, 90},
+		{-100, 270},
+		{44, 0},
+	} {
+		if RotationMatrix(c.degrees) != RotationMatrix(c.same) {
+			t.Errorf("RotationMatrix(%d) differs from RotationMatrix(%d)", c.degrees, c.same)
+		}
+	}
+	if RotationMatrix(-90) == IdentityMatrix {
+		t.Error("RotationMatrix(-90) is the identity")
+	}
+
+	if x, y = SwapAxesMatrix.Then(InvertXMatrix).Apply(0.2, 0.7); math.Abs(x-0.3) > 1e-9 || y != 0.2 {
+		t.Errorf("swapping and inverting: got %v,%v, want 0.3,0.2", x, y)
+	}
+
+	// touches offset by 0.1 and mirrored vertically
+	want := ScaleOffsetMatrix(1, -1, 0.1, 1)
+	shown := [3][2]float64{{0.1, 0.1}, {0.9, 0.1}, {0.1, 0.9}}
+	var touched [3][2]float64
+	for i, p := range shown {
+		touched[i][0], touched[i][1] = p[0]-0.1, 1-p[1]
+	}
+
+	m, err := CalibrationMatrix(touched, shown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range m {
+		if math.Abs(m[i]-want[i]) > 1e-9 {
+			t.Fatalf("calibration: got %v, want %v", m, want)
+		}
+	}
+
+	if _, err = CalibrationMatrix([3][2]float64{{0, 0}, {0.5, 0.5}, {1, 1}}, shown); err != ErrCalibrationPoints {
+		t.Errorf("expected error for collinear points, got %v", err)
+	}
+}
+
+func TestTouchTransform(t *testing.T) {
+	axis := AbsInfo{Min: 0, Max: 1000}
+	tt := NewTouchTransform(RotationMatrix(90), axis, axis)
+
+	now := time.Unix(1000, 0)
+	in := []InputEvent{
+		NewInputEvent(now, EV_ABS, ABS_MT_SLOT, 0),
+		NewInputEvent(now, EV_ABS, ABS_MT_POSITION_X, 1000),
+		NewInputEvent(now, EV_ABS, ABS_MT_POSITION_Y, 0),
+		NewInputEvent(now, EV_ABS, ABS_MT_SLOT, 1),
+		NewInputEvent(now, EV_ABS, ABS_MT_POSITION_X, 250),
+		NewInputEvent(now, EV_ABS, ABS_MT_POSITION_Y, 500),
+		NewInputEvent(now, EV_ABS, ABS_MT_PRESSURE, 30),
+		NewInputEvent(now, EV_ABS, ABS_X, 250),
+		NewInputEvent(now, EV_ABS, ABS_Y, 500),
+		NewInputEvent(now, EV_SYN, SYN_REPORT, 0),
+		// only y changes in the rotated output
+		NewInputEvent(now, EV_ABS, ABS_MT_POSITION_X, 300),
+		NewInputEvent(now, EV_SYN, SYN_REPORT, 0),
+	}
+	want := []InputEvent{
+		NewInputEvent(now, EV_ABS, ABS_MT_SLOT, 0),
+		NewInputEvent(now, EV_ABS, ABS_MT_POSITION_X, 1000),
+		NewInputEvent(now, EV_ABS, ABS_MT_POSITION_Y, 1000),
+		NewInputEvent(now, EV_ABS, ABS_MT_SLOT, 1),
+		NewInputEvent(now, EV_ABS, ABS_MT_PRESSURE, 30),
+		NewInputEvent(now, EV_ABS, ABS_X, 500),
+		NewInputEvent(now, EV_ABS, ABS_Y, 250),
+		NewInputEvent(now, EV_ABS, ABS_MT_POSITION_X, 500),
+		NewInputEvent(now, EV_ABS, ABS_MT_POSITION_Y, 250),
+		NewInputEvent(now, EV_SYN, SYN_REPORT, 0),
+		NewInputEvent(now, EV_ABS, ABS_MT_POSITION_Y, 300),
+		NewInputEvent(now, EV_SYN, SYN_REPORT, 0),
+	}
+
+	got := make([]InputEvent, 0)
+	for _, ev := range in {
+		got = append(got, tt.Process(ev)...)
+	}
+	if !sameEvents(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestTouchTransformForRanges(t *testing.T) {
+	dev := &InputDevice{AbsInfos: map[int]AbsInfo{
+		ABS_X:             {Max: 100},
+		ABS_Y:             {Max: 100},
+		ABS_MT_POSITION_X: {Max: 1000},
+		ABS_MT_POSITION_Y: {Max: 1000},
+	}}
+	tt := NewTouchTransformFor(dev, InvertXMatrix)
+
+	now := time.Unix(1000, 0)
+	got := make([]InputEvent, 0)
+	for _, ev := range []InputEvent{
+		NewInputEvent(now, EV_ABS, ABS_MT_POSITION_X, 300),
+		NewInputEvent(now, EV_ABS, ABS_MT_POSITION_Y, 400),
+		NewInputEvent(now, EV_ABS, ABS_X, 30),
+		NewInputEvent(now, EV_ABS, ABS_Y, 40),
+		NewInputEvent(now, EV_SYN, SYN_REPORT, 0),
+	} {
+		got = append(got, tt.Process(ev)...)
+	}
+
+	want := []InputEvent{
+		NewInputEvent(now, EV_ABS, ABS_X, 70),
+		NewInputEvent(now, EV_ABS, ABS_Y, 40),
+		NewInputEvent(now, EV_ABS, ABS_MT_POSITION_X, 700),
+		NewInputEvent(now, EV_ABS, ABS_MT_POSITION_Y, 400),
+		NewInputEvent(now, EV_SYN, SYN_REPORT, 0),
+	}
+	if !sameEvents(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,116 @@
+package evdev
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// TraceWriter exports input events in the Chrome trace-event JSON format,
+// which can be loaded into chrome://tracing and the Perfetto UI. Every device
+// gets its own track, key presses are shown as async slices spanning from
+// key-down to key-up, identified by device and key code so that overlapping
+// presses are told apart, and all other events as instant events. Example:
+//
+//	tw := evdev.NewTraceWriter(f)
+//	tw.WriteEvent(dev.Fn, &ev)
+//	...
+//	tw.Close()
+type TraceWriter struct {
+	w      io.Writer
+	tracks map[string]int // device -> thread id of its track
+	count  int            // number of trace events written so far
+}
+
+// Process id under which all device tracks are grouped.
+const tracePid = 1
+
+type traceEvent struct {
+	Name    string                 `json:"name"`
+	Cat     string                 `json:"cat,omitempty"`
+	Ph      string                 `json:"ph"`
+	Ts      int64                  `json:"ts"`
+	Pid     int                    `json:"pid"`
+	Tid     int                    `json:"tid"`
+	Scope   string                 `json:"s,omitempty"`
+	ID      string                 `json:"id,omitempty"`
+	IDScope string                 `json:"scope,omitempty"`
+	Args    map[string]interface{} `json:"args,omitempty"`
+}
+
+// NewTraceWriter creates a trace writer writing to w.
+func NewTraceWriter(w io.Writer) *TraceWriter {
+	return &TraceWriter{w: w, tracks: make(map[string]int)}
+}
+
+// WriteEvent adds an event of the named device to the trace.
+func (tw *TraceWriter) WriteEvent(device string, ev *InputEvent) error {
+	tid, ok := tw.tracks[device]
+	if !ok {
+		tid = len(tw.tracks) + 1
+		tw.tracks[device] = tid
+
+		err := tw.write(traceEvent{
+			Name: "thread_name", Ph: "M", Pid: tracePid, Tid: tid,
+			Args: map[string]interface{}{"name": device},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	te := traceEvent{
+		Name: CodeName(int(ev.Type), int(ev.Code)),
+		Cat:  TypeName(int(ev.Type)),
+		Ts:   int64(ev.Time.Sec)*1000000 + int64(ev.Time.Usec),
+		Pid:  tracePid,
+		Tid:  tid,
+		Args: map[string]interface{}{"value": ev.Value},
+	}
+
+	switch {
+	case ev.Type == EV_KEY && ev.Value == EvValue(KeyDown):
+		te.Ph, te.ID, te.IDScope = "b", strconv.Itoa(int(ev.Code)), device
+	case ev.Type == EV_KEY && ev.Value == EvValue(KeyUp):
+		te.Ph, te.ID, te.IDScope = "e", strconv.Itoa(int(ev.Code)), device
+	default:
+		te.Ph, te.Scope = "i", "t"
+	}
+
+	return tw.write(te)
+}
+
+// Close terminates the JSON document. It does not close the underlying writer.
+func (tw *TraceWriter) Close() error {
+	if tw.count == 0 {
+		_, err := io.WriteString(tw.w, `{"traceEvents":[`)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(tw.w, "]}\n")
+	return err
+}
+
+func (tw *TraceWriter) write(te traceEvent) error {
+	sep := ",\n"
+	if tw.count == 0 {
+		sep = "{\"traceEvents\":[\n"
+	}
+
+	b, err := json.Marshal(te)
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.WriteString(tw.w, sep); err != nil {
+		return err
+	}
+	if _, err = tw.w.Write(b); err != nil {
+		return err
+	}
+
+	tw.count++
+	return nil
+}
@@ -0,0 +1,51 @@
+package evdev
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTraceWriter(t *testing.T) {
+	buf := new(bytes.Buffer)
+	tw := NewTraceWriter(buf)
+
+	now := time.Unix(1000, 0)
+	for _, ev := range []InputEvent{
+		NewInputEvent(now, EV_KEY, KEY_A, 1),
+		NewInputEvent(now.Add(time.Millisecond), EV_KEY, KEY_B, 1),
+		NewInputEvent(now.Add(2*time.Millisecond), EV_KEY, KEY_A, 0),
+		NewInputEvent(now.Add(3*time.Millisecond), EV_KEY, KEY_B, 0),
+		NewInputEvent(now.Add(3*time.Millisecond), EV_SYN, SYN_REPORT, 0),
+	} {
+		if err := tw.WriteEvent("kbd", &ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var trace struct {
+		TraceEvents []traceEvent `json:"traceEvents"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &trace); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf)
+	}
+
+	// overlapping presses each close their own slice
+	want := []struct{ ph, id string }{{"M", ""}, {"b", "30"}, {"b", "48"}, {"e", "30"}, {"e", "48"}, {"i", ""}}
+	if len(trace.TraceEvents) != len(want) {
+		t.Fatalf("got %d trace events, want %d", len(trace.TraceEvents), len(want))
+	}
+	for i, w := range want {
+		te := trace.TraceEvents[i]
+		if te.Ph != w.ph || te.ID != w.id {
+			t.Errorf("trace event %d: got %s/%s, want %s/%s", i, te.Ph, te.ID, w.ph, w.id)
+		}
+		if w.id != "" && te.IDScope != "kbd" {
+			t.Errorf("trace event %d: scope %q", i, te.IDScope)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package evdev
+
+import "time"
+
+// TurboFilter turns held buttons into rapid press/release cycles (autofire).
+// Turbo is enabled per button through Buttons, or at runtime by holding all
+// keys of the Toggle chord and pressing the button. Tick must be called at
+// least twice per Rate for the cycles to be generated on time.
+type TurboFilter struct {
+	Rate    time.Duration   // duration of one press/release cycle
+	Buttons map[EvCode]bool // buttons with turbo enabled
+	Toggle  []EvCode        // chord toggling turbo for the next pressed button
+
+	held      map[EvCode]*turboState
+	down      map[EvCode]bool // all currently held keys, for chord detection
+	swallowed map[EvCode]bool // buttons pressed to toggle turbo, dropped until released
+}
+
+type turboState struct {
+	pressed bool      // state last emitted for the button
+	next    time.Time // when the state flips next
+}
+
+// NewTurboFilter creates a turbo filter cycling at the given rate.
+func NewTurboFilter(rate time.Duration, buttons ...EvCode) *TurboFilter {
+	f := &TurboFilter{
+		Rate:      rate,
+		Buttons:   make(map[EvCode]bool),
+		held:      make(map[EvCode]*turboState),
+		down:      make(map[EvCode]bool),
+		swallowed: make(map[EvCode]bool),
+	}
+
+	for _, btn := range buttons {
+		f.Buttons[btn] = true
+	}
+
+	return f
+}
+
+// Process passes through all events except those of turbo buttons, which
+// are generated by Tick while the button is held.
+func (f *TurboFilter) Process(ev InputEvent) []InputEvent {
+	if ev.Type != EV_KEY {
+		return []InputEvent{ev}
+	}
+
+	if f.swallowed[ev.Code] {
+		// the press toggled turbo, so its repeats and release are dropped
+		if ev.Value == EvValue(KeyUp) {
+			delete(f.swallowed, ev.Code)
+		}
+		return nil
+	}
+	if ev.Value == EvValue(KeyDown) && f.toggleHeld(ev.Code) {
+		f.Buttons[ev.Code] = !f.Buttons[ev.Code]
+		f.swallowed[ev.Code] = true
+		return nil
+	}
+	f.down[ev.Code] = ev.Value != EvValue(KeyUp)
+
+	state, cycling := f.held[ev.Code]
+	if !cycling && !f.Buttons[ev.Code] {
+		return []InputEvent{ev}
+	}
+
+	switch ev.Value {
+	case EvValue(KeyDown):
+		f.held[ev.Code] = &turboState{pressed: true, next: ev.Timestamp().Add(f.Rate / 2)}
+		return []InputEvent{ev}
+	case EvValue(KeyUp):
+		delete(f.held, ev.Code)
+		if state != nil && state.pressed {
+			return []InputEvent{ev}
+		}
+	}
+
+	// kernel autorepeat and releases of already released buttons
+	return nil
+}
+
+// Tick flips the state of every held turbo button that is due.
+func (f *TurboFilter) Tick(now time.Time) []InputEvent {
+	events := make([]InputEvent, 0)
+
+	for code, state := range f.held {
+		if now.Before(state.next) {
+			continue
+		}
+
+		state.pressed = !state.pressed
+		state.next = now.Add(f.Rate / 2)
+
+		value := EvValue(KeyUp)
+		if state.pressed {
+			value = EvValue(KeyDown)
+		}
+		events = append(events, NewInputEvent(now, EV_KEY, code, value))
+	}
+
+	if len(events) > 0 {
+		events = append(events, NewInputEvent(now, EV_SYN, SYN_REPORT, 0))
+	}
+
+	return events
+}
+
+// Check whether all keys of the toggle chord (other than code) are held.
+func (f *TurboFilter) toggleHeld(code EvCode) bool {
+	if len(f.Toggle) == 0 {
+		return false
+	}
+
+	for _, key := range f.Toggle {
+		if key == code || !f.down[key] {
+			return false
+		}
+	}
+
+	return true
+}
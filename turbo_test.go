@@ -0,0 +1,78 @@
+package evdev
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTurboFilter(t *testing.T) {
+	f := NewTurboFilter(100*time.Millisecond, BTN_SOUTH)
+	f.Toggle = []EvCode{BTN_SELECT}
+	t0 := time.Unix(1000, 0)
+	ms := time.Millisecond
+
+	var got []InputEvent
+	key := func(d time.Duration, code EvCode, value KeyEventState) {
+		got = append(got, f.Process(NewInputEvent(t0.Add(d), EV_KEY, code, EvValue(value)))...)
+	}
+	tick := func(from, to time.Duration) {
+		for d := from; d <= to; d += 10 * ms {
+			got = append(got, f.Tick(t0.Add(d))...)
+		}
+	}
+	keys := func() []EvValue {
+		values := make([]EvValue, 0)
+		for _, ev := range got {
+			if ev.Type == EV_KEY {
+				values = append(values, ev.Value)
+			}
+		}
+		got = nil
+		return values
+	}
+	same := func(a, b []EvValue) bool {
+		if len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if a[i] != b[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	// held for 220ms, the button cycles every 100ms
+	key(0, BTN_SOUTH, KeyDown)
+	key(100*ms, BTN_SOUTH, KeyHold)
+	tick(0, 210*ms)
+	key(220*ms, BTN_SOUTH, KeyUp)
+	if v := keys(); !same(v, []EvValue{1, 0, 1, 0, 1, 0}) {
+		t.Errorf("unexpected turbo cycles %v", v)
+	}
+
+	// toggling turbo off swallows the whole press of the button
+	key(1000*ms, BTN_SELECT, KeyDown)
+	key(1010*ms, BTN_SOUTH, KeyDown)
+	key(1020*ms, BTN_SOUTH, KeyHold)
+	key(1030*ms, BTN_SOUTH, KeyUp)
+	key(1040*ms, BTN_SELECT, KeyUp)
+	for _, ev := range got {
+		if ev.Code == BTN_SOUTH {
+			t.Errorf("toggle press leaked: %v", got)
+			break
+		}
+	}
+	got = nil
+	if f.Buttons[BTN_SOUTH] {
+		t.Error("expected turbo to be off")
+	}
+
+	// without turbo, presses pass through
+	key(2000*ms, BTN_SOUTH, KeyDown)
+	tick(2000*ms, 2300*ms)
+	key(2300*ms, BTN_SOUTH, KeyUp)
+	if v := keys(); !same(v, []EvValue{1, 0}) {
+		t.Errorf("unexpected events without turbo %v", v)
+	}
+}
@@ -0,0 +1,43 @@
+package evdev
+
+import "strconv"
+
+// EvType is an event type, one of the EV_* constants.
+type EvType uint16
+
+// EvCode is an event code, whose meaning depends on the event type, e.g.
+// one of the KEY_* constants for EV_KEY.
+type EvCode uint16
+
+// EvValue is the value of an event, e.g. one of the KeyEventState values
+// for EV_KEY or the position of an EV_ABS axis.
+type EvValue int32
+
+// String returns the name of the event type, e.g. "EV_KEY".
+func (t EvType) String() string {
+	return TypeName(int(t))
+}
+
+// String returns the code as a decimal number, as its name depends on the
+// event type. Use Name to get the name.
+func (c EvCode) String() string {
+	return strconv.Itoa(int(c))
+}
+
+// Name returns the name of the code for an event type, e.g. "KEY_A".
+func (c EvCode) Name(t EvType) string {
+	return CodeName(int(t), int(c))
+}
+
+func (v EvValue) String() string {
+	return strconv.Itoa(int(v))
+}
+
+// Report whether an EV_KEY code is a button rather than a key: mouse,
+// joystick, gamepad, digitizer and d-pad buttons, and the trigger happy
+// buttons of game controllers.
+func isButton(code EvCode) bool {
+	return code >= BTN_MISC && code < KEY_OK ||
+		code >= BTN_DPAD_UP && code <= BTN_DPAD_RIGHT ||
+		code >= BTN_TRIGGER_HAPPY && code <= BTN_TRIGGER_HAPPY40
+}
@@ -0,0 +1,151 @@
+package evdev
+
+import (
+	"sync"
+	"time"
+)
+
+// Upper bounds of the inter-key interval histogram buckets. The last bucket
+// of IntervalHistogram collects everything above the last bound.
+var typingIntervalBounds = []time.Duration{
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	150 * time.Millisecond,
+	200 * time.Millisecond,
+	300 * time.Millisecond,
+	500 * time.Millisecond,
+	1000 * time.Millisecond,
+}
+
+// IntervalBucket is a bucket of the inter-key interval histogram.
+type IntervalBucket struct {
+	Max   time.Duration // upper bound of the bucket, 0 for the last bucket
+	Count int
+}
+
+// TypingStats computes typing statistics from a keyboard's event stream:
+// typing speed, how often each key is pressed and the distribution of the
+// intervals between key presses. Only counters and timestamps are kept, never
+// the sequence of typed keys. It is safe for concurrent use.
+type TypingStats struct {
+	Window   time.Duration // window over which typing speed is computed
+	MaxPause time.Duration // longer intervals count as pauses, not typing
+
+	mu        sync.Mutex
+	presses   []time.Time // key presses within the window
+	counts    map[EvCode]int
+	histogram []int
+	total     time.Duration // sum of all counted intervals
+	intervals int
+	last      time.Time
+}
+
+// NewTypingStats creates statistics computing the typing speed over window.
+func NewTypingStats(window time.Duration) *TypingStats {
+	return &TypingStats{
+		Window:    window,
+		MaxPause:  2 * time.Second,
+		counts:    make(map[EvCode]int),
+		histogram: make([]int, len(typingIntervalBounds)+1),
+	}
+}
+
+// Feed passes an event to the statistics. Only key presses are counted,
+// mouse and other buttons are ignored.
+func (s *TypingStats) Feed(ev *InputEvent) {
+	if ev.Type != EV_KEY || ev.Value != EvValue(KeyDown) || isButton(ev.Code) {
+		return
+	}
+	t := ev.Timestamp()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.counts[ev.Code]++
+	s.presses = append(s.expire(t), t)
+
+	if !s.last.IsZero() {
+		if interval := t.Sub(s.last); interval >= 0 && interval <= s.MaxPause {
+			s.histogram[typingBucket(interval)]++
+			s.total += interval
+			s.intervals++
+		}
+	}
+	s.last = t
+}
+
+// KeysPerMinute returns the typing speed over the last Window before now.
+func (s *TypingStats) KeysPerMinute(now time.Time) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.presses = s.expire(now)
+	return float64(len(s.presses)) * float64(time.Minute) / float64(s.Window)
+}
+
+// WordsPerMinute returns the typing speed in words per minute, counting five
+// keys as one word.
+func (s *TypingStats) WordsPerMinute(now time.Time) float64 {
+	return s.KeysPerMinute(now) / 5
+}
+
+// KeyFrequency returns how often each key has been pressed.
+func (s *TypingStats) KeyFrequency() map[EvCode]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counts := make(map[EvCode]int, len(s.counts))
+	for code, n := range s.counts {
+		counts[code] = n
+	}
+
+	return counts
+}
+
+// IntervalHistogram returns the distribution of intervals between key presses.
+func (s *TypingStats) IntervalHistogram() []IntervalBucket {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	buckets := make([]IntervalBucket, len(s.histogram))
+	for i, n := range s.histogram {
+		buckets[i].Count = n
+		if i < len(typingIntervalBounds) {
+			buckets[i].Max = typingIntervalBounds[i]
+		}
+	}
+
+	return buckets
+}
+
+// MeanInterval returns the mean interval between key presses, excluding pauses.
+func (s *TypingStats) MeanInterval() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.intervals == 0 {
+		return 0
+	}
+
+	return s.total / time.Duration(s.intervals)
+}
+
+// Drop the key presses that are older than the window.
+func (s *TypingStats) expire(now time.Time) []time.Time {
+	i := 0
+	for i < len(s.presses) && now.Sub(s.presses[i]) > s.Window {
+		i++
+	}
+
+	return s.presses[i:]
+}
+
+func typingBucket(interval time.Duration) int {
+	for i, bound := range typingIntervalBounds {
+		if interval <= bound {
+			return i
+		}
+	}
+
+	return len(typingIntervalBounds)
+}
@@ -0,0 +1,59 @@
+package evdev
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTypingStats(t *testing.T) {
+	s := NewTypingStats(time.Minute)
+	t0 := time.Unix(1000, 0)
+	ms := time.Millisecond
+
+	press := func(d time.Duration, code EvCode, value KeyEventState) {
+		ev := NewInputEvent(t0.Add(d), EV_KEY, code, EvValue(value))
+		s.Feed(&ev)
+	}
+
+	press(0, KEY_H, KeyDown)
+	press(50*ms, KEY_H, KeyUp)
+	press(120*ms, KEY_I, KeyDown)
+	press(300*ms, KEY_I, KeyHold)
+	press(400*ms, BTN_LEFT, KeyDown)
+	press(520*ms, KEY_I, KeyDown)
+	press(5*time.Second, KEY_H, KeyDown) // after a pause
+
+	if f := s.KeyFrequency(); !reflect.DeepEqual(f, map[EvCode]int{KEY_H: 2, KEY_I: 2}) {
+		t.Errorf("unexpected key frequency %v", f)
+	}
+	if m := s.MeanInterval(); m != 260*ms {
+		t.Errorf("expected a mean interval of 260ms, got %v", m)
+	}
+
+	counts := make([]int, 0)
+	for _, b := range s.IntervalHistogram() {
+		counts = append(counts, b.Count)
+	}
+	if want := []int{0, 0, 1, 0, 0, 1, 0, 0}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("got histogram %v, want %v", counts, want)
+	}
+
+	if wpm := s.WordsPerMinute(t0.Add(10 * time.Second)); wpm != 0.8 {
+		t.Errorf("expected 0.8 words per minute, got %v", wpm)
+	}
+
+	tests := []struct {
+		now time.Duration
+		kpm float64
+	}{
+		{10 * time.Second, 4},
+		{60*time.Second + 200*ms, 2}, // the first presses left the window
+		{2 * time.Minute, 0},
+	}
+	for _, tt := range tests {
+		if kpm := s.KeysPerMinute(t0.Add(tt.now)); kpm != tt.kpm {
+			t.Errorf("at %v: got %v keys per minute, want %v", tt.now, kpm, tt.kpm)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+//go:build linux
+
+package evdev
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// UInputPath is the device node of the uinput driver.
+var UInputPath = "/dev/uinput"
+
+// UInputSetup describes a virtual input device.
+type UInputSetup struct {
+	Name string // device name, at most UINPUT_MAX_NAME_SIZE-1 bytes
+	Phys string // physical location, optional
+
+	BusType uint16 // bus type identifier, e.g. BUS_VIRTUAL
+	Vendor  uint16 // vendor identifier
+	Product uint16 // product identifier
+	Version uint16 // version identifier
+
+	Capabilities CapabilitySet   // supported event types and codes
+	AbsInfos     map[int]AbsInfo // parameters of the absolute axes
+	Properties   []int           // INPUT_PROP_* properties
+	FFEffectsMax uint32          // number of force feedback effects
+}
+
+// UInputDevice is a virtual input device created through uinput. Events
+// written to it appear on its evdev node as if they came from hardware.
+type UInputDevice struct {
+	File *os.File
+	Name string
+}
+
+// Corresponds to the uinput_setup struct.
+type uinputSetup struct {
+	id           deviceInfo
+	name         [UINPUT_MAX_NAME_SIZE]byte
+	ffEffectsMax uint32
+}
+
+// Corresponds to the uinput_abs_setup struct.
+type uinputAbsSetup struct {
+	code uint16
+	_    uint16
+	info AbsInfo
+}
+
+// Ioctls enabling the codes of each event type.
+var uinputCodeBits = map[int]uintptr{
+	EV_KEY: UI_SET_KEYBIT,
+	EV_REL: UI_SET_RELBIT,
+	EV_ABS: UI_SET_ABSBIT,
+	EV_MSC: UI_SET_MSCBIT,
+	EV_LED: UI_SET_LEDBIT,
+	EV_SND: UI_SET_SNDBIT,
+	EV_FF:  UI_SET_FFBIT,
+	EV_SW:  UI_SET_SWBIT,
+}
+
+// CreateDevice creates a virtual input device. Absolute axes without an
+// entry in AbsInfos get an empty range. The device disappears when closed.
+// Creating devices usually requires root or write access to /dev/uinput.
+func CreateDevice(setup UInputSetup) (*UInputDevice, error) {
+	if setup.Name == "" || len(setup.Name) >= UINPUT_MAX_NAME_SIZE {
+		return nil, errors.New("evdev: invalid uinput device name")
+	}
+
+	f, err := os.OpenFile(UInputPath, os.O_WRONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = uinputSetupDevice(f.Fd(), setup); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &UInputDevice{File: f, Name: setup.Name}, nil
+}
+
+// CloneDevice creates a virtual device with the name, ids, capabilities,
+// axis ranges and properties of src, e.g. for a proxy that grabs src and
+// forwards its events, remapped, to the clone. Force feedback is left out:
+// the clone would have to service the effect uploads of its clients, which
+// would otherwise block until the kernel gives up on them.
+func CloneDevice(src *InputDevice) (*UInputDevice, error) {
+	return CreateDevice(cloneSetup(src))
+}
+
+// Get the setup of a clone of src.
+func cloneSetup(src *InputDevice) UInputSetup {
+	setup := UInputSetup{
+		Name:         src.Name,
+		Phys:         src.Phys,
+		BusType:      src.BusType,
+		Vendor:       src.Vendor,
+		Product:      src.Product,
+		Version:      src.Version,
+		Capabilities: src.CapabilitySet(),
+		AbsInfos:     src.AbsInfos,
+		Properties:   src.Properties(),
+	}
+	if len(setup.Name) >= UINPUT_MAX_NAME_SIZE {
+		setup.Name = setup.Name[:UINPUT_MAX_NAME_SIZE-1]
+	}
+	delete(setup.Capabilities, EV_FF)
+
+	return setup
+}
+
+func uinputSetupDevice(fd uintptr, setup UInputSetup) error {
+	types := make([]int, 0, len(setup.Capabilities))
+	for evType := range setup.Capabilities {
+		types = append(types, evType)
+	}
+	sort.Ints(types)
+
+	for _, evType := range types {
+		if evType == EV_SYN {
+			continue
+		}
+		if err := ioctlInt(fd, UI_SET_EVBIT, uintptr(evType)); err != 0 {
+			return err
+		}
+
+		request, ok := uinputCodeBits[evType]
+		if !ok {
+			// e.g. EV_REP, which only enables kernel autorepeat
+			continue
+		}
+		for _, code := range setup.Capabilities[evType] {
+			if err := ioctlInt(fd, request, uintptr(code)); err != 0 {
+				return err
+			}
+		}
+	}
+
+	for _, prop := range setup.Properties {
+		if err := ioctlInt(fd, UI_SET_PROPBIT, uintptr(prop)); err != 0 {
+			return err
+		}
+	}
+
+	if setup.Phys != "" {
+		phys, err := syscall.BytePtrFromString(setup.Phys)
+		if err != nil {
+			return err
+		}
+		if err := ioctl(fd, UI_SET_PHYS, unsafe.Pointer(phys)); err != 0 {
+			return err
+		}
+	}
+
+	for _, axis := range setup.Capabilities[EV_ABS] {
+		abs := uinputAbsSetup{code: uint16(axis), info: setup.AbsInfos[axis]}
+		if err := ioctl(fd, UI_ABS_SETUP, unsafe.Pointer(&abs)); err != 0 {
+			return err
+		}
+	}
+
+	dev := uinputSetup{
+		id:           deviceInfo{setup.BusType, setup.Vendor, setup.Product, setup.Version},
+		ffEffectsMax: setup.FFEffectsMax,
+	}
+	copy(dev.name[:], setup.Name)
+	if err := ioctl(fd, UI_DEV_SETUP, unsafe.Pointer(&dev)); err != 0 {
+		return err
+	}
+
+	if err := ioctl(fd, UI_DEV_CREATE, nil); err != 0 {
+		return err
+	}
+
+	return nil
+}
+
+// Issue an ioctl whose argument is passed by value.
+func ioctlInt(fd uintptr, name uintptr, value uintptr) syscall.Errno {
+	_, _, err := syscall.RawSyscall(syscall.SYS_IOCTL, fd, name, value)
+	return err
+}
+
+// WriteEvent writes an event to the device. The kernel timestamps it anew.
+func (u *UInputDevice) WriteEvent(ev *InputEvent) error {
+	buffer := unsafe.Slice((*byte)(unsafe.Pointer(ev)), eventsize)
+
+	_, err := u.File.Write(buffer)
+	return closedErr(err)
+}
+
+// Emit writes an event with the given type, code and value.
+func (u *UInputDevice) Emit(evType EvType, code EvCode, value EvValue) error {
+	ev := NewInputEvent(time.Now(), evType, code, value)
+	return u.WriteEvent(&ev)
+}
+
+// Syn writes a SYN_REPORT, completing the frame of events written before.
+func (u *UInputDevice) Syn() error {
+	return u.Emit(EV_SYN, SYN_REPORT, 0)
+}
+
+// KeyDown presses a key or button.
+func (u *UInputDevice) KeyDown(code EvCode) error {
+	return writeFrame(u, EV_KEY, []EvCode{code}, []int{int(KeyDown)}, true)
+}
+
+// KeyUp releases a key or button.
+func (u *UInputDevice) KeyUp(code EvCode) error {
+	return writeFrame(u, EV_KEY, []EvCode{code}, []int{int(KeyUp)}, true)
+}
+
+// KeyPress presses and releases a key.
+func (u *UInputDevice) KeyPress(code EvCode) error {
+	if err := u.KeyDown(code); err != nil {
+		return err
+	}
+
+	return u.KeyUp(code)
+}
+
+// Click presses and releases a mouse button, e.g. BTN_LEFT.
+func (u *UInputDevice) Click(button EvCode) error {
+	return u.KeyPress(button)
+}
+
+// MoveRel moves a relative pointer.
+func (u *UInputDevice) MoveRel(dx, dy int) error {
+	return writeFrame(u, EV_REL, []EvCode{REL_X, REL_Y}, []int{dx, dy}, false)
+}
+
+// MoveAbs moves an absolute pointer to a position within the ranges of its
+// ABS_X and ABS_Y axes.
+func (u *UInputDevice) MoveAbs(x, y int) error {
+	return writeFrame(u, EV_ABS, []EvCode{ABS_X, ABS_Y}, []int{x, y}, true)
+}
+
+// TypeString types text with the US layout, pressing shift as needed.
+func (u *UInputDevice) TypeString(s string) error {
+	k := &OnScreenKeyboard{Writer: u, Layout: USKeyboardLayout()}
+	return k.TypeText(s)
+}
+
+// SysName returns the sysfs name of the device, e.g. "input42".
+func (u *UInputDevice) SysName() (string, error) {
+	name := new([MAX_NAME_SIZE]byte)
+
+	if err := ioctl(u.File.Fd(), uintptr(UI_GET_SYSNAME), unsafe.Pointer(name)); err != 0 {
+		return "", err
+	}
+
+	return bytesToString(name), nil
+}
+
+// Devnode returns the path of the evdev node of the device, e.g.
+// "/dev/input/event7". The node may take a moment to appear after creation.
+func (u *UInputDevice) Devnode() (string, error) {
+	sysname, err := u.SysName()
+	if err != nil {
+		return "", err
+	}
+
+	paths, err := filepath.Glob(filepath.Join(SysfsInputDir, sysname, "event*"))
+	if err != nil {
+		return "", err
+	}
+	if len(paths) == 0 {
+		return "", os.ErrNotExist
+	}
+
+	return filepath.Join("/dev/input", filepath.Base(paths[0])), nil
+}
+
+// Close destroys the device.
+func (u *UInputDevice) Close() error {
+	ioctl(u.File.Fd(), UI_DEV_DESTROY, nil)
+
+	return closedErr(u.File.Close())
+}
@@ -0,0 +1,43 @@
+//go:build linux
+
+package evdev
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCloneSetup(t *testing.T) {
+	caps := func(evType int, codes ...int) []CapabilityCode {
+		c := make([]CapabilityCode, 0, len(codes))
+		for _, code := range codes {
+			c = append(c, CapabilityCode{code, CodeName(evType, code)})
+		}
+		return c
+	}
+	pad := &InputDevice{
+		Name:    strings.Repeat("x", UINPUT_MAX_NAME_SIZE+10),
+		BusType: BUS_USB,
+		Vendor:  0x045e,
+		Product: 0x028e,
+		Capabilities: map[CapabilityType][]CapabilityCode{
+			{EV_KEY, "EV_KEY"}: caps(EV_KEY, BTN_SOUTH, BTN_EAST),
+			{EV_ABS, "EV_ABS"}: caps(EV_ABS, ABS_X),
+			{EV_FF, "EV_FF"}:   caps(EV_FF, FF_RUMBLE),
+		},
+		AbsInfos: map[int]AbsInfo{ABS_X: {Min: -32768, Max: 32767}},
+	}
+
+	setup := cloneSetup(pad)
+	if len(setup.Name) != UINPUT_MAX_NAME_SIZE-1 || setup.Vendor != pad.Vendor || setup.Product != pad.Product {
+		t.Errorf("unexpected setup %+v", setup)
+	}
+	want := CapabilitySet{EV_KEY: {BTN_SOUTH, BTN_EAST}, EV_ABS: {ABS_X}}
+	if !reflect.DeepEqual(setup.Capabilities, want) {
+		t.Errorf("got capabilities %v, want %v without force feedback", setup.Capabilities, want)
+	}
+	if setup.FFEffectsMax != 0 || !reflect.DeepEqual(setup.AbsInfos, pad.AbsInfos) {
+		t.Errorf("unexpected setup %+v", setup)
+	}
+}
@@ -0,0 +1,77 @@
+//go:build linux
+
+package evdev
+
+import (
+	"sync"
+	"time"
+)
+
+// Watchdog flags devices that stop delivering events although they are
+// expected to, e.g. a polling touchscreen that should report at least one
+// SYN_REPORT every few seconds. Every event read from the device has to be
+// passed to Feed; if no matching event arrives within the heartbeat
+// interval, OnStall is called so that the device can be reconnected.
+type Watchdog struct {
+	Device    *InputDevice
+	Heartbeat time.Duration
+
+	// Match selects the events that count as a heartbeat. If nil, every
+	// SYN_REPORT does.
+	Match func(ev *InputEvent) bool
+
+	// OnStall is called from a separate goroutine, once per stall.
+	OnStall func(dev *InputDevice)
+
+	mu      sync.Mutex
+	timer   *time.Timer
+	stalled bool
+}
+
+// NewWatchdog creates and starts a watchdog for dev.
+func NewWatchdog(dev *InputDevice, heartbeat time.Duration, onStall func(dev *InputDevice)) *Watchdog {
+	w := &Watchdog{Device: dev, Heartbeat: heartbeat, OnStall: onStall}
+	w.timer = time.AfterFunc(heartbeat, w.expire)
+
+	return w
+}
+
+// Feed passes an event read from the device to the watchdog.
+func (w *Watchdog) Feed(ev *InputEvent) {
+	if w.Match != nil && !w.Match(ev) {
+		return
+	}
+	if w.Match == nil && (ev.Type != EV_SYN || ev.Code != SYN_REPORT) {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.stalled = false
+	w.timer.Reset(w.Heartbeat)
+}
+
+// Stalled reports whether the heartbeat interval has passed without
+// a matching event.
+func (w *Watchdog) Stalled() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.stalled
+}
+
+// Stop stops the watchdog.
+func (w *Watchdog) Stop() {
+	w.timer.Stop()
+}
+
+func (w *Watchdog) expire() {
+	w.mu.Lock()
+	w.stalled = true
+	w.mu.Unlock()
+
+	if w.OnStall != nil {
+		w.OnStall(w.Device)
+	}
+}
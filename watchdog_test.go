@@ -0,0 +1,67 @@
+//go:build linux
+
+package evdev
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWatchdog(t *testing.T) {
+	dev := &InputDevice{Name: "touchscreen"}
+	stalls := make(chan *InputDevice, 1)
+	w := NewWatchdog(dev, 100*time.Millisecond, func(d *InputDevice) { stalls <- d })
+	defer w.Stop()
+
+	// heartbeats keep the watchdog quiet, other events don't count
+	for i := 0; i < 5; i++ {
+		time.Sleep(20 * time.Millisecond)
+		key := NewInputEvent(time.Now(), EV_KEY, BTN_TOUCH, 1)
+		syn := NewInputEvent(time.Now(), EV_SYN, SYN_REPORT, 0)
+		w.Feed(&key)
+		w.Feed(&syn)
+	}
+	if w.Stalled() {
+		t.Fatal("expected no stall while fed")
+	}
+
+	select {
+	case d := <-stalls:
+		if d != dev {
+			t.Errorf("stall reported for %v", d)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a stall")
+	}
+	if !w.Stalled() {
+		t.Error("expected the device to be stalled")
+	}
+
+	syn := NewInputEvent(time.Now(), EV_SYN, SYN_REPORT, 0)
+	w.Feed(&syn)
+	if w.Stalled() {
+		t.Error("expected a heartbeat to clear the stall")
+	}
+}
+
+func TestWatchdogMatch(t *testing.T) {
+	w := NewWatchdog(nil, time.Hour, nil)
+	defer w.Stop()
+	w.Match = func(ev *InputEvent) bool { return ev.Type == EV_ABS }
+	w.stalled = true
+
+	tests := []struct {
+		ev      InputEvent
+		stalled bool
+	}{
+		{NewInputEvent(time.Now(), EV_SYN, SYN_REPORT, 0), true},
+		{NewInputEvent(time.Now(), EV_KEY, BTN_TOUCH, 1), true},
+		{NewInputEvent(time.Now(), EV_ABS, ABS_X, 10), false},
+	}
+	for _, tt := range tests {
+		w.Feed(&tt.ev)
+		if w.Stalled() != tt.stalled {
+			t.Errorf("after %v: stalled %v, want %v", &tt.ev, w.Stalled(), tt.stalled)
+		}
+	}
+}
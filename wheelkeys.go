@@ -0,0 +1,156 @@
+package evdev
+
+import (
+	"sort"
+	"time"
+)
+
+// WheelKeys maps the motion of a relative axis to two keys.
+type WheelKeys struct {
+	Neg, Pos EvCode // keys pressed for negative and positive motion
+	Step     int    // motion per key press, at least 1
+}
+
+// WheelKeyFilter converts wheel or trackball motion into key presses, e.g.
+// for appliances controlled by a knob. Every Step of motion on a mapped axis
+// queues a press of the key for its direction, so that spinning faster
+// presses the key more often. Queued presses are emitted after SYN_REPORTs
+// and by Tick, at most one per Interval; reversing the direction discards
+// the presses still queued. Motion on other axes passes through.
+type WheelKeyFilter struct {
+	Axes       map[EvCode]WheelKeys
+	Interval   time.Duration // minimum time between presses, 0 for none
+	MaxPending int           // presses queued per axis at most, 0 for no limit
+
+	motion  map[EvCode]int // motion towards the next step
+	pending map[EvCode]int // queued presses, negative for Neg
+	next    time.Time      // when the next press may be emitted
+}
+
+// NewWheelKeyFilter creates a filter converting the motion of the given
+// axes into at most one press per interval.
+func NewWheelKeyFilter(interval time.Duration, axes map[EvCode]WheelKeys) *WheelKeyFilter {
+	return &WheelKeyFilter{
+		Axes:       axes,
+		Interval:   interval,
+		MaxPending: 10,
+		motion:     make(map[EvCode]int),
+		pending:    make(map[EvCode]int),
+	}
+}
+
+// WheelArrowKeys maps the vertical and horizontal wheel to the arrow keys,
+// one press per detent.
+func WheelArrowKeys() map[EvCode]WheelKeys {
+	return map[EvCode]WheelKeys{
+		REL_WHEEL:  {Neg: KEY_DOWN, Pos: KEY_UP, Step: 1},
+		REL_HWHEEL: {Neg: KEY_LEFT, Pos: KEY_RIGHT, Step: 1},
+	}
+}
+
+// WheelPageKeys maps the vertical wheel to page up and page down, one press
+// per detent.
+func WheelPageKeys() map[EvCode]WheelKeys {
+	return map[EvCode]WheelKeys{
+		REL_WHEEL: {Neg: KEY_PAGEDOWN, Pos: KEY_PAGEUP, Step: 1},
+	}
+}
+
+// TrackballArrowKeys maps trackball motion to the arrow keys, one press per
+// step units of motion.
+func TrackballArrowKeys(step int) map[EvCode]WheelKeys {
+	return map[EvCode]WheelKeys{
+		REL_X: {Neg: KEY_LEFT, Pos: KEY_RIGHT, Step: step},
+		REL_Y: {Neg: KEY_UP, Pos: KEY_DOWN, Step: step},
+	}
+}
+
+// Hi-res wheel axes, dropped along with the legacy axis they accompany.
+var wheelHiResAxes = map[EvCode]EvCode{
+	REL_WHEEL_HI_RES:  REL_WHEEL,
+	REL_HWHEEL_HI_RES: REL_HWHEEL,
+}
+
+// Process consumes the motion of mapped axes and emits due presses after
+// every SYN_REPORT.
+func (f *WheelKeyFilter) Process(ev InputEvent) []InputEvent {
+	switch {
+	case ev.Type == EV_SYN && ev.Code == SYN_REPORT:
+		return append([]InputEvent{ev}, f.Tick(ev.Timestamp())...)
+	case ev.Type != EV_REL:
+		return []InputEvent{ev}
+	}
+
+	if legacy, ok := wheelHiResAxes[ev.Code]; ok {
+		if _, mapped := f.Axes[legacy]; mapped {
+			return nil
+		}
+	}
+
+	keys, ok := f.Axes[ev.Code]
+	if !ok {
+		return []InputEvent{ev}
+	}
+
+	value := int(ev.Value)
+	if value*f.motion[ev.Code] < 0 || value*f.pending[ev.Code] < 0 {
+		f.motion[ev.Code] = 0
+		f.pending[ev.Code] = 0
+	}
+
+	step := keys.Step
+	if step < 1 {
+		step = 1
+	}
+	f.motion[ev.Code] += value
+	steps := f.motion[ev.Code] / step
+	f.motion[ev.Code] -= steps * step
+
+	pending := f.pending[ev.Code] + steps
+	if f.MaxPending > 0 && pending > f.MaxPending {
+		pending = f.MaxPending
+	} else if f.MaxPending > 0 && pending < -f.MaxPending {
+		pending = -f.MaxPending
+	}
+	f.pending[ev.Code] = pending
+
+	return nil
+}
+
+// Tick emits the queued presses that are due at now.
+func (f *WheelKeyFilter) Tick(now time.Time) []InputEvent {
+	events := make([]InputEvent, 0)
+
+	axes := make([]int, 0, len(f.pending))
+	for axis := range f.pending {
+		axes = append(axes, int(axis))
+	}
+	sort.Ints(axes)
+
+	for _, axis := range axes {
+		code := EvCode(axis)
+		for f.pending[code] != 0 {
+			if now.Before(f.next) {
+				return events
+			}
+
+			key := f.Axes[code].Pos
+			if f.pending[code] > 0 {
+				f.pending[code]--
+			} else {
+				key = f.Axes[code].Neg
+				f.pending[code]++
+			}
+
+			events = append(events,
+				NewInputEvent(now, EV_KEY, key, EvValue(KeyDown)),
+				NewInputEvent(now, EV_SYN, SYN_REPORT, 0),
+				NewInputEvent(now, EV_KEY, key, EvValue(KeyUp)),
+				NewInputEvent(now, EV_SYN, SYN_REPORT, 0),
+			)
+			f.next = now.Add(f.Interval)
+		}
+	}
+
+	return events
+}
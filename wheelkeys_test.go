@@ -0,0 +1,54 @@
+package evdev
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWheelKeyFilter(t *testing.T) {
+	f := NewWheelKeyFilter(100*time.Millisecond, WheelArrowKeys())
+	now := time.Unix(1000, 0)
+
+	presses := func(events []InputEvent) []EvCode {
+		keys := make([]EvCode, 0)
+		for _, ev := range events {
+			if ev.Type == EV_KEY && ev.Value == EvValue(KeyDown) {
+				keys = append(keys, ev.Code)
+			}
+		}
+		return keys
+	}
+
+	// three detents down: one press right away, the others paced by Tick
+	out := f.Process(NewInputEvent(now, EV_REL, REL_WHEEL, -3))
+	out = append(out, f.Process(NewInputEvent(now, EV_REL, REL_WHEEL_HI_RES, -360))...)
+	out = append(out, f.Process(NewInputEvent(now, EV_SYN, SYN_REPORT, 0))...)
+	if keys := presses(out); len(keys) != 1 || keys[0] != KEY_DOWN {
+		t.Fatalf("expected one KEY_DOWN press, got %v", keys)
+	}
+	if out[0].Type != EV_SYN {
+		t.Errorf("expected the wheel motion to be consumed, got %+v", out[0])
+	}
+
+	if keys := presses(f.Tick(now.Add(50 * time.Millisecond))); len(keys) != 0 {
+		t.Errorf("press emitted before the interval: %v", keys)
+	}
+	if keys := presses(f.Tick(now.Add(100 * time.Millisecond))); len(keys) != 1 {
+		t.Errorf("expected one press after the interval, got %v", keys)
+	}
+
+	// reversing discards the remaining down press
+	later := now.Add(time.Second)
+	f.Process(NewInputEvent(later, EV_REL, REL_WHEEL, 1))
+	if keys := presses(f.Process(NewInputEvent(later, EV_SYN, SYN_REPORT, 0))); len(keys) != 1 || keys[0] != KEY_UP {
+		t.Errorf("expected one KEY_UP press, got %v", keys)
+	}
+	if keys := presses(f.Tick(later.Add(time.Second))); len(keys) != 0 {
+		t.Errorf("unexpected presses after reversing: %v", keys)
+	}
+
+	// other motion passes through
+	if out := f.Process(NewInputEvent(later, EV_REL, REL_X, 5)); len(out) != 1 || out[0].Code != REL_X {
+		t.Errorf("REL_X not passed through: %+v", out)
+	}
+}